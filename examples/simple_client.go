@@ -89,6 +89,24 @@ func (c *MyOutstationCallbacks) GetApplicationIIN() types.IIN {
 	return types.IIN{IIN1: 0, IIN2: 0}
 }
 
+func (c *MyOutstationCallbacks) OnTimeSync(t time.Time) {
+	fmt.Printf("Time synchronized: %v\n", t)
+}
+
+func (c *MyOutstationCallbacks) OnEventBufferOverflow(eventType dnp3.EventType) {
+	fmt.Printf("Event buffer overflow: %s events lost\n", eventType)
+}
+
+func (c *MyOutstationCallbacks) OnColdRestart() time.Duration {
+	fmt.Printf("Cold restart requested\n")
+	return 5 * time.Second
+}
+
+func (c *MyOutstationCallbacks) OnWarmRestart() time.Duration {
+	fmt.Printf("Warm restart requested\n")
+	return time.Second
+}
+
 // State tracks measurement values (similar to C++ opendnp3 example)
 type State struct {
 	count            uint32
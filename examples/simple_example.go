@@ -62,6 +62,7 @@ func (c *MyMasterCallbacks) ProcessCounter(info dnp3.HeaderInfo, values []types.
 func (c *MyMasterCallbacks) ProcessFrozenCounter(info dnp3.HeaderInfo, values []types.IndexedFrozenCounter) {}
 func (c *MyMasterCallbacks) ProcessBinaryOutputStatus(info dnp3.HeaderInfo, values []types.IndexedBinaryOutputStatus) {}
 func (c *MyMasterCallbacks) ProcessAnalogOutputStatus(info dnp3.HeaderInfo, values []types.IndexedAnalogOutputStatus) {}
+func (c *MyMasterCallbacks) ProcessOctetString(info dnp3.HeaderInfo, values []types.IndexedOctetString) {}
 
 func (c *MyMasterCallbacks) OnReceiveIIN(iin types.IIN) {
 	fmt.Printf("IIN: [%02X,%02X]\n", iin.IIN1, iin.IIN2)
@@ -153,6 +154,24 @@ func (c *MyOutstationCallbacks) GetApplicationIIN() types.IIN {
 	return types.IIN{IIN1: 0, IIN2: 0}
 }
 
+func (c *MyOutstationCallbacks) OnTimeSync(t time.Time) {
+	fmt.Printf("Time synchronized: %v\n", t)
+}
+
+func (c *MyOutstationCallbacks) OnEventBufferOverflow(eventType dnp3.EventType) {
+	fmt.Printf("Event buffer overflow: %s events lost\n", eventType)
+}
+
+func (c *MyOutstationCallbacks) OnColdRestart() time.Duration {
+	fmt.Printf("Cold restart requested\n")
+	return 5 * time.Second
+}
+
+func (c *MyOutstationCallbacks) OnWarmRestart() time.Duration {
+	fmt.Printf("Warm restart requested\n")
+	return time.Second
+}
+
 // Example usage
 func exampleMaster() {
 	// Create manager
@@ -109,6 +109,12 @@ func (c *MyMasterCallbacks) ProcessAnalogOutputStatus(info dnp3.HeaderInfo, valu
 	fmt.Printf("Received %d analog output status values\n", len(values))
 }
 
+func (c *MyMasterCallbacks) ProcessOctetString(info dnp3.HeaderInfo, values []types.IndexedOctetString) {
+	for _, v := range values {
+		fmt.Printf("Octet string [%d]: %q\n", v.Index, v.Value.Value)
+	}
+}
+
 func (c *MyMasterCallbacks) OnReceiveIIN(iin types.IIN) {
 	// Only print if there are error flags set
 	if iin.IIN1 != 0 || iin.IIN2 != 0 {
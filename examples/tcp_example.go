@@ -254,6 +254,10 @@ func (cb *MasterCallbacks) ProcessAnalogOutputStatus(info dnp3.HeaderInfo, value
 	fmt.Println("Received %d analog output status values", len(values))
 }
 
+func (cb *MasterCallbacks) ProcessOctetString(info dnp3.HeaderInfo, values []types.IndexedOctetString) {
+	fmt.Println("Received %d octet string values", len(values))
+}
+
 func (cb *MasterCallbacks) OnReceiveIIN(iin types.IIN) {
 	fmt.Println("Received IIN: IIN1=0x%02X, IIN2=0x%02X", iin.IIN1, iin.IIN2)
 }
@@ -338,3 +342,21 @@ func (cb *OutstationCallbacks) OnUnsolicitedResponse(success bool, seq uint8) {
 func (cb *OutstationCallbacks) GetApplicationIIN() types.IIN {
 	return types.IIN{IIN1: 0, IIN2: 0}
 }
+
+func (cb *OutstationCallbacks) OnTimeSync(t time.Time) {
+	fmt.Printf("Time synchronized: %v\n", t)
+}
+
+func (cb *OutstationCallbacks) OnEventBufferOverflow(eventType dnp3.EventType) {
+	fmt.Printf("Event buffer overflow: %s events lost\n", eventType)
+}
+
+func (cb *OutstationCallbacks) OnColdRestart() time.Duration {
+	fmt.Println("Cold restart requested")
+	return 5 * time.Second
+}
+
+func (cb *OutstationCallbacks) OnWarmRestart() time.Duration {
+	fmt.Println("Warm restart requested")
+	return time.Second
+}
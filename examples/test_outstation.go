@@ -166,6 +166,24 @@ func (c *MyOutstationCallbacks) GetApplicationIIN() types.IIN {
 	return types.IIN{IIN1: 0, IIN2: 0}
 }
 
+func (c *MyOutstationCallbacks) OnTimeSync(t time.Time) {
+	fmt.Printf("[TIME SYNC] %v\n", t)
+}
+
+func (c *MyOutstationCallbacks) OnEventBufferOverflow(eventType dnp3.EventType) {
+	fmt.Printf("[EVENT OVERFLOW] %s events lost\n", eventType)
+}
+
+func (c *MyOutstationCallbacks) OnColdRestart() time.Duration {
+	fmt.Printf("[RESTART] Cold restart requested\n")
+	return 5 * time.Second
+}
+
+func (c *MyOutstationCallbacks) OnWarmRestart() time.Duration {
+	fmt.Printf("[RESTART] Warm restart requested\n")
+	return time.Second
+}
+
 // Application state for tracking measurements
 type OutstationState struct {
 	binaryCount        uint32
@@ -0,0 +1,139 @@
+package app
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// ErrInvalidAttribute is returned for a device attribute that cannot be encoded or parsed
+var ErrInvalidAttribute = fmt.Errorf("invalid device attribute")
+
+// SerializeDeviceAttribute encodes a device attribute (Group 0) object: the
+// data type code, the value length and the value
+func SerializeDeviceAttribute(attr types.DeviceAttribute) ([]byte, error) {
+	value, ok := encodeAttributeValue(attr)
+	if !ok {
+		return nil, fmt.Errorf("%w: %v value %T for set %d variation %d", ErrInvalidAttribute, attr.Type, attr.Value, attr.Set, attr.Variation)
+	}
+	if len(value) > 255 {
+		return nil, fmt.Errorf("%w: set %d variation %d is %d bytes long", ErrInvalidAttribute, attr.Set, attr.Variation, len(value))
+	}
+
+	return append([]byte{uint8(attr.Type), uint8(len(value))}, value...), nil
+}
+
+// encodeAttributeValue encodes the value of an attribute, reporting whether
+// its Go type matches the data type
+func encodeAttributeValue(attr types.DeviceAttribute) ([]byte, bool) {
+	switch v := attr.Value.(type) {
+	case string:
+		return []byte(v), attr.Type == types.AttributeVisibleString
+	case uint32:
+		return binary.LittleEndian.AppendUint32(nil, v), attr.Type == types.AttributeUnsignedInt
+	case int32:
+		return binary.LittleEndian.AppendUint32(nil, uint32(v)), attr.Type == types.AttributeSignedInt
+	case float64:
+		// Single precision when no precision is lost
+		if float64(float32(v)) == v {
+			return binary.LittleEndian.AppendUint32(nil, math.Float32bits(float32(v))), attr.Type == types.AttributeFloat
+		}
+		return binary.LittleEndian.AppendUint64(nil, math.Float64bits(v)), attr.Type == types.AttributeFloat
+	case []byte:
+		return v, attr.Type == types.AttributeOctetString || attr.Type == types.AttributeBitString
+	case types.DNP3Time:
+		return DNP3Time(v).SerializeTime48(), attr.Type == types.AttributeTime
+	case []types.AttributeListEntry:
+		var value []byte
+		for _, e := range v {
+			var properties uint8
+			if e.Writable {
+				properties = 0x01
+			}
+			value = append(value, e.Variation, properties)
+		}
+		return value, attr.Type == types.AttributeList
+	default:
+		return nil, false
+	}
+}
+
+// ParseDeviceAttribute parses a device attribute object of a set and
+// variation, returning the attribute and the number of bytes consumed
+func ParseDeviceAttribute(set uint16, variation uint8, data []byte) (types.DeviceAttribute, int, error) {
+	if len(data) < 2 || len(data) < 2+int(data[1]) {
+		return types.DeviceAttribute{}, 0, ErrInsufficientData
+	}
+	attr := types.DeviceAttribute{Set: set, Variation: variation, Type: types.AttributeType(data[0])}
+	value := data[2 : 2+int(data[1])]
+	size := 2 + len(value)
+
+	switch attr.Type {
+	case types.AttributeVisibleString:
+		attr.Value = string(value)
+	case types.AttributeUnsignedInt, types.AttributeSignedInt:
+		if len(value) == 0 || len(value) > 4 {
+			return attr, size, fmt.Errorf("%w: %d byte %v", ErrInvalidAttribute, len(value), attr.Type)
+		}
+		buf := make([]byte, 4)
+		copy(buf, value)
+		n := binary.LittleEndian.Uint32(buf)
+		if attr.Type == types.AttributeUnsignedInt {
+			attr.Value = n
+		} else {
+			// Sign-extend shorter values
+			shift := 32 - 8*len(value)
+			attr.Value = int32(n<<shift) >> shift
+		}
+	case types.AttributeFloat:
+		switch len(value) {
+		case 4:
+			attr.Value = float64(math.Float32frombits(binary.LittleEndian.Uint32(value)))
+		case 8:
+			attr.Value = math.Float64frombits(binary.LittleEndian.Uint64(value))
+		default:
+			return attr, size, fmt.Errorf("%w: %d byte FLT", ErrInvalidAttribute, len(value))
+		}
+	case types.AttributeTime:
+		if len(value) != 6 {
+			return attr, size, fmt.Errorf("%w: %d byte TIME", ErrInvalidAttribute, len(value))
+		}
+		attr.Value = types.DNP3Time(ParseTime48(value))
+	case types.AttributeList:
+		entries := make([]types.AttributeListEntry, 0, len(value)/2)
+		for i := 0; i+1 < len(value); i += 2 {
+			entries = append(entries, types.AttributeListEntry{Variation: value[i], Writable: value[i+1]&0x01 != 0})
+		}
+		attr.Value = entries
+	default:
+		// Octet and bit strings, and types this library does not know
+		attr.Value = append([]byte(nil), value...)
+	}
+
+	return attr, size, nil
+}
+
+// ReadDeviceAttribute reads a device attribute object of a set and variation
+func (p *Parser) ReadDeviceAttribute(set uint16, variation uint8) (types.DeviceAttribute, error) {
+	attr, size, err := ParseDeviceAttribute(set, variation, p.data[p.offset:])
+	p.offset += size
+	return attr, err
+}
+
+// BuildDeviceAttributes builds the objects of a device attribute response,
+// one header per attribute with the attribute set as its index
+func BuildDeviceAttributes(attributes []types.DeviceAttribute) ([]byte, error) {
+	builder := NewObjectBuilder()
+	for _, attr := range attributes {
+		data, err := SerializeDeviceAttribute(attr)
+		if err != nil {
+			return nil, err
+		}
+		set := uint32(attr.Set)
+		builder.AddHeader(GroupDeviceAttributes, attr.Variation, StartStopQualifier(set, set), StartStopRange{Start: set, Stop: set})
+		builder.AddRawData(data)
+	}
+	return builder.Build(), nil
+}
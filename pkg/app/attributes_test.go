@@ -0,0 +1,105 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+func TestDeviceAttributeRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		attr types.DeviceAttribute
+		want []byte
+	}{
+		{"visible string", types.StringAttribute(0, DeviceAttributeManufacturerName, "ACME"), []byte{1, 4, 'A', 'C', 'M', 'E'}},
+		{"unsigned int", types.UintAttribute(0, DeviceAttributeNumBinaryInputs, 300), []byte{2, 4, 0x2C, 0x01, 0x00, 0x00}},
+		{"signed int", types.IntAttribute(1, 7, -2), []byte{3, 4, 0xFE, 0xFF, 0xFF, 0xFF}},
+		{"single precision float", types.FloatAttribute(1, 8, 1.5), []byte{4, 4, 0x00, 0x00, 0xC0, 0x3F}},
+		{"double precision float", types.FloatAttribute(1, 9, 0.1), []byte{4, 8, 0x9A, 0x99, 0x99, 0x99, 0x99, 0x99, 0xB9, 0x3F}},
+		{"octet string", types.OctetStringAttribute(2, 1, []byte{0xDE, 0xAD}), []byte{5, 2, 0xDE, 0xAD}},
+		{"time", types.TimeAttribute(2, 2, 0x010203040506), []byte{7, 6, 0x06, 0x05, 0x04, 0x03, 0x02, 0x01}},
+		{"list", types.DeviceAttribute{Variation: DeviceAttributeList, Type: types.AttributeList,
+			Value: []types.AttributeListEntry{{Variation: 240}, {Variation: 247, Writable: true}}}, []byte{254, 4, 240, 0, 247, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := SerializeDeviceAttribute(tt.attr)
+			if err != nil {
+				t.Fatalf("Serialize: %v", err)
+			}
+			if !bytes.Equal(data, tt.want) {
+				t.Errorf("Serialize: got % X, want % X", data, tt.want)
+			}
+
+			got, size, err := ParseDeviceAttribute(tt.attr.Set, tt.attr.Variation, append(data, 0xFF))
+			if err != nil || size != len(data) {
+				t.Fatalf("Parse: got size %d, error %v, want size %d", size, err, len(data))
+			}
+			if !reflect.DeepEqual(got, tt.attr) {
+				t.Errorf("Parse: got %+v, want %+v", got, tt.attr)
+			}
+		})
+	}
+}
+
+func TestParseShortIntegerAttributes(t *testing.T) {
+	if attr, _, _ := ParseDeviceAttribute(0, 1, []byte{3, 1, 0xFF}); attr.Value != int32(-1) {
+		t.Errorf("1 byte INT 0xFF: got %v, want -1", attr.Value)
+	}
+	if attr, _, _ := ParseDeviceAttribute(0, 1, []byte{2, 2, 0x00, 0x80}); attr.Value != uint32(0x8000) {
+		t.Errorf("2 byte UINT: got %v, want 32768", attr.Value)
+	}
+}
+
+func TestDeviceAttributeErrors(t *testing.T) {
+	if _, err := SerializeDeviceAttribute(types.DeviceAttribute{Type: types.AttributeUnsignedInt, Value: "1"}); !errors.Is(err, ErrInvalidAttribute) {
+		t.Errorf("Mismatched type: got %v, want ErrInvalidAttribute", err)
+	}
+	if _, err := SerializeDeviceAttribute(types.StringAttribute(0, 1, string(make([]byte, 256)))); !errors.Is(err, ErrInvalidAttribute) {
+		t.Errorf("256 byte string: got %v, want ErrInvalidAttribute", err)
+	}
+	if _, _, err := ParseDeviceAttribute(0, 1, []byte{1, 5, 'A'}); !errors.Is(err, ErrInsufficientData) {
+		t.Errorf("Truncated value: got %v, want ErrInsufficientData", err)
+	}
+	if _, _, err := ParseDeviceAttribute(0, 1, []byte{7, 4, 0, 0, 0, 0}); !errors.Is(err, ErrInvalidAttribute) {
+		t.Errorf("4 byte TIME: got %v, want ErrInvalidAttribute", err)
+	}
+}
+
+func TestBuildDeviceAttributes(t *testing.T) {
+	data, err := BuildDeviceAttributes([]types.DeviceAttribute{
+		types.StringAttribute(0, DeviceAttributeSerialNumber, "42"),
+		types.UintAttribute(300, 5, 1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []byte{
+		0, 248, 0x00, 0x00, 0x00, 1, 2, '4', '2',
+		0, 5, 0x01, 0x2C, 0x01, 0x2C, 0x01, 2, 4, 1, 0, 0, 0,
+	}
+	if !bytes.Equal(data, expected) {
+		t.Errorf("Device attributes: got % X, want % X", data, expected)
+	}
+
+	parser := NewParser(data)
+	for _, want := range []uint16{0, 300} {
+		header, err := parser.ReadObjectHeader()
+		if err != nil {
+			t.Fatal(err)
+		}
+		attr, err := parser.ReadDeviceAttribute(uint16(header.Range.(StartStopRange).Start), header.Variation)
+		if err != nil || attr.Set != want {
+			t.Errorf("Read back: got set %d, error %v, want set %d", attr.Set, err, want)
+		}
+	}
+	if parser.HasMore() {
+		t.Errorf("%d bytes left after the attributes", parser.Remaining())
+	}
+}
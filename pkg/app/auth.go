@@ -0,0 +1,322 @@
+package app
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// MACAlgorithm identifies the MAC of Secure Authentication
+type MACAlgorithm uint8
+
+const (
+	MACHMACSHA256Trunc8  MACAlgorithm = 4 // HMAC-SHA-256 truncated to 8 octets, for serial links
+	MACHMACSHA256Trunc16 MACAlgorithm = 5 // HMAC-SHA-256 truncated to 16 octets, for networks
+)
+
+// Size returns the length of the MAC value, or 0 for an unsupported algorithm
+func (a MACAlgorithm) Size() int {
+	switch a {
+	case MACHMACSHA256Trunc8:
+		return 8
+	case MACHMACSHA256Trunc16:
+		return 16
+	default:
+		return 0
+	}
+}
+
+// KeyWrapAlgorithm identifies how session keys are wrapped with the update key
+type KeyWrapAlgorithm uint8
+
+const (
+	KeyWrapAES128 KeyWrapAlgorithm = 1 // AES-128 key wrap, 16 octet update key
+	KeyWrapAES256 KeyWrapAlgorithm = 2 // AES-256 key wrap, 32 octet update key
+)
+
+// KeyStatus is the session key status of a user
+type KeyStatus uint8
+
+const (
+	KeyStatusOK       KeyStatus = 1
+	KeyStatusNotInit  KeyStatus = 2
+	KeyStatusCommFail KeyStatus = 3
+	KeyStatusAuthFail KeyStatus = 4
+)
+
+// String returns the name of the key status
+func (s KeyStatus) String() string {
+	switch s {
+	case KeyStatusOK:
+		return "OK"
+	case KeyStatusNotInit:
+		return "NOT_INIT"
+	case KeyStatusCommFail:
+		return "COMM_FAIL"
+	case KeyStatusAuthFail:
+		return "AUTH_FAIL"
+	default:
+		return fmt.Sprintf("KeyStatus(%d)", uint8(s))
+	}
+}
+
+// AuthErrorCode is the reason of an authentication error
+type AuthErrorCode uint8
+
+const (
+	AuthErrorAuthenticationFailed  AuthErrorCode = 1
+	AuthErrorAggressiveUnsupported AuthErrorCode = 4
+	AuthErrorMACUnsupported        AuthErrorCode = 5
+	AuthErrorKeyWrapUnsupported    AuthErrorCode = 6
+	AuthErrorAuthorizationFailed   AuthErrorCode = 7
+	AuthErrorUnknownUser           AuthErrorCode = 11
+)
+
+// String returns the name of the error code
+func (c AuthErrorCode) String() string {
+	switch c {
+	case AuthErrorAuthenticationFailed:
+		return "AUTHENTICATION_FAILED"
+	case AuthErrorAggressiveUnsupported:
+		return "AGGRESSIVE_MODE_NOT_SUPPORTED"
+	case AuthErrorMACUnsupported:
+		return "MAC_NOT_SUPPORTED"
+	case AuthErrorKeyWrapUnsupported:
+		return "KEY_WRAP_NOT_SUPPORTED"
+	case AuthErrorAuthorizationFailed:
+		return "AUTHORIZATION_FAILED"
+	case AuthErrorUnknownUser:
+		return "UNKNOWN_USER"
+	default:
+		return fmt.Sprintf("AuthError(%d)", uint8(c))
+	}
+}
+
+// AuthReasonCritical is the challenge reason of a critical request
+const AuthReasonCritical uint8 = 1
+
+// Fixed part sizes of the authentication objects, before their
+// variable-length data
+const (
+	authChallengeSize  = 8
+	authReplySize      = 6
+	authAggressiveSize = 6
+	authKeyStatusSize  = 11
+	authKeyChangeSize  = 6
+	authErrorSize      = 15
+)
+
+// AuthChallengeObject challenges a critical request (Group 120 Var 1)
+type AuthChallengeObject struct {
+	CSQ    uint32 // Challenge sequence number
+	User   uint16
+	MAC    MACAlgorithm
+	Reason uint8
+	Data   []byte // Pseudo-random challenge data
+}
+
+// Serialize serializes the challenge
+func (c AuthChallengeObject) Serialize() []byte {
+	buf := make([]byte, authChallengeSize, authChallengeSize+len(c.Data))
+	binary.LittleEndian.PutUint32(buf[0:], c.CSQ)
+	binary.LittleEndian.PutUint16(buf[4:], c.User)
+	buf[6] = uint8(c.MAC)
+	buf[7] = c.Reason
+	return append(buf, c.Data...)
+}
+
+// ParseAuthChallenge parses a challenge
+func ParseAuthChallenge(data []byte) (AuthChallengeObject, error) {
+	if len(data) < authChallengeSize {
+		return AuthChallengeObject{}, ErrInsufficientData
+	}
+	return AuthChallengeObject{
+		CSQ:    binary.LittleEndian.Uint32(data[0:]),
+		User:   binary.LittleEndian.Uint16(data[4:]),
+		MAC:    MACAlgorithm(data[6]),
+		Reason: data[7],
+		Data:   append([]byte(nil), data[authChallengeSize:]...),
+	}, nil
+}
+
+// AuthReplyObject answers a challenge with a MAC (Group 120 Var 2)
+type AuthReplyObject struct {
+	CSQ  uint32
+	User uint16
+	MAC  []byte
+}
+
+// Serialize serializes the reply
+func (r AuthReplyObject) Serialize() []byte {
+	buf := make([]byte, authReplySize, authReplySize+len(r.MAC))
+	binary.LittleEndian.PutUint32(buf[0:], r.CSQ)
+	binary.LittleEndian.PutUint16(buf[4:], r.User)
+	return append(buf, r.MAC...)
+}
+
+// ParseAuthReply parses a reply
+func ParseAuthReply(data []byte) (AuthReplyObject, error) {
+	if len(data) < authReplySize {
+		return AuthReplyObject{}, ErrInsufficientData
+	}
+	return AuthReplyObject{
+		CSQ:  binary.LittleEndian.Uint32(data[0:]),
+		User: binary.LittleEndian.Uint16(data[4:]),
+		MAC:  append([]byte(nil), data[authReplySize:]...),
+	}, nil
+}
+
+// AuthAggressiveModeObject leads an aggressive mode request (Group 120 Var 3)
+type AuthAggressiveModeObject struct {
+	CSQ  uint32
+	User uint16
+}
+
+// Serialize serializes the aggressive mode object
+func (a AuthAggressiveModeObject) Serialize() []byte {
+	buf := make([]byte, authAggressiveSize)
+	binary.LittleEndian.PutUint32(buf[0:], a.CSQ)
+	binary.LittleEndian.PutUint16(buf[4:], a.User)
+	return buf
+}
+
+// ParseAuthAggressiveMode parses an aggressive mode object
+func ParseAuthAggressiveMode(data []byte) (AuthAggressiveModeObject, error) {
+	if len(data) < authAggressiveSize {
+		return AuthAggressiveModeObject{}, ErrInsufficientData
+	}
+	return AuthAggressiveModeObject{
+		CSQ:  binary.LittleEndian.Uint32(data[0:]),
+		User: binary.LittleEndian.Uint16(data[4:]),
+	}, nil
+}
+
+// AuthKeyStatusObject reports the session key status of a user (Group 120
+// Var 5). Its challenge data is covered by the next key change.
+type AuthKeyStatusObject struct {
+	KSQ       uint32 // Key change sequence number
+	User      uint16
+	KeyWrap   KeyWrapAlgorithm
+	Status    KeyStatus
+	MAC       MACAlgorithm
+	Challenge []byte
+	MACValue  []byte // MAC of the last key change, with status OK only
+}
+
+// Serialize serializes the key status
+func (k AuthKeyStatusObject) Serialize() []byte {
+	buf := make([]byte, authKeyStatusSize, authKeyStatusSize+len(k.Challenge)+len(k.MACValue))
+	binary.LittleEndian.PutUint32(buf[0:], k.KSQ)
+	binary.LittleEndian.PutUint16(buf[4:], k.User)
+	buf[6] = uint8(k.KeyWrap)
+	buf[7] = uint8(k.Status)
+	buf[8] = uint8(k.MAC)
+	binary.LittleEndian.PutUint16(buf[9:], uint16(len(k.Challenge)))
+	buf = append(buf, k.Challenge...)
+	return append(buf, k.MACValue...)
+}
+
+// ParseAuthKeyStatus parses a key status
+func ParseAuthKeyStatus(data []byte) (AuthKeyStatusObject, error) {
+	if len(data) < authKeyStatusSize {
+		return AuthKeyStatusObject{}, ErrInsufficientData
+	}
+	challengeEnd := authKeyStatusSize + int(binary.LittleEndian.Uint16(data[9:]))
+	if challengeEnd > len(data) {
+		return AuthKeyStatusObject{}, fmt.Errorf("%w: %d octets of challenge data beyond a %d byte object",
+			ErrInsufficientData, challengeEnd-authKeyStatusSize, len(data))
+	}
+	return AuthKeyStatusObject{
+		KSQ:       binary.LittleEndian.Uint32(data[0:]),
+		User:      binary.LittleEndian.Uint16(data[4:]),
+		KeyWrap:   KeyWrapAlgorithm(data[6]),
+		Status:    KeyStatus(data[7]),
+		MAC:       MACAlgorithm(data[8]),
+		Challenge: append([]byte(nil), data[authKeyStatusSize:challengeEnd]...),
+		MACValue:  append([]byte(nil), data[challengeEnd:]...),
+	}, nil
+}
+
+// AuthKeyChangeObject carries new session keys (Group 120 Var 6)
+type AuthKeyChangeObject struct {
+	KSQ         uint32
+	User        uint16
+	WrappedKeys []byte // Keys and the last key status, wrapped with the update key
+}
+
+// Serialize serializes the key change
+func (k AuthKeyChangeObject) Serialize() []byte {
+	buf := make([]byte, authKeyChangeSize, authKeyChangeSize+len(k.WrappedKeys))
+	binary.LittleEndian.PutUint32(buf[0:], k.KSQ)
+	binary.LittleEndian.PutUint16(buf[4:], k.User)
+	return append(buf, k.WrappedKeys...)
+}
+
+// ParseAuthKeyChange parses a key change
+func ParseAuthKeyChange(data []byte) (AuthKeyChangeObject, error) {
+	if len(data) < authKeyChangeSize {
+		return AuthKeyChangeObject{}, ErrInsufficientData
+	}
+	return AuthKeyChangeObject{
+		KSQ:         binary.LittleEndian.Uint32(data[0:]),
+		User:        binary.LittleEndian.Uint16(data[4:]),
+		WrappedKeys: append([]byte(nil), data[authKeyChangeSize:]...),
+	}, nil
+}
+
+// AuthErrorObject reports why a request was not authenticated (Group 120
+// Var 7)
+type AuthErrorObject struct {
+	CSQ         uint32
+	User        uint16
+	Association uint16
+	Code        AuthErrorCode
+	Time        DNP3Time
+	Text        string // Optional description of the error
+}
+
+// Serialize serializes the error
+func (e AuthErrorObject) Serialize() []byte {
+	buf := make([]byte, authErrorSize, authErrorSize+len(e.Text))
+	binary.LittleEndian.PutUint32(buf[0:], e.CSQ)
+	binary.LittleEndian.PutUint16(buf[4:], e.User)
+	binary.LittleEndian.PutUint16(buf[6:], e.Association)
+	buf[8] = uint8(e.Code)
+	copy(buf[9:], e.Time.SerializeTime48())
+	return append(buf, e.Text...)
+}
+
+// ParseAuthError parses an error
+func ParseAuthError(data []byte) (AuthErrorObject, error) {
+	if len(data) < authErrorSize {
+		return AuthErrorObject{}, ErrInsufficientData
+	}
+	return AuthErrorObject{
+		CSQ:         binary.LittleEndian.Uint32(data[0:]),
+		User:        binary.LittleEndian.Uint16(data[4:]),
+		Association: binary.LittleEndian.Uint16(data[6:]),
+		Code:        AuthErrorCode(data[8]),
+		Time:        ParseTime48(data[9:15]),
+		Text:        string(data[authErrorSize:]),
+	}, nil
+}
+
+// BuildAuthObject builds a Group 120 header holding one free-format object
+func BuildAuthObject(variation uint8, object []byte) []byte {
+	return buildFreeFormatObject(GroupAuthentication, variation, object)
+}
+
+// BuildAggressiveMode builds the header that starts an aggressive mode request
+func BuildAggressiveMode(obj AuthAggressiveModeObject) []byte {
+	builder := NewObjectBuilder()
+	builder.AddHeader(GroupAuthentication, AuthAggressiveMode, Qualifier8BitCount, CountRange{Count: 1})
+	builder.AddRawData(obj.Serialize())
+	return builder.Build()
+}
+
+// BuildKeyStatusRequest builds a session key status request for a user
+func BuildKeyStatusRequest(user uint16) []byte {
+	builder := NewObjectBuilder()
+	builder.AddHeader(GroupAuthentication, AuthKeyStatusRequest, Qualifier8BitCount, CountRange{Count: 1})
+	builder.AddUint16(user)
+	return builder.Build()
+}
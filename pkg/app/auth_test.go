@@ -0,0 +1,74 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAuthObjectRoundTrip(t *testing.T) {
+	challenge := AuthChallengeObject{CSQ: 7, User: 1, MAC: MACHMACSHA256Trunc16, Reason: AuthReasonCritical, Data: []byte{1, 2, 3, 4}}
+	if got, err := ParseAuthChallenge(challenge.Serialize()); err != nil || !reflect.DeepEqual(got, challenge) {
+		t.Errorf("Challenge: got %+v, %v, want %+v", got, err, challenge)
+	}
+
+	reply := AuthReplyObject{CSQ: 7, User: 1, MAC: bytes.Repeat([]byte{0xAB}, 16)}
+	if got, err := ParseAuthReply(reply.Serialize()); err != nil || !reflect.DeepEqual(got, reply) {
+		t.Errorf("Reply: got %+v, %v, want %+v", got, err, reply)
+	}
+
+	status := AuthKeyStatusObject{
+		KSQ: 3, User: 1, KeyWrap: KeyWrapAES128, Status: KeyStatusOK, MAC: MACHMACSHA256Trunc8,
+		Challenge: []byte{9, 8, 7}, MACValue: []byte{1, 1, 1, 1, 1, 1, 1, 1},
+	}
+	data := status.Serialize()
+	if data[9] != 3 || data[10] != 0 {
+		t.Errorf("Challenge data length: got % X, want 03 00", data[9:11])
+	}
+	if got, err := ParseAuthKeyStatus(data); err != nil || !reflect.DeepEqual(got, status) {
+		t.Errorf("Key status: got %+v, %v, want %+v", got, err, status)
+	}
+
+	change := AuthKeyChangeObject{KSQ: 3, User: 1, WrappedKeys: make([]byte, 40)}
+	if got, err := ParseAuthKeyChange(change.Serialize()); err != nil || !reflect.DeepEqual(got, change) {
+		t.Errorf("Key change: got %+v, %v, want %+v", got, err, change)
+	}
+
+	authErr := AuthErrorObject{CSQ: 7, User: 2, Code: AuthErrorUnknownUser, Time: 0x010203040506, Text: "no such user"}
+	if got, err := ParseAuthError(authErr.Serialize()); err != nil || got != authErr {
+		t.Errorf("Error: got %+v, %v, want %+v", got, err, authErr)
+	}
+}
+
+func TestAuthObjectErrors(t *testing.T) {
+	if _, err := ParseAuthChallenge(make([]byte, 7)); !errors.Is(err, ErrInsufficientData) {
+		t.Errorf("Short challenge: got %v, want ErrInsufficientData", err)
+	}
+
+	// Challenge data said to run past the end of the object
+	data := AuthKeyStatusObject{Challenge: []byte{1, 2, 3}}.Serialize()
+	if _, err := ParseAuthKeyStatus(data[:len(data)-1]); !errors.Is(err, ErrInsufficientData) {
+		t.Errorf("Truncated key status: got %v, want ErrInsufficientData", err)
+	}
+}
+
+func TestAuthHeaders(t *testing.T) {
+	data := BuildAggressiveMode(AuthAggressiveModeObject{CSQ: 0x01020304, User: 1})
+	expected := []byte{120, 3, 0x07, 1, 0x04, 0x03, 0x02, 0x01, 0x01, 0x00}
+	if !bytes.Equal(data, expected) {
+		t.Errorf("Aggressive mode: got % X, want % X", data, expected)
+	}
+
+	data = BuildKeyStatusRequest(5)
+	expected = []byte{120, 4, 0x07, 1, 0x05, 0x00}
+	if !bytes.Equal(data, expected) {
+		t.Errorf("Key status request: got % X, want % X", data, expected)
+	}
+
+	parser := NewParser(data)
+	header, err := parser.ReadObjectHeader()
+	if err != nil || GetObjectSize(header.Group, header.Variation) != 2 {
+		t.Errorf("Key status request size: got %v, %v, want 2", header, err)
+	}
+}
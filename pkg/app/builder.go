@@ -3,6 +3,8 @@ package app
 import (
 	"bytes"
 	"encoding/binary"
+
+	"avaneesh/dnp3-go/pkg/types"
 )
 
 // ObjectBuilder helps construct object headers and data
@@ -51,6 +53,22 @@ func (b *ObjectBuilder) AddHeader(group, variation uint8, qualifier QualifierCod
 		if r, ok := rng.(CountRange); ok {
 			binary.Write(&b.buf, binary.LittleEndian, uint32(r.Count))
 		}
+	case Qualifier8BitIndexPrefix:
+		if r, ok := rng.(IndexPrefixRange); ok {
+			b.buf.WriteByte(uint8(r.Count))
+		}
+	case Qualifier16BitIndexPrefix:
+		if r, ok := rng.(IndexPrefixRange); ok {
+			binary.Write(&b.buf, binary.LittleEndian, uint16(r.Count))
+		}
+	case Qualifier32BitIndexPrefix:
+		if r, ok := rng.(IndexPrefixRange); ok {
+			binary.Write(&b.buf, binary.LittleEndian, uint32(r.Count))
+		}
+	case QualifierFreeFormat:
+		if r, ok := rng.(FreeFormatRange); ok {
+			b.buf.WriteByte(uint8(r.Count))
+		}
 	case QualifierNoRange:
 		// No range to write
 	}
@@ -72,6 +90,18 @@ func (b *ObjectBuilder) AddRawData(data []byte) {
 	b.buf.Write(data)
 }
 
+// AddIndex adds an object index prefix of the given size (1, 2 or 4 bytes)
+func (b *ObjectBuilder) AddIndex(size int, index uint32) {
+	switch size {
+	case 1:
+		b.buf.WriteByte(uint8(index))
+	case 2:
+		binary.Write(&b.buf, binary.LittleEndian, uint16(index))
+	case 4:
+		binary.Write(&b.buf, binary.LittleEndian, index)
+	}
+}
+
 // AddByte adds a single byte
 func (b *ObjectBuilder) AddByte(val uint8) {
 	b.buf.WriteByte(val)
@@ -189,17 +219,133 @@ func BuildDisableUnsolicited(classes ...ClassField) []byte {
 // BuildRangeRead builds a read request for specific object range
 func BuildRangeRead(group, variation uint8, start, stop uint32) []byte {
 	builder := NewObjectBuilder()
+	builder.AddHeader(group, variation, StartStopQualifier(start, stop), StartStopRange{Start: start, Stop: stop})
+	return builder.Build()
+}
+
+// BuildAllObjects builds a header selecting all points of a group (qualifier 0x06)
+func BuildAllObjects(group, variation uint8) []byte {
+	builder := NewObjectBuilder()
+	builder.AddHeader(group, variation, QualifierNoRange, NoRange{})
+	return builder.Build()
+}
+
+// BuildAssignClass builds the objects of an ASSIGN CLASS request: a G60
+// header for the class (0 removes the points from event reporting) followed
+// by the range of points of a static group
+func BuildAssignClass(class, group uint8, start, stop uint32) []byte {
+	builder := NewObjectBuilder()
+	builder.AddHeader(GroupClass0Data, class+1, QualifierNoRange, NoRange{})
+	builder.AddHeader(group, 0, StartStopQualifier(start, stop), StartStopRange{Start: start, Stop: stop})
+	return builder.Build()
+}
+
+// BuildAnalogDeadbands builds index-prefixed analog input deadbands (Group 34)
+// of the given variation, as written by the master
+func BuildAnalogDeadbands(variation uint8, deadbands []types.IndexedDeadband) []byte {
+	var maxIndex uint32
+	for _, d := range deadbands {
+		if uint32(d.Index) > maxIndex {
+			maxIndex = uint32(d.Index)
+		}
+	}
+
+	builder := NewObjectBuilder()
+	qualifier, indexSize := IndexPrefixQualifier(uint32(len(deadbands)), maxIndex)
+	builder.AddHeader(GroupAnalogInputDeadband, variation, qualifier, IndexPrefixRange{Count: uint32(len(deadbands)), IndexSize: indexSize})
+	for _, d := range deadbands {
+		builder.AddIndex(indexSize, uint32(d.Index))
+		builder.AddRawData(AnalogDeadband{Value: d.Value}.Serialize(variation))
+	}
+	return builder.Build()
+}
+
+// BuildOctetStrings builds index-prefixed octet strings (Group 110) as
+// written by the master, with one header per run of strings of equal length
+func BuildOctetStrings(values []types.IndexedOctetString) []byte {
+	builder := NewObjectBuilder()
+	for start := 0; start < len(values); {
+		length := len(values[start].Value.Value)
+		end := start + 1
+		for end < len(values) && len(values[end].Value.Value) == length {
+			end++
+		}
+
+		run := values[start:end]
+		var maxIndex uint32
+		for _, v := range run {
+			if uint32(v.Index) > maxIndex {
+				maxIndex = uint32(v.Index)
+			}
+		}
+		qualifier, indexSize := IndexPrefixQualifier(uint32(len(run)), maxIndex)
+		builder.AddHeader(GroupOctetString, uint8(length), qualifier, IndexPrefixRange{Count: uint32(len(run)), IndexSize: indexSize})
+		for _, v := range run {
+			builder.AddIndex(indexSize, uint32(v.Index))
+			builder.AddRawData(v.Value.Value)
+		}
+		start = end
+	}
+	return builder.Build()
+}
+
+// BuildClearRestartIIN builds a G80V1 write clearing IIN1.7 (device restart)
+func BuildClearRestartIIN() []byte {
+	builder := NewObjectBuilder()
+	builder.AddHeader(GroupInternalIndications, 1, StartStopQualifier(7, 7), StartStopRange{Start: 7, Stop: 7})
+	builder.AddRawData([]byte{0x00})
+	return builder.Build()
+}
+
+// BuildIndexRead builds a read request for a list of point indices
+func BuildIndexRead(group, variation uint8, indices []uint32) []byte {
+	var maxIndex uint32
+	for _, index := range indices {
+		if index > maxIndex {
+			maxIndex = index
+		}
+	}
+
+	builder := NewObjectBuilder()
+	qualifier, indexSize := IndexPrefixQualifier(uint32(len(indices)), maxIndex)
+	builder.AddHeader(group, variation, qualifier, IndexPrefixRange{Count: uint32(len(indices)), IndexSize: indexSize})
+	for _, index := range indices {
+		builder.AddIndex(indexSize, index)
+	}
+	return builder.Build()
+}
 
-	// Choose appropriate qualifier based on range size
-	var qualifier QualifierCode
-	if stop <= 255 && start <= 255 {
-		qualifier = Qualifier8BitStartStop
-	} else if stop <= 65535 && start <= 65535 {
-		qualifier = Qualifier16BitStartStop
-	} else {
-		qualifier = Qualifier32BitStartStop
+// StartStopQualifier returns the smallest start-stop qualifier able to encode the range
+func StartStopQualifier(start, stop uint32) QualifierCode {
+	switch {
+	case start <= 0xFF && stop <= 0xFF:
+		return Qualifier8BitStartStop
+	case start <= 0xFFFF && stop <= 0xFFFF:
+		return Qualifier16BitStartStop
+	default:
+		return Qualifier32BitStartStop
 	}
+}
 
-	builder.AddHeader(group, variation, qualifier, StartStopRange{Start: start, Stop: stop})
+// IndexPrefixQualifier returns the smallest index-prefixed qualifier able to
+// encode count objects with indices up to maxIndex, and its index size in bytes
+func IndexPrefixQualifier(count, maxIndex uint32) (QualifierCode, int) {
+	switch {
+	case count <= 0xFF && maxIndex <= 0xFF:
+		return Qualifier8BitIndexPrefix, 1
+	case count <= 0xFFFF && maxIndex <= 0xFFFF:
+		return Qualifier16BitIndexPrefix, 2
+	default:
+		return Qualifier32BitIndexPrefix, 4
+	}
+}
+
+// buildFreeFormatObject builds a free-format header (qualifier 0x5B)
+// holding one object preceded by its size
+func buildFreeFormatObject(group, variation uint8, object []byte) []byte {
+	builder := NewObjectBuilder()
+	builder.AddHeader(group, variation, QualifierFreeFormat, FreeFormatRange{Count: 1})
+	builder.AddUint16(uint16(len(object)))
+	builder.AddRawData(object)
 	return builder.Build()
 }
@@ -1,7 +1,10 @@
 package app
 
 import (
+	"bytes"
 	"testing"
+
+	"avaneesh/dnp3-go/pkg/types"
 )
 
 func TestObjectBuilder(t *testing.T) {
@@ -182,3 +185,90 @@ func TestBuilderReset(t *testing.T) {
 		t.Error("Reset did not clear previous data - variations should differ")
 	}
 }
+
+func TestObjectBuilderIndexPrefix(t *testing.T) {
+	builder := NewObjectBuilder()
+	builder.AddHeader(GroupBinaryOutputCommand, 1, Qualifier16BitIndexPrefix, IndexPrefixRange{Count: 2, IndexSize: 2})
+	builder.AddIndex(2, 5)
+	builder.AddRawData(make([]byte, 11))
+	builder.AddIndex(2, 300)
+	builder.AddRawData(make([]byte, 11))
+
+	parser := NewParser(builder.Build())
+	header, err := parser.ReadObjectHeader()
+	if err != nil {
+		t.Fatalf("ReadObjectHeader failed: %v", err)
+	}
+
+	r, ok := header.Range.(IndexPrefixRange)
+	if !ok {
+		t.Fatalf("Range type: got %T, want IndexPrefixRange", header.Range)
+	}
+	if r.Count != 2 || r.IndexSize != 2 {
+		t.Errorf("Range: got %+v, want {Count:2 IndexSize:2}", r)
+	}
+
+	for _, want := range []uint32{5, 300} {
+		index, err := parser.ReadIndex(r.IndexSize)
+		if err != nil {
+			t.Fatalf("ReadIndex failed: %v", err)
+		}
+		if index != want {
+			t.Errorf("Index: got %d, want %d", index, want)
+		}
+		parser.Skip(11)
+	}
+
+	if parser.HasMore() {
+		t.Errorf("Unexpected %d trailing bytes", parser.Remaining())
+	}
+}
+
+func TestQualifierSelection(t *testing.T) {
+	tests := []struct {
+		start, stop uint32
+		want        QualifierCode
+	}{
+		{0, 255, Qualifier8BitStartStop},
+		{0, 256, Qualifier16BitStartStop},
+		{0, 65536, Qualifier32BitStartStop},
+	}
+	for _, tt := range tests {
+		if got := StartStopQualifier(tt.start, tt.stop); got != tt.want {
+			t.Errorf("StartStopQualifier(%d, %d): got 0x%02X, want 0x%02X", tt.start, tt.stop, got, tt.want)
+		}
+	}
+
+	if q, size := IndexPrefixQualifier(2, 255); q != Qualifier8BitIndexPrefix || size != 1 {
+		t.Errorf("IndexPrefixQualifier(2, 255): got 0x%02X/%d, want 0x17/1", q, size)
+	}
+	if q, size := IndexPrefixQualifier(256, 10); q != Qualifier16BitIndexPrefix || size != 2 {
+		t.Errorf("IndexPrefixQualifier(256, 10): got 0x%02X/%d, want 0x28/2", q, size)
+	}
+}
+
+func TestBuildIndexRead(t *testing.T) {
+	data := BuildIndexRead(GroupAnalogInput, 0, []uint32{7, 1000})
+
+	expected := []byte{30, 0, 0x28, 0x02, 0x00, 0x07, 0x00, 0xE8, 0x03}
+	if !bytes.Equal(data, expected) {
+		t.Errorf("Index read: got % X, want % X", data, expected)
+	}
+}
+
+func TestBuildOctetStrings(t *testing.T) {
+	data := BuildOctetStrings([]types.IndexedOctetString{
+		{Index: 0, Value: types.OctetString{Value: []byte("AB")}},
+		{Index: 4, Value: types.OctetString{Value: []byte("CD")}},
+		{Index: 300, Value: types.OctetString{Value: []byte("XYZ")}},
+	})
+
+	// One header per run of equal lengths, with the length as the variation
+	expected := []byte{
+		110, 2, 0x17, 0x02, 0x00, 'A', 'B', 0x04, 'C', 'D',
+		110, 3, 0x28, 0x01, 0x00, 0x2C, 0x01, 'X', 'Y', 'Z',
+	}
+	if !bytes.Equal(data, expected) {
+		t.Errorf("Octet strings: got % X, want % X", data, expected)
+	}
+}
@@ -2,9 +2,17 @@ package app
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"math"
+
+	"avaneesh/dnp3-go/pkg/types"
 )
 
+// ErrUnsupportedCommand is returned for command data that is neither a CROB
+// nor an analog output
+var ErrUnsupportedCommand = errors.New("unsupported command")
+
 // Control codes for CROB
 const (
 	ControlCodeNUL           uint8 = 0x00 // No operation
@@ -185,6 +193,14 @@ func NewAnalogOutputBlockFloat(value float32) AnalogOutputBlock {
 	}
 }
 
+// NewAnalogOutputBlockDouble creates an analog output block with float64 value
+func NewAnalogOutputBlockDouble(value float64) AnalogOutputBlock {
+	return AnalogOutputBlock{
+		Value:  value,
+		Status: 0,
+	}
+}
+
 // SerializeInt32 serializes as 32-bit integer (Group 41, Var 1)
 func (a AnalogOutputBlock) SerializeInt32() []byte {
 	buf := make([]byte, 5)
@@ -222,11 +238,25 @@ func (a AnalogOutputBlock) SerializeFloat() []byte {
 		val = v
 	}
 
-	binary.LittleEndian.PutUint32(buf[0:], uint32(val))
+	binary.LittleEndian.PutUint32(buf[0:], math.Float32bits(val))
 	buf[4] = a.Status
 	return buf
 }
 
+// SerializeDouble serializes as float64 (Group 41, Var 4)
+func (a AnalogOutputBlock) SerializeDouble() []byte {
+	buf := make([]byte, 9)
+
+	var val float64
+	if v, ok := a.Value.(float64); ok {
+		val = v
+	}
+
+	binary.LittleEndian.PutUint64(buf[0:], math.Float64bits(val))
+	buf[8] = a.Status
+	return buf
+}
+
 // ParseAnalogOutputInt32 parses 32-bit analog output command
 func ParseAnalogOutputInt32(data []byte) (AnalogOutputBlock, error) {
 	if len(data) < 5 {
@@ -251,6 +281,30 @@ func ParseAnalogOutputInt16(data []byte) (AnalogOutputBlock, error) {
 	}, nil
 }
 
+// ParseAnalogOutputFloat parses float32 analog output command
+func ParseAnalogOutputFloat(data []byte) (AnalogOutputBlock, error) {
+	if len(data) < 5 {
+		return AnalogOutputBlock{}, fmt.Errorf("analog output data too short: %d bytes", len(data))
+	}
+
+	return AnalogOutputBlock{
+		Value:  math.Float32frombits(binary.LittleEndian.Uint32(data[0:])),
+		Status: data[4],
+	}, nil
+}
+
+// ParseAnalogOutputDouble parses float64 analog output command
+func ParseAnalogOutputDouble(data []byte) (AnalogOutputBlock, error) {
+	if len(data) < 9 {
+		return AnalogOutputBlock{}, fmt.Errorf("analog output data too short: %d bytes", len(data))
+	}
+
+	return AnalogOutputBlock{
+		Value:  math.Float64frombits(binary.LittleEndian.Uint64(data[0:])),
+		Status: data[8],
+	}, nil
+}
+
 // BuildCROBRequest builds a CROB control request for a specific point
 func BuildCROBRequest(index uint16, crob CROB) []byte {
 	builder := NewObjectBuilder()
@@ -279,3 +333,89 @@ func BuildAnalogOutputRequest(index uint16, value int32) []byte {
 
 	return builder.Build()
 }
+
+// BuildCommands builds the objects of a SELECT, OPERATE or DIRECT OPERATE
+// request: CROBs (G12V1) and analog outputs (G41V1-4) in the order given,
+// with one index-prefixed header per run of commands of the same object
+// type. The outstation echoes them in this order.
+func BuildCommands(commands []types.Command) ([]byte, error) {
+	builder := NewObjectBuilder()
+	for start := 0; start < len(commands); {
+		group, variation, err := commandObject(commands[start])
+		if err != nil {
+			return nil, err
+		}
+
+		end := start + 1
+		maxIndex := uint32(commands[start].Index)
+		for ; end < len(commands); end++ {
+			g, v, err := commandObject(commands[end])
+			if err != nil {
+				return nil, err
+			}
+			if g != group || v != variation {
+				break
+			}
+			if uint32(commands[end].Index) > maxIndex {
+				maxIndex = uint32(commands[end].Index)
+			}
+		}
+
+		run := commands[start:end]
+		qualifier, indexSize := IndexPrefixQualifier(uint32(len(run)), maxIndex)
+		builder.AddHeader(group, variation, qualifier, IndexPrefixRange{Count: uint32(len(run)), IndexSize: indexSize})
+		for _, cmd := range run {
+			builder.AddIndex(indexSize, uint32(cmd.Index))
+			builder.AddRawData(serializeCommand(cmd))
+		}
+		start = end
+	}
+	return builder.Build(), nil
+}
+
+// commandObject returns the group and variation carrying a command
+func commandObject(cmd types.Command) (uint8, uint8, error) {
+	switch cmd.Data.(type) {
+	case types.CROB:
+		return GroupBinaryOutputCommand, 1, nil
+	case types.AnalogOutputInt32:
+		return GroupAnalogOutputCommand, 1, nil
+	case types.AnalogOutputInt16:
+		return GroupAnalogOutputCommand, 2, nil
+	case types.AnalogOutputFloat32:
+		return GroupAnalogOutputCommand, 3, nil
+	case types.AnalogOutputDouble64:
+		return GroupAnalogOutputCommand, 4, nil
+	}
+	return 0, 0, fmt.Errorf("%w: %T at index %d", ErrUnsupportedCommand, cmd.Data, cmd.Index)
+}
+
+// serializeCommand serializes a command whose data commandObject accepted
+func serializeCommand(cmd types.Command) []byte {
+	switch data := cmd.Data.(type) {
+	case types.CROB:
+		var crob CROB
+		switch data.OpType {
+		case types.ControlCodeLatchOn:
+			crob = NewLatchOn()
+		case types.ControlCodeLatchOff:
+			crob = NewLatchOff()
+		case types.ControlCodePulseOn:
+			crob = NewPulseOn(data.OnTimeMs)
+		case types.ControlCodePulseOff:
+			crob = NewPulseOff(data.OffTimeMs)
+		default:
+			crob = NewCROB(uint8(data.OpType), data.Count, data.OnTimeMs, data.OffTimeMs)
+		}
+		return crob.Serialize()
+	case types.AnalogOutputInt32:
+		return NewAnalogOutputBlockInt32(data.Value).SerializeInt32()
+	case types.AnalogOutputInt16:
+		return NewAnalogOutputBlockInt16(data.Value).SerializeInt16()
+	case types.AnalogOutputFloat32:
+		return NewAnalogOutputBlockFloat(data.Value).SerializeFloat()
+	case types.AnalogOutputDouble64:
+		return NewAnalogOutputBlockDouble(data.Value).SerializeDouble()
+	}
+	return nil
+}
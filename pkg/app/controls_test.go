@@ -1,7 +1,10 @@
 package app
 
 import (
+	"errors"
 	"testing"
+
+	"avaneesh/dnp3-go/pkg/types"
 )
 
 func TestCROBSerialize(t *testing.T) {
@@ -131,6 +134,36 @@ func TestAnalogOutputInt16(t *testing.T) {
 	}
 }
 
+func TestAnalogOutputFloatDouble(t *testing.T) {
+	floatData := []byte{0x00, 0x00, 0x20, 0x41, 0x04} // 10.0, status 4
+	parsed, err := ParseAnalogOutputFloat(floatData)
+	if err != nil {
+		t.Fatalf("Parse float failed: %v", err)
+	}
+	if val, ok := parsed.Value.(float32); !ok || val != 10.0 {
+		t.Errorf("Float value: got %v, want 10", parsed.Value)
+	}
+	if parsed.Status != 0x04 {
+		t.Errorf("Float status: got %d, want 4", parsed.Status)
+	}
+
+	doubleData := []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x14, 0xC0, 0x00} // -5.0
+	parsed, err = ParseAnalogOutputDouble(doubleData)
+	if err != nil {
+		t.Fatalf("Parse double failed: %v", err)
+	}
+	if val, ok := parsed.Value.(float64); !ok || val != -5.0 {
+		t.Errorf("Double value: got %v, want -5", parsed.Value)
+	}
+
+	if _, err := ParseAnalogOutputFloat(floatData[:4]); err == nil {
+		t.Error("Expected error for short float data")
+	}
+	if _, err := ParseAnalogOutputDouble(doubleData[:8]); err == nil {
+		t.Error("Expected error for short double data")
+	}
+}
+
 func TestBuildCROBRequest(t *testing.T) {
 	crob := NewLatchOn()
 	data := BuildCROBRequest(5, crob)
@@ -165,3 +198,78 @@ func TestBuildAnalogOutputRequest(t *testing.T) {
 		t.Errorf("Group: got %d, want %d", data[0], GroupAnalogOutputCommand)
 	}
 }
+
+func TestAnalogOutputFloatDoubleRoundTrip(t *testing.T) {
+	data := NewAnalogOutputBlockFloat(12.5).SerializeFloat()
+	parsed, err := ParseAnalogOutputFloat(data)
+	if err != nil {
+		t.Fatalf("Parse float failed: %v", err)
+	}
+	if val, ok := parsed.Value.(float32); !ok || val != 12.5 {
+		t.Errorf("Float value: got %v, want 12.5", parsed.Value)
+	}
+
+	data = NewAnalogOutputBlockDouble(-0.25).SerializeDouble()
+	if len(data) != 9 {
+		t.Fatalf("Expected 9 bytes, got %d", len(data))
+	}
+	parsed, err = ParseAnalogOutputDouble(data)
+	if err != nil {
+		t.Fatalf("Parse double failed: %v", err)
+	}
+	if val, ok := parsed.Value.(float64); !ok || val != -0.25 {
+		t.Errorf("Double value: got %v, want -0.25", parsed.Value)
+	}
+}
+
+func TestBuildCommands(t *testing.T) {
+	commands := []types.Command{
+		{Index: 1, Data: types.AnalogOutputFloat32{Value: 50}},
+		{Index: 300, Data: types.AnalogOutputFloat32{Value: 60}},
+		{Index: 2, Data: types.CROB{OpType: types.ControlCodeLatchOn}},
+		{Index: 3, Data: types.AnalogOutputInt16{Value: -7}},
+	}
+	data, err := BuildCommands(commands)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		group, variation uint8
+		qualifier        QualifierCode
+		indices          []uint32
+	}{
+		{GroupAnalogOutputCommand, 3, Qualifier16BitIndexPrefix, []uint32{1, 300}},
+		{GroupBinaryOutputCommand, 1, Qualifier8BitIndexPrefix, []uint32{2}},
+		{GroupAnalogOutputCommand, 2, Qualifier8BitIndexPrefix, []uint32{3}},
+	}
+
+	parser := NewParser(data)
+	for _, w := range want {
+		header, err := parser.ReadObjectHeader()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.Group != w.group || header.Variation != w.variation || header.Qualifier != w.qualifier {
+			t.Fatalf("Header: got G%dV%d q=0x%02X, want G%dV%d q=0x%02X",
+				header.Group, header.Variation, header.Qualifier, w.group, w.variation, w.qualifier)
+		}
+		r := header.Range.(IndexPrefixRange)
+		for _, index := range w.indices {
+			got, err := parser.ReadIndex(r.IndexSize)
+			if err != nil || got != index {
+				t.Fatalf("Index: got %d (%v), want %d", got, err, index)
+			}
+			if _, err := parser.ReadBytes(GetObjectSize(w.group, w.variation)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if parser.HasMore() {
+		t.Errorf("%d unexpected trailing bytes", parser.Remaining())
+	}
+
+	if _, err := BuildCommands([]types.Command{{Index: 1, Data: 42}}); !errors.Is(err, ErrUnsupportedCommand) {
+		t.Errorf("Unsupported command data: got %v, want ErrUnsupportedCommand", err)
+	}
+}
@@ -207,6 +207,24 @@ func (a AnalogInput) SerializeDouble() []byte {
 	return buf
 }
 
+// Serialize serializes analog input using the given Group 30 variation
+func (a AnalogInput) Serialize(variation uint8) []byte {
+	switch variation {
+	case AnalogInput32Bit:
+		return a.Serialize32Bit()
+	case AnalogInput16Bit:
+		return a.Serialize16Bit()
+	case AnalogInput32BitNoFlag:
+		return a.Serialize32Bit()[1:]
+	case AnalogInput16BitNoFlag:
+		return a.Serialize16Bit()[1:]
+	case AnalogInputDouble:
+		return a.SerializeDouble()
+	default:
+		return a.SerializeFloat()
+	}
+}
+
 // ParseAnalogInput32Bit parses 32-bit analog input with flag
 func ParseAnalogInput32Bit(data []byte) AnalogInput {
 	if len(data) < 5 {
@@ -269,18 +287,83 @@ func NewAnalogInputEventInt32(value int32, timestamp uint64) AnalogInputEvent {
 
 // SerializeInt32WithTime serializes as 32-bit integer with time (Group 32, Var 3)
 func (e AnalogInputEvent) SerializeInt32WithTime() []byte {
-	buf := make([]byte, 11)
-	buf[0] = e.Flags
-
-	var val int32
-	if v, ok := e.Value.(int32); ok {
-		val = v
+	return e.Serialize(AnalogInputEvent32BitWithTime)
+}
+
+// Serialize serializes the event using the given Group 32 variation
+func (e AnalogInputEvent) Serialize(variation uint8) []byte {
+	ai := AnalogInput{Value: e.Value, Flags: e.Flags}
+
+	var buf []byte
+	withTime := false
+	switch variation {
+	case AnalogInputEvent16BitNoTime:
+		buf = ai.Serialize16Bit()
+	case AnalogInputEvent32BitWithTime:
+		buf, withTime = ai.Serialize32Bit(), true
+	case AnalogInputEvent16BitWithTime:
+		buf, withTime = ai.Serialize16Bit(), true
+	case AnalogInputEventFloatNoTime:
+		buf = ai.SerializeFloat()
+	case AnalogInputEventDoubleNoTime:
+		buf = ai.SerializeDouble()
+	case AnalogInputEventFloatWithTime:
+		buf, withTime = ai.SerializeFloat(), true
+	case AnalogInputEventDoubleWithTime:
+		buf, withTime = ai.SerializeDouble(), true
+	default:
+		buf = ai.Serialize32Bit()
+	}
+
+	if withTime {
+		buf = append(buf, DNP3Time(e.Timestamp).SerializeTime48()...)
 	}
-	binary.LittleEndian.PutUint32(buf[1:], uint32(val))
+	return buf
+}
 
-	// DNP3 timestamp is 48-bit (6 bytes)
-	binary.LittleEndian.PutUint64(buf[5:], e.Timestamp)
-	return buf[:11]
+// AnalogDeadband represents an analog input reporting deadband (Group 34)
+type AnalogDeadband struct {
+	Value float64
+}
+
+// Serialize serializes the deadband using the given Group 34 variation.
+// Integer variations are rounded and limited to their range.
+func (d AnalogDeadband) Serialize(variation uint8) []byte {
+	switch variation {
+	case AnalogDeadband16Bit:
+		buf := make([]byte, 2)
+		binary.LittleEndian.PutUint16(buf, uint16(math.Min(math.Max(math.Round(d.Value), 0), math.MaxUint16)))
+		return buf
+	case AnalogDeadband32Bit:
+		buf := make([]byte, 4)
+		binary.LittleEndian.PutUint32(buf, uint32(math.Min(math.Max(math.Round(d.Value), 0), math.MaxUint32)))
+		return buf
+	default:
+		buf := make([]byte, 4)
+		binary.LittleEndian.PutUint32(buf, math.Float32bits(float32(d.Value)))
+		return buf
+	}
+}
+
+// ParseAnalogDeadband parses a deadband of the given Group 34 variation
+func ParseAnalogDeadband(variation uint8, data []byte) AnalogDeadband {
+	switch variation {
+	case AnalogDeadband16Bit:
+		if len(data) < 2 {
+			return AnalogDeadband{}
+		}
+		return AnalogDeadband{Value: float64(binary.LittleEndian.Uint16(data))}
+	case AnalogDeadband32Bit:
+		if len(data) < 4 {
+			return AnalogDeadband{}
+		}
+		return AnalogDeadband{Value: float64(binary.LittleEndian.Uint32(data))}
+	default:
+		if len(data) < 4 {
+			return AnalogDeadband{}
+		}
+		return AnalogDeadband{Value: float64(math.Float32frombits(binary.LittleEndian.Uint32(data)))}
+	}
 }
 
 // Counter represents a counter data point (Group 20)
@@ -313,6 +396,20 @@ func (c Counter) Serialize16Bit() []byte {
 	return buf
 }
 
+// Serialize serializes counter using the given Group 20 variation
+func (c Counter) Serialize(variation uint8) []byte {
+	switch variation {
+	case Counter16BitWithFlag:
+		return c.Serialize16Bit()
+	case Counter32Bit:
+		return c.Serialize32Bit()[1:]
+	case Counter16Bit:
+		return c.Serialize16Bit()[1:]
+	default:
+		return c.Serialize32Bit()
+	}
+}
+
 // ParseCounter32Bit parses 32-bit counter with flag
 func ParseCounter32Bit(data []byte) Counter {
 	if len(data) < 5 {
@@ -335,6 +432,29 @@ func ParseCounter16Bit(data []byte) Counter {
 	}
 }
 
+// CounterEvent represents a counter change event (Group 22)
+type CounterEvent struct {
+	Value     uint32 // Counter value
+	Flags     uint8  // Status flags
+	Timestamp uint64 // DNP3 time (ms since epoch), optional
+}
+
+// Serialize serializes the event using the given Group 22 variation
+func (e CounterEvent) Serialize(variation uint8) []byte {
+	c := Counter{Value: e.Value, Flags: e.Flags}
+
+	switch variation {
+	case CounterEvent16BitWithFlag:
+		return c.Serialize16Bit()
+	case CounterEvent32BitWithFlagTime:
+		return append(c.Serialize32Bit(), DNP3Time(e.Timestamp).SerializeTime48()...)
+	case CounterEvent16BitWithFlagTime:
+		return append(c.Serialize16Bit(), DNP3Time(e.Timestamp).SerializeTime48()...)
+	default:
+		return c.Serialize32Bit()
+	}
+}
+
 // BinaryOutput represents a binary output status (Group 10)
 type BinaryOutput struct {
 	Value bool  // Output state
@@ -393,3 +513,178 @@ func (a AnalogOutputStatus) Serialize32Bit() []byte {
 	binary.LittleEndian.PutUint32(buf[1:], uint32(val))
 	return buf
 }
+
+// Serialize serializes analog output status using the given Group 40 variation
+func (a AnalogOutputStatus) Serialize(variation uint8) []byte {
+	// Group 40 shares its encodings with the flagged Group 30 variations
+	ai := AnalogInput{Value: a.Value, Flags: a.Flags}
+
+	switch variation {
+	case AnalogOutputStatus16Bit:
+		return ai.Serialize16Bit()
+	case AnalogOutputStatusFloat:
+		return ai.SerializeFloat()
+	case AnalogOutputStatusDouble:
+		return ai.SerializeDouble()
+	default:
+		return ai.Serialize32Bit()
+	}
+}
+
+// AnalogOutputEvent represents an analog output status change event (Group 42)
+type AnalogOutputEvent struct {
+	Value     interface{} // int16, int32, float32, or float64
+	Flags     uint8       // Status flags
+	Timestamp uint64      // DNP3 time (ms since epoch), optional
+}
+
+// Serialize serializes the event using the given Group 42 variation
+func (e AnalogOutputEvent) Serialize(variation uint8) []byte {
+	// Group 42 variations are numbered and encoded like Group 32
+	return AnalogInputEvent{Value: e.Value, Flags: e.Flags, Timestamp: e.Timestamp}.Serialize(variation)
+}
+
+// Double-bit binary states carried in the top two bits of the flags octet
+const (
+	DoubleBitIntermediate  uint8 = 0
+	DoubleBitOff           uint8 = 1
+	DoubleBitOn            uint8 = 2
+	DoubleBitIndeterminate uint8 = 3
+
+	doubleBitStateShift = 6
+	doubleBitFlagsMask  = 0x3F
+)
+
+// DoubleBitBinaryInput represents a double-bit binary input (Group 3)
+type DoubleBitBinaryInput struct {
+	Value uint8 // Double-bit state (0-3)
+	Flags uint8 // Status flags (lower 6 bits)
+}
+
+// Serialize serializes double-bit binary input with flags (Group 3, Var 2)
+func (d DoubleBitBinaryInput) Serialize() []byte {
+	return []byte{d.Flags&doubleBitFlagsMask | (d.Value&0x03)<<doubleBitStateShift}
+}
+
+// ParseDoubleBitBinaryInput parses double-bit binary input with flags
+func ParseDoubleBitBinaryInput(data []byte) DoubleBitBinaryInput {
+	if len(data) < 1 {
+		return DoubleBitBinaryInput{}
+	}
+	return DoubleBitBinaryInput{
+		Value: data[0] >> doubleBitStateShift,
+		Flags: data[0] & doubleBitFlagsMask,
+	}
+}
+
+// DoubleBitBinaryEvent represents a double-bit binary input change event (Group 4)
+type DoubleBitBinaryEvent struct {
+	Value     uint8  // Double-bit state (0-3)
+	Flags     uint8  // Status flags (lower 6 bits)
+	Timestamp uint64 // DNP3 time (ms since epoch), optional
+}
+
+// Serialize serializes the event using the given Group 4 variation
+func (e DoubleBitBinaryEvent) Serialize(variation uint8) []byte {
+	buf := DoubleBitBinaryInput{Value: e.Value, Flags: e.Flags}.Serialize()
+	if variation == DoubleBitBinaryEventWithTime {
+		buf = append(buf, DNP3Time(e.Timestamp).SerializeTime48()...)
+	}
+	return buf
+}
+
+// BinaryOutputEvent represents a binary output status change event (Group 11)
+type BinaryOutputEvent struct {
+	Value     bool   // Output state
+	Flags     uint8  // Status flags
+	Timestamp uint64 // DNP3 time (ms since epoch), optional
+}
+
+// Serialize serializes the event using the given Group 11 variation
+func (e BinaryOutputEvent) Serialize(variation uint8) []byte {
+	buf := []byte{e.Flags}
+	if variation == BinaryOutputEventWithTime {
+		buf = append(buf, DNP3Time(e.Timestamp).SerializeTime48()...)
+	}
+	return buf
+}
+
+// FrozenCounter represents a frozen counter data point (Group 21)
+type FrozenCounter struct {
+	Value     uint32 // Counter value at the time of freeze
+	Flags     uint8  // Status flags
+	Timestamp uint64 // Time of freeze (ms since epoch), optional
+}
+
+// Serialize serializes the frozen counter using the given Group 21 variation
+func (f FrozenCounter) Serialize(variation uint8) []byte {
+	c := Counter{Value: f.Value, Flags: f.Flags}
+
+	switch variation {
+	case FrozenCounter16BitWithFlag:
+		return c.Serialize16Bit()
+	case FrozenCounter32BitWithFlagTime:
+		return append(c.Serialize32Bit(), DNP3Time(f.Timestamp).SerializeTime48()...)
+	case FrozenCounter16BitWithFlagTime:
+		return append(c.Serialize16Bit(), DNP3Time(f.Timestamp).SerializeTime48()...)
+	case FrozenCounter32Bit:
+		return c.Serialize32Bit()[1:]
+	case FrozenCounter16Bit:
+		return c.Serialize16Bit()[1:]
+	default:
+		return c.Serialize32Bit()
+	}
+}
+
+// FrozenCounterEvent represents a frozen counter event (Group 23)
+type FrozenCounterEvent struct {
+	Value     uint32 // Counter value at the time of freeze
+	Flags     uint8  // Status flags
+	Timestamp uint64 // Time of freeze (ms since epoch), optional
+}
+
+// Serialize serializes the event using the given Group 23 variation
+func (e FrozenCounterEvent) Serialize(variation uint8) []byte {
+	// Group 23 variations match the flagged Group 21 variations
+	return FrozenCounter{Value: e.Value, Flags: e.Flags, Timestamp: e.Timestamp}.Serialize(variation)
+}
+
+// PackBits packs binary states into bytes, least significant bit first
+// (Group 1 and Group 10 variation 1)
+func PackBits(values []bool) []byte {
+	buf := make([]byte, (len(values)+7)/8)
+	for i, v := range values {
+		if v {
+			buf[i/8] |= 1 << (i % 8)
+		}
+	}
+	return buf
+}
+
+// UnpackBits reads count binary states packed by PackBits
+func UnpackBits(data []byte, count int) []bool {
+	values := make([]bool, count)
+	for i := range values {
+		values[i] = data[i/8]&(1<<(i%8)) != 0
+	}
+	return values
+}
+
+// PackDoubleBits packs double-bit states into bytes, two bits per point
+// starting at the least significant bits (Group 3 variation 1)
+func PackDoubleBits(values []uint8) []byte {
+	buf := make([]byte, (len(values)+3)/4)
+	for i, v := range values {
+		buf[i/4] |= (v & 0x03) << (2 * (i % 4))
+	}
+	return buf
+}
+
+// UnpackDoubleBits reads count double-bit states packed by PackDoubleBits
+func UnpackDoubleBits(data []byte, count int) []uint8 {
+	values := make([]uint8, count)
+	for i := range values {
+		values[i] = (data[i/4] >> (2 * (i % 4))) & 0x03
+	}
+	return values
+}
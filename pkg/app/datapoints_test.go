@@ -1,6 +1,8 @@
 package app
 
 import (
+	"bytes"
+	"reflect"
 	"testing"
 )
 
@@ -106,6 +108,30 @@ func TestCounter16BitSerialize(t *testing.T) {
 	}
 }
 
+func TestCounterVariations(t *testing.T) {
+	counter := Counter{Value: 0x12345678, Flags: FlagOnline}
+
+	tests := []struct {
+		variation uint8
+		want      []byte
+	}{
+		{Counter32BitWithFlag, []byte{0x01, 0x78, 0x56, 0x34, 0x12}},
+		{Counter16BitWithFlag, []byte{0x01, 0x78, 0x56}},
+		{Counter32Bit, []byte{0x78, 0x56, 0x34, 0x12}},
+		{Counter16Bit, []byte{0x78, 0x56}},
+	}
+
+	for _, tt := range tests {
+		data := counter.Serialize(tt.variation)
+		if !bytes.Equal(data, tt.want) {
+			t.Errorf("G20V%d: got % X, want % X", tt.variation, data, tt.want)
+		}
+		if size := GetObjectSize(GroupCounter, tt.variation); size != len(tt.want) {
+			t.Errorf("G20V%d size: got %d, want %d", tt.variation, size, len(tt.want))
+		}
+	}
+}
+
 func TestBinaryInputEvent(t *testing.T) {
 	event := NewBinaryInputEvent(true, 1234567890)
 
@@ -122,6 +148,64 @@ func TestBinaryInputEvent(t *testing.T) {
 	}
 }
 
+func TestAnalogInputEventSerialize(t *testing.T) {
+	event := AnalogInputEvent{Value: float64(-2.5), Flags: FlagOnline, Timestamp: 0x010203040506}
+
+	tests := []struct {
+		variation uint8
+		size      int
+		withTime  bool
+	}{
+		{AnalogInputEvent32BitNoTime, 5, false},
+		{AnalogInputEvent16BitNoTime, 3, false},
+		{AnalogInputEvent32BitWithTime, 11, true},
+		{AnalogInputEvent16BitWithTime, 9, true},
+		{AnalogInputEventFloatNoTime, 5, false},
+		{AnalogInputEventDoubleNoTime, 9, false},
+		{AnalogInputEventFloatWithTime, 11, true},
+		{AnalogInputEventDoubleWithTime, 15, true},
+	}
+
+	for _, tt := range tests {
+		data := event.Serialize(tt.variation)
+		if len(data) != tt.size || len(data) != GetObjectSize(GroupAnalogInputEvent, tt.variation) {
+			t.Errorf("G32V%d: got %d bytes, want %d", tt.variation, len(data), tt.size)
+			continue
+		}
+		if data[0] != FlagOnline {
+			t.Errorf("G32V%d flags: got 0x%02X, want 0x%02X", tt.variation, data[0], FlagOnline)
+		}
+		if tt.withTime {
+			if ts := ParseTime48(data[len(data)-6:]); uint64(ts) != event.Timestamp {
+				t.Errorf("G32V%d time: got 0x%X, want 0x%X", tt.variation, ts, event.Timestamp)
+			}
+		}
+	}
+
+	if v := ParseAnalogInputFloat(event.Serialize(AnalogInputEventFloatWithTime)).Value.(float32); v != -2.5 {
+		t.Errorf("Float value: got %v, want -2.5", v)
+	}
+}
+
+func TestCounterEventSerialize(t *testing.T) {
+	event := CounterEvent{Value: 70000, Flags: FlagOnline, Timestamp: 1234}
+
+	data := event.Serialize(CounterEvent32BitWithFlagTime)
+	if len(data) != 11 {
+		t.Fatalf("Expected 11 bytes, got %d", len(data))
+	}
+	if c := ParseCounter32Bit(data); c.Value != 70000 {
+		t.Errorf("Value: got %d, want 70000", c.Value)
+	}
+	if ts := ParseTime48(data[5:]); ts != 1234 {
+		t.Errorf("Time: got %d, want 1234", ts)
+	}
+
+	if data := event.Serialize(CounterEvent16BitWithFlag); len(data) != 3 {
+		t.Errorf("16-bit: got %d bytes, want 3", len(data))
+	}
+}
+
 func TestBinaryOutput(t *testing.T) {
 	bo := NewBinaryOutput(false)
 
@@ -155,3 +239,121 @@ func TestAnalogOutputStatus(t *testing.T) {
 		t.Errorf("Flag: got 0x%02X, want 0x%02X", data[0], FlagOnline)
 	}
 }
+
+func TestAnalogOutputStatusVariations(t *testing.T) {
+	ao := AnalogOutputStatus{Value: 12.5, Flags: FlagOnline}
+
+	tests := []struct {
+		variation uint8
+		size      int
+	}{
+		{AnalogOutputStatus32Bit, 5},
+		{AnalogOutputStatus16Bit, 3},
+		{AnalogOutputStatusFloat, 5},
+		{AnalogOutputStatusDouble, 9},
+	}
+
+	for _, tt := range tests {
+		if data := ao.Serialize(tt.variation); len(data) != tt.size {
+			t.Errorf("G40V%d: got %d bytes, want %d", tt.variation, len(data), tt.size)
+		}
+	}
+
+	if v := ParseAnalogInputFloat(ao.Serialize(AnalogOutputStatusFloat)).Value; v != float32(12.5) {
+		t.Errorf("Float value: got %v, want 12.5", v)
+	}
+
+	event := AnalogOutputEvent{Value: 12.5, Flags: FlagOnline, Timestamp: 99}
+	if data := event.Serialize(AnalogOutputEventDoubleWithTime); len(data) != 15 || ParseTime48(data[9:]) != 99 {
+		t.Errorf("G42V8: got % X", data)
+	}
+}
+
+func TestDoubleBitBinaryInput(t *testing.T) {
+	dbi := DoubleBitBinaryInput{Value: DoubleBitOn, Flags: FlagOnline}
+
+	data := dbi.Serialize()
+	if data[0] != 0x81 {
+		t.Errorf("Flags: got 0x%02X, want 0x81", data[0])
+	}
+
+	parsed := ParseDoubleBitBinaryInput(data)
+	if parsed.Value != DoubleBitOn || parsed.Flags != FlagOnline {
+		t.Errorf("Parsed: got %+v", parsed)
+	}
+
+	event := DoubleBitBinaryEvent{Value: DoubleBitOff, Flags: FlagOnline, Timestamp: 1000}
+	data = event.Serialize(DoubleBitBinaryEventWithTime)
+	if len(data) != 7 || data[0] != 0x41 || ParseTime48(data[1:]) != 1000 {
+		t.Errorf("G4V2: got % X", data)
+	}
+}
+
+func TestFrozenCounterVariations(t *testing.T) {
+	fc := FrozenCounter{Value: 0x12345678, Flags: FlagOnline, Timestamp: 5000}
+
+	tests := []struct {
+		variation uint8
+		want      []byte
+	}{
+		{FrozenCounter32BitWithFlag, []byte{0x01, 0x78, 0x56, 0x34, 0x12}},
+		{FrozenCounter16BitWithFlag, []byte{0x01, 0x78, 0x56}},
+		{FrozenCounter32Bit, []byte{0x78, 0x56, 0x34, 0x12}},
+		{FrozenCounter16Bit, []byte{0x78, 0x56}},
+	}
+
+	for _, tt := range tests {
+		if data := fc.Serialize(tt.variation); !bytes.Equal(data, tt.want) {
+			t.Errorf("G21V%d: got % X, want % X", tt.variation, data, tt.want)
+		}
+	}
+
+	data := FrozenCounterEvent{Value: 7, Flags: FlagOnline, Timestamp: 5000}.Serialize(FrozenCounterEvent32BitWithFlagTime)
+	if len(data) != GetObjectSize(GroupFrozenCounterEvent, FrozenCounterEvent32BitWithFlagTime) || ParseTime48(data[5:]) != 5000 {
+		t.Errorf("G23V5: got % X", data)
+	}
+}
+
+func TestAnalogDeadbandVariations(t *testing.T) {
+	tests := []struct {
+		variation uint8
+		value     float64
+		want      []byte
+		parsed    float64
+	}{
+		{AnalogDeadband16Bit, 2.6, []byte{0x03, 0x00}, 3},
+		{AnalogDeadband16Bit, 70000, []byte{0xFF, 0xFF}, 65535},
+		{AnalogDeadband32Bit, 70000, []byte{0x70, 0x11, 0x01, 0x00}, 70000},
+		{AnalogDeadbandFloat, 0.5, []byte{0x00, 0x00, 0x00, 0x3F}, 0.5},
+	}
+
+	for _, tt := range tests {
+		data := AnalogDeadband{Value: tt.value}.Serialize(tt.variation)
+		if !bytes.Equal(data, tt.want) || len(data) != GetObjectSize(GroupAnalogInputDeadband, tt.variation) {
+			t.Errorf("G34V%d %g: got % X, want % X", tt.variation, tt.value, data, tt.want)
+		}
+		if got := ParseAnalogDeadband(tt.variation, data).Value; got != tt.parsed {
+			t.Errorf("G34V%d parsed: got %g, want %g", tt.variation, got, tt.parsed)
+		}
+	}
+}
+
+func TestPackBits(t *testing.T) {
+	if got := PackBits([]bool{true, false, true, false, false, false, false, false, true}); !bytes.Equal(got, []byte{0x05, 0x01}) {
+		t.Errorf("PackBits: got % X, want 05 01", got)
+	}
+	if got := PackDoubleBits([]uint8{DoubleBitOff, DoubleBitOn, DoubleBitIndeterminate, DoubleBitIntermediate, DoubleBitOn}); !bytes.Equal(got, []byte{0x39, 0x02}) {
+		t.Errorf("PackDoubleBits: got % X, want 39 02", got)
+	}
+}
+
+func TestUnpackBits(t *testing.T) {
+	bits := []bool{true, false, true, false, false, false, false, false, true}
+	if got := UnpackBits(PackBits(bits), len(bits)); !reflect.DeepEqual(got, bits) {
+		t.Errorf("UnpackBits: got %v, want %v", got, bits)
+	}
+	doubleBits := []uint8{DoubleBitOff, DoubleBitOn, DoubleBitIndeterminate, DoubleBitIntermediate, DoubleBitOn}
+	if got := UnpackDoubleBits(PackDoubleBits(doubleBits), len(doubleBits)); !reflect.DeepEqual(got, doubleBits) {
+		t.Errorf("UnpackDoubleBits: got %v, want %v", got, doubleBits)
+	}
+}
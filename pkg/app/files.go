@@ -0,0 +1,343 @@
+package app
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// FileStatus is the status code of a file command or file transport
+type FileStatus uint8
+
+const (
+	FileStatusSuccess          FileStatus = 0
+	FileStatusPermissionDenied FileStatus = 1
+	FileStatusInvalidMode      FileStatus = 2
+	FileStatusNotFound         FileStatus = 3
+	FileStatusLocked           FileStatus = 4
+	FileStatusTooManyOpen      FileStatus = 5
+	FileStatusInvalidHandle    FileStatus = 6
+	FileStatusWriteBlockSize   FileStatus = 7 // Block larger than the negotiated size
+	FileStatusCommLost         FileStatus = 8
+	FileStatusCannotAbort      FileStatus = 9
+	FileStatusNotOpened        FileStatus = 16
+	FileStatusHandleExpired    FileStatus = 17
+	FileStatusBufferOverrun    FileStatus = 18
+	FileStatusFatal            FileStatus = 19
+	FileStatusBlockSequence    FileStatus = 20 // Block number out of sequence
+	FileStatusUndefined        FileStatus = 255
+)
+
+// String returns the name of the status
+func (s FileStatus) String() string {
+	switch s {
+	case FileStatusSuccess:
+		return "SUCCESS"
+	case FileStatusPermissionDenied:
+		return "PERMISSION_DENIED"
+	case FileStatusInvalidMode:
+		return "INVALID_MODE"
+	case FileStatusNotFound:
+		return "FILE_NOT_FOUND"
+	case FileStatusLocked:
+		return "FILE_LOCKED"
+	case FileStatusTooManyOpen:
+		return "TOO_MANY_OPEN"
+	case FileStatusInvalidHandle:
+		return "INVALID_HANDLE"
+	case FileStatusWriteBlockSize:
+		return "WRITE_BLOCK_SIZE"
+	case FileStatusCommLost:
+		return "COMM_LOST"
+	case FileStatusCannotAbort:
+		return "CANNOT_ABORT"
+	case FileStatusNotOpened:
+		return "NOT_OPENED"
+	case FileStatusHandleExpired:
+		return "HANDLE_EXPIRED"
+	case FileStatusBufferOverrun:
+		return "BUFFER_OVERRUN"
+	case FileStatusFatal:
+		return "FATAL"
+	case FileStatusBlockSequence:
+		return "BLOCK_SEQUENCE"
+	default:
+		return fmt.Sprintf("FileStatus(%d)", uint8(s))
+	}
+}
+
+// FileMode is the operational mode of a file command
+type FileMode uint16
+
+const (
+	FileModeNull   FileMode = 0 // Delete
+	FileModeRead   FileMode = 1
+	FileModeWrite  FileMode = 2 // Create or truncate
+	FileModeAppend FileMode = 3
+)
+
+// File types of a file descriptor
+const (
+	FileTypeDirectory uint16 = 0
+	FileTypeSimple    uint16 = 1
+)
+
+// lastBlockFlag marks the last block of a file transport object
+const lastBlockFlag = 0x80000000
+
+// Fixed part sizes of the file-control objects, before their strings
+const (
+	fileAuthenticationSize  = 12
+	fileCommandSize         = 26
+	fileCommandStatusSize   = 13
+	fileTransportSize       = 8
+	fileTransportStatusSize = 9
+	fileDescriptorSize      = 20
+)
+
+// FileAuthenticationObject requests an authentication key (Group 70 Var 2)
+type FileAuthenticationObject struct {
+	UserName string
+	Password string
+	Key      uint32 // Zero in the request, the issued key in the response
+}
+
+// Serialize serializes the authentication object
+func (f FileAuthenticationObject) Serialize() []byte {
+	buf := make([]byte, fileAuthenticationSize, fileAuthenticationSize+len(f.UserName)+len(f.Password))
+	binary.LittleEndian.PutUint16(buf[0:], fileAuthenticationSize)
+	binary.LittleEndian.PutUint16(buf[2:], uint16(len(f.UserName)))
+	binary.LittleEndian.PutUint16(buf[4:], uint16(fileAuthenticationSize+len(f.UserName)))
+	binary.LittleEndian.PutUint16(buf[6:], uint16(len(f.Password)))
+	binary.LittleEndian.PutUint32(buf[8:], f.Key)
+	buf = append(buf, f.UserName...)
+	return append(buf, f.Password...)
+}
+
+// ParseFileAuthentication parses an authentication object
+func ParseFileAuthentication(data []byte) (FileAuthenticationObject, error) {
+	if len(data) < fileAuthenticationSize {
+		return FileAuthenticationObject{}, ErrInsufficientData
+	}
+	user, err := fileString(data, binary.LittleEndian.Uint16(data[0:]), binary.LittleEndian.Uint16(data[2:]))
+	if err != nil {
+		return FileAuthenticationObject{}, err
+	}
+	password, err := fileString(data, binary.LittleEndian.Uint16(data[4:]), binary.LittleEndian.Uint16(data[6:]))
+	if err != nil {
+		return FileAuthenticationObject{}, err
+	}
+	return FileAuthenticationObject{UserName: user, Password: password, Key: binary.LittleEndian.Uint32(data[8:])}, nil
+}
+
+// FileCommandObject opens or deletes a file (Group 70 Var 3)
+type FileCommandObject struct {
+	Name         string
+	Created      DNP3Time
+	Permissions  uint16 // Unix-style permission bits
+	AuthKey      uint32
+	Size         uint32 // Size of a file opened for writing
+	Mode         FileMode
+	MaxBlockSize uint16
+	RequestID    uint16
+}
+
+// Serialize serializes the file command
+func (f FileCommandObject) Serialize() []byte {
+	buf := make([]byte, fileCommandSize, fileCommandSize+len(f.Name))
+	binary.LittleEndian.PutUint16(buf[0:], fileCommandSize)
+	binary.LittleEndian.PutUint16(buf[2:], uint16(len(f.Name)))
+	copy(buf[4:], f.Created.SerializeTime48())
+	binary.LittleEndian.PutUint16(buf[10:], f.Permissions)
+	binary.LittleEndian.PutUint32(buf[12:], f.AuthKey)
+	binary.LittleEndian.PutUint32(buf[16:], f.Size)
+	binary.LittleEndian.PutUint16(buf[20:], uint16(f.Mode))
+	binary.LittleEndian.PutUint16(buf[22:], f.MaxBlockSize)
+	binary.LittleEndian.PutUint16(buf[24:], f.RequestID)
+	return append(buf, f.Name...)
+}
+
+// ParseFileCommand parses a file command
+func ParseFileCommand(data []byte) (FileCommandObject, error) {
+	if len(data) < fileCommandSize {
+		return FileCommandObject{}, ErrInsufficientData
+	}
+	name, err := fileString(data, binary.LittleEndian.Uint16(data[0:]), binary.LittleEndian.Uint16(data[2:]))
+	if err != nil {
+		return FileCommandObject{}, err
+	}
+	return FileCommandObject{
+		Name:         name,
+		Created:      ParseTime48(data[4:10]),
+		Permissions:  binary.LittleEndian.Uint16(data[10:]),
+		AuthKey:      binary.LittleEndian.Uint32(data[12:]),
+		Size:         binary.LittleEndian.Uint32(data[16:]),
+		Mode:         FileMode(binary.LittleEndian.Uint16(data[20:])),
+		MaxBlockSize: binary.LittleEndian.Uint16(data[22:]),
+		RequestID:    binary.LittleEndian.Uint16(data[24:]),
+	}, nil
+}
+
+// FileCommandStatusObject answers a file command, and closes or aborts a
+// file when sent by the master (Group 70 Var 4)
+type FileCommandStatusObject struct {
+	Handle       uint32
+	Size         uint32
+	MaxBlockSize uint16
+	RequestID    uint16
+	Status       FileStatus
+	Text         string // Optional description of the status
+}
+
+// Serialize serializes the file command status
+func (f FileCommandStatusObject) Serialize() []byte {
+	buf := make([]byte, fileCommandStatusSize, fileCommandStatusSize+len(f.Text))
+	binary.LittleEndian.PutUint32(buf[0:], f.Handle)
+	binary.LittleEndian.PutUint32(buf[4:], f.Size)
+	binary.LittleEndian.PutUint16(buf[8:], f.MaxBlockSize)
+	binary.LittleEndian.PutUint16(buf[10:], f.RequestID)
+	buf[12] = uint8(f.Status)
+	return append(buf, f.Text...)
+}
+
+// ParseFileCommandStatus parses a file command status
+func ParseFileCommandStatus(data []byte) (FileCommandStatusObject, error) {
+	if len(data) < fileCommandStatusSize {
+		return FileCommandStatusObject{}, ErrInsufficientData
+	}
+	return FileCommandStatusObject{
+		Handle:       binary.LittleEndian.Uint32(data[0:]),
+		Size:         binary.LittleEndian.Uint32(data[4:]),
+		MaxBlockSize: binary.LittleEndian.Uint16(data[8:]),
+		RequestID:    binary.LittleEndian.Uint16(data[10:]),
+		Status:       FileStatus(data[12]),
+		Text:         string(data[fileCommandStatusSize:]),
+	}, nil
+}
+
+// FileTransportObject carries a block of file data (Group 70 Var 5)
+type FileTransportObject struct {
+	Handle uint32
+	Block  uint32 // Block number, counting from 0
+	Last   bool   // Last block of the file
+	Data   []byte
+}
+
+// Serialize serializes the file transport object
+func (f FileTransportObject) Serialize() []byte {
+	buf := make([]byte, fileTransportSize, fileTransportSize+len(f.Data))
+	binary.LittleEndian.PutUint32(buf[0:], f.Handle)
+	binary.LittleEndian.PutUint32(buf[4:], fileBlockNumber(f.Block, f.Last))
+	return append(buf, f.Data...)
+}
+
+// ParseFileTransport parses a file transport object
+func ParseFileTransport(data []byte) (FileTransportObject, error) {
+	if len(data) < fileTransportSize {
+		return FileTransportObject{}, ErrInsufficientData
+	}
+	block := binary.LittleEndian.Uint32(data[4:])
+	return FileTransportObject{
+		Handle: binary.LittleEndian.Uint32(data[0:]),
+		Block:  block &^ lastBlockFlag,
+		Last:   block&lastBlockFlag != 0,
+		Data:   append([]byte(nil), data[fileTransportSize:]...),
+	}, nil
+}
+
+// FileTransportStatusObject answers a written block (Group 70 Var 6)
+type FileTransportStatusObject struct {
+	Handle uint32
+	Block  uint32
+	Last   bool
+	Status FileStatus
+	Text   string // Optional description of the status
+}
+
+// Serialize serializes the file transport status
+func (f FileTransportStatusObject) Serialize() []byte {
+	buf := make([]byte, fileTransportStatusSize, fileTransportStatusSize+len(f.Text))
+	binary.LittleEndian.PutUint32(buf[0:], f.Handle)
+	binary.LittleEndian.PutUint32(buf[4:], fileBlockNumber(f.Block, f.Last))
+	buf[8] = uint8(f.Status)
+	return append(buf, f.Text...)
+}
+
+// ParseFileTransportStatus parses a file transport status
+func ParseFileTransportStatus(data []byte) (FileTransportStatusObject, error) {
+	if len(data) < fileTransportStatusSize {
+		return FileTransportStatusObject{}, ErrInsufficientData
+	}
+	block := binary.LittleEndian.Uint32(data[4:])
+	return FileTransportStatusObject{
+		Handle: binary.LittleEndian.Uint32(data[0:]),
+		Block:  block &^ lastBlockFlag,
+		Last:   block&lastBlockFlag != 0,
+		Status: FileStatus(data[8]),
+		Text:   string(data[fileTransportStatusSize:]),
+	}, nil
+}
+
+// FileDescriptorObject describes a file or directory (Group 70 Var 7). A
+// directory read returns its entries as consecutive descriptors.
+type FileDescriptorObject struct {
+	Name        string
+	Type        uint16 // FileTypeDirectory or FileTypeSimple
+	Size        uint32 // Bytes of a file, entries of a directory
+	Created     DNP3Time
+	Permissions uint16
+	RequestID   uint16
+}
+
+// Serialize serializes the file descriptor
+func (f FileDescriptorObject) Serialize() []byte {
+	buf := make([]byte, fileDescriptorSize, fileDescriptorSize+len(f.Name))
+	binary.LittleEndian.PutUint16(buf[0:], fileDescriptorSize)
+	binary.LittleEndian.PutUint16(buf[2:], uint16(len(f.Name)))
+	binary.LittleEndian.PutUint16(buf[4:], f.Type)
+	binary.LittleEndian.PutUint32(buf[6:], f.Size)
+	copy(buf[10:], f.Created.SerializeTime48())
+	binary.LittleEndian.PutUint16(buf[16:], f.Permissions)
+	binary.LittleEndian.PutUint16(buf[18:], f.RequestID)
+	return append(buf, f.Name...)
+}
+
+// ParseFileDescriptor parses a file descriptor, returning it and its size
+func ParseFileDescriptor(data []byte) (FileDescriptorObject, int, error) {
+	if len(data) < fileDescriptorSize {
+		return FileDescriptorObject{}, 0, ErrInsufficientData
+	}
+	offset, size := binary.LittleEndian.Uint16(data[0:]), binary.LittleEndian.Uint16(data[2:])
+	name, err := fileString(data, offset, size)
+	if err != nil {
+		return FileDescriptorObject{}, 0, err
+	}
+	return FileDescriptorObject{
+		Name:        name,
+		Type:        binary.LittleEndian.Uint16(data[4:]),
+		Size:        binary.LittleEndian.Uint32(data[6:]),
+		Created:     ParseTime48(data[10:16]),
+		Permissions: binary.LittleEndian.Uint16(data[16:]),
+		RequestID:   binary.LittleEndian.Uint16(data[18:]),
+	}, int(offset) + int(size), nil
+}
+
+// BuildFileObject builds a Group 70 header holding one object
+func BuildFileObject(variation uint8, object []byte) []byte {
+	return buildFreeFormatObject(GroupFile, variation, object)
+}
+
+// fileString returns the string at an offset of a file-control object
+func fileString(data []byte, offset, size uint16) (string, error) {
+	if int(offset)+int(size) > len(data) {
+		return "", fmt.Errorf("%w: string at %d+%d beyond a %d byte object", ErrInsufficientData, offset, size, len(data))
+	}
+	return string(data[offset : offset+size]), nil
+}
+
+// fileBlockNumber encodes a block number with the last block flag
+func fileBlockNumber(block uint32, last bool) uint32 {
+	if last {
+		return block | lastBlockFlag
+	}
+	return block &^ lastBlockFlag
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFileObjectRoundTrip(t *testing.T) {
+	command := FileCommandObject{
+		Name: "/logs/a.txt", Created: 0x010203040506, Permissions: 0644, AuthKey: 7,
+		Size: 1000, Mode: FileModeWrite, MaxBlockSize: 512, RequestID: 3,
+	}
+	data := command.Serialize()
+	if len(data) != 26+len(command.Name) || data[0] != 26 || data[2] != byte(len(command.Name)) {
+		t.Fatalf("File command: got % X", data)
+	}
+	if got, err := ParseFileCommand(data); err != nil || !reflect.DeepEqual(got, command) {
+		t.Errorf("File command: got %+v, %v, want %+v", got, err, command)
+	}
+
+	auth := FileAuthenticationObject{UserName: "operator", Password: "secret", Key: 0xDEADBEEF}
+	if got, err := ParseFileAuthentication(auth.Serialize()); err != nil || got != auth {
+		t.Errorf("Authentication: got %+v, %v, want %+v", got, err, auth)
+	}
+
+	status := FileCommandStatusObject{Handle: 9, Size: 1000, MaxBlockSize: 512, RequestID: 3, Status: FileStatusLocked, Text: "busy"}
+	if got, err := ParseFileCommandStatus(status.Serialize()); err != nil || got != status {
+		t.Errorf("Command status: got %+v, %v, want %+v", got, err, status)
+	}
+
+	transport := FileTransportObject{Handle: 9, Block: 4, Last: true, Data: []byte{1, 2, 3}}
+	data = transport.Serialize()
+	if !bytes.Equal(data[4:8], []byte{0x04, 0x00, 0x00, 0x80}) {
+		t.Errorf("Last block number: got % X, want 04 00 00 80", data[4:8])
+	}
+	if got, err := ParseFileTransport(data); err != nil || !reflect.DeepEqual(got, transport) {
+		t.Errorf("Transport: got %+v, %v, want %+v", got, err, transport)
+	}
+
+	transportStatus := FileTransportStatusObject{Handle: 9, Block: 4, Status: FileStatusBlockSequence}
+	if got, err := ParseFileTransportStatus(transportStatus.Serialize()); err != nil || got != transportStatus {
+		t.Errorf("Transport status: got %+v, %v, want %+v", got, err, transportStatus)
+	}
+
+	descriptor := FileDescriptorObject{Name: "a.txt", Type: FileTypeSimple, Size: 1000, Created: 5, Permissions: 0600, RequestID: 3}
+	data = descriptor.Serialize()
+	got, size, err := ParseFileDescriptor(append(data, 0xFF))
+	if err != nil || size != len(data) || got != descriptor {
+		t.Errorf("Descriptor: got %+v, size %d, %v, want %+v, size %d", got, size, err, descriptor, len(data))
+	}
+}
+
+func TestFileObjectErrors(t *testing.T) {
+	if _, err := ParseFileCommand(make([]byte, 25)); !errors.Is(err, ErrInsufficientData) {
+		t.Errorf("Short command: got %v, want ErrInsufficientData", err)
+	}
+
+	// Name said to run past the end of the object
+	data := FileCommandObject{Name: "abc"}.Serialize()
+	if _, err := ParseFileCommand(data[:len(data)-1]); !errors.Is(err, ErrInsufficientData) {
+		t.Errorf("Truncated name: got %v, want ErrInsufficientData", err)
+	}
+}
+
+func TestFreeFormatHeader(t *testing.T) {
+	object := FileCommandStatusObject{Handle: 1}.Serialize()
+	data := BuildFileObject(FileCommandStatus, object)
+
+	expected := append([]byte{70, 4, 0x5B, 1, byte(len(object)), 0}, object...)
+	if !bytes.Equal(data, expected) {
+		t.Fatalf("Free-format header: got % X, want % X", data, expected)
+	}
+
+	parser := NewParser(data)
+	header, err := parser.ReadObjectHeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Range != (FreeFormatRange{Count: 1}) || GetCount(header.Range) != 1 {
+		t.Errorf("Range: got %+v, want a free-format count of 1", header.Range)
+	}
+	read, err := parser.ReadFreeFormatObject()
+	if err != nil || !bytes.Equal(read, object) || parser.HasMore() {
+		t.Errorf("Object: got % X, %v, want % X", read, err, object)
+	}
+
+	if err := ValidateRange(FreeFormatRange{}); !errors.Is(err, ErrInvalidCount) {
+		t.Errorf("Zero count: got %v, want ErrInvalidCount", err)
+	}
+}
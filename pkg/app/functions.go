@@ -36,6 +36,8 @@ const (
 	FuncGetFileInfo           FunctionCode = 0x1C // Get File Info
 	FuncAuthenticateFile      FunctionCode = 0x1D // Authenticate File
 	FuncAbortFile             FunctionCode = 0x1E // Abort File
+	FuncAuthRequest           FunctionCode = 0x20 // Authentication Request
+	FuncAuthRequestNoAck      FunctionCode = 0x21 // Authentication Request No Ack
 	FuncResponse              FunctionCode = 0x81 // Response
 	FuncUnsolicitedResponse   FunctionCode = 0x82 // Unsolicited Response
 	FuncAuthResponse          FunctionCode = 0x83 // Auth Response
@@ -62,6 +64,12 @@ func (f FunctionCode) String() string {
 		return "Response"
 	case FuncUnsolicitedResponse:
 		return "UnsolicitedResponse"
+	case FuncAuthRequest:
+		return "AuthRequest"
+	case FuncAuthRequestNoAck:
+		return "AuthRequestNoAck"
+	case FuncAuthResponse:
+		return "AuthResponse"
 	case FuncEnableUnsolicited:
 		return "EnableUnsolicited"
 	case FuncDisableUnsolicited:
@@ -72,6 +72,29 @@ func BuildTimeSyncNowRequest(seq uint8) *APDU {
 	return BuildTimeSyncRequest(seq, time.Now())
 }
 
+// BuildDelayMeasurementRequest creates a delay measurement request, the first
+// step of non-LAN time synchronization
+func BuildDelayMeasurementRequest(seq uint8) *APDU {
+	return NewRequestAPDU(FuncDelayMeasurement, seq, nil)
+}
+
+// BuildRecordCurrentTimeRequest creates a record current time request, the
+// first step of LAN time synchronization
+func BuildRecordCurrentTimeRequest(seq uint8) *APDU {
+	return NewRequestAPDU(FuncRecordCurrentTime, seq, nil)
+}
+
+// BuildLastRecordedTimeRequest creates the write of the time recorded by a
+// record current time request
+func BuildLastRecordedTimeRequest(seq uint8, t time.Time) *APDU {
+	return BuildWriteRequest(seq, BuildLastRecordedTime(t))
+}
+
+// BuildAssignClassRequest creates an assign class request
+func BuildAssignClassRequest(seq uint8, objects []byte) *APDU {
+	return NewRequestAPDU(FuncAssignClass, seq, objects)
+}
+
 // BuildColdRestartRequest creates a cold restart request
 func BuildColdRestartRequest(seq uint8) *APDU {
 	return NewRequestAPDU(FuncColdRestart, seq, nil)
@@ -169,6 +192,12 @@ func (s *SequenceCounter) SetSequence(seq uint8) {
 	s.current = seq & AppCtrlSeqMask
 }
 
+// NextSequence returns the sequence number following seq, e.g. for the next
+// fragment of a multi-fragment response
+func NextSequence(seq uint8) uint8 {
+	return (seq + 1) & AppCtrlSeqMask
+}
+
 // UnsolicitedSequenceCounter manages sequence for unsolicited responses
 type UnsolicitedSequenceCounter struct {
 	current uint8
@@ -4,6 +4,7 @@ package app
 
 // Object Group numbers
 const (
+	GroupDeviceAttributes      uint8 = 0 // Variation is the attribute, index is the attribute set
 	GroupBinaryInput           uint8 = 1
 	GroupBinaryInputEvent      uint8 = 2
 	GroupDoubleBitBinaryInput  uint8 = 3
@@ -19,15 +20,21 @@ const (
 	GroupFrozenAnalogInput     uint8 = 31
 	GroupAnalogInputEvent      uint8 = 32
 	GroupFrozenAnalogEvent     uint8 = 33
+	GroupAnalogInputDeadband   uint8 = 34
 	GroupAnalogOutputStatus    uint8 = 40
 	GroupAnalogOutputEvent     uint8 = 42
 	GroupAnalogOutputCommand   uint8 = 41
 	GroupTimeDate              uint8 = 50
+	GroupTimeDelay             uint8 = 52
 	GroupClass0Data            uint8 = 60
 	GroupClass1Data            uint8 = 61
 	GroupClass2Data            uint8 = 62
 	GroupClass3Data            uint8 = 63
+	GroupFile                  uint8 = 70
 	GroupInternalIndications   uint8 = 80
+	GroupOctetString           uint8 = 110 // Variation is the string length
+	GroupOctetStringEvent      uint8 = 111 // Variation is the string length
+	GroupAuthentication        uint8 = 120
 )
 
 // Common variations
@@ -35,6 +42,36 @@ const (
 	VariationAny uint8 = 0 // Request any variation
 )
 
+// Device Attribute variations (Group 0) of the standard attribute set 0
+const (
+	DeviceAttributeMaxAnalogOutputIndex uint8 = 220
+	DeviceAttributeNumAnalogOutputs     uint8 = 221
+	DeviceAttributeMaxBinaryOutputIndex uint8 = 223
+	DeviceAttributeNumBinaryOutputs     uint8 = 224
+	DeviceAttributeMaxCounterIndex      uint8 = 228
+	DeviceAttributeNumCounters          uint8 = 229
+	DeviceAttributeMaxAnalogInputIndex  uint8 = 232
+	DeviceAttributeNumAnalogInputs      uint8 = 233
+	DeviceAttributeMaxDoubleBitIndex    uint8 = 235
+	DeviceAttributeNumDoubleBitInputs   uint8 = 236
+	DeviceAttributeMaxBinaryInputIndex  uint8 = 238
+	DeviceAttributeNumBinaryInputs      uint8 = 239
+	DeviceAttributeMaxTxFragSize        uint8 = 240
+	DeviceAttributeMaxRxFragSize        uint8 = 241
+	DeviceAttributeSoftwareVersion      uint8 = 242
+	DeviceAttributeHardwareVersion      uint8 = 243
+	DeviceAttributeOwnerName            uint8 = 244
+	DeviceAttributeLocationName         uint8 = 245
+	DeviceAttributeIDCode               uint8 = 246
+	DeviceAttributeDeviceName           uint8 = 247
+	DeviceAttributeSerialNumber         uint8 = 248
+	DeviceAttributeSubsetAndConformance uint8 = 249
+	DeviceAttributeProductNameAndModel  uint8 = 250
+	DeviceAttributeManufacturerName     uint8 = 252
+	DeviceAttributeAll                  uint8 = 254 // READ only: every attribute of the set
+	DeviceAttributeList                 uint8 = 255 // READ only: the variations supported by the set
+)
+
 // Binary Input variations (Group 1)
 const (
 	BinaryInputAny                  uint8 = 0
@@ -50,13 +87,71 @@ const (
 	BinaryInputEventWithRelativeTime uint8 = 3
 )
 
+// Double-bit Binary Input variations (Group 3)
+const (
+	DoubleBitBinaryInputAny         uint8 = 0
+	DoubleBitBinaryInputPacked      uint8 = 1 // Packed format, 2 bits per point
+	DoubleBitBinaryInputWithFlags   uint8 = 2 // With flags
+)
+
+// Double-bit Binary Input Event variations (Group 4)
+const (
+	DoubleBitBinaryEventAny         uint8 = 0
+	DoubleBitBinaryEventWithoutTime uint8 = 1
+	DoubleBitBinaryEventWithTime    uint8 = 2
+	DoubleBitBinaryEventWithRelativeTime uint8 = 3
+)
+
+// Binary Output Status variations (Group 10)
+const (
+	BinaryOutputAny                 uint8 = 0
+	BinaryOutputPacked              uint8 = 1 // Packed format
+	BinaryOutputWithFlags           uint8 = 2 // With flags
+)
+
+// Binary Output Event variations (Group 11)
+const (
+	BinaryOutputEventAny            uint8 = 0
+	BinaryOutputEventWithoutTime    uint8 = 1
+	BinaryOutputEventWithTime       uint8 = 2
+)
+
 // Counter variations (Group 20)
 const (
 	CounterAny                      uint8 = 0
-	Counter32Bit                    uint8 = 1
-	Counter16Bit                    uint8 = 2
-	Counter32BitWithFlag            uint8 = 5
-	Counter16BitWithFlag            uint8 = 6
+	Counter32BitWithFlag            uint8 = 1
+	Counter16BitWithFlag            uint8 = 2
+	Counter32Bit                    uint8 = 5 // Without flag
+	Counter16Bit                    uint8 = 6 // Without flag
+)
+
+// Counter Event variations (Group 22)
+const (
+	CounterEventAny                 uint8 = 0
+	CounterEvent32BitWithFlag       uint8 = 1
+	CounterEvent16BitWithFlag       uint8 = 2
+	CounterEvent32BitWithFlagTime   uint8 = 5
+	CounterEvent16BitWithFlagTime   uint8 = 6
+)
+
+// Frozen Counter variations (Group 21)
+const (
+	FrozenCounterAny                uint8 = 0
+	FrozenCounter32BitWithFlag      uint8 = 1
+	FrozenCounter16BitWithFlag      uint8 = 2
+	FrozenCounter32BitWithFlagTime  uint8 = 5
+	FrozenCounter16BitWithFlagTime  uint8 = 6
+	FrozenCounter32Bit              uint8 = 9  // Without flag
+	FrozenCounter16Bit              uint8 = 10 // Without flag
+)
+
+// Frozen Counter Event variations (Group 23)
+const (
+	FrozenCounterEventAny           uint8 = 0
+	FrozenCounterEvent32BitWithFlag uint8 = 1
+	FrozenCounterEvent16BitWithFlag uint8 = 2
+	FrozenCounterEvent32BitWithFlagTime uint8 = 5
+	FrozenCounterEvent16BitWithFlagTime uint8 = 6
 )
 
 // Analog Input variations (Group 30)
@@ -83,6 +178,73 @@ const (
 	AnalogInputEventDoubleWithTime  uint8 = 8
 )
 
+// Analog Input Deadband variations (Group 34)
+const (
+	AnalogDeadbandAny   uint8 = 0
+	AnalogDeadband16Bit uint8 = 1 // Unsigned 16-bit integer
+	AnalogDeadband32Bit uint8 = 2 // Unsigned 32-bit integer
+	AnalogDeadbandFloat uint8 = 3 // Single-precision float
+)
+
+// Analog Output Status variations (Group 40)
+const (
+	AnalogOutputStatusAny           uint8 = 0
+	AnalogOutputStatus32Bit         uint8 = 1 // 32-bit integer with flag
+	AnalogOutputStatus16Bit         uint8 = 2 // 16-bit integer with flag
+	AnalogOutputStatusFloat         uint8 = 3 // Single-precision float with flag
+	AnalogOutputStatusDouble        uint8 = 4 // Double-precision float with flag
+)
+
+// Analog Output Event variations (Group 42)
+const (
+	AnalogOutputEventAny            uint8 = 0
+	AnalogOutputEvent32BitNoTime    uint8 = 1
+	AnalogOutputEvent16BitNoTime    uint8 = 2
+	AnalogOutputEvent32BitWithTime  uint8 = 3
+	AnalogOutputEvent16BitWithTime  uint8 = 4
+	AnalogOutputEventFloatNoTime    uint8 = 5
+	AnalogOutputEventDoubleNoTime   uint8 = 6
+	AnalogOutputEventFloatWithTime  uint8 = 7
+	AnalogOutputEventDoubleWithTime uint8 = 8
+)
+
+// Time and Date variations (Group 50)
+const (
+	TimeDateAbsolute     uint8 = 1 // Absolute time
+	TimeDateInterval     uint8 = 2 // Absolute time and interval
+	TimeDateLastRecorded uint8 = 3 // Absolute time at last recorded time
+)
+
+// Time Delay variations (Group 52)
+const (
+	TimeDelayCoarse uint8 = 1 // Delay in seconds
+	TimeDelayFine   uint8 = 2 // Delay in milliseconds
+)
+
+// File-control variations (Group 70), sent with the free-format qualifier
+const (
+	FileAuthentication  uint8 = 2 // User name and password, answered with an authentication key
+	FileCommand         uint8 = 3 // Open or delete a file
+	FileCommandStatus   uint8 = 4 // File handle and status of a command, also used to close and abort
+	FileTransport       uint8 = 5 // Block of file data
+	FileTransportStatus uint8 = 6 // Status of a written block
+	FileDescriptor      uint8 = 7 // Directory entry or file information
+	FileSpecification   uint8 = 8 // File name, for ACTIVATE CONFIG
+)
+
+// Secure Authentication variations (Group 120). Variations 3 and 4 use an
+// 8-bit count, the others the free-format qualifier.
+const (
+	AuthChallenge        uint8 = 1 // Challenge of a critical request
+	AuthReply            uint8 = 2 // MAC answering a challenge
+	AuthAggressiveMode   uint8 = 3 // Leads a critical request authenticated without a challenge
+	AuthKeyStatusRequest uint8 = 4 // Session key status request of a user
+	AuthKeyStatus        uint8 = 5 // Session key status, with challenge data for the next key change
+	AuthKeyChange        uint8 = 6 // New session keys wrapped with the update key
+	AuthError            uint8 = 7 // Authentication error
+	AuthMAC              uint8 = 9 // Ends an aggressive mode request
+)
+
 // Qualifier codes
 type QualifierCode uint8
 
@@ -97,6 +259,9 @@ const (
 	Qualifier8BitCount            QualifierCode = 0x07 // 8-bit quantity
 	Qualifier16BitCount           QualifierCode = 0x08 // 16-bit quantity
 	Qualifier32BitCount           QualifierCode = 0x09 // 32-bit quantity
+	Qualifier8BitIndexPrefix      QualifierCode = 0x17 // 8-bit quantity, 8-bit index prefix per object
+	Qualifier16BitIndexPrefix     QualifierCode = 0x28 // 16-bit quantity, 16-bit index prefix per object
+	Qualifier32BitIndexPrefix     QualifierCode = 0x39 // 32-bit quantity, 32-bit index prefix per object
 	QualifierFreeFormat           QualifierCode = 0x5B // Free format
 )
 
@@ -128,6 +293,21 @@ type CountRange struct {
 
 func (CountRange) isRange() {}
 
+// IndexPrefixRange represents a count of objects each preceded by its index
+type IndexPrefixRange struct {
+	Count     uint32
+	IndexSize int // Size of each index prefix in bytes (1, 2 or 4)
+}
+
+func (IndexPrefixRange) isRange() {}
+
+// FreeFormatRange represents a count of objects each preceded by its size
+type FreeFormatRange struct {
+	Count uint32
+}
+
+func (FreeFormatRange) isRange() {}
+
 // NoRange represents headers with no range
 type NoRange struct{}
 
@@ -64,8 +64,19 @@ func (p *Parser) ReadObjectHeader() (*ObjectHeader, error) {
 		header.Range, err = p.readCount16()
 	case Qualifier32BitCount:
 		header.Range, err = p.readCount32()
+	case Qualifier8BitIndexPrefix:
+		header.Range, err = p.readIndexPrefix(p.readCount8, 1)
+	case Qualifier16BitIndexPrefix:
+		header.Range, err = p.readIndexPrefix(p.readCount16, 2)
+	case Qualifier32BitIndexPrefix:
+		header.Range, err = p.readIndexPrefix(p.readCount32, 4)
 	case QualifierNoRange:
 		header.Range = NoRange{}
+	case QualifierFreeFormat:
+		var r Range
+		if r, err = p.readCount8(); err == nil {
+			header.Range = FreeFormatRange{Count: r.(CountRange).Count}
+		}
 	default:
 		return nil, fmt.Errorf("unsupported qualifier: 0x%02X", header.Qualifier)
 	}
@@ -152,6 +163,39 @@ func (p *Parser) readCount32() (Range, error) {
 	return r, nil
 }
 
+// readIndexPrefix reads the count of an index-prefixed header
+func (p *Parser) readIndexPrefix(readCount func() (Range, error), indexSize int) (Range, error) {
+	r, err := readCount()
+	if err != nil {
+		return nil, err
+	}
+	return IndexPrefixRange{
+		Count:     r.(CountRange).Count,
+		IndexSize: indexSize,
+	}, nil
+}
+
+// ReadIndex reads an object index prefix of the given size (1, 2 or 4 bytes)
+func (p *Parser) ReadIndex(size int) (uint32, error) {
+	if p.Remaining() < size {
+		return 0, ErrInsufficientData
+	}
+
+	var index uint32
+	switch size {
+	case 1:
+		index = uint32(p.data[p.offset])
+	case 2:
+		index = uint32(binary.LittleEndian.Uint16(p.data[p.offset:]))
+	case 4:
+		index = binary.LittleEndian.Uint32(p.data[p.offset:])
+	default:
+		return 0, ErrInvalidRange
+	}
+	p.offset += size
+	return index, nil
+}
+
 // ReadBytes reads n bytes from the parser
 func (p *Parser) ReadBytes(n int) ([]byte, error) {
 	if p.Remaining() < n {
@@ -162,6 +206,17 @@ func (p *Parser) ReadBytes(n int) ([]byte, error) {
 	return data, nil
 }
 
+// ReadFreeFormatObject reads an object of a free-format header, returning
+// the object without its size prefix
+func (p *Parser) ReadFreeFormatObject() ([]byte, error) {
+	if p.Remaining() < 2 {
+		return nil, ErrInsufficientData
+	}
+	size := int(binary.LittleEndian.Uint16(p.data[p.offset:]))
+	p.offset += 2
+	return p.ReadBytes(size)
+}
+
 // Skip skips n bytes
 func (p *Parser) Skip(n int) error {
 	if p.Remaining() < n {
@@ -186,6 +241,10 @@ func GetCount(r Range) uint32 {
 		return 0
 	case CountRange:
 		return v.Count
+	case IndexPrefixRange:
+		return v.Count
+	case FreeFormatRange:
+		return v.Count
 	case NoRange:
 		return 0
 	default:
@@ -86,6 +86,50 @@ func BuildTimeSync(t time.Time) []byte {
 	return builder.Build()
 }
 
+// BuildLastRecordedTime builds the last recorded time object (Group 50, Var 3)
+// written at the end of LAN time synchronization
+func BuildLastRecordedTime(t time.Time) []byte {
+	builder := NewObjectBuilder()
+	builder.AddHeader(GroupTimeDate, TimeDateLastRecorded, Qualifier8BitCount, CountRange{Count: 1})
+	builder.AddRawData(FromTime(t).SerializeTime48())
+	return builder.Build()
+}
+
+// TimeAndInterval represents absolute time and interval (Group 50, Var 2),
+// used to schedule freezes
+type TimeAndInterval struct {
+	Time     DNP3Time
+	Interval uint32 // Interval in milliseconds, 0 for a single freeze
+}
+
+// Serialize serializes time and interval (Group 50, Var 2)
+func (t TimeAndInterval) Serialize() []byte {
+	buf := make([]byte, 10)
+	copy(buf, t.Time.SerializeTime48())
+	binary.LittleEndian.PutUint32(buf[6:], t.Interval)
+	return buf
+}
+
+// ParseTimeAndInterval parses time and interval from wire format
+func ParseTimeAndInterval(data []byte) TimeAndInterval {
+	if len(data) < 10 {
+		return TimeAndInterval{}
+	}
+	return TimeAndInterval{
+		Time:     ParseTime48(data),
+		Interval: binary.LittleEndian.Uint32(data[6:]),
+	}
+}
+
+// BuildFreezeAtTime builds the time and interval object of a freeze-at-time
+// request (Group 50, Var 2). The headers of the counters to freeze follow it.
+func BuildFreezeAtTime(t time.Time, interval time.Duration) []byte {
+	builder := NewObjectBuilder()
+	builder.AddHeader(GroupTimeDate, TimeDateInterval, Qualifier8BitCount, CountRange{Count: 1})
+	builder.AddRawData(TimeAndInterval{Time: FromTime(t), Interval: uint32(interval.Milliseconds())}.Serialize())
+	return builder.Build()
+}
+
 // BuildTimeSyncNow builds a time synchronization request with current time
 func BuildTimeSyncNow() []byte {
 	return BuildTimeSync(time.Now())
@@ -108,6 +152,11 @@ func (d TimeDelay) SerializeCoarse() []byte {
 	return buf
 }
 
+// SerializeFine serializes fine time delay in ms (Group 52, Var 2)
+func (d TimeDelay) SerializeFine() []byte {
+	return d.SerializeCoarse() // Same encoding, only the unit differs
+}
+
 // ParseTimeDelayCoarse parses coarse time delay
 func ParseTimeDelayCoarse(data []byte) TimeDelay {
 	if len(data) < 2 {
@@ -118,6 +167,42 @@ func ParseTimeDelayCoarse(data []byte) TimeDelay {
 	}
 }
 
+// ParseTimeDelay returns the delay of a coarse (seconds) or fine
+// (milliseconds) time delay object
+func ParseTimeDelay(variation uint8, data []byte) time.Duration {
+	delay := time.Duration(ParseTimeDelayCoarse(data).Delay)
+	if variation == TimeDelayCoarse {
+		return delay * time.Second
+	}
+	return delay * time.Millisecond
+}
+
+// BuildTimeDelay builds a time delay object, using the fine variation
+// (milliseconds) when the delay fits and the coarse one (seconds) otherwise
+func BuildTimeDelay(delay time.Duration) []byte {
+	variation := TimeDelayFine
+	value := delay.Milliseconds()
+	if value > 0xFFFF {
+		variation = TimeDelayCoarse
+		value = int64((delay + time.Second - 1) / time.Second)
+		if value > 0xFFFF {
+			value = 0xFFFF
+		}
+	}
+	if value < 0 {
+		value = 0
+	}
+
+	builder := NewObjectBuilder()
+	builder.AddHeader(GroupTimeDelay, variation, Qualifier8BitCount, CountRange{Count: 1})
+	if variation == TimeDelayFine {
+		builder.AddRawData(NewTimeDelay(uint16(value)).SerializeFine())
+	} else {
+		builder.AddRawData(NewTimeDelay(uint16(value)).SerializeCoarse())
+	}
+	return builder.Build()
+}
+
 // RelativeTime represents relative time offset for events
 type RelativeTime uint16
 
@@ -52,6 +52,14 @@ func ValidateRange(r Range) error {
 		if v.Count == 0 {
 			return fmt.Errorf("%w: count=0", ErrInvalidCount)
 		}
+	case IndexPrefixRange:
+		if v.Count == 0 {
+			return fmt.Errorf("%w: count=0", ErrInvalidCount)
+		}
+	case FreeFormatRange:
+		if v.Count == 0 {
+			return fmt.Errorf("%w: count=0", ErrInvalidCount)
+		}
 	case NoRange:
 		// No validation needed
 	default:
@@ -119,10 +127,11 @@ func ValidateAPDU(apdu *APDU) error {
 func IsValidGroup(group uint8) bool {
 	// Common valid groups
 	validGroups := map[uint8]bool{
+		0: true, // Device attributes
 		1: true, 2: true, 3: true, 4: true,
 		10: true, 11: true, 12: true,
 		20: true, 21: true, 22: true, 23: true,
-		30: true, 31: true, 32: true, 33: true,
+		30: true, 31: true, 32: true, 33: true, 34: true,
 		40: true, 41: true, 42: true, 43: true,
 		50: true, 51: true, 52: true,
 		60: true, 61: true, 62: true, 63: true,
@@ -161,12 +170,16 @@ func IsValidVariation(group, variation uint8) bool {
 		return variation >= 1 && variation <= 6
 	case GroupAnalogInputEvent: // Group 32
 		return variation >= 1 && variation <= 8
+	case GroupAnalogInputDeadband: // Group 34
+		return variation >= 1 && variation <= 3
 	case GroupAnalogOutputStatus: // Group 40
 		return variation >= 1 && variation <= 4
 	case GroupAnalogOutputCommand: // Group 41
 		return variation >= 1 && variation <= 4
 	case GroupTimeDate: // Group 50
 		return variation >= 1 && variation <= 3
+	case GroupTimeDelay: // Group 52
+		return variation >= 1 && variation <= 2
 	case GroupClass0Data: // Group 60
 		return variation >= 1 && variation <= 4
 	default:
@@ -187,6 +200,9 @@ func IsValidQualifier(q QualifierCode) bool {
 		Qualifier8BitCount:            true,
 		Qualifier16BitCount:           true,
 		Qualifier32BitCount:           true,
+		Qualifier8BitIndexPrefix:      true,
+		Qualifier16BitIndexPrefix:     true,
+		Qualifier32BitIndexPrefix:     true,
 		QualifierFreeFormat:           true,
 	}
 	return validQualifiers[q]
@@ -234,6 +250,36 @@ func GetObjectSize(group, variation uint8) int {
 			return 3
 		}
 
+	case GroupDoubleBitBinaryInput: // Group 3
+		switch variation {
+		case 2: // With flags
+			return 1
+		}
+
+	case GroupDoubleBitBinaryEvent: // Group 4
+		switch variation {
+		case 1: // Without time
+			return 1
+		case 2: // With absolute time
+			return 7
+		case 3: // With relative time
+			return 3
+		}
+
+	case GroupBinaryOutput: // Group 10
+		switch variation {
+		case 2: // With flags
+			return 1
+		}
+
+	case GroupBinaryOutputEvent: // Group 11
+		switch variation {
+		case 1: // Without time
+			return 1
+		case 2: // With absolute time
+			return 7
+		}
+
 	case GroupBinaryOutputCommand: // Group 12
 		switch variation {
 		case 1: // CROB
@@ -242,10 +288,54 @@ func GetObjectSize(group, variation uint8) int {
 
 	case GroupCounter: // Group 20
 		switch variation {
-		case 1, 5: // 32-bit
+		case 1: // 32-bit with flag
+			return 5
+		case 2: // 16-bit with flag
+			return 3
+		case 5: // 32-bit without flag
+			return 4
+		case 6: // 16-bit without flag
+			return 2
+		}
+
+	case GroupCounterEvent: // Group 22
+		switch variation {
+		case 1: // 32-bit with flag
 			return 5
-		case 2, 6: // 16-bit
+		case 2: // 16-bit with flag
 			return 3
+		case 5: // 32-bit with flag and time
+			return 11
+		case 6: // 16-bit with flag and time
+			return 9
+		}
+
+	case GroupFrozenCounter: // Group 21
+		switch variation {
+		case 1: // 32-bit with flag
+			return 5
+		case 2: // 16-bit with flag
+			return 3
+		case 5: // 32-bit with flag and time
+			return 11
+		case 6: // 16-bit with flag and time
+			return 9
+		case 9: // 32-bit without flag
+			return 4
+		case 10: // 16-bit without flag
+			return 2
+		}
+
+	case GroupFrozenCounterEvent: // Group 23
+		switch variation {
+		case 1: // 32-bit with flag
+			return 5
+		case 2: // 16-bit with flag
+			return 3
+		case 5: // 32-bit with flag and time
+			return 11
+		case 6: // 16-bit with flag and time
+			return 9
 		}
 
 	case GroupAnalogInput: // Group 30
@@ -254,6 +344,10 @@ func GetObjectSize(group, variation uint8) int {
 			return 5
 		case 2: // 16-bit with flag
 			return 3
+		case 3: // 32-bit without flag
+			return 4
+		case 4: // 16-bit without flag
+			return 2
 		case 5: // Float with flag
 			return 5
 		case 6: // Double with flag
@@ -270,6 +364,24 @@ func GetObjectSize(group, variation uint8) int {
 			return 11
 		case 4: // 16-bit with time
 			return 9
+		case 5: // Float no time
+			return 5
+		case 6: // Double no time
+			return 9
+		case 7: // Float with time
+			return 11
+		case 8: // Double with time
+			return 15
+		}
+
+	case GroupAnalogInputDeadband: // Group 34
+		switch variation {
+		case 1: // 16-bit
+			return 2
+		case 2: // 32-bit
+			return 4
+		case 3: // Float
+			return 4
 		}
 
 	case GroupAnalogOutputStatus: // Group 40
@@ -284,6 +396,26 @@ func GetObjectSize(group, variation uint8) int {
 			return 9
 		}
 
+	case GroupAnalogOutputEvent: // Group 42
+		switch variation {
+		case 1: // 32-bit no time
+			return 5
+		case 2: // 16-bit no time
+			return 3
+		case 3: // 32-bit with time
+			return 11
+		case 4: // 16-bit with time
+			return 9
+		case 5: // Float no time
+			return 5
+		case 6: // Double no time
+			return 9
+		case 7: // Float with time
+			return 11
+		case 8: // Double with time
+			return 15
+		}
+
 	case GroupAnalogOutputCommand: // Group 41
 		switch variation {
 		case 1: // 32-bit
@@ -300,6 +432,27 @@ func GetObjectSize(group, variation uint8) int {
 		switch variation {
 		case 1: // Absolute time
 			return 6
+		case 2: // Absolute time and interval
+			return 10
+		case 3: // Absolute time at last recorded time
+			return 6
+		}
+
+	case GroupTimeDelay: // Group 52
+		switch variation {
+		case 1, 2: // Coarse (seconds) or fine (milliseconds)
+			return 2
+		}
+
+	case GroupOctetString, GroupOctetStringEvent: // Groups 110, 111
+		return int(variation) // String length, 0 only in requests
+
+	case GroupAuthentication: // Group 120
+		switch variation {
+		case AuthAggressiveMode: // Challenge sequence number and user
+			return 6
+		case AuthKeyStatusRequest: // User
+			return 2
 		}
 	}
 
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/secauth"
 	"avaneesh/dnp3-go/pkg/types"
 )
 
@@ -21,6 +22,26 @@ type Master interface {
 	ScanIntegrity() error
 	ScanClasses(classes app.ClassField) error
 	ScanRange(objGroup, variation uint8, start, stop uint16) error
+	SyncTime(mode TimeSyncMode) error                            // Sets the outstation clock from GetTime
+	AssignClass(class, objGroup uint8, start, stop uint16) error // Moves points to event class 0-3
+
+	// Analog input deadbands (G34); variation 1: 16-bit, 2: 32-bit, 3: float
+	ReadDeadbands(start, stop uint16) ([]types.IndexedDeadband, error)
+	WriteDeadbands(variation uint8, deadbands []types.IndexedDeadband) error
+
+	// Octet strings (G110) of 1-255 bytes
+	ReadOctetStrings(start, stop uint16) ([]types.IndexedOctetString, error)
+	WriteOctetStrings(values []types.IndexedOctetString) error
+
+	// Device attributes (G0): vendor, product, versions, point counts, ...
+	ReadDeviceAttributes() ([]types.DeviceAttribute, error)
+
+	// File transfer (G70); names are paths on the outstation such as "/logs/event.txt"
+	ReadFile(name string) ([]byte, error)
+	WriteFile(name string, data []byte) error
+	ListDirectory(path string) ([]types.FileInfo, error)
+	DeleteFile(name string) error
+	GetFileInfo(name string) (types.FileInfo, error)
 
 	// Command operations
 	SelectAndOperate(commands []types.Command) ([]types.CommandStatus, error)
@@ -63,6 +84,7 @@ type SOEHandler interface {
 	ProcessFrozenCounter(info HeaderInfo, values []types.IndexedFrozenCounter)
 	ProcessBinaryOutputStatus(info HeaderInfo, values []types.IndexedBinaryOutputStatus)
 	ProcessAnalogOutputStatus(info HeaderInfo, values []types.IndexedAnalogOutputStatus)
+	ProcessOctetString(info HeaderInfo, values []types.IndexedOctetString)
 }
 
 // ResponseInfo contains information about a response fragment
@@ -94,6 +116,21 @@ const (
 	TaskTypeClassScan
 	TaskTypeRangeScan
 	TaskTypeCommand
+	TaskTypeTimeSync
+	TaskTypeAssignClass
+	TaskTypeDeadband
+	TaskTypeOctetString
+	TaskTypeDeviceAttributes
+	TaskTypeFile
+	TaskTypeStartup
+)
+
+// TimeSyncMode selects the time synchronization procedure
+type TimeSyncMode int
+
+const (
+	TimeSyncNonLAN TimeSyncMode = iota // DELAY MEASUREMENT, then WRITE G50V1 corrected for the link delay (serial)
+	TimeSyncLAN                        // RECORD CURRENT TIME, then WRITE G50V3 last recorded time
 )
 
 // TaskResult indicates the result of a task
@@ -131,6 +168,13 @@ type MasterConfig struct {
 	// Advanced
 	MaxRxFragSize uint16 // Default: 2048
 	MaxTxFragSize uint16 // Default: 2048
+
+	// File transfer credentials; when set, the master authenticates before OPEN and DELETE
+	FileUserName string
+	FilePassword string
+
+	// Secure Authentication (G120) of critical requests, nil to disable
+	SecureAuth *secauth.MasterConfig
 }
 
 // DefaultMasterConfig returns a master config with default values
@@ -28,6 +28,9 @@ func newMaster(config MasterConfig, callbacks MasterCallbacks, ch *channel.Chann
 		IntegrityPeriod:       config.IntegrityPeriod,
 		MaxRxFragSize:         config.MaxRxFragSize,
 		MaxTxFragSize:         config.MaxTxFragSize,
+		FileUserName:          config.FileUserName,
+		FilePassword:          config.FilePassword,
+		SecureAuth:            config.SecureAuth,
 	}
 
 	wrappedCallbacks := &masterCallbacksWrapper{callbacks: callbacks}
@@ -51,6 +54,18 @@ type masterWrapper struct {
 		ScanIntegrity() error
 		ScanClasses(classes app.ClassField) error
 		ScanRange(objGroup, variation uint8, start, stop uint16) error
+		SyncTime(mode master.TimeSyncMode) error
+		AssignClass(class, objGroup uint8, start, stop uint16) error
+		ReadDeadbands(start, stop uint16) ([]types.IndexedDeadband, error)
+		WriteDeadbands(variation uint8, deadbands []types.IndexedDeadband) error
+		ReadOctetStrings(start, stop uint16) ([]types.IndexedOctetString, error)
+		WriteOctetStrings(values []types.IndexedOctetString) error
+		ReadDeviceAttributes() ([]types.DeviceAttribute, error)
+		ReadFile(name string) ([]byte, error)
+		WriteFile(name string, data []byte) error
+		ListDirectory(path string) ([]types.FileInfo, error)
+		DeleteFile(name string) error
+		GetFileInfo(name string) (types.FileInfo, error)
 		SelectAndOperate(commands []types.Command) ([]types.CommandStatus, error)
 		DirectOperate(commands []types.Command) ([]types.CommandStatus, error)
 	}
@@ -97,6 +112,54 @@ func (m *masterWrapper) ScanRange(objGroup, variation uint8, start, stop uint16)
 	return m.internal.ScanRange(objGroup, variation, start, stop)
 }
 
+func (m *masterWrapper) SyncTime(mode TimeSyncMode) error {
+	return m.internal.SyncTime(master.TimeSyncMode(mode))
+}
+
+func (m *masterWrapper) AssignClass(class, objGroup uint8, start, stop uint16) error {
+	return m.internal.AssignClass(class, objGroup, start, stop)
+}
+
+func (m *masterWrapper) ReadDeadbands(start, stop uint16) ([]types.IndexedDeadband, error) {
+	return m.internal.ReadDeadbands(start, stop)
+}
+
+func (m *masterWrapper) WriteDeadbands(variation uint8, deadbands []types.IndexedDeadband) error {
+	return m.internal.WriteDeadbands(variation, deadbands)
+}
+
+func (m *masterWrapper) ReadOctetStrings(start, stop uint16) ([]types.IndexedOctetString, error) {
+	return m.internal.ReadOctetStrings(start, stop)
+}
+
+func (m *masterWrapper) WriteOctetStrings(values []types.IndexedOctetString) error {
+	return m.internal.WriteOctetStrings(values)
+}
+
+func (m *masterWrapper) ReadDeviceAttributes() ([]types.DeviceAttribute, error) {
+	return m.internal.ReadDeviceAttributes()
+}
+
+func (m *masterWrapper) ReadFile(name string) ([]byte, error) {
+	return m.internal.ReadFile(name)
+}
+
+func (m *masterWrapper) WriteFile(name string, data []byte) error {
+	return m.internal.WriteFile(name, data)
+}
+
+func (m *masterWrapper) ListDirectory(path string) ([]types.FileInfo, error) {
+	return m.internal.ListDirectory(path)
+}
+
+func (m *masterWrapper) DeleteFile(name string) error {
+	return m.internal.DeleteFile(name)
+}
+
+func (m *masterWrapper) GetFileInfo(name string) (types.FileInfo, error) {
+	return m.internal.GetFileInfo(name)
+}
+
 func (m *masterWrapper) SelectAndOperate(commands []types.Command) ([]types.CommandStatus, error) {
 	return m.internal.SelectAndOperate(commands)
 }
@@ -189,6 +252,15 @@ func (w *masterCallbacksWrapper) ProcessAnalogOutputStatus(info master.HeaderInf
 	}, values)
 }
 
+func (w *masterCallbacksWrapper) ProcessOctetString(info master.HeaderInfo, values []types.IndexedOctetString) {
+	w.callbacks.ProcessOctetString(HeaderInfo{
+		Group:     info.Group,
+		Variation: info.Variation,
+		Qualifier: info.Qualifier,
+		IsEvent:   info.IsEvent,
+	}, values)
+}
+
 func (w *masterCallbacksWrapper) OnReceiveIIN(iin types.IIN) {
 	w.callbacks.OnReceiveIIN(iin)
 }
@@ -1,8 +1,12 @@
 package dnp3
 
 import (
+	"io"
+	"io/fs"
 	"time"
 
+	"avaneesh/dnp3-go/pkg/outstation"
+	"avaneesh/dnp3-go/pkg/secauth"
 	"avaneesh/dnp3-go/pkg/types"
 )
 
@@ -14,6 +18,9 @@ type Outstation interface {
 	// SetConfig updates the outstation configuration
 	SetConfig(config OutstationConfig) error
 
+	// EventBufferStats returns the event buffer usage of each event type
+	EventBufferStats() EventBufferStats
+
 	// Control
 	Enable() error
 	Disable() error
@@ -32,6 +39,23 @@ type OutstationCallbacks interface {
 
 	// GetApplicationIIN returns application-specific IIN bits
 	GetApplicationIIN() types.IIN
+
+	// OnTimeSync is called with the new time after the master synchronizes
+	// the outstation clock
+	OnTimeSync(t time.Time)
+
+	// OnEventBufferOverflow is called when events of a type are lost because
+	// its buffer is full. It is called once per type until the master next
+	// confirms events, and must not block.
+	OnEventBufferOverflow(eventType EventType)
+
+	// OnColdRestart is called on a COLD RESTART request and returns the time
+	// the master should wait before communicating again
+	OnColdRestart() time.Duration
+
+	// OnWarmRestart is called on a WARM RESTART request and returns the time
+	// the master should wait before communicating again
+	OnWarmRestart() time.Duration
 }
 
 // CommandHandler processes commands from master
@@ -74,6 +98,60 @@ const (
 	OperateTypeDirectOperateNoAck
 )
 
+// EventType identifies the measurement type of an event
+type EventType int
+
+const (
+	EventTypeBinary EventType = iota
+	EventTypeDoubleBitBinary
+	EventTypeAnalog
+	EventTypeCounter
+	EventTypeFrozenCounter
+	EventTypeBinaryOutputStatus
+	EventTypeAnalogOutputStatus
+	EventTypeOctetString
+)
+
+// EventOverflowPolicy chooses which event is lost when the buffer for an
+// event type is full
+type EventOverflowPolicy int
+
+const (
+	EventOverflowDiscardOldest   EventOverflowPolicy = iota // Discard the oldest event of the type
+	EventOverflowDiscardNewest                              // Discard the incoming event
+	EventOverflowKeepFirstOfType                            // Keep the first event of each point, discarding later ones
+)
+
+// DeadbandMode chooses how an analog or counter point decides that a change
+// from the value last reported in an event is large enough to report again
+type DeadbandMode int
+
+const (
+	DeadbandAbsolute    DeadbandMode = iota // The change exceeds Deadband
+	DeadbandPercent                         // The change exceeds Deadband percent of FullScale
+	DeadbandIntegrating                     // The change summed over time exceeds Deadband value-seconds, so slow drifts are reported
+)
+
+// EventTypeStats reports how one event type uses its share of the buffer
+type EventTypeStats struct {
+	Limit     uint   // Configured capacity
+	Count     uint   // Events buffered now
+	HighWater uint   // Most events buffered at once
+	Discarded uint64 // Events lost because the type was full
+}
+
+// EventBufferStats reports event buffer usage per event type
+type EventBufferStats struct {
+	Binary             EventTypeStats
+	DoubleBitBinary    EventTypeStats
+	Analog             EventTypeStats
+	Counter            EventTypeStats
+	FrozenCounter      EventTypeStats
+	BinaryOutputStatus EventTypeStats
+	AnalogOutputStatus EventTypeStats
+	OctetString        EventTypeStats
+}
+
 // EventMode controls event generation
 type EventMode int
 
@@ -101,6 +179,7 @@ const (
 	MeasurementTypeFrozenCounter
 	MeasurementTypeBinaryOutputStatus
 	MeasurementTypeAnalogOutputStatus
+	MeasurementTypeOctetString
 )
 
 // OutstationConfig configures an outstation session
@@ -115,17 +194,25 @@ type OutstationConfig struct {
 	// Database
 	Database DatabaseConfig
 
-	// Event buffers
-	MaxBinaryEvents       uint // Per class
-	MaxAnalogEvents       uint
-	MaxCounterEvents      uint
-	MaxDoubleBitEvents    uint
+	// Event buffers, sized per event type across all classes
+	MaxBinaryEvents        uint                // Default: 100
+	MaxAnalogEvents        uint                // Default: 100
+	MaxCounterEvents       uint                // Default: 100
+	MaxDoubleBitEvents     uint                // Default: 100
+	MaxFrozenCounterEvents uint                // Default: 100
+	MaxBinaryOutputEvents  uint                // Default: 100
+	MaxAnalogOutputEvents  uint                // Default: 100
+	MaxOctetStringEvents   uint                // Default: 100
+	EventOverflowPolicy    EventOverflowPolicy // Default: discard oldest, reported in IIN2.3
 
 	// Behavior
 	AllowUnsolicited      bool          // Allow unsolicited responses
 	UnsolConfirmTimeout   time.Duration // Default: 5s
+	UnsolMaxRetries       uint          // Default: 3, retransmissions of an unconfirmed unsolicited response
+	SolConfirmTimeout     time.Duration // Default: 5s, events are kept until confirmed
 	SelectTimeout         time.Duration // Default: 10s
 	MaxControlsPerRequest uint          // Default: 16
+	TimeSyncInterval      time.Duration // Default: 30m, NeedTime is requested again this long after a sync (0 = startup only)
 
 	// IIN bits
 	LocalControl  bool // IIN1.5
@@ -134,6 +221,52 @@ type OutstationConfig struct {
 	// Advanced
 	MaxRxFragSize uint16 // Default: 2048
 	MaxTxFragSize uint16 // Default: 2048
+
+	// Device attributes (G0) reported to the master
+	DeviceAttributes DeviceAttributes
+
+	// File transfer (G70), disabled without a file system
+	FileTransfer FileTransferConfig
+
+	// Secure Authentication (G120) of critical requests, nil to disable
+	SecureAuth *secauth.OutstationConfig
+}
+
+// FileTransferConfig serves files to the master
+type FileTransferConfig struct {
+	FileSystem   FileSystem
+	MaxBlockSize uint16                               // Default: as much as a fragment holds
+	MaxOpenFiles uint                                 // Default: 4
+	Authenticate func(userName, password string) bool // When set, OPEN and DELETE need a key from AUTHENTICATE FILE
+}
+
+// FileSystem is the file store of file transfer. Names use forward slashes
+// relative to its root.
+type FileSystem interface {
+	fs.FS
+	OpenWrite(name string, append bool) (io.WriteCloser, error)
+	Remove(name string) error
+}
+
+// DirFileSystem returns a FileSystem serving the files below a directory
+func DirFileSystem(dir string) (FileSystem, error) {
+	return outstation.DirFileSystem(dir)
+}
+
+// DeviceAttributes identify the device to a master reading group 0. Empty
+// strings are not reported; point counts and fragment sizes always are.
+type DeviceAttributes struct {
+	ManufacturerName     string
+	ProductNameAndModel  string
+	SoftwareVersion      string
+	HardwareVersion      string
+	SerialNumber         string
+	SubsetAndConformance string
+	DeviceName           string
+	IDCode               string
+	LocationName         string
+	OwnerName            string
+	UserDefined          []types.DeviceAttribute // Sets 1 and above, or further set 0 variations
 }
 
 // DatabaseConfig defines point counts and configurations
@@ -145,13 +278,15 @@ type DatabaseConfig struct {
 	FrozenCounter []FrozenCounterPointConfig
 	BinaryOutput  []BinaryOutputStatusPointConfig
 	AnalogOutput  []AnalogOutputStatusPointConfig
+	OctetString   []OctetStringPointConfig
 }
 
 // BinaryPointConfig configures a binary point
 type BinaryPointConfig struct {
-	StaticVariation uint8 // Default variation for static reads
-	EventVariation  uint8 // Default variation for events
-	Class           uint8 // Event class (0=none, 1-3)
+	StaticVariation uint8               // Default variation for static reads
+	EventVariation  uint8               // Default variation for events
+	Class           uint8               // Event class (0=none, 1-3)
+	ChatterFilter   ChatterFilterConfig // Default: disabled
 }
 
 // DoubleBitBinaryPointConfig configures a double-bit binary point
@@ -159,6 +294,15 @@ type DoubleBitBinaryPointConfig struct {
 	StaticVariation uint8
 	EventVariation  uint8
 	Class           uint8
+	ChatterFilter   ChatterFilterConfig // Default: disabled
+}
+
+// ChatterFilterConfig suppresses the events of a point that changes state
+// too often. The point reports the CHATTER_FILTER flag while suppressed.
+type ChatterFilterConfig struct {
+	Transitions uint          // State changes within Window that trip the filter, zero to disable
+	Window      time.Duration // Time over which state changes are counted
+	QuietPeriod time.Duration // Time without state changes before events resume
 }
 
 // AnalogPointConfig configures an analog point
@@ -166,7 +310,9 @@ type AnalogPointConfig struct {
 	StaticVariation uint8
 	EventVariation  uint8
 	Class           uint8
-	Deadband        float64 // Event generation threshold
+	Deadband        float64      // Event generation threshold
+	DeadbandMode    DeadbandMode // Default: absolute
+	FullScale       float64      // Span of the point, for DeadbandPercent
 }
 
 // CounterPointConfig configures a counter point
@@ -174,7 +320,9 @@ type CounterPointConfig struct {
 	StaticVariation uint8
 	EventVariation  uint8
 	Class           uint8
-	Deadband        uint32 // Event generation threshold
+	Deadband        uint32       // Event generation threshold
+	DeadbandMode    DeadbandMode // Default: absolute
+	FullScale       uint32       // Span of the point, for DeadbandPercent
 }
 
 // FrozenCounterPointConfig configures a frozen counter point
@@ -199,18 +347,32 @@ type AnalogOutputStatusPointConfig struct {
 	Deadband        float64
 }
 
+// OctetStringPointConfig configures an octet string point, reported in the
+// variation equal to its length (1-255 bytes)
+type OctetStringPointConfig struct {
+	Class uint8 // Event class (0=none, 1-3)
+}
+
 // DefaultOutstationConfig returns an outstation config with default values
 func DefaultOutstationConfig() OutstationConfig {
 	return OutstationConfig{
-		MaxBinaryEvents:       100,
-		MaxAnalogEvents:       100,
-		MaxCounterEvents:      100,
-		MaxDoubleBitEvents:    100,
-		AllowUnsolicited:      true,
-		UnsolConfirmTimeout:   5 * time.Second,
-		SelectTimeout:         10 * time.Second,
-		MaxControlsPerRequest: 16,
-		MaxRxFragSize:         2048,
-		MaxTxFragSize:         2048,
+		MaxBinaryEvents:        100,
+		MaxAnalogEvents:        100,
+		MaxCounterEvents:       100,
+		MaxDoubleBitEvents:     100,
+		MaxFrozenCounterEvents: 100,
+		MaxBinaryOutputEvents:  100,
+		MaxAnalogOutputEvents:  100,
+		MaxOctetStringEvents:   100,
+		EventOverflowPolicy:    EventOverflowDiscardOldest,
+		AllowUnsolicited:       true,
+		UnsolConfirmTimeout:    5 * time.Second,
+		UnsolMaxRetries:        3,
+		SolConfirmTimeout:      5 * time.Second,
+		SelectTimeout:          10 * time.Second,
+		MaxControlsPerRequest:  16,
+		TimeSyncInterval:       30 * time.Minute,
+		MaxRxFragSize:          2048,
+		MaxTxFragSize:          2048,
 	}
 }
@@ -2,6 +2,7 @@ package dnp3
 
 import (
 	"errors"
+	"time"
 
 	"avaneesh/dnp3-go/pkg/channel"
 	"avaneesh/dnp3-go/pkg/internal/logger"
@@ -11,25 +12,7 @@ import (
 
 // newOutstation creates a new outstation instance
 func newOutstation(config OutstationConfig, callbacks OutstationCallbacks, ch *channel.Channel, log logger.Logger) (Outstation, error) {
-	// Convert dnp3 config to outstation config
-	outstationConfig := outstation.OutstationConfig{
-		ID:                    config.ID,
-		LocalAddress:          config.LocalAddress,
-		RemoteAddress:         config.RemoteAddress,
-		Database:              convertDatabaseConfig(config.Database),
-		MaxBinaryEvents:       config.MaxBinaryEvents,
-		MaxAnalogEvents:       config.MaxAnalogEvents,
-		MaxCounterEvents:      config.MaxCounterEvents,
-		MaxDoubleBitEvents:    config.MaxDoubleBitEvents,
-		AllowUnsolicited:      config.AllowUnsolicited,
-		UnsolConfirmTimeout:   config.UnsolConfirmTimeout,
-		SelectTimeout:         config.SelectTimeout,
-		MaxControlsPerRequest: config.MaxControlsPerRequest,
-		LocalControl:          config.LocalControl,
-		DeviceTrouble:         config.DeviceTrouble,
-		MaxRxFragSize:         config.MaxRxFragSize,
-		MaxTxFragSize:         config.MaxTxFragSize,
-	}
+	outstationConfig := convertOutstationConfig(config)
 
 	wrappedCallbacks := &outstationCallbacksWrapper{callbacks: callbacks}
 	internalOutstation, err := outstation.New(outstationConfig, wrappedCallbacks, ch, log)
@@ -40,6 +23,44 @@ func newOutstation(config OutstationConfig, callbacks OutstationCallbacks, ch *c
 	return &outstationWrapper{internal: internalOutstation}, nil
 }
 
+// convertOutstationConfig converts a dnp3 config to an outstation config
+func convertOutstationConfig(config OutstationConfig) outstation.OutstationConfig {
+	return outstation.OutstationConfig{
+		ID:                     config.ID,
+		LocalAddress:           config.LocalAddress,
+		RemoteAddress:          config.RemoteAddress,
+		Database:               convertDatabaseConfig(config.Database),
+		MaxBinaryEvents:        config.MaxBinaryEvents,
+		MaxAnalogEvents:        config.MaxAnalogEvents,
+		MaxCounterEvents:       config.MaxCounterEvents,
+		MaxDoubleBitEvents:     config.MaxDoubleBitEvents,
+		MaxFrozenCounterEvents: config.MaxFrozenCounterEvents,
+		MaxBinaryOutputEvents:  config.MaxBinaryOutputEvents,
+		MaxAnalogOutputEvents:  config.MaxAnalogOutputEvents,
+		MaxOctetStringEvents:   config.MaxOctetStringEvents,
+		EventOverflowPolicy:    outstation.EventOverflowPolicy(config.EventOverflowPolicy),
+		AllowUnsolicited:       config.AllowUnsolicited,
+		UnsolConfirmTimeout:    config.UnsolConfirmTimeout,
+		UnsolMaxRetries:        config.UnsolMaxRetries,
+		SolConfirmTimeout:      config.SolConfirmTimeout,
+		SelectTimeout:          config.SelectTimeout,
+		MaxControlsPerRequest:  config.MaxControlsPerRequest,
+		TimeSyncInterval:       config.TimeSyncInterval,
+		LocalControl:           config.LocalControl,
+		DeviceTrouble:          config.DeviceTrouble,
+		MaxRxFragSize:          config.MaxRxFragSize,
+		MaxTxFragSize:          config.MaxTxFragSize,
+		DeviceAttributes:       outstation.DeviceAttributes(config.DeviceAttributes),
+		FileTransfer: outstation.FileTransferConfig{
+			FileSystem:   config.FileTransfer.FileSystem,
+			MaxBlockSize: config.FileTransfer.MaxBlockSize,
+			MaxOpenFiles: config.FileTransfer.MaxOpenFiles,
+			Authenticate: config.FileTransfer.Authenticate,
+		},
+		SecureAuth: config.SecureAuth,
+	}
+}
+
 func convertDatabaseConfig(config DatabaseConfig) outstation.DatabaseConfig {
 	return outstation.DatabaseConfig{
 		Binary:        convertBinaryConfigs(config.Binary),
@@ -49,6 +70,7 @@ func convertDatabaseConfig(config DatabaseConfig) outstation.DatabaseConfig {
 		FrozenCounter: convertFrozenCounterConfigs(config.FrozenCounter),
 		BinaryOutput:  convertBinaryOutputConfigs(config.BinaryOutput),
 		AnalogOutput:  convertAnalogOutputConfigs(config.AnalogOutput),
+		OctetString:   convertOctetStringConfigs(config.OctetString),
 	}
 }
 
@@ -59,6 +81,7 @@ func convertBinaryConfigs(configs []BinaryPointConfig) []outstation.BinaryPointC
 			StaticVariation: c.StaticVariation,
 			EventVariation:  c.EventVariation,
 			Class:           c.Class,
+			ChatterFilter:   outstation.ChatterFilterConfig(c.ChatterFilter),
 		}
 	}
 	return result
@@ -71,6 +94,7 @@ func convertDoubleBitConfigs(configs []DoubleBitBinaryPointConfig) []outstation.
 			StaticVariation: c.StaticVariation,
 			EventVariation:  c.EventVariation,
 			Class:           c.Class,
+			ChatterFilter:   outstation.ChatterFilterConfig(c.ChatterFilter),
 		}
 	}
 	return result
@@ -84,6 +108,8 @@ func convertAnalogConfigs(configs []AnalogPointConfig) []outstation.AnalogPointC
 			EventVariation:  c.EventVariation,
 			Class:           c.Class,
 			Deadband:        c.Deadband,
+			DeadbandMode:    outstation.DeadbandMode(c.DeadbandMode),
+			FullScale:       c.FullScale,
 		}
 	}
 	return result
@@ -97,6 +123,8 @@ func convertCounterConfigs(configs []CounterPointConfig) []outstation.CounterPoi
 			EventVariation:  c.EventVariation,
 			Class:           c.Class,
 			Deadband:        c.Deadband,
+			DeadbandMode:    outstation.DeadbandMode(c.DeadbandMode),
+			FullScale:       c.FullScale,
 		}
 	}
 	return result
@@ -139,6 +167,16 @@ func convertAnalogOutputConfigs(configs []AnalogOutputStatusPointConfig) []outst
 	return result
 }
 
+func convertOctetStringConfigs(configs []OctetStringPointConfig) []outstation.OctetStringPointConfig {
+	result := make([]outstation.OctetStringPointConfig, len(configs))
+	for i, c := range configs {
+		result[i] = outstation.OctetStringPointConfig{
+			Class: c.Class,
+		}
+	}
+	return result
+}
+
 // outstationCallbacksWrapper wraps dnp3.OutstationCallbacks to outstation.OutstationCallbacks
 type outstationCallbacksWrapper struct {
 	callbacks OutstationCallbacks
@@ -209,6 +247,27 @@ func (w *outstationCallbacksWrapper) GetApplicationIIN() types.IIN {
 	return w.callbacks.GetApplicationIIN()
 }
 
+func (w *outstationCallbacksWrapper) OnTimeSync(t time.Time) {
+	w.callbacks.OnTimeSync(t)
+}
+
+// String returns the event type name
+func (t EventType) String() string {
+	return outstation.EventType(t).String()
+}
+
+func (w *outstationCallbacksWrapper) OnEventBufferOverflow(eventType outstation.EventType) {
+	w.callbacks.OnEventBufferOverflow(EventType(eventType))
+}
+
+func (w *outstationCallbacksWrapper) OnColdRestart() time.Duration {
+	return w.callbacks.OnColdRestart()
+}
+
+func (w *outstationCallbacksWrapper) OnWarmRestart() time.Duration {
+	return w.callbacks.OnWarmRestart()
+}
+
 // updateHandlerWrapper wraps UpdateHandler
 type updateHandlerWrapper struct {
 	handler outstation.UpdateHandler
@@ -272,6 +331,12 @@ func (b *UpdateBuilder) UpdateAnalogOutputStatus(value types.AnalogOutputStatus,
 	return b
 }
 
+// UpdateOctetString updates an octet string point
+func (b *UpdateBuilder) UpdateOctetString(value types.OctetString, index uint16, mode EventMode) *UpdateBuilder {
+	b.builder.UpdateOctetString(value, index, outstation.EventMode(mode))
+	return b
+}
+
 // Build builds the updates
 func (b *UpdateBuilder) Build() *Updates {
 	internalUpdates := b.builder.Build()
@@ -286,6 +351,7 @@ type outstationWrapper struct {
 		Shutdown() error
 		Apply(updates *outstation.Updates) error
 		SetConfig(config outstation.OutstationConfig) error
+		EventBufferStats() outstation.EventBufferStats
 	}
 }
 
@@ -311,23 +377,19 @@ func (o *outstationWrapper) Apply(updates *Updates) error {
 }
 
 func (o *outstationWrapper) SetConfig(config OutstationConfig) error {
-	outstationConfig := outstation.OutstationConfig{
-		ID:                    config.ID,
-		LocalAddress:          config.LocalAddress,
-		RemoteAddress:         config.RemoteAddress,
-		Database:              convertDatabaseConfig(config.Database),
-		MaxBinaryEvents:       config.MaxBinaryEvents,
-		MaxAnalogEvents:       config.MaxAnalogEvents,
-		MaxCounterEvents:      config.MaxCounterEvents,
-		MaxDoubleBitEvents:    config.MaxDoubleBitEvents,
-		AllowUnsolicited:      config.AllowUnsolicited,
-		UnsolConfirmTimeout:   config.UnsolConfirmTimeout,
-		SelectTimeout:         config.SelectTimeout,
-		MaxControlsPerRequest: config.MaxControlsPerRequest,
-		LocalControl:          config.LocalControl,
-		DeviceTrouble:         config.DeviceTrouble,
-		MaxRxFragSize:         config.MaxRxFragSize,
-		MaxTxFragSize:         config.MaxTxFragSize,
+	return o.internal.SetConfig(convertOutstationConfig(config))
+}
+
+func (o *outstationWrapper) EventBufferStats() EventBufferStats {
+	stats := o.internal.EventBufferStats()
+	return EventBufferStats{
+		Binary:             EventTypeStats(stats.Binary),
+		DoubleBitBinary:    EventTypeStats(stats.DoubleBitBinary),
+		Analog:             EventTypeStats(stats.Analog),
+		Counter:            EventTypeStats(stats.Counter),
+		FrozenCounter:      EventTypeStats(stats.FrozenCounter),
+		BinaryOutputStatus: EventTypeStats(stats.BinaryOutputStatus),
+		AnalogOutputStatus: EventTypeStats(stats.AnalogOutputStatus),
+		OctetString:        EventTypeStats(stats.OctetString),
 	}
-	return o.internal.SetConfig(outstationConfig)
 }
@@ -0,0 +1,93 @@
+package master
+
+import (
+	"fmt"
+	"time"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/secauth"
+)
+
+// sendAuthenticated sends a critical request under Secure Authentication.
+// Session keys are changed first when due; the request then carries its
+// own MAC in aggressive mode, or waits for the outstation's challenge.
+func (m *master) sendAuthenticated(apdu *app.APDU, timeout time.Duration) (*app.APDU, error) {
+	if m.auth.NeedsKeyChange() {
+		if err := m.changeKeys(timeout); err != nil {
+			return nil, fmt.Errorf("session key change: %w", err)
+		}
+
+		// Follow on from the key change so an OPERATE still directly
+		// follows its SELECT
+		resequenced := *apdu
+		resequenced.Sequence = m.getNextSequence()
+		apdu = &resequenced
+	}
+
+	if aggressive := m.auth.Aggressive(apdu); aggressive != nil {
+		resp, err := m.exchange(aggressive, timeout)
+		if err != nil {
+			return nil, err
+		}
+		if resp.FunctionCode == app.FuncAuthResponse {
+			return nil, m.auth.Refused(resp.Objects)
+		}
+		return resp, nil
+	}
+
+	raw := apdu.Serialize()
+	resp, err := m.exchange(apdu, timeout)
+	if err != nil || resp.FunctionCode != app.FuncAuthResponse {
+		return resp, err
+	}
+
+	// Answer the challenge in the sequence of the request
+	reply, err := m.auth.Reply(resp.Objects, raw)
+	if err != nil {
+		return nil, err
+	}
+	resp, err = m.exchange(app.NewRequestAPDU(app.FuncAuthRequest, apdu.Sequence, reply), timeout)
+	if err != nil {
+		return nil, err
+	}
+	if resp.FunctionCode == app.FuncAuthResponse {
+		return nil, m.auth.Refused(resp.Objects)
+	}
+	return resp, nil
+}
+
+// changeKeys sets new session keys with the outstation
+func (m *master) changeKeys(timeout time.Duration) error {
+	m.logger.Debug("Master %s: Changing session keys", m.config.ID)
+
+	status, err := m.authRequest(m.auth.KeyStatusRequest(), timeout)
+	if err != nil {
+		return err
+	}
+	change, err := m.auth.ChangeKeys(status)
+	if err != nil {
+		return err
+	}
+	status, err = m.authRequest(change, timeout)
+	if err != nil {
+		m.auth.InvalidateKeys()
+		return err
+	}
+	return m.auth.ConfirmKeys(status)
+}
+
+// authRequest sends an AUTH REQUEST and returns the objects of the AUTH
+// RESPONSE answering it
+func (m *master) authRequest(objects []byte, timeout time.Duration) ([]byte, error) {
+	resp, err := m.exchange(app.NewRequestAPDU(app.FuncAuthRequest, m.getNextSequence(), objects), timeout)
+	if err != nil {
+		return nil, err
+	}
+	if resp.FunctionCode != app.FuncAuthResponse {
+		if err := checkRejected(resp); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: expected AUTH RESPONSE, got %s", secauth.ErrAuthentication, resp.FunctionCode)
+	}
+	return resp.Objects, nil
+}
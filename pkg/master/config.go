@@ -1,9 +1,11 @@
 package master
 
 import (
+	"fmt"
 	"time"
 
 	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/secauth"
 	"avaneesh/dnp3-go/pkg/types"
 )
 
@@ -33,6 +35,14 @@ type MasterConfig struct {
 	// Advanced
 	MaxRxFragSize uint16
 	MaxTxFragSize uint16
+
+	// File transfer credentials, sent with AUTHENTICATE FILE before OPEN
+	// and DELETE when FileUserName is set
+	FileUserName string
+	FilePassword string
+
+	// Secure Authentication of critical requests (G120), nil to disable
+	SecureAuth *secauth.MasterConfig
 }
 
 // MasterCallbacks defines application callbacks for master
@@ -57,6 +67,7 @@ type SOEHandler interface {
 	ProcessFrozenCounter(info HeaderInfo, values []types.IndexedFrozenCounter)
 	ProcessBinaryOutputStatus(info HeaderInfo, values []types.IndexedBinaryOutputStatus)
 	ProcessAnalogOutputStatus(info HeaderInfo, values []types.IndexedAnalogOutputStatus)
+	ProcessOctetString(info HeaderInfo, values []types.IndexedOctetString)
 }
 
 // ResponseInfo contains information about a response fragment
@@ -82,8 +93,39 @@ const (
 	TaskTypeClassScan
 	TaskTypeRangeScan
 	TaskTypeCommand
+	TaskTypeTimeSync
+	TaskTypeAssignClass
+	TaskTypeDeadband
+	TaskTypeOctetString
+	TaskTypeDeviceAttributes
+	TaskTypeFile
+	TaskTypeStartup
+)
+
+// TimeSyncMode selects the time synchronization procedure
+type TimeSyncMode int
+
+const (
+	// TimeSyncNonLAN measures the link delay with DELAY MEASUREMENT and writes
+	// the time corrected by it (G50V1), for serial links
+	TimeSyncNonLAN TimeSyncMode = iota
+	// TimeSyncLAN has the outstation RECORD CURRENT TIME and then writes the
+	// master's time at that moment (G50V3)
+	TimeSyncLAN
 )
 
+// String returns the time synchronization mode name
+func (m TimeSyncMode) String() string {
+	switch m {
+	case TimeSyncNonLAN:
+		return "non-LAN"
+	case TimeSyncLAN:
+		return "LAN"
+	default:
+		return fmt.Sprintf("TimeSyncMode(%d)", int(m))
+	}
+}
+
 // TaskResult indicates the result of a task
 type TaskResult int
 
@@ -0,0 +1,379 @@
+package master
+
+import (
+	"errors"
+	"fmt"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// ErrFileStatus is returned when the outstation refuses a file operation
+var ErrFileStatus = errors.New("file operation failed")
+
+// defaultFragSize is assumed when a fragment size is not configured
+const defaultFragSize = 2048
+
+// fileBlockOverhead is the size of a G70V5 header, object size and
+// transport object fields preceding the data of a block
+const fileBlockOverhead = 14
+
+// fileBlockSize returns the largest block that fits a single fragment in
+// each direction, so a block never needs a multi-fragment response
+func (m *master) fileBlockSize() uint16 {
+	rx, tx := int(m.config.MaxRxFragSize), int(m.config.MaxTxFragSize)
+	if rx == 0 {
+		rx = defaultFragSize
+	}
+	if tx == 0 {
+		tx = defaultFragSize
+	}
+	// Responses carry 4 header octets, requests 2. A fragment too small for
+	// any data still asks for one octet rather than wrapping around.
+	return uint16(max(min(rx-4, tx-2)-fileBlockOverhead, 1))
+}
+
+// fileRequest sends a file request holding one G70 object and returns the
+// variation and object of the response
+func (m *master) fileRequest(fc app.FunctionCode, variation uint8, object []byte) (uint8, []byte, error) {
+	apdu := app.NewRequestAPDU(fc, m.getNextSequence(), app.BuildFileObject(variation, object))
+	resp, err := m.sendAndWait(apdu, m.config.ResponseTimeout)
+	if err != nil {
+		return 0, nil, err
+	}
+	if err := checkRejected(resp); err != nil {
+		return 0, nil, err
+	}
+
+	objects, err := readFileObjects(resp)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(objects) != 1 {
+		return 0, nil, fmt.Errorf("expected one file object in the %s response, got %d", fc, len(objects))
+	}
+	return objects[0].variation, objects[0].data, nil
+}
+
+// fileObject is a G70 object of a response
+type fileObject struct {
+	variation uint8
+	data      []byte
+}
+
+// readFileObjects returns the G70 objects of a response
+func readFileObjects(resp *app.APDU) ([]fileObject, error) {
+	var objects []fileObject
+
+	parser := app.NewParser(resp.Objects)
+	for parser.HasMore() {
+		header, err := parser.ReadObjectHeader()
+		if err != nil {
+			return objects, err
+		}
+		if header.Group != app.GroupFile {
+			return objects, fmt.Errorf("expected file objects, got G%dV%d", header.Group, header.Variation)
+		}
+		if _, ok := header.Range.(app.FreeFormatRange); !ok {
+			return objects, fmt.Errorf("G70V%d without a free-format qualifier", header.Variation)
+		}
+		for i := uint32(0); i < app.GetCount(header.Range); i++ {
+			data, err := parser.ReadFreeFormatObject()
+			if err != nil {
+				return objects, err
+			}
+			objects = append(objects, fileObject{variation: header.Variation, data: data})
+		}
+	}
+
+	return objects, nil
+}
+
+// commandStatus returns the status answering a file command, which is an
+// error unless it reports success
+func commandStatus(variation uint8, object []byte, operation, name string) (app.FileCommandStatusObject, error) {
+	if variation != app.FileCommandStatus {
+		return app.FileCommandStatusObject{}, fmt.Errorf("%s %q: unexpected response G70V%d", operation, name, variation)
+	}
+	status, err := app.ParseFileCommandStatus(object)
+	if err != nil {
+		return status, err
+	}
+	if status.Status != app.FileStatusSuccess {
+		return status, fmt.Errorf("%w: %s %q: %s", ErrFileStatus, operation, name, status.Status)
+	}
+	return status, nil
+}
+
+// authenticateFile obtains a key for OPEN or DELETE when credentials are
+// configured, and zero otherwise
+func (m *master) authenticateFile() (uint32, error) {
+	if m.config.FileUserName == "" {
+		return 0, nil
+	}
+
+	auth := app.FileAuthenticationObject{UserName: m.config.FileUserName, Password: m.config.FilePassword}
+	variation, object, err := m.fileRequest(app.FuncAuthenticateFile, app.FileAuthentication, auth.Serialize())
+	if err != nil {
+		return 0, err
+	}
+	if variation != app.FileAuthentication {
+		return 0, fmt.Errorf("authenticate: unexpected response G70V%d", variation)
+	}
+	reply, err := app.ParseFileAuthentication(object)
+	if err != nil {
+		return 0, err
+	}
+	if reply.Key == 0 {
+		return 0, fmt.Errorf("%w: authentication refused for %q", ErrFileStatus, auth.UserName)
+	}
+	return reply.Key, nil
+}
+
+// openFile opens a file, returning the handle and block size granted by the
+// outstation
+func (m *master) openFile(name string, mode app.FileMode, size uint32) (app.FileCommandStatusObject, error) {
+	key, err := m.authenticateFile()
+	if err != nil {
+		return app.FileCommandStatusObject{}, err
+	}
+
+	cmd := app.FileCommandObject{
+		Name:         name,
+		Created:      app.FromTime(m.callbacks.GetTime()),
+		Permissions:  0644,
+		AuthKey:      key,
+		Size:         size,
+		Mode:         mode,
+		MaxBlockSize: m.fileBlockSize(),
+	}
+	variation, object, err := m.fileRequest(app.FuncOpenFile, app.FileCommand, cmd.Serialize())
+	if err != nil {
+		return app.FileCommandStatusObject{}, err
+	}
+	status, err := commandStatus(variation, object, "open", name)
+	if err == nil && (status.MaxBlockSize == 0 || status.MaxBlockSize > cmd.MaxBlockSize) {
+		err = fmt.Errorf("open %q: invalid block size %d", name, status.MaxBlockSize)
+		m.closeFile(status.Handle, name, true)
+	}
+	return status, err
+}
+
+// closeFile closes a file, or aborts it so a partly written file is
+// discarded
+func (m *master) closeFile(handle uint32, name string, abort bool) error {
+	fc, operation := app.FuncCloseFile, "close"
+	if abort {
+		fc, operation = app.FuncAbortFile, "abort"
+	}
+
+	variation, object, err := m.fileRequest(fc, app.FileCommandStatus, app.FileCommandStatusObject{Handle: handle}.Serialize())
+	if err != nil {
+		return err
+	}
+	_, err = commandStatus(variation, object, operation, name)
+	return err
+}
+
+// performReadFile reads a file, or the descriptors of a directory, block by
+// block
+func (m *master) performReadFile(name string) ([]byte, error) {
+	status, err := m.openFile(name, app.FileModeRead, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	for block := uint32(0); ; block++ {
+		last, err := m.readFileBlock(status.Handle, block, &data)
+		if err != nil {
+			m.closeFile(status.Handle, name, true)
+			return nil, fmt.Errorf("read %q: %w", name, err)
+		}
+		if last {
+			break
+		}
+	}
+
+	return data, m.closeFile(status.Handle, name, false)
+}
+
+// readFileBlock reads the next block of a file, appending its data and
+// reporting whether it is the last
+func (m *master) readFileBlock(handle, block uint32, data *[]byte) (bool, error) {
+	objects := app.BuildAllObjects(app.GroupFile, app.FileTransport)
+	req := app.BuildReadRequest(m.getNextSequence(), objects)
+	resp, err := m.sendAndWait(req, m.config.ResponseTimeout)
+	if errors.Is(err, ErrTimeout) {
+		// The same sequence number asks the outstation for the same block
+		// again rather than the next one
+		resp, err = m.sendAndWait(req, m.config.ResponseTimeout)
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := checkRejected(resp); err != nil {
+		return false, err
+	}
+
+	fileObjects, err := readFileObjects(resp)
+	if err != nil {
+		return false, err
+	}
+	// Blocks of other open files are not ours to consume
+	for _, obj := range fileObjects {
+		switch obj.variation {
+		case app.FileTransport:
+			transport, err := app.ParseFileTransport(obj.data)
+			if err != nil {
+				return false, err
+			}
+			if transport.Handle != handle {
+				continue
+			}
+			if transport.Block != block {
+				return false, fmt.Errorf("%w: block %d received, expected %d", ErrFileStatus, transport.Block, block)
+			}
+			*data = append(*data, transport.Data...)
+			return transport.Last, nil
+		case app.FileTransportStatus:
+			status, err := app.ParseFileTransportStatus(obj.data)
+			if err != nil {
+				return false, err
+			}
+			if status.Handle == handle {
+				return false, fmt.Errorf("%w: block %d: %s %s", ErrFileStatus, block, status.Status, status.Text)
+			}
+		}
+	}
+	return false, fmt.Errorf("no data for block %d", block)
+}
+
+// performWriteFile writes a file block by block, creating or truncating it
+func (m *master) performWriteFile(name string, data []byte) error {
+	status, err := m.openFile(name, app.FileModeWrite, uint32(len(data)))
+	if err != nil {
+		return err
+	}
+
+	blockSize := int(status.MaxBlockSize)
+	for block := uint32(0); ; block++ {
+		n := min(blockSize, len(data))
+		last := n == len(data)
+		if err := m.writeFileBlock(status.Handle, block, last, data[:n]); err != nil {
+			m.closeFile(status.Handle, name, true)
+			return fmt.Errorf("write %q: %w", name, err)
+		}
+		data = data[n:]
+		if last {
+			break
+		}
+	}
+
+	return m.closeFile(status.Handle, name, false)
+}
+
+// writeFileBlock writes a block and checks the outstation's status for it
+func (m *master) writeFileBlock(handle, block uint32, last bool, data []byte) error {
+	transport := app.FileTransportObject{Handle: handle, Block: block, Last: last, Data: data}
+	objects := app.BuildFileObject(app.FileTransport, transport.Serialize())
+	resp, err := m.sendAndWait(app.BuildWriteRequest(m.getNextSequence(), objects), m.config.ResponseTimeout)
+	if err != nil {
+		return err
+	}
+	if err := checkRejected(resp); err != nil {
+		return err
+	}
+
+	fileObjects, err := readFileObjects(resp)
+	if err != nil {
+		return err
+	}
+	for _, obj := range fileObjects {
+		if obj.variation != app.FileTransportStatus {
+			continue
+		}
+		status, err := app.ParseFileTransportStatus(obj.data)
+		if err != nil {
+			return err
+		}
+		if status.Handle != handle || status.Block != block {
+			continue
+		}
+		if status.Status != app.FileStatusSuccess {
+			return fmt.Errorf("%w: block %d: %s %s", ErrFileStatus, block, status.Status, status.Text)
+		}
+		return nil
+	}
+	return fmt.Errorf("no status for block %d", block)
+}
+
+// performListDirectory reads a directory, which the outstation returns as
+// consecutive file descriptors
+func (m *master) performListDirectory(path string) ([]types.FileInfo, error) {
+	data, err := m.performReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []types.FileInfo
+	for len(data) > 0 {
+		descriptor, size, err := app.ParseFileDescriptor(data)
+		if err != nil {
+			return files, fmt.Errorf("list %q: %w", path, err)
+		}
+		files = append(files, fileInfo(descriptor))
+		data = data[size:]
+	}
+	return files, nil
+}
+
+// performDeleteFile deletes a file
+func (m *master) performDeleteFile(name string) error {
+	key, err := m.authenticateFile()
+	if err != nil {
+		return err
+	}
+
+	cmd := app.FileCommandObject{Name: name, AuthKey: key, Mode: app.FileModeNull}
+	variation, object, err := m.fileRequest(app.FuncDeleteFile, app.FileCommand, cmd.Serialize())
+	if err != nil {
+		return err
+	}
+	_, err = commandStatus(variation, object, "delete", name)
+	return err
+}
+
+// performGetFileInfo reads the descriptor of a file or directory
+func (m *master) performGetFileInfo(name string) (types.FileInfo, error) {
+	request := app.FileDescriptorObject{Name: name}
+	variation, object, err := m.fileRequest(app.FuncGetFileInfo, app.FileDescriptor, request.Serialize())
+	if err != nil {
+		return types.FileInfo{}, err
+	}
+	if variation != app.FileDescriptor {
+		// The outstation reports why it has no descriptor
+		_, err := commandStatus(variation, object, "get info of", name)
+		if err == nil {
+			err = fmt.Errorf("get info of %q: no descriptor returned", name)
+		}
+		return types.FileInfo{}, err
+	}
+
+	descriptor, _, err := app.ParseFileDescriptor(object)
+	if err != nil {
+		return types.FileInfo{}, err
+	}
+	return fileInfo(descriptor), nil
+}
+
+// fileInfo converts a file descriptor
+func fileInfo(d app.FileDescriptorObject) types.FileInfo {
+	return types.FileInfo{
+		Name:        d.Name,
+		Directory:   d.Type == app.FileTypeDirectory,
+		Size:        d.Size,
+		Created:     types.DNP3Time(d.Created),
+		Permissions: d.Permissions,
+	}
+}
@@ -0,0 +1,319 @@
+package master
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// expectFileRequest receives a request holding one G70 object and returns it
+func (h *testHarness) expectFileRequest(fc app.FunctionCode, variation uint8) (*app.APDU, []byte) {
+	h.t.Helper()
+
+	req := h.expectRequest(fc)
+	objects, err := readFileObjects(req)
+	if err != nil || len(objects) != 1 || objects[0].variation != variation {
+		h.t.Fatalf("%s: got %+v, %v, want one G70V%d object", fc, objects, err, variation)
+	}
+	return req, objects[0].data
+}
+
+// respondFile answers a request with G70 objects of one variation
+func (h *testHarness) respondFile(req *app.APDU, variation uint8, objects ...[]byte) {
+	h.t.Helper()
+
+	var data []byte
+	for _, object := range objects {
+		data = append(data, app.BuildFileObject(variation, object)...)
+	}
+	h.respond(app.NewResponseAPDU(req.Sequence, app.IIN{}, data))
+}
+
+// expectOpen answers an OPEN with a handle and block size, returning the command
+func (h *testHarness) expectOpen(handle uint32, blockSize uint16) app.FileCommandObject {
+	h.t.Helper()
+
+	req, object := h.expectFileRequest(app.FuncOpenFile, app.FileCommand)
+	cmd, err := app.ParseFileCommand(object)
+	if err != nil {
+		h.t.Fatalf("ParseFileCommand failed: %v", err)
+	}
+	h.respondFile(req, app.FileCommandStatus, app.FileCommandStatusObject{Handle: handle, MaxBlockSize: blockSize}.Serialize())
+	return cmd
+}
+
+// expectClose answers a CLOSE or ABORT of a handle with success
+func (h *testHarness) expectClose(fc app.FunctionCode, handle uint32) {
+	h.t.Helper()
+
+	req, object := h.expectFileRequest(fc, app.FileCommandStatus)
+	status, err := app.ParseFileCommandStatus(object)
+	if err != nil || status.Handle != handle {
+		h.t.Fatalf("%s: got %+v, %v, want handle %d", fc, status, err, handle)
+	}
+	h.respondFile(req, app.FileCommandStatus, app.FileCommandStatusObject{Handle: handle}.Serialize())
+}
+
+func TestFileBlockSize(t *testing.T) {
+	tests := []struct {
+		rx, tx uint16
+		want   uint16
+	}{
+		{0, 0, defaultFragSize - 4 - fileBlockOverhead},
+		{249, 0, 249 - 4 - fileBlockOverhead},
+		{0, 249, 249 - 2 - fileBlockOverhead},
+		{18, 18, 1},
+		{10, 2048, 1},
+		{2048, 1, 1},
+	}
+
+	for _, tt := range tests {
+		m := &master{config: MasterConfig{MaxRxFragSize: tt.rx, MaxTxFragSize: tt.tx}}
+		if got := m.fileBlockSize(); got != tt.want {
+			t.Errorf("fileBlockSize(rx=%d, tx=%d): got %d, want %d", tt.rx, tt.tx, got, tt.want)
+		}
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	h := newTestHarness(t, MasterConfig{MaxRxFragSize: 249})
+
+	var data []byte
+	done := h.run(func() (err error) {
+		data, err = h.master.performReadFile("data.bin")
+		return err
+	})
+
+	cmd := h.expectOpen(7, 4)
+	if cmd.Name != "data.bin" || cmd.Mode != app.FileModeRead || cmd.MaxBlockSize != h.master.fileBlockSize() {
+		t.Errorf("OPEN: got %+v", cmd)
+	}
+
+	// A block of another open file in the same response is skipped
+	req := h.expectRequest(app.FuncRead)
+	h.respondFile(req, app.FileTransport,
+		app.FileTransportObject{Handle: 8, Block: 0, Data: []byte("zz")}.Serialize(),
+		app.FileTransportObject{Handle: 7, Block: 0, Data: []byte("abcd")}.Serialize())
+	req = h.expectRequest(app.FuncRead)
+	h.respondFile(req, app.FileTransport, app.FileTransportObject{Handle: 7, Block: 1, Last: true, Data: []byte("ef")}.Serialize())
+	h.expectClose(app.FuncCloseFile, 7)
+
+	if err := h.wait(done); err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "abcdef" {
+		t.Errorf("Read: got %q, want %q", data, "abcdef")
+	}
+}
+
+func TestReadFileRepeatsLostBlock(t *testing.T) {
+	h := newTestHarness(t, MasterConfig{ResponseTimeout: 100 * time.Millisecond})
+
+	var data []byte
+	done := h.run(func() (err error) {
+		data, err = h.master.performReadFile("data.bin")
+		return err
+	})
+
+	h.expectOpen(7, 4)
+
+	// The response to the first READ is lost, so it is sent again as is
+	first := h.expectRequest(app.FuncRead)
+	repeat := h.expectRequest(app.FuncRead)
+	if repeat.Sequence != first.Sequence {
+		t.Errorf("Repeated READ: got seq=%d, want %d", repeat.Sequence, first.Sequence)
+	}
+	h.respondFile(repeat, app.FileTransport, app.FileTransportObject{Handle: 7, Last: true, Data: []byte("abcd")}.Serialize())
+	h.expectClose(app.FuncCloseFile, 7)
+
+	if err := h.wait(done); err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "abcd" {
+		t.Errorf("Read: got %q, want %q", data, "abcd")
+	}
+}
+
+func TestReadFileAborts(t *testing.T) {
+	h := newTestHarness(t, MasterConfig{})
+
+	done := h.run(func() error {
+		_, err := h.master.performReadFile("data.bin")
+		return err
+	})
+
+	h.expectOpen(7, 4)
+	req := h.expectRequest(app.FuncRead)
+	h.respondFile(req, app.FileTransportStatus, app.FileTransportStatusObject{Handle: 7, Status: app.FileStatusFatal}.Serialize())
+	h.expectClose(app.FuncAbortFile, 7)
+
+	if err := h.wait(done); !errors.Is(err, ErrFileStatus) {
+		t.Errorf("Read: got %v, want ErrFileStatus", err)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	h := newTestHarness(t, MasterConfig{})
+
+	done := h.run(func() error { return h.master.performWriteFile("new.bin", []byte("0123456789")) })
+
+	cmd := h.expectOpen(3, 4)
+	if cmd.Name != "new.bin" || cmd.Mode != app.FileModeWrite || cmd.Size != 10 {
+		t.Errorf("OPEN: got %+v", cmd)
+	}
+
+	// The data is split into blocks of the size granted by the outstation
+	for i, want := range []string{"0123", "4567", "89"} {
+		req, object := h.expectFileRequest(app.FuncWrite, app.FileTransport)
+		transport, err := app.ParseFileTransport(object)
+		if err != nil {
+			t.Fatalf("ParseFileTransport failed: %v", err)
+		}
+		last := i == 2
+		if transport.Handle != 3 || transport.Block != uint32(i) || transport.Last != last || string(transport.Data) != want {
+			t.Errorf("Block %d: got %+v", i, transport)
+		}
+		status := app.FileTransportStatusObject{Handle: 3, Block: uint32(i), Last: last}
+		h.respondFile(req, app.FileTransportStatus, status.Serialize())
+	}
+	h.expectClose(app.FuncCloseFile, 3)
+
+	if err := h.wait(done); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWriteFileBlockRefused(t *testing.T) {
+	h := newTestHarness(t, MasterConfig{})
+
+	done := h.run(func() error { return h.master.performWriteFile("new.bin", []byte("0123456789")) })
+
+	h.expectOpen(3, 4)
+	req, _ := h.expectFileRequest(app.FuncWrite, app.FileTransport)
+	status := app.FileTransportStatusObject{Handle: 3, Block: 0, Status: app.FileStatusWriteBlockSize}
+	h.respondFile(req, app.FileTransportStatus, status.Serialize())
+	h.expectClose(app.FuncAbortFile, 3)
+
+	if err := h.wait(done); !errors.Is(err, ErrFileStatus) {
+		t.Errorf("Write: got %v, want ErrFileStatus", err)
+	}
+}
+
+func TestOpenRefused(t *testing.T) {
+	h := newTestHarness(t, MasterConfig{})
+
+	done := h.run(func() error { return h.master.performWriteFile("new.bin", nil) })
+
+	req, _ := h.expectFileRequest(app.FuncOpenFile, app.FileCommand)
+	h.respondFile(req, app.FileCommandStatus, app.FileCommandStatusObject{Status: app.FileStatusPermissionDenied}.Serialize())
+
+	if err := h.wait(done); !errors.Is(err, ErrFileStatus) {
+		t.Errorf("Write: got %v, want ErrFileStatus", err)
+	}
+	h.expectNoRequest()
+}
+
+func TestListDirectory(t *testing.T) {
+	h := newTestHarness(t, MasterConfig{})
+
+	var files []types.FileInfo
+	done := h.run(func() (err error) {
+		files, err = h.master.performListDirectory("logs")
+		return err
+	})
+
+	h.expectOpen(5, 1024)
+	listing := app.FileDescriptorObject{Name: "a.log", Type: app.FileTypeSimple, Size: 12}.Serialize()
+	listing = append(listing, app.FileDescriptorObject{Name: "old", Type: app.FileTypeDirectory, Size: 2}.Serialize()...)
+	req := h.expectRequest(app.FuncRead)
+	h.respondFile(req, app.FileTransport, app.FileTransportObject{Handle: 5, Last: true, Data: listing}.Serialize())
+	h.expectClose(app.FuncCloseFile, 5)
+
+	if err := h.wait(done); err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files[0].Name != "a.log" || files[0].Directory || files[0].Size != 12 ||
+		files[1].Name != "old" || !files[1].Directory || files[1].Size != 2 {
+		t.Errorf("List: got %+v", files)
+	}
+}
+
+func TestDeleteFileAuthenticated(t *testing.T) {
+	h := newTestHarness(t, MasterConfig{FileUserName: "operator", FilePassword: "secret"})
+
+	done := h.run(func() error { return h.master.performDeleteFile("old.bin") })
+
+	req, object := h.expectFileRequest(app.FuncAuthenticateFile, app.FileAuthentication)
+	auth, err := app.ParseFileAuthentication(object)
+	if err != nil || auth.UserName != "operator" || auth.Password != "secret" {
+		t.Errorf("AUTHENTICATE: got %+v, %v", auth, err)
+	}
+	h.respondFile(req, app.FileAuthentication, app.FileAuthenticationObject{Key: 99}.Serialize())
+
+	req, object = h.expectFileRequest(app.FuncDeleteFile, app.FileCommand)
+	cmd, err := app.ParseFileCommand(object)
+	if err != nil || cmd.Name != "old.bin" || cmd.Mode != app.FileModeNull || cmd.AuthKey != 99 {
+		t.Errorf("DELETE: got %+v, %v", cmd, err)
+	}
+	h.respondFile(req, app.FileCommandStatus, app.FileCommandStatusObject{}.Serialize())
+
+	if err := h.wait(done); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeleteFileAuthenticationRefused(t *testing.T) {
+	h := newTestHarness(t, MasterConfig{FileUserName: "operator", FilePassword: "wrong"})
+
+	done := h.run(func() error { return h.master.performDeleteFile("old.bin") })
+
+	req, _ := h.expectFileRequest(app.FuncAuthenticateFile, app.FileAuthentication)
+	h.respondFile(req, app.FileAuthentication, app.FileAuthenticationObject{}.Serialize())
+
+	if err := h.wait(done); !errors.Is(err, ErrFileStatus) {
+		t.Errorf("Delete: got %v, want ErrFileStatus", err)
+	}
+	h.expectNoRequest()
+}
+
+func TestGetFileInfo(t *testing.T) {
+	h := newTestHarness(t, MasterConfig{})
+
+	done := h.run(func() error {
+		info, err := h.master.performGetFileInfo("data.bin")
+		if err == nil && (info.Name != "data.bin" || info.Directory || info.Size != 1000 || info.Permissions != 0644) {
+			t.Errorf("Info: got %+v", info)
+		}
+		return err
+	})
+
+	req, object := h.expectFileRequest(app.FuncGetFileInfo, app.FileDescriptor)
+	if request, _, err := app.ParseFileDescriptor(object); err != nil || request.Name != "data.bin" {
+		t.Errorf("GET FILE INFO: got %+v, %v", request, err)
+	}
+	descriptor := app.FileDescriptorObject{Name: "data.bin", Type: app.FileTypeSimple, Size: 1000, Permissions: 0644}
+	h.respondFile(req, app.FileDescriptor, descriptor.Serialize())
+
+	if err := h.wait(done); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetFileInfoNotFound(t *testing.T) {
+	h := newTestHarness(t, MasterConfig{})
+
+	done := h.run(func() error {
+		_, err := h.master.performGetFileInfo("missing.bin")
+		return err
+	})
+
+	req, _ := h.expectFileRequest(app.FuncGetFileInfo, app.FileDescriptor)
+	h.respondFile(req, app.FileCommandStatus, app.FileCommandStatusObject{Status: app.FileStatusNotFound}.Serialize())
+
+	if err := h.wait(done); !errors.Is(err, ErrFileStatus) {
+		t.Errorf("Info: got %v, want ErrFileStatus", err)
+	}
+}
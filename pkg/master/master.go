@@ -11,14 +11,22 @@ import (
 	"avaneesh/dnp3-go/pkg/channel"
 	"avaneesh/dnp3-go/pkg/internal/logger"
 	"avaneesh/dnp3-go/pkg/internal/queue"
+	"avaneesh/dnp3-go/pkg/secauth"
 	"avaneesh/dnp3-go/pkg/types"
 )
 
 var (
-	ErrMasterDisabled = errors.New("master is disabled")
-	ErrTimeout        = errors.New("operation timeout")
+	ErrMasterDisabled   = errors.New("master is disabled")
+	ErrTimeout          = errors.New("operation timeout")
+	ErrRejected         = errors.New("request rejected by outstation")
+	ErrNotSupported     = errors.New("function code not supported")
+	ErrFragmentSequence = errors.New("response fragment out of sequence")
 )
 
+// maxQueuedFragments is how many received fragments may wait for the
+// request goroutine before further ones are dropped
+const maxQueuedFragments = 8
+
 // MasterConfig and callback interfaces moved here to avoid circular import
 // These will be type-aliased or wrapped in dnp3 package
 
@@ -42,6 +50,7 @@ type master struct {
 	seqCounter   *app.SequenceCounter
 	lastIIN      types.IIN
 	stateMu      sync.RWMutex
+	auth         *secauth.Master // Secure Authentication, nil when disabled
 
 	// Concurrency
 	ctx          context.Context
@@ -51,6 +60,7 @@ type master struct {
 	// Response handling
 	pendingResp  chan *app.APDU
 	pendingMu    sync.Mutex
+	lastUnsol    []byte // Last unsolicited fragment, only used by the receiving goroutine
 }
 
 // New creates a new master
@@ -59,6 +69,14 @@ func New(config MasterConfig, callbacks MasterCallbacks, ch *channel.Channel, lo
 		log = logger.NewNoOpLogger()
 	}
 
+	var auth *secauth.Master
+	if config.SecureAuth != nil {
+		var err error
+		if auth, err = secauth.NewMaster(*config.SecureAuth); err != nil {
+			return nil, err
+		}
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	m := &master{
@@ -70,9 +88,10 @@ func New(config MasterConfig, callbacks MasterCallbacks, ch *channel.Channel, lo
 		nextScanID:  1,
 		enabled:     false,
 		seqCounter:  app.NewSequenceCounter(),
+		auth:        auth,
 		ctx:         ctx,
 		cancel:      cancel,
-		pendingResp: make(chan *app.APDU, 1),
+		pendingResp: make(chan *app.APDU, maxQueuedFragments),
 	}
 
 	// Create session
@@ -108,15 +127,9 @@ func (m *master) Enable() error {
 	}()
 
 	// Perform startup sequence
-	if m.config.DisableUnsolOnStartup {
-		// TODO: Send disable unsolicited
-	}
-
-	if m.config.StartupIntegrityScan {
-		go func() {
-			time.Sleep(100 * time.Millisecond)
-			m.ScanIntegrity()
-		}()
+	if m.config.DisableUnsolOnStartup || m.config.StartupIntegrityScan || eventClasses(m.config.UnsolClassMask) != nil {
+		task := &StartupTask{priority: PriorityHigh}
+		m.taskQueue.Push(task, task.Priority(), time.Now())
 	}
 
 	// Start automatic integrity scan if configured
@@ -244,7 +257,14 @@ func (m *master) onReceiveAPDU(data []byte) error {
 		m.callbacks.OnReceiveIIN(apdu.IIN)
 	}
 
-	// Send to pending response channel
+	// Unsolicited responses never answer a request
+	if apdu.FunctionCode == app.FuncUnsolicitedResponse {
+		m.handleUnsolicited(apdu, data)
+		return nil
+	}
+
+	// Solicited fragments are processed and confirmed by the exchange
+	// waiting for them, once their sequence number is checked
 	m.pendingMu.Lock()
 	select {
 	case m.pendingResp <- apdu:
@@ -253,16 +273,23 @@ func (m *master) onReceiveAPDU(data []byte) error {
 	}
 	m.pendingMu.Unlock()
 
-	// Process measurements
-	if apdu.IsResponse() && len(apdu.Objects) > 0 {
-		m.processMeasurements(apdu)
-	}
-
 	return nil
 }
 
-// sendAndWait sends an APDU and waits for response
+// sendAndWait sends an APDU and waits for response, authenticating it
+// when Secure Authentication is enabled
 func (m *master) sendAndWait(apdu *app.APDU, timeout time.Duration) (*app.APDU, error) {
+	if m.auth != nil && secauth.IsCritical(apdu.FunctionCode) {
+		return m.sendAuthenticated(apdu, timeout)
+	}
+	return m.exchange(apdu, timeout)
+}
+
+// exchange sends an APDU as it is and waits for the response, collecting
+// its fragments until FIN with the timeout applied to each fragment
+func (m *master) exchange(apdu *app.APDU, timeout time.Duration) (*app.APDU, error) {
+	m.discardResponses()
+
 	// Serialize and send
 	data := apdu.Serialize()
 	if err := m.session.sendAPDU(data); err != nil {
@@ -271,7 +298,55 @@ func (m *master) sendAndWait(apdu *app.APDU, timeout time.Duration) (*app.APDU,
 
 	m.logger.Debug("Master %s: Sent APDU: %s", m.config.ID, apdu)
 
-	// Wait for response
+	var resp *app.APDU
+	for {
+		frag, err := m.waitFragment(timeout)
+		if err != nil {
+			if resp != nil && err == ErrTimeout {
+				return nil, fmt.Errorf("%w: waiting for fragment seq=%d", ErrTimeout, app.NextSequence(resp.Sequence))
+			}
+			return nil, err
+		}
+
+		if resp == nil {
+			// The first fragment echoes the request's sequence number
+			if !frag.FIR || frag.Sequence != apdu.Sequence {
+				m.logger.Warn("Master %s: Ignoring response seq=%d FIR=%v to request seq=%d",
+					m.config.ID, frag.Sequence, frag.FIR, apdu.Sequence)
+				continue
+			}
+			resp = frag
+		} else {
+			if frag.FIR || frag.Sequence != app.NextSequence(resp.Sequence) {
+				return nil, fmt.Errorf("%w: got seq=%d FIR=%v, want seq=%d",
+					ErrFragmentSequence, frag.Sequence, frag.FIR, app.NextSequence(resp.Sequence))
+			}
+			resp = appendFragment(resp, frag)
+		}
+		m.acceptFragment(frag)
+
+		if resp.FIN {
+			return resp, nil
+		}
+	}
+}
+
+// acceptFragment processes the measurements of a fragment belonging to the
+// response being collected, then confirms it if requested so the
+// outstation only releases events the master has processed. A task
+// completes once its last fragment is processed; authentication objects
+// are not measurements.
+func (m *master) acceptFragment(frag *app.APDU) {
+	if frag.FunctionCode != app.FuncAuthResponse && len(frag.Objects) > 0 {
+		m.processMeasurements(frag)
+	}
+	if frag.CON {
+		m.sendConfirm(frag.Sequence, false)
+	}
+}
+
+// waitFragment waits for the next response fragment
+func (m *master) waitFragment(timeout time.Duration) (*app.APDU, error) {
 	select {
 	case resp := <-m.pendingResp:
 		return resp, nil
@@ -282,6 +357,35 @@ func (m *master) sendAndWait(apdu *app.APDU, timeout time.Duration) (*app.APDU,
 	}
 }
 
+// discardResponses drops fragments left over from a request that timed
+// out, so they are not taken as the answer to the next one
+func (m *master) discardResponses() {
+	for {
+		select {
+		case resp := <-m.pendingResp:
+			m.logger.Debug("Master %s: Discarding stale response seq=%d", m.config.ID, resp.Sequence)
+		default:
+			return
+		}
+	}
+}
+
+// appendFragment joins the next fragment of a response to the fragments
+// before it. Object headers never span fragments, so the objects simply
+// follow on. IIN1 is current as of the last fragment while the IIN2
+// request errors of every fragment are kept.
+func appendFragment(resp, frag *app.APDU) *app.APDU {
+	objects := make([]byte, 0, len(resp.Objects)+len(frag.Objects))
+	objects = append(objects, resp.Objects...)
+	objects = append(objects, frag.Objects...)
+
+	joined := *frag
+	joined.FIR = true
+	joined.Objects = objects
+	joined.IIN.IIN2 |= resp.IIN.IIN2
+	return &joined
+}
+
 // Session returns the session (for channel registration)
 func (m *master) Session() channel.Session {
 	return m.session
@@ -0,0 +1,362 @@
+package master
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/channel"
+	"avaneesh/dnp3-go/pkg/link"
+	"avaneesh/dnp3-go/pkg/transport"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// fakePhysical is an in-memory physical channel capturing written frames
+type fakePhysical struct {
+	writes chan []byte
+}
+
+func newFakePhysical() *fakePhysical {
+	return &fakePhysical{writes: make(chan []byte, 256)}
+}
+
+func (f *fakePhysical) Read(ctx context.Context) ([]byte, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (f *fakePhysical) Write(ctx context.Context, data []byte) error {
+	f.writes <- data
+	return nil
+}
+
+func (f *fakePhysical) Close() error                                               { return nil }
+func (f *fakePhysical) Statistics() channel.TransportStats                         { return channel.TransportStats{} }
+func (f *fakePhysical) SetConnectionStateListener(channel.ConnectionStateListener) {}
+
+// testCallbacks records the measurements and fragments reported to the master's callbacks
+type testCallbacks struct {
+	mu            sync.Mutex
+	fragments     []ResponseInfo
+	binaries      []types.IndexedBinary
+	doubleBits    []types.IndexedDoubleBitBinary
+	analogs       []types.IndexedAnalog
+	binaryOutputs []types.IndexedBinaryOutputStatus
+	results       []TaskResult
+}
+
+func (c *testCallbacks) OnBeginFragment(info ResponseInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fragments = append(c.fragments, info)
+}
+
+func (c *testCallbacks) OnEndFragment(info ResponseInfo) {}
+
+func (c *testCallbacks) ProcessBinary(info HeaderInfo, values []types.IndexedBinary) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.binaries = append(c.binaries, values...)
+}
+
+func (c *testCallbacks) ProcessDoubleBitBinary(info HeaderInfo, values []types.IndexedDoubleBitBinary) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.doubleBits = append(c.doubleBits, values...)
+}
+
+func (c *testCallbacks) ProcessAnalog(info HeaderInfo, values []types.IndexedAnalog) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.analogs = append(c.analogs, values...)
+}
+
+func (c *testCallbacks) ProcessCounter(info HeaderInfo, values []types.IndexedCounter)             {}
+func (c *testCallbacks) ProcessFrozenCounter(info HeaderInfo, values []types.IndexedFrozenCounter) {}
+
+func (c *testCallbacks) ProcessBinaryOutputStatus(info HeaderInfo, values []types.IndexedBinaryOutputStatus) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.binaryOutputs = append(c.binaryOutputs, values...)
+}
+
+func (c *testCallbacks) ProcessAnalogOutputStatus(info HeaderInfo, values []types.IndexedAnalogOutputStatus) {
+}
+func (c *testCallbacks) ProcessOctetString(info HeaderInfo, values []types.IndexedOctetString) {}
+
+func (c *testCallbacks) OnReceiveIIN(iin types.IIN)            {}
+func (c *testCallbacks) OnTaskStart(taskType TaskType, id int) {}
+
+func (c *testCallbacks) OnTaskComplete(taskType TaskType, id int, result TaskResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results = append(c.results, result)
+}
+
+func (c *testCallbacks) GetTime() time.Time { return time.Now() }
+
+// testHarness drives a master directly at the APDU level
+type testHarness struct {
+	t         *testing.T
+	master    *master
+	callbacks *testCallbacks
+	physical  *fakePhysical
+	rx        *transport.Layer
+}
+
+func newTestHarness(t *testing.T, config MasterConfig) *testHarness {
+	t.Helper()
+
+	physical := newFakePhysical()
+	ch := channel.New("test", physical, nil)
+	if err := ch.Open(); err != nil {
+		t.Fatalf("Open channel failed: %v", err)
+	}
+	t.Cleanup(func() { ch.Close() })
+
+	if config.LocalAddress == 0 {
+		config.LocalAddress = 1
+		config.RemoteAddress = 1024
+	}
+	if config.ResponseTimeout == 0 {
+		config.ResponseTimeout = time.Second
+	}
+
+	callbacks := &testCallbacks{}
+	m, err := New(config, callbacks, ch, nil)
+	if err != nil {
+		t.Fatalf("New master failed: %v", err)
+	}
+	t.Cleanup(func() { m.Shutdown() })
+
+	return &testHarness{
+		t:         t,
+		master:    m,
+		callbacks: callbacks,
+		physical:  physical,
+		rx:        transport.NewLayer(),
+	}
+}
+
+// run starts a master operation and returns the channel its result arrives on
+func (h *testHarness) run(operation func() error) <-chan error {
+	done := make(chan error, 1)
+	go func() { done <- operation() }()
+	return done
+}
+
+// wait returns the result of an operation started with run
+func (h *testHarness) wait(done <-chan error) error {
+	h.t.Helper()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(5 * time.Second):
+		h.t.Fatalf("Timed out waiting for the operation to finish")
+		return nil
+	}
+}
+
+// respond delivers an APDU from the outstation to the master
+func (h *testHarness) respond(apdu *app.APDU) {
+	h.t.Helper()
+
+	if err := h.master.onReceiveAPDU(apdu.Serialize()); err != nil {
+		h.t.Fatalf("onReceiveAPDU failed: %v", err)
+	}
+}
+
+// receive waits for the next APDU sent by the master
+func (h *testHarness) receive() *app.APDU {
+	h.t.Helper()
+
+	for {
+		select {
+		case data := <-h.physical.writes:
+			frame, _, err := link.Parse(data)
+			if err != nil {
+				h.t.Fatalf("Link parse failed: %v", err)
+			}
+			apduData, err := h.rx.Receive(frame.UserData)
+			if err != nil {
+				h.t.Fatalf("Transport receive failed: %v", err)
+			}
+			if apduData == nil {
+				continue
+			}
+			apdu, err := app.Parse(apduData)
+			if err != nil {
+				h.t.Fatalf("APDU parse failed: %v", err)
+			}
+			return apdu
+		case <-time.After(time.Second):
+			h.t.Fatalf("Timed out waiting for request")
+			return nil
+		}
+	}
+}
+
+// expectNoRequest verifies that the master sends nothing
+func (h *testHarness) expectNoRequest() {
+	h.t.Helper()
+
+	select {
+	case <-h.physical.writes:
+		h.t.Fatalf("Unexpected request")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// expectRequest receives the next APDU and checks its function code
+func (h *testHarness) expectRequest(fc app.FunctionCode) *app.APDU {
+	h.t.Helper()
+
+	req := h.receive()
+	if req.FunctionCode != fc {
+		h.t.Fatalf("Request: got %s, want %s", req.FunctionCode, fc)
+	}
+	return req
+}
+
+// expectPending verifies that an operation started with run has not finished
+func (h *testHarness) expectPending(done <-chan error) {
+	h.t.Helper()
+
+	select {
+	case err := <-done:
+		h.t.Fatalf("Operation finished early: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// analogFragment builds a response fragment holding analog input 0
+func analogFragment(seq uint8, value int16, fir, fin, con bool) *app.APDU {
+	builder := app.NewObjectBuilder()
+	builder.AddHeader(app.GroupAnalogInput, app.AnalogInput16BitNoFlag, app.Qualifier8BitStartStop, app.StartStopRange{Start: 0, Stop: 0})
+	builder.AddInt16(value)
+
+	frag := app.NewResponseAPDU(seq, app.IIN{}, builder.Build())
+	frag.FIR, frag.FIN, frag.CON = fir, fin, con
+	return frag
+}
+
+// analogValues returns the analog values processed so far
+func (h *testHarness) analogValues() []float64 {
+	h.callbacks.mu.Lock()
+	defer h.callbacks.mu.Unlock()
+
+	var values []float64
+	for _, analog := range h.callbacks.analogs {
+		values = append(values, analog.Value.Value)
+	}
+	return values
+}
+
+func TestMultiFragmentResponse(t *testing.T) {
+	h := newTestHarness(t, MasterConfig{})
+
+	done := h.run(h.master.performIntegrityScan)
+	req := h.expectRequest(app.FuncRead)
+
+	// Each fragment is confirmed with its own sequence number
+	h.respond(analogFragment(req.Sequence, 1, true, false, true))
+	if confirm := h.expectRequest(app.FuncConfirm); confirm.Sequence != req.Sequence || confirm.UNS {
+		t.Errorf("CONFIRM: got seq=%d UNS=%t, want seq=%d", confirm.Sequence, confirm.UNS, req.Sequence)
+	}
+	h.expectPending(done)
+	h.respond(analogFragment(app.NextSequence(req.Sequence), 2, false, true, false))
+
+	if err := h.wait(done); err != nil {
+		t.Fatal(err)
+	}
+	if values := h.analogValues(); len(values) != 2 || values[0] != 1 || values[1] != 2 {
+		t.Errorf("Analogs: got %v, want [1 2]", values)
+	}
+	c := h.callbacks
+	if len(c.fragments) != 2 || !c.fragments[0].FIR || c.fragments[0].FIN || c.fragments[1].FIR || !c.fragments[1].FIN {
+		t.Errorf("Fragments: got %+v", c.fragments)
+	}
+	h.expectNoRequest()
+}
+
+func TestFragmentOutOfSequence(t *testing.T) {
+	h := newTestHarness(t, MasterConfig{})
+
+	done := h.run(h.master.performIntegrityScan)
+	req := h.expectRequest(app.FuncRead)
+
+	h.respond(analogFragment(req.Sequence, 1, true, false, true))
+	h.expectRequest(app.FuncConfirm)
+
+	// The next fragment skips a sequence number, so it is neither
+	// processed nor confirmed
+	h.respond(analogFragment(app.NextSequence(app.NextSequence(req.Sequence)), 2, false, true, true))
+
+	if err := h.wait(done); !errors.Is(err, ErrFragmentSequence) {
+		t.Errorf("Scan: got %v, want ErrFragmentSequence", err)
+	}
+	if values := h.analogValues(); len(values) != 1 || values[0] != 1 {
+		t.Errorf("Analogs: got %v, want [1]", values)
+	}
+	h.expectNoRequest()
+}
+
+func TestStaleResponseIgnored(t *testing.T) {
+	h := newTestHarness(t, MasterConfig{})
+
+	done := h.run(h.master.performIntegrityScan)
+	req := h.expectRequest(app.FuncRead)
+
+	// A response to an earlier request and a fragment without FIR are
+	// skipped while waiting for the first fragment, and neither processed
+	// nor confirmed
+	h.respond(analogFragment((req.Sequence-1)&app.AppCtrlSeqMask, 1, true, true, true))
+	h.respond(analogFragment(req.Sequence, 2, false, true, true))
+	h.expectPending(done)
+	h.respond(analogFragment(req.Sequence, 3, true, true, false))
+
+	if err := h.wait(done); err != nil {
+		t.Fatal(err)
+	}
+	if values := h.analogValues(); len(values) != 1 || values[0] != 3 {
+		t.Errorf("Analogs: got %v, want [3]", values)
+	}
+	h.expectNoRequest()
+}
+
+func TestQueuedResponsesDiscarded(t *testing.T) {
+	h := newTestHarness(t, MasterConfig{})
+
+	// Responses no request is waiting for fill the queue, then are dropped
+	// without being processed or confirmed. They carry the sequence number
+	// of the next request.
+	seq := h.master.seqCounter.Current()
+	for range maxQueuedFragments + 2 {
+		h.respond(analogFragment(seq, 1, true, true, true))
+	}
+	h.expectNoRequest()
+	if n := len(h.master.pendingResp); n != maxQueuedFragments {
+		t.Errorf("Queued fragments: got %d, want %d", n, maxQueuedFragments)
+	}
+
+	// They are discarded before the next request is sent rather than taken
+	// as its answer
+	done := h.run(h.master.performIntegrityScan)
+	req := h.expectRequest(app.FuncRead)
+	if req.Sequence != seq {
+		t.Fatalf("READ: got seq=%d, want %d", req.Sequence, seq)
+	}
+	h.expectPending(done)
+	h.respond(analogFragment(req.Sequence, 2, true, true, false))
+
+	if err := h.wait(done); err != nil {
+		t.Fatal(err)
+	}
+	if values := h.analogValues(); len(values) != 1 || values[0] != 2 {
+		t.Errorf("Analogs: got %v, want [2]", values)
+	}
+}
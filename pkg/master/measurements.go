@@ -1,6 +1,8 @@
 package master
 
 import (
+	"fmt"
+
 	"avaneesh/dnp3-go/pkg/app"
 	"avaneesh/dnp3-go/pkg/types"
 )
@@ -42,18 +44,42 @@ func (m *master) processMeasurements(apdu *app.APDU) {
 		case app.GroupBinaryInput, app.GroupBinaryInputEvent:
 			m.processBinaryObjects(parser, header, headerInfo)
 
+		case app.GroupDoubleBitBinaryInput, app.GroupDoubleBitBinaryEvent:
+			m.processDoubleBitBinaryObjects(parser, header, headerInfo)
+
 		case app.GroupAnalogInput, app.GroupAnalogInputEvent:
 			m.processAnalogObjects(parser, header, headerInfo)
 
 		case app.GroupCounter, app.GroupCounterEvent:
 			m.processCounterObjects(parser, header, headerInfo)
 
+		case app.GroupFrozenCounter, app.GroupFrozenCounterEvent:
+			m.processFrozenCounterObjects(parser, header, headerInfo)
+
 		case app.GroupBinaryOutput, app.GroupBinaryOutputEvent:
 			m.processBinaryOutputStatus(parser, header, headerInfo)
 
 		case app.GroupAnalogOutputStatus, app.GroupAnalogOutputEvent:
 			m.processAnalogOutputStatus(parser, header, headerInfo)
 
+		case app.GroupOctetString, app.GroupOctetStringEvent:
+			m.processOctetStrings(parser, header, headerInfo)
+
+		case app.GroupDeviceAttributes:
+			// Returned by ReadDeviceAttributes; read here only to reach the next header
+			if _, err := readDeviceAttributes(parser, header); err != nil {
+				m.logger.Error("Master %s: Failed to read device attributes: %v", m.config.ID, err)
+			}
+
+		case app.GroupFile:
+			// Returned by the file operations; each object carries its own size
+			for i := uint32(0); i < app.GetCount(header.Range); i++ {
+				if _, err := parser.ReadFreeFormatObject(); err != nil {
+					m.logger.Error("Master %s: Failed to read file object: %v", m.config.ID, err)
+					break
+				}
+			}
+
 		default:
 			// Skip unknown group using app layer helper
 			count := app.GetCount(header.Range)
@@ -74,6 +100,22 @@ func (m *master) processBinaryObjects(parser *app.Parser, header *app.ObjectHead
 	count := app.GetCount(header.Range)
 	values := make([]types.IndexedBinary, 0, count)
 
+	if header.Group == app.GroupBinaryInput && header.Variation == app.BinaryInputPacked {
+		start, data, err := readPacked(parser, header, 1)
+		if err != nil {
+			m.logger.Error("Master %s: Failed to read packed binaries: %v", m.config.ID, err)
+			return
+		}
+		for i, state := range app.UnpackBits(data, int(count)) {
+			values = append(values, types.IndexedBinary{
+				Index: uint16(start + uint32(i)),
+				Value: types.Binary{Value: state, Flags: packedFlags(state)},
+			})
+		}
+		m.callbacks.ProcessBinary(info, values)
+		return
+	}
+
 	// Use app layer helper for object size
 	objectSize := app.GetObjectSize(header.Group, header.Variation)
 	if objectSize == 0 {
@@ -81,14 +123,14 @@ func (m *master) processBinaryObjects(parser *app.Parser, header *app.ObjectHead
 		return
 	}
 
-	// Determine starting index
-	startIndex := uint32(0)
-	if r, ok := header.Range.(app.StartStopRange); ok {
-		startIndex = r.Start
-	}
-
 	// Parse each object using app layer helpers
 	for i := uint32(0); i < count; i++ {
+		index, err := objectIndex(parser, header, i)
+		if err != nil {
+			m.logger.Error("Master %s: Failed to read binary index: %v", m.config.ID, err)
+			break
+		}
+
 		data, err := parser.ReadBytes(objectSize)
 		if err != nil {
 			m.logger.Error("Master %s: Failed to read binary object: %v", m.config.ID, err)
@@ -100,10 +142,11 @@ func (m *master) processBinaryObjects(parser *app.Parser, header *app.ObjectHead
 
 		// Convert to indexed value with proper wrapper type
 		value := types.IndexedBinary{
-			Index: uint16(startIndex + i),
+			Index: uint16(index),
 			Value: types.Binary{
 				Value: bi.Value,
 				Flags: types.Flags(bi.Flags),
+				Time:  eventTime(header, data),
 			},
 		}
 
@@ -113,6 +156,63 @@ func (m *master) processBinaryObjects(parser *app.Parser, header *app.ObjectHead
 	m.callbacks.ProcessBinary(info, values)
 }
 
+// processDoubleBitBinaryObjects processes double-bit binary input objects using app layer parsers
+func (m *master) processDoubleBitBinaryObjects(parser *app.Parser, header *app.ObjectHeader, info HeaderInfo) {
+	count := app.GetCount(header.Range)
+	values := make([]types.IndexedDoubleBitBinary, 0, count)
+
+	if header.Group == app.GroupDoubleBitBinaryInput && header.Variation == app.DoubleBitBinaryInputPacked {
+		start, data, err := readPacked(parser, header, 2)
+		if err != nil {
+			m.logger.Error("Master %s: Failed to read packed double-bit binaries: %v", m.config.ID, err)
+			return
+		}
+		for i, state := range app.UnpackDoubleBits(data, int(count)) {
+			values = append(values, types.IndexedDoubleBitBinary{
+				Index: uint16(start + uint32(i)),
+				Value: types.DoubleBitBinary{Value: types.DoubleBitValue(state), Flags: types.Flags(app.FlagOnline)},
+			})
+		}
+		m.callbacks.ProcessDoubleBitBinary(info, values)
+		return
+	}
+
+	objectSize := app.GetObjectSize(header.Group, header.Variation)
+	if objectSize == 0 {
+		m.logger.Warn("Master %s: Unknown size for G%dV%d", m.config.ID, header.Group, header.Variation)
+		return
+	}
+
+	for i := uint32(0); i < count; i++ {
+		index, err := objectIndex(parser, header, i)
+		if err != nil {
+			m.logger.Error("Master %s: Failed to read double-bit binary index: %v", m.config.ID, err)
+			break
+		}
+
+		data, err := parser.ReadBytes(objectSize)
+		if err != nil {
+			m.logger.Error("Master %s: Failed to read double-bit binary object: %v", m.config.ID, err)
+			break
+		}
+
+		dbi := app.ParseDoubleBitBinaryInput(data)
+
+		value := types.IndexedDoubleBitBinary{
+			Index: uint16(index),
+			Value: types.DoubleBitBinary{
+				Value: types.DoubleBitValue(dbi.Value),
+				Flags: types.Flags(dbi.Flags),
+				Time:  eventTime(header, data),
+			},
+		}
+
+		values = append(values, value)
+	}
+
+	m.callbacks.ProcessDoubleBitBinary(info, values)
+}
+
 // processAnalogObjects processes analog input objects using app layer parsers
 func (m *master) processAnalogObjects(parser *app.Parser, header *app.ObjectHeader, info HeaderInfo) {
 	count := app.GetCount(header.Range)
@@ -124,12 +224,13 @@ func (m *master) processAnalogObjects(parser *app.Parser, header *app.ObjectHead
 		return
 	}
 
-	startIndex := uint32(0)
-	if r, ok := header.Range.(app.StartStopRange); ok {
-		startIndex = r.Start
-	}
-
 	for i := uint32(0); i < count; i++ {
+		index, err := objectIndex(parser, header, i)
+		if err != nil {
+			m.logger.Error("Master %s: Failed to read analog index: %v", m.config.ID, err)
+			break
+		}
+
 		data, err := parser.ReadBytes(objectSize)
 		if err != nil {
 			m.logger.Error("Master %s: Failed to read analog object: %v", m.config.ID, err)
@@ -140,7 +241,7 @@ func (m *master) processAnalogObjects(parser *app.Parser, header *app.ObjectHead
 		var ai app.AnalogInput
 		var analogValue float64
 
-		switch header.Variation {
+		switch analogValueVariation(header) {
 		case app.AnalogInput32Bit:
 			ai = app.ParseAnalogInput32Bit(data)
 			if val, ok := ai.Value.(int32); ok {
@@ -151,6 +252,16 @@ func (m *master) processAnalogObjects(parser *app.Parser, header *app.ObjectHead
 			if val, ok := ai.Value.(int16); ok {
 				analogValue = float64(val)
 			}
+		case app.AnalogInput32BitNoFlag:
+			ai = app.ParseAnalogInput32Bit(append([]byte{app.FlagOnline}, data...))
+			if val, ok := ai.Value.(int32); ok {
+				analogValue = float64(val)
+			}
+		case app.AnalogInput16BitNoFlag:
+			ai = app.ParseAnalogInput16Bit(append([]byte{app.FlagOnline}, data...))
+			if val, ok := ai.Value.(int16); ok {
+				analogValue = float64(val)
+			}
 		case app.AnalogInputFloat:
 			ai = app.ParseAnalogInputFloat(data)
 			if val, ok := ai.Value.(float32); ok {
@@ -170,10 +281,11 @@ func (m *master) processAnalogObjects(parser *app.Parser, header *app.ObjectHead
 		}
 
 		value := types.IndexedAnalog{
-			Index: uint16(startIndex + i),
+			Index: uint16(index),
 			Value: types.Analog{
 				Value: analogValue,
 				Flags: types.Flags(ai.Flags),
+				Time:  eventTime(header, data),
 			},
 		}
 
@@ -194,12 +306,13 @@ func (m *master) processCounterObjects(parser *app.Parser, header *app.ObjectHea
 		return
 	}
 
-	startIndex := uint32(0)
-	if r, ok := header.Range.(app.StartStopRange); ok {
-		startIndex = r.Start
-	}
-
 	for i := uint32(0); i < count; i++ {
+		index, err := objectIndex(parser, header, i)
+		if err != nil {
+			m.logger.Error("Master %s: Failed to read counter index: %v", m.config.ID, err)
+			break
+		}
+
 		data, err := parser.ReadBytes(objectSize)
 		if err != nil {
 			m.logger.Error("Master %s: Failed to read counter object: %v", m.config.ID, err)
@@ -209,20 +322,23 @@ func (m *master) processCounterObjects(parser *app.Parser, header *app.ObjectHea
 		// Parse using app layer helpers
 		var counter app.Counter
 
-		switch header.Variation {
-		case app.Counter32Bit, app.Counter32BitWithFlag:
-			counter = app.ParseCounter32Bit(data)
-		case app.Counter16Bit, app.Counter16BitWithFlag:
+		switch {
+		case header.Group == app.GroupCounter && header.Variation == app.Counter32Bit:
+			counter = app.ParseCounter32Bit(append([]byte{app.FlagOnline}, data...))
+		case header.Group == app.GroupCounter && header.Variation == app.Counter16Bit:
+			counter = app.ParseCounter16Bit(append([]byte{app.FlagOnline}, data...))
+		case header.Variation == app.Counter16BitWithFlag || header.Variation == app.CounterEvent16BitWithFlagTime:
 			counter = app.ParseCounter16Bit(data)
 		default:
 			counter = app.ParseCounter32Bit(data)
 		}
 
 		value := types.IndexedCounter{
-			Index: uint16(startIndex + i),
+			Index: uint16(index),
 			Value: types.Counter{
 				Value: counter.Value,
 				Flags: types.Flags(counter.Flags),
+				Time:  eventTime(header, data),
 			},
 		}
 
@@ -232,22 +348,91 @@ func (m *master) processCounterObjects(parser *app.Parser, header *app.ObjectHea
 	m.callbacks.ProcessCounter(info, values)
 }
 
+// processFrozenCounterObjects processes frozen counter objects using app layer parsers
+func (m *master) processFrozenCounterObjects(parser *app.Parser, header *app.ObjectHeader, info HeaderInfo) {
+	count := app.GetCount(header.Range)
+	values := make([]types.IndexedFrozenCounter, 0, count)
+
+	objectSize := app.GetObjectSize(header.Group, header.Variation)
+	if objectSize == 0 {
+		m.logger.Warn("Master %s: Unknown size for G%dV%d", m.config.ID, header.Group, header.Variation)
+		return
+	}
+
+	for i := uint32(0); i < count; i++ {
+		index, err := objectIndex(parser, header, i)
+		if err != nil {
+			m.logger.Error("Master %s: Failed to read frozen counter index: %v", m.config.ID, err)
+			break
+		}
+
+		data, err := parser.ReadBytes(objectSize)
+		if err != nil {
+			m.logger.Error("Master %s: Failed to read frozen counter object: %v", m.config.ID, err)
+			break
+		}
+
+		// Variations without flag carry only the value
+		var counter app.Counter
+		switch {
+		case header.Group == app.GroupFrozenCounter && header.Variation == app.FrozenCounter32Bit:
+			counter = app.ParseCounter32Bit(append([]byte{app.FlagOnline}, data...))
+		case header.Group == app.GroupFrozenCounter && header.Variation == app.FrozenCounter16Bit:
+			counter = app.ParseCounter16Bit(append([]byte{app.FlagOnline}, data...))
+		case header.Variation == app.FrozenCounter16BitWithFlag, header.Variation == app.FrozenCounter16BitWithFlagTime:
+			counter = app.ParseCounter16Bit(data)
+		default:
+			counter = app.ParseCounter32Bit(data)
+		}
+
+		value := types.IndexedFrozenCounter{
+			Index: uint16(index),
+			Value: types.FrozenCounter{
+				Value: counter.Value,
+				Flags: types.Flags(counter.Flags),
+				Time:  eventTime(header, data),
+			},
+		}
+
+		values = append(values, value)
+	}
+
+	m.callbacks.ProcessFrozenCounter(info, values)
+}
+
 // processBinaryOutputStatus processes binary output status using app layer parsers
 func (m *master) processBinaryOutputStatus(parser *app.Parser, header *app.ObjectHeader, info HeaderInfo) {
 	count := app.GetCount(header.Range)
 	values := make([]types.IndexedBinaryOutputStatus, 0, count)
 
+	if header.Group == app.GroupBinaryOutput && header.Variation == app.BinaryOutputPacked {
+		start, data, err := readPacked(parser, header, 1)
+		if err != nil {
+			m.logger.Error("Master %s: Failed to read packed binary outputs: %v", m.config.ID, err)
+			return
+		}
+		for i, state := range app.UnpackBits(data, int(count)) {
+			values = append(values, types.IndexedBinaryOutputStatus{
+				Index: uint16(start + uint32(i)),
+				Value: types.BinaryOutputStatus{Value: state, Flags: packedFlags(state)},
+			})
+		}
+		m.callbacks.ProcessBinaryOutputStatus(info, values)
+		return
+	}
+
 	objectSize := app.GetObjectSize(header.Group, header.Variation)
 	if objectSize == 0 {
 		objectSize = 1 // Binary output is 1 byte
 	}
 
-	startIndex := uint32(0)
-	if r, ok := header.Range.(app.StartStopRange); ok {
-		startIndex = r.Start
-	}
-
 	for i := uint32(0); i < count; i++ {
+		index, err := objectIndex(parser, header, i)
+		if err != nil {
+			m.logger.Error("Master %s: Failed to read binary output index: %v", m.config.ID, err)
+			break
+		}
+
 		data, err := parser.ReadBytes(objectSize)
 		if err != nil {
 			m.logger.Error("Master %s: Failed to read binary output: %v", m.config.ID, err)
@@ -257,10 +442,11 @@ func (m *master) processBinaryOutputStatus(parser *app.Parser, header *app.Objec
 		bo := app.ParseBinaryOutput(data)
 
 		value := types.IndexedBinaryOutputStatus{
-			Index: uint16(startIndex + i),
+			Index: uint16(index),
 			Value: types.BinaryOutputStatus{
 				Value: bo.Value,
 				Flags: types.Flags(bo.Flags),
+				Time:  eventTime(header, data),
 			},
 		}
 
@@ -281,23 +467,24 @@ func (m *master) processAnalogOutputStatus(parser *app.Parser, header *app.Objec
 		return
 	}
 
-	startIndex := uint32(0)
-	if r, ok := header.Range.(app.StartStopRange); ok {
-		startIndex = r.Start
-	}
-
 	for i := uint32(0); i < count; i++ {
+		index, err := objectIndex(parser, header, i)
+		if err != nil {
+			m.logger.Error("Master %s: Failed to read analog output index: %v", m.config.ID, err)
+			break
+		}
+
 		data, err := parser.ReadBytes(objectSize)
 		if err != nil {
 			m.logger.Error("Master %s: Failed to read analog output: %v", m.config.ID, err)
 			break
 		}
 
-		// Parse analog output status (Group 40)
+		// Parse analog output status (Group 40) or event (Group 42)
 		var ai app.AnalogInput
 		var analogValue float64
 
-		switch header.Variation {
+		switch analogOutputValueVariation(header) {
 		case 1: // 32-bit
 			ai = app.ParseAnalogInput32Bit(data)
 			if val, ok := ai.Value.(int32); ok {
@@ -321,10 +508,11 @@ func (m *master) processAnalogOutputStatus(parser *app.Parser, header *app.Objec
 		}
 
 		value := types.IndexedAnalogOutputStatus{
-			Index: uint16(startIndex + i),
+			Index: uint16(index),
 			Value: types.AnalogOutputStatus{
 				Value: analogValue,
 				Flags: types.Flags(ai.Flags),
+				Time:  eventTime(header, data),
 			},
 		}
 
@@ -334,6 +522,111 @@ func (m *master) processAnalogOutputStatus(parser *app.Parser, header *app.Objec
 	m.callbacks.ProcessAnalogOutputStatus(info, values)
 }
 
+// processOctetStrings processes octet string objects (Group 110) or events
+// (Group 111), whose variation is the string length
+func (m *master) processOctetStrings(parser *app.Parser, header *app.ObjectHeader, info HeaderInfo) {
+	values, err := readOctetStrings(parser, header)
+	if err != nil {
+		m.logger.Error("Master %s: Failed to read octet strings: %v", m.config.ID, err)
+	}
+	if len(values) > 0 {
+		m.callbacks.ProcessOctetString(info, values)
+	}
+}
+
+// readOctetStrings reads the octet strings under a header
+func readOctetStrings(parser *app.Parser, header *app.ObjectHeader) ([]types.IndexedOctetString, error) {
+	count := app.GetCount(header.Range)
+	size := app.GetObjectSize(header.Group, header.Variation)
+	if size == 0 {
+		return nil, fmt.Errorf("octet string G%dV0 without a length", header.Group)
+	}
+
+	values := make([]types.IndexedOctetString, 0, count)
+	for i := uint32(0); i < count; i++ {
+		index, err := objectIndex(parser, header, i)
+		if err != nil {
+			return values, err
+		}
+		data, err := parser.ReadBytes(size)
+		if err != nil {
+			return values, err
+		}
+		values = append(values, types.IndexedOctetString{
+			Index: uint16(index),
+			Value: types.OctetString{Value: append([]byte(nil), data...)},
+		})
+	}
+	return values, nil
+}
+
+// parseOctetStrings returns the octet strings (G110) of a response
+func parseOctetStrings(resp *app.APDU) ([]types.IndexedOctetString, error) {
+	var values []types.IndexedOctetString
+
+	parser := app.NewParser(resp.Objects)
+	for parser.HasMore() {
+		header, err := parser.ReadObjectHeader()
+		if err != nil {
+			return values, err
+		}
+		if header.Group != app.GroupOctetString {
+			return values, fmt.Errorf("expected octet string objects, got G%dV%d", header.Group, header.Variation)
+		}
+
+		strings, err := readOctetStrings(parser, header)
+		values = append(values, strings...)
+		if err != nil {
+			return values, err
+		}
+	}
+
+	return values, nil
+}
+
+// readDeviceAttributes reads the device attributes under a header, one per
+// attribute set of its range
+func readDeviceAttributes(parser *app.Parser, header *app.ObjectHeader) ([]types.DeviceAttribute, error) {
+	count := app.GetCount(header.Range)
+	attributes := make([]types.DeviceAttribute, 0, count)
+	for i := uint32(0); i < count; i++ {
+		set, err := objectIndex(parser, header, i)
+		if err != nil {
+			return attributes, err
+		}
+		attr, err := parser.ReadDeviceAttribute(uint16(set), header.Variation)
+		if err != nil {
+			return attributes, err
+		}
+		attributes = append(attributes, attr)
+	}
+	return attributes, nil
+}
+
+// parseDeviceAttributes returns the device attributes (G0) of a response
+func parseDeviceAttributes(resp *app.APDU) ([]types.DeviceAttribute, error) {
+	var attributes []types.DeviceAttribute
+
+	parser := app.NewParser(resp.Objects)
+	for parser.HasMore() {
+		header, err := parser.ReadObjectHeader()
+		if err != nil {
+			return attributes, err
+		}
+		if header.Group != app.GroupDeviceAttributes {
+			return attributes, fmt.Errorf("expected device attribute objects, got G%dV%d", header.Group, header.Variation)
+		}
+
+		read, err := readDeviceAttributes(parser, header)
+		attributes = append(attributes, read...)
+		if err != nil {
+			return attributes, err
+		}
+	}
+
+	return attributes, nil
+}
+
 // isEventGroup returns true if the group is an event group
 func isEventGroup(group uint8) bool {
 	switch group {
@@ -344,9 +637,153 @@ func isEventGroup(group uint8) bool {
 		app.GroupAnalogInputEvent,
 		app.GroupFrozenAnalogEvent,
 		app.GroupBinaryOutputEvent,
-		app.GroupAnalogOutputEvent:
+		app.GroupAnalogOutputEvent,
+		app.GroupOctetStringEvent:
 		return true
 	default:
 		return false
 	}
 }
+
+// parseDeadbands returns the analog input deadbands (G34) of a response
+func parseDeadbands(resp *app.APDU) ([]types.IndexedDeadband, error) {
+	var deadbands []types.IndexedDeadband
+
+	parser := app.NewParser(resp.Objects)
+	for parser.HasMore() {
+		header, err := parser.ReadObjectHeader()
+		if err != nil {
+			return deadbands, err
+		}
+
+		size := app.GetObjectSize(header.Group, header.Variation)
+		if header.Group != app.GroupAnalogInputDeadband || size == 0 {
+			return deadbands, fmt.Errorf("expected deadband objects, got G%dV%d", header.Group, header.Variation)
+		}
+
+		for i := uint32(0); i < app.GetCount(header.Range); i++ {
+			index, err := objectIndex(parser, header, i)
+			if err != nil {
+				return deadbands, err
+			}
+			data, err := parser.ReadBytes(size)
+			if err != nil {
+				return deadbands, err
+			}
+			deadbands = append(deadbands, types.IndexedDeadband{
+				Index: uint16(index),
+				Value: app.ParseAnalogDeadband(header.Variation, data).Value,
+			})
+		}
+	}
+
+	return deadbands, nil
+}
+
+// objectIndex returns the point index of the i-th object under a header,
+// consuming the index prefix when the qualifier carries one
+func objectIndex(parser *app.Parser, header *app.ObjectHeader, i uint32) (uint32, error) {
+	switch r := header.Range.(type) {
+	case app.StartStopRange:
+		return r.Start + i, nil
+	case app.IndexPrefixRange:
+		return parser.ReadIndex(r.IndexSize)
+	default:
+		return i, nil
+	}
+}
+
+// readPacked reads the bit-packed objects of a start-stop header, with bits
+// bits per point, and returns the index of the first point and the packed bytes
+func readPacked(parser *app.Parser, header *app.ObjectHeader, bits int) (uint32, []byte, error) {
+	r, ok := header.Range.(app.StartStopRange)
+	if !ok {
+		return 0, nil, fmt.Errorf("packed G%dV%d without start-stop range", header.Group, header.Variation)
+	}
+	size := (uint64(app.GetCount(header.Range))*uint64(bits) + 7) / 8
+	if size > uint64(parser.Remaining()) {
+		return 0, nil, app.ErrInsufficientData
+	}
+	data, err := parser.ReadBytes(int(size))
+	return r.Start, data, err
+}
+
+// packedFlags returns the flags of a packed binary state, which is reported
+// without flags and so taken as online
+func packedFlags(state bool) types.Flags {
+	flags := app.FlagOnline
+	if state {
+		flags |= app.FlagState
+	}
+	return types.Flags(flags)
+}
+
+// analogValueVariation maps analog event variations onto the static variation
+// with the same value encoding
+func analogValueVariation(header *app.ObjectHeader) uint8 {
+	if header.Group != app.GroupAnalogInputEvent {
+		return header.Variation
+	}
+
+	switch header.Variation {
+	case app.AnalogInputEvent32BitWithTime:
+		return app.AnalogInput32Bit
+	case app.AnalogInputEvent16BitWithTime:
+		return app.AnalogInput16Bit
+	case app.AnalogInputEventFloatWithTime:
+		return app.AnalogInputFloat
+	case app.AnalogInputEventDoubleWithTime:
+		return app.AnalogInputDouble
+	default:
+		return header.Variation
+	}
+}
+
+// analogOutputValueVariation maps analog output event variations onto the
+// Group 40 variation with the same value encoding
+func analogOutputValueVariation(header *app.ObjectHeader) uint8 {
+	if header.Group != app.GroupAnalogOutputEvent {
+		return header.Variation
+	}
+
+	switch header.Variation {
+	case app.AnalogOutputEvent32BitNoTime, app.AnalogOutputEvent32BitWithTime:
+		return app.AnalogOutputStatus32Bit
+	case app.AnalogOutputEvent16BitNoTime, app.AnalogOutputEvent16BitWithTime:
+		return app.AnalogOutputStatus16Bit
+	case app.AnalogOutputEventFloatNoTime, app.AnalogOutputEventFloatWithTime:
+		return app.AnalogOutputStatusFloat
+	case app.AnalogOutputEventDoubleNoTime, app.AnalogOutputEventDoubleWithTime:
+		return app.AnalogOutputStatusDouble
+	default:
+		return header.Variation
+	}
+}
+
+// eventTime extracts the trailing 48-bit timestamp of event variations with time
+func eventTime(header *app.ObjectHeader, data []byte) types.DNP3Time {
+	withTime := false
+	switch header.Group {
+	case app.GroupBinaryInputEvent:
+		withTime = header.Variation == app.BinaryInputEventWithTime
+	case app.GroupDoubleBitBinaryEvent:
+		withTime = header.Variation == app.DoubleBitBinaryEventWithTime
+	case app.GroupBinaryOutputEvent:
+		withTime = header.Variation == app.BinaryOutputEventWithTime
+	case app.GroupCounterEvent:
+		withTime = header.Variation == app.CounterEvent32BitWithFlagTime || header.Variation == app.CounterEvent16BitWithFlagTime
+	case app.GroupFrozenCounter, app.GroupFrozenCounterEvent:
+		withTime = header.Variation == app.FrozenCounter32BitWithFlagTime || header.Variation == app.FrozenCounter16BitWithFlagTime
+	case app.GroupAnalogInputEvent, app.GroupAnalogOutputEvent:
+		switch header.Variation {
+		case app.AnalogInputEvent32BitWithTime, app.AnalogInputEvent16BitWithTime,
+			app.AnalogInputEventFloatWithTime, app.AnalogInputEventDoubleWithTime:
+			withTime = true
+		}
+	}
+
+	if !withTime || len(data) < 6 {
+		return types.ZeroTime()
+	}
+	return types.DNP3Time(app.ParseTime48(data[len(data)-6:]))
+}
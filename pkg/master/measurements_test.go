@@ -0,0 +1,49 @@
+package master
+
+import (
+	"testing"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+func TestPackedObjects(t *testing.T) {
+	h := newTestHarness(t, MasterConfig{})
+
+	builder := app.NewObjectBuilder()
+	builder.AddHeader(app.GroupBinaryInput, app.BinaryInputPacked, app.Qualifier8BitStartStop, app.StartStopRange{Start: 3, Stop: 11})
+	builder.AddRawData(app.PackBits([]bool{true, false, false, false, false, false, false, false, true}))
+	builder.AddHeader(app.GroupDoubleBitBinaryInput, app.DoubleBitBinaryInputPacked, app.Qualifier8BitStartStop, app.StartStopRange{Start: 0, Stop: 1})
+	builder.AddRawData(app.PackDoubleBits([]uint8{app.DoubleBitOn, app.DoubleBitOff}))
+	builder.AddHeader(app.GroupBinaryOutput, app.BinaryOutputPacked, app.Qualifier8BitStartStop, app.StartStopRange{Start: 5, Stop: 5})
+	builder.AddRawData(app.PackBits([]bool{true}))
+	builder.AddHeader(app.GroupAnalogInput, app.AnalogInput16BitNoFlag, app.Qualifier8BitStartStop, app.StartStopRange{Start: 0, Stop: 0})
+	builder.AddInt16(42)
+
+	done := h.run(h.master.performIntegrityScan)
+	req := h.expectRequest(app.FuncRead)
+	h.respond(app.NewResponseAPDU(req.Sequence, app.IIN{}, builder.Build()))
+	if err := h.wait(done); err != nil {
+		t.Fatal(err)
+	}
+
+	c := h.callbacks
+	if len(c.binaries) != 9 || c.binaries[0].Index != 3 || !c.binaries[0].Value.Value ||
+		c.binaries[1].Value.Value || c.binaries[8].Index != 11 || !c.binaries[8].Value.Value {
+		t.Fatalf("Packed binaries: got %+v", c.binaries)
+	}
+	if c.binaries[0].Value.Flags != types.Flags(app.FlagOnline|app.FlagState) {
+		t.Errorf("Packed binary flags: got 0x%02X, want online and state", c.binaries[0].Value.Flags)
+	}
+	if len(c.doubleBits) != 2 || c.doubleBits[0].Value.Value != types.DoubleBitOn || c.doubleBits[1].Value.Value != types.DoubleBitOff {
+		t.Errorf("Packed double-bit binaries: got %+v", c.doubleBits)
+	}
+	if len(c.binaryOutputs) != 1 || c.binaryOutputs[0].Index != 5 || !c.binaryOutputs[0].Value.Value {
+		t.Errorf("Packed binary outputs: got %+v", c.binaryOutputs)
+	}
+
+	// The header after the packed ones is still read
+	if len(c.analogs) != 1 || c.analogs[0].Value.Value != 42 {
+		t.Errorf("Analog after packed objects: got %+v", c.analogs)
+	}
+}
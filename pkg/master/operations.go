@@ -2,6 +2,7 @@ package master
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"avaneesh/dnp3-go/pkg/app"
@@ -199,17 +200,21 @@ func (m *master) SelectAndOperate(commands []types.Command) ([]types.CommandStat
 		commands:     commands,
 		selectBefore: true,
 		priority:     PriorityHigh,
+		started:      make(chan struct{}),
 		result:       make(chan CommandResult, 1),
 	}
 
 	m.taskQueue.Push(task, task.Priority(), time.Now())
 
+	// The SELECT and the OPERATE each have their own response timeout
+	if err := m.waitTaskStart(task.started); err != nil {
+		return nil, err
+	}
+
 	// Wait for result
 	select {
 	case result := <-task.result:
 		return result.Statuses, result.Error
-	case <-time.After(m.config.ResponseTimeout * 2): // SELECT + OPERATE
-		return nil, ErrTimeout
 	case <-m.ctx.Done():
 		return nil, m.ctx.Err()
 	}
@@ -221,17 +226,20 @@ func (m *master) DirectOperate(commands []types.Command) ([]types.CommandStatus,
 		commands:     commands,
 		selectBefore: false,
 		priority:     PriorityHigh,
+		started:      make(chan struct{}),
 		result:       make(chan CommandResult, 1),
 	}
 
 	m.taskQueue.Push(task, task.Priority(), time.Now())
 
+	if err := m.waitTaskStart(task.started); err != nil {
+		return nil, err
+	}
+
 	// Wait for result
 	select {
 	case result := <-task.result:
 		return result.Statuses, result.Error
-	case <-time.After(m.config.ResponseTimeout):
-		return nil, ErrTimeout
 	case <-m.ctx.Done():
 		return nil, m.ctx.Err()
 	}
@@ -240,7 +248,10 @@ func (m *master) DirectOperate(commands []types.Command) ([]types.CommandStatus,
 // performSelectAndOperate executes SELECT and OPERATE using app layer helpers
 func (m *master) performSelectAndOperate(commands []types.Command) ([]types.CommandStatus, error) {
 	// Build command objects using app layer helpers
-	objects := buildCROBObjects(commands)
+	objects, err := app.BuildCommands(commands)
+	if err != nil {
+		return nil, err
+	}
 
 	// SELECT phase
 	selectAPDU := app.BuildSelectRequest(m.getNextSequence(), objects)
@@ -249,10 +260,11 @@ func (m *master) performSelectAndOperate(commands []types.Command) ([]types.Comm
 		return nil, err
 	}
 
-	// Parse SELECT response status
-	statuses := parseCommandResponse(selectResp, len(commands))
-	if !allSuccess(statuses) {
-		return statuses, nil
+	// Parse SELECT response status; a refused SELECT is never followed by
+	// OPERATE
+	statuses, err := parseCommandResponse(selectResp, len(commands))
+	if err != nil || !allSuccess(statuses) {
+		return statuses, err
 	}
 
 	// OPERATE phase with same objects
@@ -263,12 +275,15 @@ func (m *master) performSelectAndOperate(commands []types.Command) ([]types.Comm
 	}
 
 	// Parse OPERATE response status
-	return parseCommandResponse(operateResp, len(commands)), nil
+	return parseCommandResponse(operateResp, len(commands))
 }
 
 // performDirectOperate executes DIRECT OPERATE using app layer helpers
 func (m *master) performDirectOperate(commands []types.Command) ([]types.CommandStatus, error) {
-	objects := buildCROBObjects(commands)
+	objects, err := app.BuildCommands(commands)
+	if err != nil {
+		return nil, err
+	}
 
 	apdu := app.BuildDirectOperateRequest(m.getNextSequence(), objects)
 	resp, err := m.sendAndWait(apdu, m.config.ResponseTimeout)
@@ -276,98 +291,65 @@ func (m *master) performDirectOperate(commands []types.Command) ([]types.Command
 		return nil, err
 	}
 
-	return parseCommandResponse(resp, len(commands)), nil
+	return parseCommandResponse(resp, len(commands))
 }
 
-// buildCROBObjects builds CROB objects from commands using app layer helpers
-func buildCROBObjects(commands []types.Command) []byte {
-	builder := app.NewObjectBuilder()
-
-	for _, cmd := range commands {
-		// Extract CROB from command data
-		crobData, ok := cmd.Data.(types.CROB)
-		if !ok {
-			continue // Skip non-CROB commands
-		}
-
-		// Convert types.CROB to app.CROB
-		var crob app.CROB
-
-		switch crobData.OpType {
-		case types.ControlCodeLatchOn:
-			crob = app.NewLatchOn()
-		case types.ControlCodeLatchOff:
-			crob = app.NewLatchOff()
-		case types.ControlCodePulseOn:
-			crob = app.NewPulseOn(crobData.OnTimeMs)
-		case types.ControlCodePulseOff:
-			crob = app.NewPulseOff(crobData.OffTimeMs)
-		default:
-			crob = app.NewCROB(uint8(crobData.OpType), crobData.Count, crobData.OnTimeMs, crobData.OffTimeMs)
-		}
-
-		// Add object header for this command
-		builder.AddHeader(
-			app.GroupBinaryOutputCommand,
-			1, // CROB variation
-			app.Qualifier8BitStartStop,
-			app.StartStopRange{Start: uint32(cmd.Index), Stop: uint32(cmd.Index)},
-		)
-
-		// Add CROB data
-		builder.AddRawData(crob.Serialize())
+// parseCommandResponse parses command response and extracts status codes.
+// Each echoed object's status is its last byte, whatever its size. A
+// rejected request is an error, and a command the outstation did not echo
+// gets CommandStatusFormatError as it cannot have been executed.
+func parseCommandResponse(apdu *app.APDU, numCommands int) ([]types.CommandStatus, error) {
+	if err := checkRejected(apdu); err != nil {
+		return nil, err
 	}
 
-	return builder.Build()
-}
-
-// parseCommandResponse parses command response and extracts status codes
-func parseCommandResponse(apdu *app.APDU, numCommands int) []types.CommandStatus {
 	statuses := make([]types.CommandStatus, numCommands)
 
-	if apdu == nil || len(apdu.Objects) == 0 {
-		// No response data, assume success
-		for i := range statuses {
-			statuses[i] = types.CommandStatusSuccess
-		}
-		return statuses
-	}
-
 	// Parse response objects to extract status codes
 	parser := app.NewParser(apdu.Objects)
 	cmdIndex := 0
 
+parse:
 	for parser.HasMore() && cmdIndex < numCommands {
 		header, err := parser.ReadObjectHeader()
 		if err != nil {
 			break
 		}
 
-		count := app.GetCount(header.Range)
+		size := app.GetObjectSize(header.Group, header.Variation)
+		if size == 0 {
+			break
+		}
 
-		// Read CROB responses
+		indexSize := 0
+		if r, ok := header.Range.(app.IndexPrefixRange); ok {
+			indexSize = r.IndexSize
+		}
+
+		count := app.GetCount(header.Range)
 		for i := uint32(0); i < count && cmdIndex < numCommands; i++ {
-			data, err := parser.ReadBytes(11) // CROB is 11 bytes
-			if err != nil {
-				break
+			if indexSize > 0 {
+				if _, err := parser.ReadIndex(indexSize); err != nil {
+					break parse
+				}
 			}
 
-			crob, err := app.ParseCROB(data)
+			data, err := parser.ReadBytes(size)
 			if err != nil {
-				statuses[cmdIndex] = types.CommandStatusFormatError
-			} else {
-				statuses[cmdIndex] = types.CommandStatus(crob.Status)
+				break parse
 			}
+
+			statuses[cmdIndex] = types.CommandStatus(data[size-1])
 			cmdIndex++
 		}
 	}
 
-	// Fill remaining with success if not enough responses
+	// Commands missing from the echo were not executed
 	for i := cmdIndex; i < numCommands; i++ {
-		statuses[i] = types.CommandStatusSuccess
+		statuses[i] = types.CommandStatusFormatError
 	}
 
-	return statuses
+	return statuses, nil
 }
 
 // allSuccess checks if all command statuses are successful
@@ -382,28 +364,411 @@ func allSuccess(statuses []types.CommandStatus) bool {
 
 // performEnableUnsolicited enables unsolicited responses using app layer helpers
 func (m *master) performEnableUnsolicited(classes app.ClassField) error {
-	apdu := app.BuildEnableUnsolicitedRequest(m.getNextSequence(), classes)
+	apdu := app.BuildEnableUnsolicitedRequest(m.getNextSequence(), eventClasses(classes)...)
 
-	_, err := m.sendAndWait(apdu, m.config.ResponseTimeout)
-	return err
+	resp, err := m.sendAndWait(apdu, m.config.ResponseTimeout)
+	if err != nil {
+		return err
+	}
+	return checkRejected(resp)
 }
 
 // performDisableUnsolicited disables unsolicited responses using app layer helpers
 func (m *master) performDisableUnsolicited(classes app.ClassField) error {
-	apdu := app.BuildDisableUnsolicitedRequest(m.getNextSequence(), classes)
+	apdu := app.BuildDisableUnsolicitedRequest(m.getNextSequence(), eventClasses(classes)...)
 
-	_, err := m.sendAndWait(apdu, m.config.ResponseTimeout)
+	resp, err := m.sendAndWait(apdu, m.config.ResponseTimeout)
+	if err != nil {
+		return err
+	}
+	return checkRejected(resp)
+}
+
+// SyncTime synchronizes the outstation clock to the time from GetTime
+func (m *master) SyncTime(mode TimeSyncMode) error {
+	task := &TimeSyncTask{
+		mode:     mode,
+		priority: PriorityHigh,
+		started:  make(chan struct{}),
+		result:   make(chan error, 1),
+	}
+
+	m.taskQueue.Push(task, task.Priority(), time.Now())
+
+	// The measurement and the WRITE each have their own response timeout
+	if err := m.waitTaskStart(task.started); err != nil {
+		return err
+	}
+
+	// Wait for result
+	select {
+	case err := <-task.result:
+		return err
+	case <-m.ctx.Done():
+		return m.ctx.Err()
+	}
+}
+
+// performTimeSync performs the LAN or non-LAN time synchronization procedure
+func (m *master) performTimeSync(mode TimeSyncMode) error {
+	if mode == TimeSyncLAN {
+		return m.performLANTimeSync()
+	}
+	return m.performNonLANTimeSync()
+}
+
+// performNonLANTimeSync measures the one-way link delay as half the round trip
+// less the outstation's processing time, then writes the time plus that delay
+func (m *master) performNonLANTimeSync() error {
+	start := m.callbacks.GetTime()
+	resp, err := m.sendAndWait(app.BuildDelayMeasurementRequest(m.getNextSequence()), m.config.ResponseTimeout)
+	if err != nil {
+		return err
+	}
+	roundTrip := m.callbacks.GetTime().Sub(start)
+	if err := checkRejected(resp); err != nil {
+		return err
+	}
+
+	processing, err := parseTimeDelay(resp)
+	if err != nil {
+		return err
+	}
+	delay := (roundTrip - processing) / 2
+	if delay < 0 {
+		delay = 0
+	}
+	m.logger.Debug("Master %s: Measured link delay %s (round trip %s, outstation %s)",
+		m.config.ID, delay, roundTrip, processing)
+
+	seq := m.getNextSequence()
+	resp, err = m.sendAndWait(app.BuildTimeSyncRequest(seq, m.callbacks.GetTime().Add(delay)), m.config.ResponseTimeout)
+	if err != nil {
+		return err
+	}
+	return checkRejected(resp)
+}
+
+// performLANTimeSync has the outstation record the time the request arrives
+// and then writes the master's time at that moment
+func (m *master) performLANTimeSync() error {
+	recorded := m.callbacks.GetTime()
+	resp, err := m.sendAndWait(app.BuildRecordCurrentTimeRequest(m.getNextSequence()), m.config.ResponseTimeout)
+	if err != nil {
+		return err
+	}
+	if err := checkRejected(resp); err != nil {
+		return err
+	}
+
+	resp, err = m.sendAndWait(app.BuildLastRecordedTimeRequest(m.getNextSequence(), recorded), m.config.ResponseTimeout)
+	if err != nil {
+		return err
+	}
+	return checkRejected(resp)
+}
+
+// parseTimeDelay returns the time delay object (G52) of a response
+func parseTimeDelay(resp *app.APDU) (time.Duration, error) {
+	parser := app.NewParser(resp.Objects)
+	header, err := parser.ReadObjectHeader()
+	if err != nil {
+		return 0, err
+	}
+	if header.Group != app.GroupTimeDelay {
+		return 0, fmt.Errorf("expected time delay object, got G%dV%d", header.Group, header.Variation)
+	}
+
+	data, err := parser.ReadBytes(app.GetObjectSize(header.Group, header.Variation))
+	if err != nil {
+		return 0, err
+	}
+	return app.ParseTimeDelay(header.Variation, data), nil
+}
+
+// checkRejected returns ErrRejected if the response reports the request as
+// unsupported or invalid, also wrapping ErrNotSupported when the function
+// code is unsupported
+func checkRejected(resp *app.APDU) error {
+	const requestErrors = types.IIN2NoFuncCodeSupport | types.IIN2ObjectUnknown | types.IIN2ParameterError
+	if resp.IIN.IIN2&types.IIN2NoFuncCodeSupport != 0 {
+		return fmt.Errorf("%w: %w: IIN2=0x%02X", ErrRejected, ErrNotSupported, resp.IIN.IIN2)
+	}
+	if resp.IIN.IIN2&requestErrors != 0 {
+		return fmt.Errorf("%w: IIN2=0x%02X", ErrRejected, resp.IIN.IIN2)
+	}
+	return nil
+}
+
+// AssignClass moves points start through stop of a static object group to an
+// event class, 0 to stop them generating events
+func (m *master) AssignClass(class, group uint8, start, stop uint16) error {
+	if class > 3 {
+		return fmt.Errorf("invalid event class %d", class)
+	}
+
+	task := &AssignClassTask{
+		class:    class,
+		group:    group,
+		start:    start,
+		stop:     stop,
+		priority: PriorityHigh,
+		started:  make(chan struct{}),
+		result:   make(chan error, 1),
+	}
+
+	m.taskQueue.Push(task, task.Priority(), time.Now())
+
+	if err := m.waitTaskStart(task.started); err != nil {
+		return err
+	}
+
+	// Wait for result
+	select {
+	case err := <-task.result:
+		return err
+	case <-m.ctx.Done():
+		return m.ctx.Err()
+	}
+}
+
+// performAssignClass sends an ASSIGN CLASS request for a range of points
+func (m *master) performAssignClass(class, group uint8, start, stop uint16) error {
+	objects := app.BuildAssignClass(class, group, uint32(start), uint32(stop))
+	resp, err := m.sendAndWait(app.BuildAssignClassRequest(m.getNextSequence(), objects), m.config.ResponseTimeout)
+	if err != nil {
+		return err
+	}
+	return checkRejected(resp)
+}
+
+// ReadDeadbands reads the deadbands of analog inputs start through stop
+func (m *master) ReadDeadbands(start, stop uint16) ([]types.IndexedDeadband, error) {
+	return m.runDeadbandTask(&DeadbandTask{start: start, stop: stop})
+}
+
+// WriteDeadbands writes analog input deadbands using the given Group 34
+// variation (1: 16-bit, 2: 32-bit, 3: float)
+func (m *master) WriteDeadbands(variation uint8, deadbands []types.IndexedDeadband) error {
+	if app.GetObjectSize(app.GroupAnalogInputDeadband, variation) == 0 {
+		return fmt.Errorf("invalid deadband variation %d", variation)
+	}
+	_, err := m.runDeadbandTask(&DeadbandTask{write: true, variation: variation, deadbands: deadbands})
 	return err
 }
 
-// performTimeSync performs time synchronization using app layer helpers
-func (m *master) performTimeSync() error {
-	apdu := app.BuildTimeSyncNowRequest(m.getNextSequence())
+// runDeadbandTask queues a deadband task and waits for its result
+func (m *master) runDeadbandTask(task *DeadbandTask) ([]types.IndexedDeadband, error) {
+	task.priority = PriorityHigh
+	task.started = make(chan struct{})
+	task.result = make(chan DeadbandResult, 1)
 
-	_, err := m.sendAndWait(apdu, m.config.ResponseTimeout)
+	m.taskQueue.Push(task, task.Priority(), time.Now())
+
+	if err := m.waitTaskStart(task.started); err != nil {
+		return nil, err
+	}
+
+	// Wait for result
+	select {
+	case result := <-task.result:
+		return result.Deadbands, result.Error
+	case <-m.ctx.Done():
+		return nil, m.ctx.Err()
+	}
+}
+
+// performReadDeadbands reads a range of analog input deadbands in the
+// variation chosen by the outstation
+func (m *master) performReadDeadbands(start, stop uint16) ([]types.IndexedDeadband, error) {
+	objects := app.BuildRangeRead(app.GroupAnalogInputDeadband, app.AnalogDeadbandAny, uint32(start), uint32(stop))
+	resp, err := m.sendAndWait(app.BuildReadRequest(m.getNextSequence(), objects), m.config.ResponseTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRejected(resp); err != nil {
+		return nil, err
+	}
+	return parseDeadbands(resp)
+}
+
+// performWriteDeadbands writes analog input deadbands
+func (m *master) performWriteDeadbands(variation uint8, deadbands []types.IndexedDeadband) error {
+	objects := app.BuildAnalogDeadbands(variation, deadbands)
+	resp, err := m.sendAndWait(app.BuildWriteRequest(m.getNextSequence(), objects), m.config.ResponseTimeout)
+	if err != nil {
+		return err
+	}
+	return checkRejected(resp)
+}
+
+// ReadOctetStrings reads the octet strings start through stop
+func (m *master) ReadOctetStrings(start, stop uint16) ([]types.IndexedOctetString, error) {
+	return m.runOctetStringTask(&OctetStringTask{start: start, stop: stop})
+}
+
+// WriteOctetStrings writes octet strings of 1 to 255 bytes
+func (m *master) WriteOctetStrings(values []types.IndexedOctetString) error {
+	for _, v := range values {
+		if len(v.Value.Value) == 0 || len(v.Value.Value) > types.MaxOctetStringLength {
+			return fmt.Errorf("octet string %d: invalid length %d", v.Index, len(v.Value.Value))
+		}
+	}
+	_, err := m.runOctetStringTask(&OctetStringTask{write: true, values: values})
 	return err
 }
 
+// runOctetStringTask queues an octet string task and waits for its result
+func (m *master) runOctetStringTask(task *OctetStringTask) ([]types.IndexedOctetString, error) {
+	task.priority = PriorityHigh
+	task.started = make(chan struct{})
+	task.result = make(chan OctetStringResult, 1)
+
+	m.taskQueue.Push(task, task.Priority(), time.Now())
+
+	if err := m.waitTaskStart(task.started); err != nil {
+		return nil, err
+	}
+
+	// Wait for result
+	select {
+	case result := <-task.result:
+		return result.Values, result.Error
+	case <-m.ctx.Done():
+		return nil, m.ctx.Err()
+	}
+}
+
+// performReadOctetStrings reads a range of octet strings
+func (m *master) performReadOctetStrings(start, stop uint16) ([]types.IndexedOctetString, error) {
+	objects := app.BuildRangeRead(app.GroupOctetString, app.VariationAny, uint32(start), uint32(stop))
+	resp, err := m.sendAndWait(app.BuildReadRequest(m.getNextSequence(), objects), m.config.ResponseTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRejected(resp); err != nil {
+		return nil, err
+	}
+	return parseOctetStrings(resp)
+}
+
+// performWriteOctetStrings writes octet strings
+func (m *master) performWriteOctetStrings(values []types.IndexedOctetString) error {
+	objects := app.BuildOctetStrings(values)
+	resp, err := m.sendAndWait(app.BuildWriteRequest(m.getNextSequence(), objects), m.config.ResponseTimeout)
+	if err != nil {
+		return err
+	}
+	return checkRejected(resp)
+}
+
+// ReadDeviceAttributes reads every device attribute (G0) of the outstation:
+// the standard set 0 and any user-defined sets
+func (m *master) ReadDeviceAttributes() ([]types.DeviceAttribute, error) {
+	task := &DeviceAttributesTask{
+		priority: PriorityHigh,
+		started:  make(chan struct{}),
+		result:   make(chan DeviceAttributesResult, 1),
+	}
+
+	m.taskQueue.Push(task, task.Priority(), time.Now())
+
+	if err := m.waitTaskStart(task.started); err != nil {
+		return nil, err
+	}
+
+	// Wait for result
+	select {
+	case result := <-task.result:
+		return result.Attributes, result.Error
+	case <-m.ctx.Done():
+		return nil, m.ctx.Err()
+	}
+}
+
+// performReadDeviceAttributes reads all attributes of all sets (G0V254, qualifier 0x06)
+func (m *master) performReadDeviceAttributes() ([]types.DeviceAttribute, error) {
+	objects := app.BuildAllObjects(app.GroupDeviceAttributes, app.DeviceAttributeAll)
+	resp, err := m.sendAndWait(app.BuildReadRequest(m.getNextSequence(), objects), m.config.ResponseTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRejected(resp); err != nil {
+		return nil, err
+	}
+	return parseDeviceAttributes(resp)
+}
+
+// ReadFile reads a file of the outstation
+func (m *master) ReadFile(name string) ([]byte, error) {
+	result := m.runFileTask(&FileTask{operation: fileOpRead, name: name})
+	return result.Data, result.Error
+}
+
+// WriteFile writes a file of the outstation, creating it or replacing its
+// contents
+func (m *master) WriteFile(name string, data []byte) error {
+	return m.runFileTask(&FileTask{operation: fileOpWrite, name: name, data: data}).Error
+}
+
+// ListDirectory lists the files and directories of an outstation directory
+func (m *master) ListDirectory(path string) ([]types.FileInfo, error) {
+	result := m.runFileTask(&FileTask{operation: fileOpList, name: path})
+	return result.Files, result.Error
+}
+
+// DeleteFile deletes a file of the outstation
+func (m *master) DeleteFile(name string) error {
+	return m.runFileTask(&FileTask{operation: fileOpDelete, name: name}).Error
+}
+
+// GetFileInfo describes a file or directory of the outstation
+func (m *master) GetFileInfo(name string) (types.FileInfo, error) {
+	result := m.runFileTask(&FileTask{operation: fileOpInfo, name: name})
+	return result.Info, result.Error
+}
+
+// runFileTask queues a file task and waits for its result. A transfer takes
+// a request per block, each with its own response timeout, so only the wait
+// for the task to start is limited here.
+func (m *master) runFileTask(task *FileTask) FileResult {
+	task.priority = PriorityHigh
+	task.started = make(chan struct{})
+	task.result = make(chan FileResult, 1)
+
+	m.taskQueue.Push(task, task.Priority(), time.Now())
+
+	if err := m.waitTaskStart(task.started); err != nil {
+		return FileResult{Error: err}
+	}
+
+	// Wait for result
+	select {
+	case result := <-task.result:
+		return result
+	case <-m.ctx.Done():
+		return FileResult{Error: m.ctx.Err()}
+	}
+}
+
+// waitTaskStart waits for a queued task to start, for TaskStartTimeout or
+// else ResponseTimeout. Callers bound only this wait, as each request of a
+// running task has its own response timeout.
+func (m *master) waitTaskStart(started <-chan struct{}) error {
+	startTimeout := m.config.TaskStartTimeout
+	if startTimeout == 0 {
+		startTimeout = m.config.ResponseTimeout
+	}
+	select {
+	case <-started:
+		return nil
+	case <-time.After(startTimeout):
+		return ErrTimeout
+	case <-m.ctx.Done():
+		return m.ctx.Err()
+	}
+}
+
 // performColdRestart performs cold restart using app layer helpers
 func (m *master) performColdRestart() error {
 	apdu := app.BuildColdRestartRequest(m.getNextSequence())
@@ -0,0 +1,312 @@
+package master
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// writtenTime returns the G50 variation and time written by a time sync WRITE
+func writtenTime(t *testing.T, req *app.APDU) (uint8, time.Time) {
+	t.Helper()
+
+	parser := app.NewParser(req.Objects)
+	header, err := parser.ReadObjectHeader()
+	if err != nil {
+		t.Fatalf("ReadObjectHeader failed: %v", err)
+	}
+	if header.Group != app.GroupTimeDate {
+		t.Fatalf("WRITE: got G%dV%d, want G50", header.Group, header.Variation)
+	}
+	data, err := parser.ReadBytes(6)
+	if err != nil {
+		t.Fatalf("ReadBytes failed: %v", err)
+	}
+	return header.Variation, app.ParseTime48(data).ToTime()
+}
+
+func TestSyncTimeNonLAN(t *testing.T) {
+	h := newTestHarness(t, MasterConfig{})
+	h.master.Enable()
+
+	done := h.run(func() error { return h.master.SyncTime(TimeSyncNonLAN) })
+
+	// 100ms round trip of which the outstation spent 60ms leaves 20ms each way
+	req := h.expectRequest(app.FuncDelayMeasurement)
+	time.Sleep(100 * time.Millisecond)
+	h.respond(app.NewResponseAPDU(req.Sequence, app.IIN{}, app.BuildTimeDelay(60*time.Millisecond)))
+
+	req = h.expectRequest(app.FuncWrite)
+	received := time.Now()
+	variation, written := writtenTime(t, req)
+	if variation != 1 {
+		t.Errorf("WRITE: got G50V%d, want G50V1", variation)
+	}
+	if ahead := written.Sub(received); ahead < 15*time.Millisecond || ahead > 60*time.Millisecond {
+		t.Errorf("Written time is %s ahead, want the 20ms link delay", ahead)
+	}
+	h.respond(app.NewResponseAPDU(req.Sequence, app.IIN{}, nil))
+
+	if err := h.wait(done); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSyncTimeLAN(t *testing.T) {
+	h := newTestHarness(t, MasterConfig{})
+	h.master.Enable()
+
+	before := time.Now().Truncate(time.Millisecond)
+	done := h.run(func() error { return h.master.SyncTime(TimeSyncLAN) })
+
+	req := h.expectRequest(app.FuncRecordCurrentTime)
+	recorded := time.Now()
+	time.Sleep(50 * time.Millisecond)
+	h.respond(app.NewResponseAPDU(req.Sequence, app.IIN{}, nil))
+
+	// The time written is the one at RECORD CURRENT TIME, not at the WRITE
+	req = h.expectRequest(app.FuncWrite)
+	variation, written := writtenTime(t, req)
+	if variation != 3 {
+		t.Errorf("WRITE: got G50V%d, want G50V3", variation)
+	}
+	if written.Before(before) || written.After(recorded) {
+		t.Errorf("Written time %s not between %s and %s", written, before, recorded)
+	}
+	h.respond(app.NewResponseAPDU(req.Sequence, app.IIN{}, nil))
+
+	if err := h.wait(done); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSyncTimeWaitsForBothExchanges(t *testing.T) {
+	h := newTestHarness(t, MasterConfig{ResponseTimeout: 200 * time.Millisecond, TaskStartTimeout: time.Second})
+	h.master.Enable()
+
+	// An integrity scan ahead in the queue delays the start of the time sync
+	h.master.ScanIntegrity()
+	req := h.expectRequest(app.FuncRead)
+	done := h.run(func() error { return h.master.SyncTime(TimeSyncLAN) })
+	time.Sleep(150 * time.Millisecond)
+	h.respond(app.NewResponseAPDU(req.Sequence, app.IIN{}, nil))
+
+	// Each exchange is within the response timeout, together they exceed twice it
+	for _, fc := range []app.FunctionCode{app.FuncRecordCurrentTime, app.FuncWrite} {
+		req := h.expectRequest(fc)
+		time.Sleep(150 * time.Millisecond)
+		h.respond(app.NewResponseAPDU(req.Sequence, app.IIN{}, nil))
+	}
+
+	if err := h.wait(done); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSyncTimeRejected(t *testing.T) {
+	h := newTestHarness(t, MasterConfig{})
+	h.master.Enable()
+
+	done := h.run(func() error { return h.master.SyncTime(TimeSyncLAN) })
+	req := h.expectRequest(app.FuncRecordCurrentTime)
+	h.respond(app.NewResponseAPDU(req.Sequence, app.IIN{IIN2: app.IIN2NoFuncCodeSupport}, nil))
+
+	if err := h.wait(done); err == nil {
+		t.Error("SyncTime succeeded although RECORD CURRENT TIME was not supported")
+	}
+	h.expectNoRequest()
+}
+
+// testCommands are a CROB on index 0 and an analog output on index 1
+var testCommands = []types.Command{
+	{Index: 0, Type: types.CommandTypeCROB, Data: types.CROB{OpType: types.ControlCodeLatchOn, Count: 1}},
+	{Index: 1, Type: types.CommandTypeAnalogOutputInt16, Data: types.AnalogOutputInt16{Value: 7}},
+}
+
+// commandEcho answers a command request by echoing the first commands with
+// statuses, each in its own header so its status is the header's last octet
+func (h *testHarness) commandEcho(req *app.APDU, statuses ...types.CommandStatus) {
+	h.t.Helper()
+
+	var objects []byte
+	for i, status := range statuses {
+		object, err := app.BuildCommands(testCommands[i : i+1])
+		if err != nil {
+			h.t.Fatalf("BuildCommands failed: %v", err)
+		}
+		object[len(object)-1] = uint8(status)
+		objects = append(objects, object...)
+	}
+	h.respond(app.NewResponseAPDU(req.Sequence, app.IIN{}, objects))
+}
+
+func TestCommandEcho(t *testing.T) {
+	success, formatError := types.CommandStatusSuccess, types.CommandStatusFormatError
+	tests := []struct {
+		name string
+		echo []types.CommandStatus
+		want []types.CommandStatus
+	}{
+		{"full", []types.CommandStatus{success, types.CommandStatusHardwareError}, []types.CommandStatus{success, types.CommandStatusHardwareError}},
+		{"short", []types.CommandStatus{success}, []types.CommandStatus{success, formatError}},
+		{"empty", nil, []types.CommandStatus{formatError, formatError}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHarness(t, MasterConfig{})
+
+			var statuses []types.CommandStatus
+			done := h.run(func() (err error) {
+				statuses, err = h.master.performDirectOperate(testCommands)
+				return err
+			})
+			h.commandEcho(h.expectRequest(app.FuncDirectOperate), tt.echo...)
+
+			if err := h.wait(done); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(statuses, tt.want) {
+				t.Errorf("Statuses: got %v, want %v", statuses, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectAndOperate(t *testing.T) {
+	h := newTestHarness(t, MasterConfig{})
+
+	var statuses []types.CommandStatus
+	done := h.run(func() (err error) {
+		statuses, err = h.master.performSelectAndOperate(testCommands)
+		return err
+	})
+	h.commandEcho(h.expectRequest(app.FuncSelect), types.CommandStatusSuccess, types.CommandStatusSuccess)
+	h.commandEcho(h.expectRequest(app.FuncOperate), types.CommandStatusSuccess, types.CommandStatusSuccess)
+
+	if err := h.wait(done); err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 2 || !allSuccess(statuses) {
+		t.Errorf("Statuses: got %v, want two successes", statuses)
+	}
+}
+
+func TestSelectAndOperateWaitsForBothExchanges(t *testing.T) {
+	h := newTestHarness(t, MasterConfig{ResponseTimeout: 200 * time.Millisecond, TaskStartTimeout: time.Second})
+	h.master.Enable()
+
+	h.master.ScanIntegrity()
+	req := h.expectRequest(app.FuncRead)
+	done := h.run(func() error {
+		_, err := h.master.SelectAndOperate(testCommands)
+		return err
+	})
+	time.Sleep(150 * time.Millisecond)
+	h.respond(app.NewResponseAPDU(req.Sequence, app.IIN{}, nil))
+
+	// Each exchange is within the response timeout, together they exceed twice it
+	for _, fc := range []app.FunctionCode{app.FuncSelect, app.FuncOperate} {
+		req := h.expectRequest(fc)
+		time.Sleep(150 * time.Millisecond)
+		h.commandEcho(req, types.CommandStatusSuccess, types.CommandStatusSuccess)
+	}
+
+	if err := h.wait(done); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueuedOperationWaitsForStart(t *testing.T) {
+	tests := []struct {
+		name    string
+		fc      app.FunctionCode
+		run     func(m *master) error
+		respond func(h *testHarness, req *app.APDU)
+	}{
+		{"direct operate", app.FuncDirectOperate,
+			func(m *master) error { _, err := m.DirectOperate(testCommands); return err },
+			func(h *testHarness, req *app.APDU) {
+				h.commandEcho(req, types.CommandStatusSuccess, types.CommandStatusSuccess)
+			}},
+		{"assign class", app.FuncAssignClass,
+			func(m *master) error { return m.AssignClass(1, app.GroupBinaryInput, 0, 3) }, nil},
+		{"deadbands", app.FuncWrite,
+			func(m *master) error {
+				return m.WriteDeadbands(1, []types.IndexedDeadband{{Index: 0, Value: 5}})
+			}, nil},
+		{"octet strings", app.FuncWrite,
+			func(m *master) error {
+				return m.WriteOctetStrings([]types.IndexedOctetString{{Index: 0, Value: types.OctetString{Value: []byte("a")}}})
+			}, nil},
+		{"device attributes", app.FuncRead,
+			func(m *master) error { _, err := m.ReadDeviceAttributes(); return err }, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHarness(t, MasterConfig{ResponseTimeout: 200 * time.Millisecond, TaskStartTimeout: time.Second})
+			h.master.Enable()
+
+			// The scan ahead in the queue and the operation's own exchange
+			// together take longer than the response timeout
+			h.master.ScanIntegrity()
+			req := h.expectRequest(app.FuncRead)
+			done := h.run(func() error { return tt.run(h.master) })
+			time.Sleep(150 * time.Millisecond)
+			h.respond(app.NewResponseAPDU(req.Sequence, app.IIN{}, nil))
+
+			req = h.expectRequest(tt.fc)
+			time.Sleep(150 * time.Millisecond)
+			if tt.respond != nil {
+				tt.respond(h, req)
+			} else {
+				h.respond(app.NewResponseAPDU(req.Sequence, app.IIN{}, nil))
+			}
+
+			if err := h.wait(done); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestSelectShortEcho(t *testing.T) {
+	h := newTestHarness(t, MasterConfig{})
+
+	done := h.run(func() error {
+		_, err := h.master.performSelectAndOperate(testCommands)
+		return err
+	})
+	h.commandEcho(h.expectRequest(app.FuncSelect), types.CommandStatusSuccess)
+
+	// A command missing from the SELECT echo was not selected
+	if err := h.wait(done); err != nil {
+		t.Fatal(err)
+	}
+	h.expectNoRequest()
+}
+
+func TestSelectRejected(t *testing.T) {
+	// The outstation answers a SELECT it cannot parse or does not support
+	// with IIN2 set and no objects
+	for _, iin2 := range []uint8{app.IIN2NoFuncCodeSupport, app.IIN2ObjectUnknown, app.IIN2ParameterError} {
+		h := newTestHarness(t, MasterConfig{})
+
+		done := h.run(func() error {
+			_, err := h.master.performSelectAndOperate(testCommands)
+			return err
+		})
+		req := h.expectRequest(app.FuncSelect)
+		h.respond(app.NewResponseAPDU(req.Sequence, app.IIN{IIN2: iin2}, nil))
+
+		if err := h.wait(done); !errors.Is(err, ErrRejected) {
+			t.Errorf("IIN2=0x%02X: got %v, want ErrRejected", iin2, err)
+		}
+		h.expectNoRequest()
+	}
+}
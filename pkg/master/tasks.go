@@ -40,6 +40,30 @@ func (t *IntegrityScanTask) Type() TaskType {
 	return TaskTypeIntegrityScan
 }
 
+// StartupTask runs the startup sequence, retried every TaskRetryPeriod
+// until it completes
+type StartupTask struct {
+	priority int
+}
+
+func (t *StartupTask) Execute(m *master) error {
+	m.logger.Info("Master %s: Executing startup sequence", m.config.ID)
+
+	err := m.performStartup()
+	if err != nil && m.config.TaskRetryPeriod > 0 {
+		m.taskQueue.Push(t, t.Priority(), time.Now().Add(m.config.TaskRetryPeriod))
+	}
+	return err
+}
+
+func (t *StartupTask) Priority() int {
+	return t.priority
+}
+
+func (t *StartupTask) Type() TaskType {
+	return TaskTypeStartup
+}
+
 // ClassScanTask performs a class scan
 type ClassScanTask struct {
 	id       int
@@ -89,6 +113,7 @@ type CommandTask struct {
 	commands     []types.Command
 	selectBefore bool
 	priority     int
+	started      chan struct{}
 	result       chan CommandResult
 }
 
@@ -98,6 +123,7 @@ type CommandResult struct {
 }
 
 func (t *CommandTask) Execute(m *master) error {
+	close(t.started)
 	m.logger.Info("Master %s: Executing command task (%d commands)", m.config.ID, len(t.commands))
 
 	var statuses []types.CommandStatus
@@ -126,6 +152,264 @@ func (t *CommandTask) Type() TaskType {
 	return TaskTypeCommand
 }
 
+// TimeSyncTask synchronizes the outstation clock
+type TimeSyncTask struct {
+	mode     TimeSyncMode
+	priority int
+	started  chan struct{}
+	result   chan error
+}
+
+func (t *TimeSyncTask) Execute(m *master) error {
+	close(t.started)
+	m.logger.Info("Master %s: Executing time synchronization (%s)", m.config.ID, t.mode)
+
+	err := m.performTimeSync(t.mode)
+
+	// Send result
+	select {
+	case t.result <- err:
+	default:
+	}
+
+	return err
+}
+
+func (t *TimeSyncTask) Priority() int {
+	return t.priority
+}
+
+func (t *TimeSyncTask) Type() TaskType {
+	return TaskTypeTimeSync
+}
+
+// AssignClassTask assigns a range of points to an event class
+type AssignClassTask struct {
+	class       uint8
+	group       uint8
+	start, stop uint16
+	priority    int
+	started     chan struct{}
+	result      chan error
+}
+
+func (t *AssignClassTask) Execute(m *master) error {
+	close(t.started)
+	m.logger.Info("Master %s: Executing assign class (G%d %d-%d to class %d)", m.config.ID, t.group, t.start, t.stop, t.class)
+
+	err := m.performAssignClass(t.class, t.group, t.start, t.stop)
+
+	// Send result
+	select {
+	case t.result <- err:
+	default:
+	}
+
+	return err
+}
+
+func (t *AssignClassTask) Priority() int {
+	return t.priority
+}
+
+func (t *AssignClassTask) Type() TaskType {
+	return TaskTypeAssignClass
+}
+
+// DeadbandTask reads or writes analog input deadbands
+type DeadbandTask struct {
+	write       bool
+	start, stop uint16                  // Points to read
+	variation   uint8                   // Variation to write
+	deadbands   []types.IndexedDeadband // Deadbands to write
+	priority    int
+	started     chan struct{}
+	result      chan DeadbandResult
+}
+
+type DeadbandResult struct {
+	Deadbands []types.IndexedDeadband
+	Error     error
+}
+
+func (t *DeadbandTask) Execute(m *master) error {
+	close(t.started)
+
+	var deadbands []types.IndexedDeadband
+	var err error
+
+	if t.write {
+		m.logger.Info("Master %s: Executing deadband write (%d points)", m.config.ID, len(t.deadbands))
+		err = m.performWriteDeadbands(t.variation, t.deadbands)
+	} else {
+		m.logger.Info("Master %s: Executing deadband read (%d-%d)", m.config.ID, t.start, t.stop)
+		deadbands, err = m.performReadDeadbands(t.start, t.stop)
+	}
+
+	// Send result
+	select {
+	case t.result <- DeadbandResult{Deadbands: deadbands, Error: err}:
+	default:
+	}
+
+	return err
+}
+
+func (t *DeadbandTask) Priority() int {
+	return t.priority
+}
+
+func (t *DeadbandTask) Type() TaskType {
+	return TaskTypeDeadband
+}
+
+// OctetStringTask reads or writes octet strings
+type OctetStringTask struct {
+	write       bool
+	start, stop uint16                     // Points to read
+	values      []types.IndexedOctetString // Strings to write
+	priority    int
+	started     chan struct{}
+	result      chan OctetStringResult
+}
+
+type OctetStringResult struct {
+	Values []types.IndexedOctetString
+	Error  error
+}
+
+func (t *OctetStringTask) Execute(m *master) error {
+	close(t.started)
+
+	var values []types.IndexedOctetString
+	var err error
+
+	if t.write {
+		m.logger.Info("Master %s: Executing octet string write (%d points)", m.config.ID, len(t.values))
+		err = m.performWriteOctetStrings(t.values)
+	} else {
+		m.logger.Info("Master %s: Executing octet string read (%d-%d)", m.config.ID, t.start, t.stop)
+		values, err = m.performReadOctetStrings(t.start, t.stop)
+	}
+
+	// Send result
+	select {
+	case t.result <- OctetStringResult{Values: values, Error: err}:
+	default:
+	}
+
+	return err
+}
+
+func (t *OctetStringTask) Priority() int {
+	return t.priority
+}
+
+func (t *OctetStringTask) Type() TaskType {
+	return TaskTypeOctetString
+}
+
+// DeviceAttributesTask reads every device attribute
+type DeviceAttributesTask struct {
+	priority int
+	started  chan struct{}
+	result   chan DeviceAttributesResult
+}
+
+type DeviceAttributesResult struct {
+	Attributes []types.DeviceAttribute
+	Error      error
+}
+
+func (t *DeviceAttributesTask) Execute(m *master) error {
+	close(t.started)
+	m.logger.Info("Master %s: Executing device attributes read", m.config.ID)
+	attributes, err := m.performReadDeviceAttributes()
+
+	// Send result
+	select {
+	case t.result <- DeviceAttributesResult{Attributes: attributes, Error: err}:
+	default:
+	}
+
+	return err
+}
+
+func (t *DeviceAttributesTask) Priority() int {
+	return t.priority
+}
+
+func (t *DeviceAttributesTask) Type() TaskType {
+	return TaskTypeDeviceAttributes
+}
+
+// fileOperation selects the work of a file task
+type fileOperation int
+
+const (
+	fileOpRead fileOperation = iota
+	fileOpWrite
+	fileOpList
+	fileOpDelete
+	fileOpInfo
+)
+
+// FileTask carries out a file operation, which takes several requests
+type FileTask struct {
+	operation fileOperation
+	name      string
+	data      []byte
+	priority  int
+	started   chan struct{}
+	result    chan FileResult
+}
+
+type FileResult struct {
+	Data  []byte
+	Files []types.FileInfo
+	Info  types.FileInfo
+	Error error
+}
+
+func (t *FileTask) Execute(m *master) error {
+	close(t.started)
+
+	var result FileResult
+	switch t.operation {
+	case fileOpRead:
+		m.logger.Info("Master %s: Executing file read of %q", m.config.ID, t.name)
+		result.Data, result.Error = m.performReadFile(t.name)
+	case fileOpWrite:
+		m.logger.Info("Master %s: Executing file write of %q (%d bytes)", m.config.ID, t.name, len(t.data))
+		result.Error = m.performWriteFile(t.name, t.data)
+	case fileOpList:
+		m.logger.Info("Master %s: Executing directory read of %q", m.config.ID, t.name)
+		result.Files, result.Error = m.performListDirectory(t.name)
+	case fileOpDelete:
+		m.logger.Info("Master %s: Executing file delete of %q", m.config.ID, t.name)
+		result.Error = m.performDeleteFile(t.name)
+	case fileOpInfo:
+		m.logger.Info("Master %s: Executing file info of %q", m.config.ID, t.name)
+		result.Info, result.Error = m.performGetFileInfo(t.name)
+	}
+
+	// Send result
+	select {
+	case t.result <- result:
+	default:
+	}
+
+	return result.Error
+}
+
+func (t *FileTask) Priority() int {
+	return t.priority
+}
+
+func (t *FileTask) Type() TaskType {
+	return TaskTypeFile
+}
+
 // PeriodicScan represents a periodic scan task
 type PeriodicScan struct {
 	id       int
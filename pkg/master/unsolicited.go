@@ -0,0 +1,91 @@
+package master
+
+import (
+	"bytes"
+	"errors"
+
+	"avaneesh/dnp3-go/pkg/app"
+)
+
+// handleUnsolicited confirms and processes an unsolicited response. A
+// repeat of the last fragment, resent because its CONFIRM was lost, is
+// confirmed again but not processed twice.
+func (m *master) handleUnsolicited(apdu *app.APDU, data []byte) {
+	duplicate := m.lastUnsol != nil && bytes.Equal(data, m.lastUnsol)
+	m.lastUnsol = append(m.lastUnsol[:0], data...)
+
+	if apdu.CON {
+		m.sendConfirm(apdu.Sequence, true)
+	}
+
+	if duplicate {
+		m.logger.Debug("Master %s: Ignoring repeated unsolicited response seq=%d", m.config.ID, apdu.Sequence)
+		return
+	}
+
+	if len(apdu.Objects) > 0 {
+		m.processMeasurements(apdu)
+	}
+}
+
+// sendConfirm confirms a response fragment, echoing its sequence number
+func (m *master) sendConfirm(seq uint8, unsolicited bool) {
+	confirm := app.BuildConfirmRequest(seq)
+	confirm.UNS = unsolicited
+	if err := m.session.sendAPDU(confirm.Serialize()); err != nil {
+		m.logger.Warn("Master %s: Failed to send CONFIRM: %v", m.config.ID, err)
+	}
+}
+
+// performStartup runs the startup sequence: unsolicited responses are
+// disabled while the integrity poll establishes current values, then
+// enabled for the configured classes so only later changes are reported.
+// The integrity poll runs even when disabling fails.
+func (m *master) performStartup() error {
+	var failed error
+	if m.config.DisableUnsolOnStartup {
+		failed = m.unsolicitedStartupError("disable", m.performDisableUnsolicited(app.ClassAll))
+	}
+
+	if m.config.StartupIntegrityScan {
+		if err := m.performIntegrityScan(); err != nil {
+			return errors.Join(failed, err)
+		}
+	}
+
+	if classes := m.config.UnsolClassMask & app.ClassAll; classes != 0 {
+		err := m.performEnableUnsolicited(classes)
+		if err == nil {
+			m.logger.Info("Master %s: Enabled unsolicited responses for %s", m.config.ID, classes)
+		}
+		failed = errors.Join(failed, m.unsolicitedStartupError("enable", err))
+	}
+	return failed
+}
+
+// unsolicitedStartupError logs a failed ENABLE or DISABLE UNSOLICITED of the
+// startup sequence and returns the error. An outstation without unsolicited
+// responses supports neither, which is not worth retrying the startup for.
+func (m *master) unsolicitedStartupError(operation string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, ErrNotSupported) {
+		m.logger.Info("Master %s: Outstation does not support unsolicited responses", m.config.ID)
+		return nil
+	}
+	m.logger.Warn("Master %s: Failed to %s unsolicited responses: %v", m.config.ID, operation, err)
+	return err
+}
+
+// eventClasses splits a class mask into the event classes it holds, the
+// form enable and disable unsolicited requests take
+func eventClasses(mask app.ClassField) []app.ClassField {
+	var classes []app.ClassField
+	for _, class := range []app.ClassField{app.Class1, app.Class2, app.Class3} {
+		if mask.HasClass(class) {
+			classes = append(classes, class)
+		}
+	}
+	return classes
+}
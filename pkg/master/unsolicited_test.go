@@ -0,0 +1,126 @@
+package master
+
+import (
+	"testing"
+	"time"
+
+	"avaneesh/dnp3-go/pkg/app"
+)
+
+// startupConfig runs the full startup sequence and retries it quickly
+func startupConfig() MasterConfig {
+	return MasterConfig{
+		DisableUnsolOnStartup: true,
+		StartupIntegrityScan:  true,
+		UnsolClassMask:        app.Class1 | app.Class2,
+		TaskRetryPeriod:       100 * time.Millisecond,
+	}
+}
+
+// expectStartupRequest answers the next startup request with an IIN2
+func (h *testHarness) expectStartupRequest(fc app.FunctionCode, iin2 uint8) {
+	h.t.Helper()
+
+	req := h.expectRequest(fc)
+	h.respond(app.NewResponseAPDU(req.Sequence, app.IIN{IIN2: iin2}, nil))
+}
+
+func TestStartupSequence(t *testing.T) {
+	h := newTestHarness(t, startupConfig())
+	h.master.Enable()
+
+	h.expectStartupRequest(app.FuncDisableUnsolicited, 0)
+	h.expectStartupRequest(app.FuncRead, 0)
+	req := h.expectRequest(app.FuncEnableUnsolicited)
+
+	// Only the configured classes are enabled
+	parser := app.NewParser(req.Objects)
+	var classes []uint8
+	for parser.HasMore() {
+		header, err := parser.ReadObjectHeader()
+		if err != nil {
+			t.Fatalf("ReadObjectHeader failed: %v", err)
+		}
+		classes = append(classes, header.Variation)
+	}
+	if len(classes) != 2 || classes[0] != 2 || classes[1] != 3 {
+		t.Errorf("ENABLE UNSOLICITED: got G60 variations %v, want [2 3]", classes)
+	}
+	h.respond(app.NewResponseAPDU(req.Sequence, app.IIN{}, nil))
+
+	// The completed startup is not retried
+	time.Sleep(150 * time.Millisecond)
+	h.expectNoRequest()
+}
+
+func TestStartupWithoutUnsolicitedSupport(t *testing.T) {
+	h := newTestHarness(t, startupConfig())
+	h.master.Enable()
+
+	h.expectStartupRequest(app.FuncDisableUnsolicited, app.IIN2NoFuncCodeSupport)
+	h.expectStartupRequest(app.FuncRead, 0)
+	h.expectStartupRequest(app.FuncEnableUnsolicited, app.IIN2NoFuncCodeSupport)
+
+	time.Sleep(150 * time.Millisecond)
+	h.expectNoRequest()
+}
+
+func TestStartupRetriedAfterDisableFails(t *testing.T) {
+	h := newTestHarness(t, startupConfig())
+	h.master.Enable()
+
+	// The integrity poll still runs, and the whole sequence is retried
+	h.expectStartupRequest(app.FuncDisableUnsolicited, app.IIN2ParameterError)
+	h.expectStartupRequest(app.FuncRead, 0)
+	h.expectStartupRequest(app.FuncEnableUnsolicited, 0)
+	h.expectStartupRequest(app.FuncDisableUnsolicited, 0)
+	h.expectStartupRequest(app.FuncRead, 0)
+	h.expectStartupRequest(app.FuncEnableUnsolicited, 0)
+
+	time.Sleep(150 * time.Millisecond)
+	h.expectNoRequest()
+}
+
+func TestUnsolicitedConfirmed(t *testing.T) {
+	h := newTestHarness(t, MasterConfig{})
+
+	// An unsolicited response asking for confirmation is confirmed with its
+	// sequence number and the UNS bit
+	h.respond(app.NewUnsolicitedResponseAPDU(5, app.IIN{}, nil))
+	confirm := h.expectRequest(app.FuncConfirm)
+	if confirm.Sequence != 5 || !confirm.UNS {
+		t.Errorf("CONFIRM: got seq=%d UNS=%t, want seq=5 UNS=true", confirm.Sequence, confirm.UNS)
+	}
+
+	unconfirmed := app.NewUnsolicitedResponseAPDU(6, app.IIN{}, nil)
+	unconfirmed.CON = false
+	h.respond(unconfirmed)
+	h.expectNoRequest()
+}
+
+func TestUnsolicitedDuplicate(t *testing.T) {
+	h := newTestHarness(t, MasterConfig{})
+
+	builder := app.NewObjectBuilder()
+	builder.AddHeader(app.GroupAnalogInput, app.AnalogInput16BitNoFlag, app.Qualifier8BitStartStop, app.StartStopRange{Start: 0, Stop: 0})
+	builder.AddInt16(42)
+	objects := builder.Build()
+
+	// A repeat with the same UNS sequence is confirmed again but processed once
+	for range 2 {
+		h.respond(app.NewUnsolicitedResponseAPDU(3, app.IIN{}, objects))
+		if confirm := h.expectRequest(app.FuncConfirm); confirm.Sequence != 3 {
+			t.Errorf("CONFIRM: got seq=%d, want 3", confirm.Sequence)
+		}
+	}
+	if n := len(h.callbacks.analogs); n != 1 {
+		t.Fatalf("Repeated unsolicited response processed %d times, want once", n)
+	}
+
+	// The same values under the next sequence number are a new response
+	h.respond(app.NewUnsolicitedResponseAPDU(4, app.IIN{}, objects))
+	h.expectRequest(app.FuncConfirm)
+	if n := len(h.callbacks.analogs); n != 2 {
+		t.Errorf("Next unsolicited response: got %d analogs, want 2", n)
+	}
+}
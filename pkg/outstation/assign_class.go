@@ -0,0 +1,95 @@
+package outstation
+
+import (
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// classAssignment moves the selected points of a static group to an event class
+type classAssignment struct {
+	st      *staticType
+	indices []int
+	class   uint8
+}
+
+// handleAssignClass handles ASSIGN CLASS requests. Each G60 header names a
+// class (V1 for none, V2-V4 for classes 1-3) for the point headers after it.
+// Events already buffered keep the class they were generated with.
+func (o *outstation) handleAssignClass(apdu *app.APDU) error {
+	o.logger.Debug("Outstation %s: Handling ASSIGN CLASS request", o.config.ID)
+
+	assignments, iin2 := o.parseClassAssignments(apdu.Objects)
+
+	o.database.mu.Lock()
+	for _, a := range assignments {
+		for _, index := range a.indices {
+			a.st.setClass(o.database, index, a.class)
+		}
+	}
+	o.database.mu.Unlock()
+
+	for _, a := range assignments {
+		o.logger.Info("Outstation %s: Assigned %d points of group %d to class %d",
+			o.config.ID, len(a.indices), a.st.group, a.class)
+	}
+
+	iin := o.responseIIN()
+	iin.IIN2 |= iin2
+	response := app.NewResponseAPDU(apdu.Sequence, iin, nil)
+	return o.session.sendAPDU(response.Serialize())
+}
+
+// parseClassAssignments returns the assignments of an ASSIGN CLASS request up
+// to the first invalid header, and the IIN2 bits for any error
+func (o *outstation) parseClassAssignments(objects []byte) ([]classAssignment, uint8) {
+	var assignments []classAssignment
+	var iin2 uint8
+	haveClass := false
+	var class uint8
+
+	parser := app.NewParser(objects)
+	for parser.HasMore() {
+		header, err := parser.ReadObjectHeader()
+		if err != nil {
+			o.logger.Warn("Outstation %s: Failed to parse ASSIGN CLASS header: %v", o.config.ID, err)
+			return assignments, iin2 | types.IIN2ParameterError
+		}
+
+		if header.Group == app.GroupClass0Data {
+			// V1-V4 name classes 0-3; V0 names none
+			if header.Variation > 4 {
+				return assignments, iin2 | types.IIN2ObjectUnknown
+			}
+			if header.Variation == 0 {
+				return assignments, iin2 | types.IIN2ParameterError
+			}
+			class = header.Variation - 1
+			haveClass = true
+			continue
+		}
+
+		st := findStaticType(header.Group)
+		if st == nil {
+			o.logger.Debug("Outstation %s: Unsupported ASSIGN CLASS object G%dV%d", o.config.ID, header.Group, header.Variation)
+			return assignments, iin2 | types.IIN2ObjectUnknown
+		}
+
+		// Points must follow the class they are assigned to
+		if !haveClass {
+			return assignments, iin2 | types.IIN2ParameterError
+		}
+
+		o.database.mu.RLock()
+		count := st.count(o.database)
+		o.database.mu.RUnlock()
+
+		sel, selIIN, err := selectPoints(header, parser, count)
+		if err != nil {
+			return assignments, iin2 | types.IIN2ParameterError
+		}
+		iin2 |= selIIN
+		assignments = append(assignments, classAssignment{st: st, indices: sel.indices, class: class})
+	}
+
+	return assignments, iin2
+}
@@ -0,0 +1,84 @@
+package outstation
+
+import (
+	"testing"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+func TestAssignClassMovesPoints(t *testing.T) {
+	h := newTestHarness(t, eventTestConfig())
+	db := h.outstation.database
+
+	// Binary 0 to class 3, the counter out of event reporting
+	objects := append(app.BuildAssignClass(3, app.GroupBinaryInput, 0, 0), app.BuildAssignClass(0, app.GroupCounter, 0, 0)...)
+	resp := h.request(app.BuildAssignClassRequest(0, objects))
+	if resp.IIN.IIN2 != 0 {
+		t.Fatalf("IIN2: got 0x%02X, want 0", resp.IIN.IIN2)
+	}
+	if db.binary[0].class != 3 || db.binary[1].class != 2 || db.counter[0].class != 0 {
+		t.Fatalf("Classes: got binary %d/%d counter %d, want 3/2 and 0",
+			db.binary[0].class, db.binary[1].class, db.counter[0].class)
+	}
+
+	db.UpdateBinary(0, types.Binary{Value: true, Flags: types.FlagOnline}, EventModeDetect)
+	db.UpdateCounter(0, types.Counter{Value: 5, Flags: types.FlagOnline}, EventModeDetect)
+
+	eb := h.outstation.eventBuffer
+	if c1, c3 := eb.GetClass1Count(), eb.GetClass3Count(); c1 != 0 || c3 != 1 {
+		t.Errorf("Events: got class1=%d class3=%d, want only the binary in class 3", c1, c3)
+	}
+}
+
+func TestAssignClassSeveralHeaders(t *testing.T) {
+	h := newTestHarness(t, allTypesConfig())
+	db := h.outstation.database
+
+	// One class header followed by two point headers
+	b := app.NewObjectBuilder()
+	b.AddHeader(app.GroupClass0Data, 3, app.QualifierNoRange, app.NoRange{})
+	b.AddHeader(app.GroupAnalogInput, 0, app.QualifierNoRange, app.NoRange{})
+	b.AddHeader(app.GroupFrozenCounter, 0, app.QualifierNoRange, app.NoRange{})
+
+	resp := h.request(app.BuildAssignClassRequest(0, b.Build()))
+	if resp.IIN.IIN2 != 0 {
+		t.Fatalf("IIN2: got 0x%02X, want 0", resp.IIN.IIN2)
+	}
+	for i := range db.analog {
+		if db.analog[i].class != 2 {
+			t.Errorf("Analog %d class: got %d, want 2", i, db.analog[i].class)
+		}
+	}
+	if db.frozenCounter[0].class != 2 {
+		t.Errorf("Frozen counter class: got %d, want 2", db.frozenCounter[0].class)
+	}
+}
+
+func TestAssignClassInvalid(t *testing.T) {
+	pointsOnly := app.BuildAllObjects(app.GroupBinaryInput, 0)
+	classHeader := func(variation uint8) []byte { return app.BuildAllObjects(app.GroupClass0Data, variation) }
+
+	tests := []struct {
+		name     string
+		objects  []byte
+		wantIIN2 uint8
+	}{
+		{"points without class", pointsOnly, types.IIN2ParameterError},
+		{"bad class variation", append(classHeader(5), pointsOnly...), types.IIN2ObjectUnknown},
+		{"class variation 0", append(classHeader(0), pointsOnly...), types.IIN2ParameterError},
+		{"unknown group", append(classHeader(2), app.BuildAllObjects(app.GroupFrozenAnalogInput, 0)...), types.IIN2ObjectUnknown},
+		{"index out of range", app.BuildAssignClass(2, app.GroupBinaryInput, 1, 9), types.IIN2ParameterError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHarness(t, eventTestConfig())
+
+			resp := h.request(app.BuildAssignClassRequest(0, tt.objects))
+			if resp.IIN.IIN2 != tt.wantIIN2 {
+				t.Errorf("IIN2: got 0x%02X, want 0x%02X", resp.IIN.IIN2, tt.wantIIN2)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package outstation
+
+import (
+	"fmt"
+	"sort"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// defaultMaxRxFragSize is reported when MaxRxFragSize is not configured
+const defaultMaxRxFragSize = 2048
+
+// standardAttributes returns the configured strings of attribute set 0
+func (a DeviceAttributes) standardAttributes() []types.DeviceAttribute {
+	strings := []struct {
+		variation uint8
+		value     string
+	}{
+		{app.DeviceAttributeManufacturerName, a.ManufacturerName},
+		{app.DeviceAttributeProductNameAndModel, a.ProductNameAndModel},
+		{app.DeviceAttributeSoftwareVersion, a.SoftwareVersion},
+		{app.DeviceAttributeHardwareVersion, a.HardwareVersion},
+		{app.DeviceAttributeSerialNumber, a.SerialNumber},
+		{app.DeviceAttributeSubsetAndConformance, a.SubsetAndConformance},
+		{app.DeviceAttributeDeviceName, a.DeviceName},
+		{app.DeviceAttributeIDCode, a.IDCode},
+		{app.DeviceAttributeLocationName, a.LocationName},
+		{app.DeviceAttributeOwnerName, a.OwnerName},
+	}
+
+	var attributes []types.DeviceAttribute
+	for _, s := range strings {
+		if s.value != "" {
+			attributes = append(attributes, types.StringAttribute(0, s.variation, s.value))
+		}
+	}
+	return attributes
+}
+
+// pointAttributes returns the point counts and highest indices of set 0.
+// The highest index is left out for types without points.
+func (c DatabaseConfig) pointAttributes() []types.DeviceAttribute {
+	counts := []struct {
+		count, maxIndex uint8
+		points          int
+	}{
+		{app.DeviceAttributeNumBinaryInputs, app.DeviceAttributeMaxBinaryInputIndex, len(c.Binary)},
+		{app.DeviceAttributeNumDoubleBitInputs, app.DeviceAttributeMaxDoubleBitIndex, len(c.DoubleBit)},
+		{app.DeviceAttributeNumAnalogInputs, app.DeviceAttributeMaxAnalogInputIndex, len(c.Analog)},
+		{app.DeviceAttributeNumCounters, app.DeviceAttributeMaxCounterIndex, len(c.Counter)},
+		{app.DeviceAttributeNumBinaryOutputs, app.DeviceAttributeMaxBinaryOutputIndex, len(c.BinaryOutput)},
+		{app.DeviceAttributeNumAnalogOutputs, app.DeviceAttributeMaxAnalogOutputIndex, len(c.AnalogOutput)},
+	}
+
+	var attributes []types.DeviceAttribute
+	for _, n := range counts {
+		attributes = append(attributes, types.UintAttribute(0, n.count, uint32(n.points)))
+		if n.points > 0 {
+			attributes = append(attributes, types.UintAttribute(0, n.maxIndex, uint32(n.points-1)))
+		}
+	}
+	return attributes
+}
+
+// deviceAttributes returns every attribute the outstation reports, ordered
+// by set and variation
+func (c OutstationConfig) deviceAttributes() []types.DeviceAttribute {
+	txSize, rxSize := uint32(c.MaxTxFragSize), uint32(c.MaxRxFragSize)
+	if txSize == 0 {
+		txSize = defaultMaxTxFragSize
+	}
+	if rxSize == 0 {
+		rxSize = defaultMaxRxFragSize
+	}
+
+	attributes := c.DeviceAttributes.standardAttributes()
+	attributes = append(attributes, c.Database.pointAttributes()...)
+	attributes = append(attributes,
+		types.UintAttribute(0, app.DeviceAttributeMaxTxFragSize, txSize),
+		types.UintAttribute(0, app.DeviceAttributeMaxRxFragSize, rxSize))
+	attributes = append(attributes, c.DeviceAttributes.UserDefined...)
+
+	sort.SliceStable(attributes, func(i, j int) bool {
+		if attributes[i].Set != attributes[j].Set {
+			return attributes[i].Set < attributes[j].Set
+		}
+		return attributes[i].Variation < attributes[j].Variation
+	})
+	return attributes
+}
+
+// checkDeviceAttributes checks that the configured attributes can be
+// encoded and that user-defined ones do not hide another attribute
+func checkDeviceAttributes(config OutstationConfig) error {
+	seen := make(map[[2]int]bool)
+	for _, a := range config.deviceAttributes() {
+		if a.Variation == 0 || a.Variation >= app.DeviceAttributeAll {
+			return fmt.Errorf("device attribute set %d: invalid variation %d", a.Set, a.Variation)
+		}
+		key := [2]int{int(a.Set), int(a.Variation)}
+		if seen[key] {
+			return fmt.Errorf("device attribute set %d variation %d is defined twice", a.Set, a.Variation)
+		}
+		seen[key] = true
+		if _, err := app.SerializeDeviceAttribute(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selectDeviceAttributes returns the attributes answering a READ of G0:
+// variation 254 for every attribute of the sets, 255 for the list of their
+// variations, or a single attribute. The sets are all of them (qualifier
+// 0x06) or a start-stop range. Returns IIN2 bits for any error.
+func (o *outstation) selectDeviceAttributes(header *app.ObjectHeader) ([]types.DeviceAttribute, uint8) {
+	var inSet func(set uint16) bool
+	switch r := header.Range.(type) {
+	case app.NoRange:
+		inSet = func(uint16) bool { return true }
+	case app.StartStopRange:
+		inSet = func(set uint16) bool { return uint32(set) >= r.Start && uint32(set) <= r.Stop }
+	default:
+		return nil, types.IIN2ParameterError
+	}
+
+	var selected []types.DeviceAttribute
+	for _, a := range o.config.deviceAttributes() {
+		if !inSet(a.Set) {
+			continue
+		}
+		switch header.Variation {
+		case app.DeviceAttributeAll:
+			selected = append(selected, a)
+		case app.DeviceAttributeList:
+			n := len(selected)
+			if n == 0 || selected[n-1].Set != a.Set {
+				selected = append(selected, types.DeviceAttribute{
+					Set: a.Set, Variation: app.DeviceAttributeList, Type: types.AttributeList, Value: []types.AttributeListEntry{},
+				})
+				n++
+			}
+			list := selected[n-1].Value.([]types.AttributeListEntry)
+			selected[n-1].Value = append(list, types.AttributeListEntry{Variation: a.Variation})
+		default:
+			if a.Variation == header.Variation {
+				selected = append(selected, a)
+			}
+		}
+	}
+
+	if len(selected) == 0 {
+		return nil, types.IIN2ObjectUnknown
+	}
+	return selected, 0
+}
+
+// writeDeviceAttributes writes the attributes, one header each
+func (o *outstation) writeDeviceAttributes(w *fragmentWriter, attributes []types.DeviceAttribute) {
+	for _, a := range attributes {
+		data, err := app.BuildDeviceAttributes([]types.DeviceAttribute{a})
+		if err != nil {
+			o.logger.Warn("Outstation %s: Failed to encode device attribute: %v", o.config.ID, err)
+			continue
+		}
+		w.fit(1, func(int) int { return len(data) })
+		w.write(data)
+	}
+}
@@ -0,0 +1,142 @@
+package outstation
+
+import (
+	"reflect"
+	"testing"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// attributesConfig has three binaries, two analogs, a name and version, and
+// a user-defined attribute set
+func attributesConfig() OutstationConfig {
+	config := allTypesConfig()
+	config.Database.Binary = make([]BinaryPointConfig, 3)
+	config.Database.Analog = make([]AnalogPointConfig, 2)
+	config.MaxTxFragSize = 1024
+	config.DeviceAttributes = DeviceAttributes{
+		ManufacturerName: "ACME",
+		SoftwareVersion:  "1.2.3",
+		UserDefined:      []types.DeviceAttribute{types.FloatAttribute(1, 10, 2.5)},
+	}
+	return config
+}
+
+// parseAttributes returns the device attributes of a response
+func parseAttributes(t *testing.T, objects []byte) []types.DeviceAttribute {
+	t.Helper()
+
+	var attributes []types.DeviceAttribute
+	parser := app.NewParser(objects)
+	for parser.HasMore() {
+		header, err := parser.ReadObjectHeader()
+		if err != nil {
+			t.Fatalf("Failed to parse header: %v", err)
+		}
+		r, ok := header.Range.(app.StartStopRange)
+		if header.Group != app.GroupDeviceAttributes || !ok || r.Start != r.Stop {
+			t.Fatalf("Expected a G0 header for one attribute set, got %+v", header)
+		}
+		attr, err := parser.ReadDeviceAttribute(uint16(r.Start), header.Variation)
+		if err != nil {
+			t.Fatalf("Failed to parse attribute: %v", err)
+		}
+		attributes = append(attributes, attr)
+	}
+	return attributes
+}
+
+func TestReadAllDeviceAttributes(t *testing.T) {
+	h := newTestHarness(t, attributesConfig())
+
+	resp := h.request(app.BuildReadRequest(0, app.BuildAllObjects(app.GroupDeviceAttributes, app.DeviceAttributeAll)))
+	if resp.IIN.IIN2 != 0 {
+		t.Fatalf("IIN2: got 0x%02X, want 0", resp.IIN.IIN2)
+	}
+	attributes := parseAttributes(t, resp.Objects)
+
+	want := []types.DeviceAttribute{
+		types.StringAttribute(0, app.DeviceAttributeManufacturerName, "ACME"),
+		types.StringAttribute(0, app.DeviceAttributeSoftwareVersion, "1.2.3"),
+		types.UintAttribute(0, app.DeviceAttributeNumBinaryInputs, 3),
+		types.UintAttribute(0, app.DeviceAttributeMaxBinaryInputIndex, 2),
+		types.UintAttribute(0, app.DeviceAttributeNumAnalogInputs, 2),
+		types.UintAttribute(0, app.DeviceAttributeMaxTxFragSize, 1024),
+		types.UintAttribute(0, app.DeviceAttributeMaxRxFragSize, 2048),
+		types.FloatAttribute(1, 10, 2.5),
+	}
+	for _, w := range want {
+		got, ok := types.FindDeviceAttribute(attributes, w.Set, w.Variation)
+		if !ok || !reflect.DeepEqual(got, w) {
+			t.Errorf("Set %d variation %d: got %+v, want %+v", w.Set, w.Variation, got, w)
+		}
+	}
+	if _, ok := types.FindDeviceAttribute(attributes, 0, app.DeviceAttributeSerialNumber); ok {
+		t.Error("An unconfigured string should not be reported")
+	}
+	if last := attributes[len(attributes)-1]; last.Set != 1 {
+		t.Errorf("Last attribute: got set %d, want the user-defined set after set 0", last.Set)
+	}
+}
+
+func TestReadDeviceAttributeList(t *testing.T) {
+	h := newTestHarness(t, attributesConfig())
+
+	resp := h.request(app.BuildReadRequest(0, app.BuildRangeRead(app.GroupDeviceAttributes, app.DeviceAttributeList, 1, 1)))
+	attributes := parseAttributes(t, resp.Objects)
+	want := []types.DeviceAttribute{{Set: 1, Variation: app.DeviceAttributeList, Type: types.AttributeList,
+		Value: []types.AttributeListEntry{{Variation: 10}}}}
+	if !reflect.DeepEqual(attributes, want) {
+		t.Errorf("Set 1 list: got %+v, want %+v", attributes, want)
+	}
+}
+
+func TestReadSingleDeviceAttribute(t *testing.T) {
+	tests := []struct {
+		name      string
+		objects   []byte
+		wantIIN2  uint8
+		wantCount int
+	}{
+		{"configured string", app.BuildAllObjects(app.GroupDeviceAttributes, app.DeviceAttributeManufacturerName), 0, 1},
+		{"unconfigured string", app.BuildAllObjects(app.GroupDeviceAttributes, app.DeviceAttributeSerialNumber), types.IIN2ObjectUnknown, 0},
+		{"set without attributes", app.BuildRangeRead(app.GroupDeviceAttributes, app.DeviceAttributeAll, 2, 5), types.IIN2ObjectUnknown, 0},
+		{"index list", app.BuildIndexRead(app.GroupDeviceAttributes, app.DeviceAttributeAll, []uint32{0}), types.IIN2ParameterError, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHarness(t, attributesConfig())
+
+			resp := h.request(app.BuildReadRequest(0, tt.objects))
+			if resp.IIN.IIN2 != tt.wantIIN2 {
+				t.Errorf("IIN2: got 0x%02X, want 0x%02X", resp.IIN.IIN2, tt.wantIIN2)
+			}
+			if n := len(parseAttributes(t, resp.Objects)); n != tt.wantCount {
+				t.Errorf("Attributes: got %d, want %d", n, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestCheckDeviceAttributes(t *testing.T) {
+	tests := []struct {
+		name        string
+		userDefined types.DeviceAttribute
+	}{
+		{"hides a point count", types.UintAttribute(0, app.DeviceAttributeNumBinaryInputs, 9)},
+		{"reserved variation", types.StringAttribute(1, app.DeviceAttributeAll, "x")},
+		{"mismatched type", types.DeviceAttribute{Set: 1, Variation: 1, Type: types.AttributeTime, Value: "x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := attributesConfig()
+			config.DeviceAttributes.UserDefined = append(config.DeviceAttributes.UserDefined, tt.userDefined)
+			if err := checkDeviceAttributes(config); err == nil {
+				t.Error("Expected the configuration to be rejected")
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package outstation
+
+import (
+	"avaneesh/dnp3-go/pkg/app"
+)
+
+// authenticate passes a request through Secure Authentication, sending any
+// challenge, key status or error itself. It returns the request to
+// process, or nil when there is none.
+func (o *outstation) authenticate(apdu *app.APDU, data []byte) (*app.APDU, error) {
+	request, objects, err := o.auth.Receive(apdu, data)
+	if err != nil {
+		o.logger.Warn("Outstation %s: Secure authentication: %v", o.config.ID, err)
+	}
+
+	if objects != nil {
+		response := app.NewResponseAPDU(apdu.Sequence, o.responseIIN(), objects)
+		response.FunctionCode = app.FuncAuthResponse
+		if err := o.session.sendAPDU(response.Serialize()); err != nil {
+			return nil, err
+		}
+	}
+	return request, nil
+}
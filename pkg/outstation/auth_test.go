@@ -0,0 +1,99 @@
+package outstation
+
+import (
+	"bytes"
+	"testing"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/secauth"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// authHarness creates an outstation authenticating user 1 with the given
+// role, and a master for that user with session keys already set
+func authHarness(t *testing.T, role secauth.Role) (*testHarness, *secauth.Master) {
+	t.Helper()
+
+	updateKey := bytes.Repeat([]byte{0x11}, 32)
+	config := allTypesConfig()
+	config.SecureAuth = &secauth.OutstationConfig{
+		Users: []secauth.User{{Number: secauth.DefaultUser, UpdateKey: updateKey, Role: role}},
+	}
+	h := newTestHarness(t, config)
+
+	m, err := secauth.NewMaster(secauth.MasterConfig{UpdateKey: updateKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := h.request(app.NewRequestAPDU(app.FuncAuthRequest, 0, m.KeyStatusRequest()))
+	if resp.FunctionCode != app.FuncAuthResponse {
+		t.Fatalf("Key status request: got %s, want AUTH_RESPONSE", resp.FunctionCode)
+	}
+	change, err := m.ChangeKeys(resp.Objects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp = h.request(app.NewRequestAPDU(app.FuncAuthRequest, 1, change))
+	if err := m.ConfirmKeys(resp.Objects); err != nil {
+		t.Fatalf("Key change: %v", err)
+	}
+	return h, m
+}
+
+func TestSecureAuthChallenge(t *testing.T) {
+	h, m := authHarness(t, secauth.RoleEngineer)
+
+	// Reads are not critical
+	if resp := h.request(app.BuildIntegrityPollRequest(2)); resp.FunctionCode != app.FuncResponse {
+		t.Fatalf("READ: got %s, want RESPONSE", resp.FunctionCode)
+	}
+
+	// A WRITE is held until the challenge is answered
+	write := app.BuildWriteRequest(3, app.BuildClearRestartIIN())
+	challenge := h.request(write)
+	if challenge.FunctionCode != app.FuncAuthResponse || challenge.Sequence != 3 {
+		t.Fatalf("WRITE: got %s seq %d, want AUTH_RESPONSE seq 3", challenge.FunctionCode, challenge.Sequence)
+	}
+	if challenge.IIN.IIN1&types.IIN1DeviceRestart == 0 {
+		t.Error("WRITE processed before its challenge was answered")
+	}
+
+	reply, err := m.Reply(challenge.Objects, write.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := h.request(app.NewRequestAPDU(app.FuncAuthRequest, 3, reply))
+	if resp.FunctionCode != app.FuncResponse || resp.Sequence != 3 {
+		t.Fatalf("Reply: got %s seq %d, want RESPONSE seq 3", resp.FunctionCode, resp.Sequence)
+	}
+	if resp.IIN.IIN1&types.IIN1DeviceRestart != 0 {
+		t.Error("Authenticated WRITE did not clear the restart IIN")
+	}
+}
+
+func TestSecureAuthRefused(t *testing.T) {
+	h, m := authHarness(t, secauth.RoleViewer)
+
+	restart := app.BuildColdRestartRequest(2)
+	challenge := h.request(restart)
+	reply, err := m.Reply(challenge.Objects, restart.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := h.request(app.NewRequestAPDU(app.FuncAuthRequest, 2, reply))
+	if resp.FunctionCode != app.FuncAuthResponse {
+		t.Fatalf("Viewer restart: got %s, want AUTH_RESPONSE", resp.FunctionCode)
+	}
+	if err := m.Refused(resp.Objects); err == nil {
+		t.Error("Viewer restart was not refused")
+	}
+}
+
+func TestSecureAuthDisabled(t *testing.T) {
+	h := newTestHarness(t, allTypesConfig())
+
+	resp := h.request(app.NewRequestAPDU(app.FuncAuthRequest, 0, app.BuildKeyStatusRequest(1)))
+	if resp.IIN.IIN2&types.IIN2NoFuncCodeSupport == 0 {
+		t.Errorf("AUTH REQUEST without Secure Authentication: IIN2=0x%02X, want NO_FUNC_CODE_SUPPORT", resp.IIN.IIN2)
+	}
+}
@@ -0,0 +1,110 @@
+package outstation
+
+import (
+	"time"
+
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// chatterFilter tracks the state changes of a binary or double-bit point.
+// While active the point carries the CHATTER_FILTER flag and its state
+// changes produce no events; a timer releases it after the quiet period.
+type chatterFilter struct {
+	config      ChatterFilterConfig
+	transitions []time.Time // State changes within the window, oldest first
+	active      bool
+	quietUntil  time.Time   // Release time, pushed back by every state change
+	timer       *time.Timer // Runs onQuiet once the point may be quiet
+	onQuiet     func()
+}
+
+// filter applies the filter to an update and returns the flags to store
+// and whether a state change may be reported
+func (f *chatterFilter) filter(transition bool, flags types.Flags) (types.Flags, bool) {
+	if f.config.Transitions == 0 {
+		return flags, transition
+	}
+	if transition {
+		f.record(time.Now())
+	}
+	if f.active {
+		// Tripping changes the flags, which reports the state at that moment
+		return flags.WithChatterFilter(true), false
+	}
+	return flags, transition
+}
+
+// record counts a state change and trips the filter once there have been
+// too many within the window
+func (f *chatterFilter) record(now time.Time) {
+	if !f.active {
+		f.transitions = append(f.transitions, now)
+		start := 0
+		for start < len(f.transitions) && now.Sub(f.transitions[start]) > f.config.Window {
+			start++
+		}
+		f.transitions = f.transitions[start:]
+		if uint(len(f.transitions)) < f.config.Transitions {
+			return
+		}
+		f.active = true
+		f.transitions = nil
+	}
+
+	f.quietUntil = now.Add(f.config.QuietPeriod)
+	if f.timer == nil {
+		f.timer = time.AfterFunc(f.config.QuietPeriod, f.onQuiet)
+	} else {
+		f.timer.Reset(f.config.QuietPeriod)
+	}
+}
+
+// release deactivates the filter if the quiet period has passed. A state
+// change racing with the timer leaves it active with the timer rearmed.
+func (f *chatterFilter) release() bool {
+	if !f.active || time.Now().Before(f.quietUntil) {
+		return false
+	}
+	f.active = false
+	return true
+}
+
+// reset deactivates the filter and forgets the state changes
+func (f *chatterFilter) reset() {
+	if f.timer != nil {
+		f.timer.Stop()
+	}
+	f.transitions = nil
+	f.active = false
+}
+
+// releaseBinaryChatter clears the chatter flag of a binary point whose
+// filter has been quiet long enough, reporting its current state
+func (db *Database) releaseBinaryChatter(index uint16) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	point := &db.binary[index]
+	if !point.chatter.release() {
+		return
+	}
+	value := point.value
+	value.Flags = value.Flags.WithChatterFilter(false)
+	value.Time = types.ZeroTime()
+	db.updateBinary(index, value, EventModeDetect)
+}
+
+// releaseDoubleBitChatter is releaseBinaryChatter for a double-bit point
+func (db *Database) releaseDoubleBitChatter(index uint16) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	point := &db.doubleBit[index]
+	if !point.chatter.release() {
+		return
+	}
+	value := point.value
+	value.Flags = value.Flags.WithChatterFilter(false)
+	value.Time = types.ZeroTime()
+	db.updateDoubleBitBinary(index, value, EventModeDetect)
+}
@@ -0,0 +1,97 @@
+package outstation
+
+import (
+	"testing"
+	"time"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// chatterConfig has one class 1 binary that trips after 3 changes in a second
+func chatterConfig(quiet time.Duration) OutstationConfig {
+	return OutstationConfig{Database: DatabaseConfig{
+		Binary: []BinaryPointConfig{{
+			EventVariation: app.BinaryInputEventWithoutTime,
+			Class:          1,
+			ChatterFilter:  ChatterFilterConfig{Transitions: 3, Window: time.Second, QuietPeriod: quiet},
+		}},
+	}}
+}
+
+func TestChatterFilterSuppressesAndReleases(t *testing.T) {
+	h := newTestHarness(t, chatterConfig(50*time.Millisecond))
+	db := h.outstation.database
+	eb := h.outstation.eventBuffer
+
+	db.UpdateBinary(0, types.Binary{Value: false, Flags: types.FlagOnline}, EventModeDetect)
+	for i := 0; i < 5; i++ {
+		db.UpdateBinary(0, types.Binary{Value: i%2 == 0, Flags: types.FlagOnline}, EventModeDetect)
+	}
+
+	// Online, two changes, then the third change trips the filter
+	events := binaryEvents(t, eb)
+	if len(events) != 4 {
+		t.Fatalf("Events before release: got %d, want 4", len(events))
+	}
+	if tripped := events[3]; !tripped.Value || !tripped.Flags.IsChatterFiltered() {
+		t.Errorf("Trip event: got %+v, want ON with the chatter filter flag", tripped)
+	}
+	if v, _ := db.GetBinary(0); !v.Flags.IsChatterFiltered() || !v.Value {
+		t.Errorf("Static value: got %+v, want the latest state with the chatter filter flag", v)
+	}
+
+	// The release reports the state at that moment without the flag
+	waitFor(t, "chatter filter release", func() bool { return len(binaryEvents(t, eb)) == 5 })
+	if released := binaryEvents(t, eb)[4]; !released.Value || released.Flags != types.FlagOnline {
+		t.Errorf("Release event: got %+v, want ON and online", released)
+	}
+
+	db.UpdateBinary(0, types.Binary{Value: false, Flags: types.FlagOnline}, EventModeDetect)
+	if n := len(binaryEvents(t, eb)); n != 6 {
+		t.Errorf("Events after release: got %d, want changes reported again", n)
+	}
+}
+
+func TestChatterFilterStaysActiveWhileChattering(t *testing.T) {
+	h := newTestHarness(t, chatterConfig(80*time.Millisecond))
+	db := h.outstation.database
+
+	for i := 0; i < 3; i++ {
+		db.UpdateBinary(0, types.Binary{Value: i%2 == 0, Flags: types.FlagOnline}, EventModeDetect)
+	}
+
+	// Each change pushes back the release
+	for i := 0; i < 4; i++ {
+		time.Sleep(40 * time.Millisecond)
+		db.UpdateBinary(0, types.Binary{Value: i%2 == 1, Flags: types.FlagOnline}, EventModeDetect)
+		if v, _ := db.GetBinary(0); !v.Flags.IsChatterFiltered() {
+			t.Fatalf("Filter released after %d changes 40ms apart", i+1)
+		}
+	}
+
+	waitFor(t, "chatter filter release", func() bool {
+		v, _ := db.GetBinary(0)
+		return !v.Flags.IsChatterFiltered()
+	})
+}
+
+func TestChatterFilterSlowChangesPass(t *testing.T) {
+	config := chatterConfig(time.Second)
+	config.Database.Binary[0].ChatterFilter.Window = 20 * time.Millisecond
+	h := newTestHarness(t, config)
+	db := h.outstation.database
+
+	for i := 0; i < 4; i++ {
+		db.UpdateBinary(0, types.Binary{Value: i%2 == 0, Flags: types.FlagOnline}, EventModeDetect)
+		time.Sleep(15 * time.Millisecond)
+	}
+	for _, e := range binaryEvents(t, h.outstation.eventBuffer) {
+		if e.Flags.IsChatterFiltered() {
+			t.Fatal("Changes slower than the window should not trip the filter")
+		}
+	}
+	if n := len(binaryEvents(t, h.outstation.eventBuffer)); n != 4 {
+		t.Errorf("Events: got %d, want 4", n)
+	}
+}
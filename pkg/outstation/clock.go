@@ -0,0 +1,112 @@
+package outstation
+
+import (
+	"sync"
+	"time"
+
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// clock is the outstation's DNP3 time, kept as an offset from the local clock
+// that the master sets through time synchronization
+type clock struct {
+	mu             sync.RWMutex
+	offset         time.Duration
+	lastSync       time.Time     // Local time of the last synchronization, zero if none
+	recorded       time.Time     // Local time of the last RECORD CURRENT TIME, zero if none
+	resyncInterval time.Duration // Zero to request time only at startup
+}
+
+// newClock creates an unsynchronized clock
+func newClock(resyncInterval time.Duration) *clock {
+	return &clock{resyncInterval: resyncInterval}
+}
+
+// now returns the current DNP3 time
+func (c *clock) now() types.DNP3Time {
+	t, _ := c.stamp()
+	return t
+}
+
+// stamp returns the current DNP3 time and its quality
+func (c *clock) stamp() (types.DNP3Time, types.TimeQuality) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	quality := types.TimeQualitySynchronized
+	if c.needTimeLocked() {
+		quality = types.TimeQualityUnsynchronized
+	}
+	return types.FromTime(time.Now().Add(c.offset)), quality
+}
+
+// set synchronizes the clock to the given time
+func (c *clock) set(t types.DNP3Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	local := time.Now()
+	c.offset = t.ToTime().Sub(local)
+	c.lastSync = local
+}
+
+// localTime converts a DNP3 time, such as one given by the master, to the
+// local time it falls at
+func (c *clock) localTime(t types.DNP3Time) time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return t.ToTime().Add(-c.offset)
+}
+
+// record remembers the local time a RECORD CURRENT TIME request arrived
+func (c *clock) record(at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.recorded = at
+}
+
+// setRecorded synchronizes the clock given the master's time at the last
+// record, and returns the time now. It fails if no time has been recorded.
+func (c *clock) setRecorded(t types.DNP3Time) (types.DNP3Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.recorded.IsZero() {
+		return 0, false
+	}
+
+	local := time.Now()
+	c.offset = t.ToTime().Sub(c.recorded)
+	c.lastSync = local
+	c.recorded = time.Time{}
+	return types.FromTime(local.Add(c.offset)), true
+}
+
+// invalidate marks the clock as needing synchronization, as after a cold restart
+func (c *clock) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastSync = time.Time{}
+	c.recorded = time.Time{}
+}
+
+// needTime reports whether the master should be asked for time (IIN1.4)
+func (c *clock) needTime() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.needTimeLocked()
+}
+
+// setResyncInterval changes the time after a sync before NeedTime is set again
+func (c *clock) setResyncInterval(interval time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.resyncInterval = interval
+}
+
+func (c *clock) needTimeLocked() bool {
+	if c.lastSync.IsZero() {
+		return true
+	}
+	return c.resyncInterval > 0 && time.Since(c.lastSync) >= c.resyncInterval
+}
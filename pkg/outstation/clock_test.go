@@ -0,0 +1,122 @@
+package outstation
+
+import (
+	"testing"
+	"time"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// syncTime writes t to the outstation as a G50V1 time synchronization
+func (h *testHarness) syncTime(seq uint8, t time.Time) *app.APDU {
+	return h.request(app.BuildWriteRequest(seq, app.BuildTimeSync(t)))
+}
+
+// binaryEvents returns the binary events waiting in class 1
+func binaryEvents(t *testing.T, eb *EventBuffer) []types.Binary {
+	t.Helper()
+
+	var values []types.Binary
+	for _, e := range eb.SelectEvents(app.Class1, false) {
+		if v, ok := e.Value.(types.Binary); ok {
+			values = append(values, v)
+		}
+	}
+	eb.Unselect(false)
+	return values
+}
+
+func TestTimeSyncClearsNeedTime(t *testing.T) {
+	h := newTestHarness(t, eventTestConfig())
+
+	resp := h.request(app.BuildReadRequest(0, app.BuildClassRead(app.Class0)))
+	if resp.IIN.IIN1&types.IIN1NeedTime == 0 {
+		t.Fatal("IIN1.4 should be set at startup")
+	}
+
+	synced := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	resp = h.syncTime(1, synced)
+	if resp.IIN.IIN1&types.IIN1NeedTime != 0 || resp.IIN.IIN2 != 0 {
+		t.Errorf("Time sync response: got IIN1=0x%02X IIN2=0x%02X, want NeedTime cleared", resp.IIN.IIN1, resp.IIN.IIN2)
+	}
+	if len(h.callbacks.timeSyncs) != 1 || !h.callbacks.timeSyncs[0].Equal(synced) {
+		t.Errorf("OnTimeSync: got %v, want [%v]", h.callbacks.timeSyncs, synced)
+	}
+
+	// A cold restart loses the time
+	h.request(app.BuildColdRestartRequest(2))
+	resp = h.request(app.BuildReadRequest(3, app.BuildClassRead(app.Class0)))
+	if resp.IIN.IIN1&types.IIN1NeedTime == 0 {
+		t.Error("IIN1.4 should be set after cold restart")
+	}
+}
+
+func TestTimeSyncResyncInterval(t *testing.T) {
+	config := eventTestConfig()
+	config.TimeSyncInterval = 30 * time.Millisecond
+	h := newTestHarness(t, config)
+
+	resp := h.syncTime(0, time.Now())
+	if resp.IIN.IIN1&types.IIN1NeedTime != 0 {
+		t.Fatal("IIN1.4 should be cleared by the time sync")
+	}
+	waitFor(t, "NeedTime after resync interval", h.outstation.clock.needTime)
+
+	resp = h.request(app.BuildReadRequest(1, app.BuildClassRead(app.Class0)))
+	if resp.IIN.IIN1&types.IIN1NeedTime == 0 {
+		t.Error("IIN1.4 should be set once the resync interval has passed")
+	}
+}
+
+func TestEventsStampedFromSyncedClock(t *testing.T) {
+	h := newTestHarness(t, eventTestConfig())
+	db := h.outstation.database
+
+	db.UpdateBinary(0, types.Binary{Value: true, Flags: types.FlagOnline}, EventModeDetect)
+
+	synced := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	h.syncTime(0, synced)
+	db.UpdateBinary(0, types.Binary{Value: false, Flags: types.FlagOnline}, EventModeDetect)
+
+	// A timestamp supplied by the application is kept as is
+	db.UpdateBinary(0, types.Binary{Value: true, Flags: types.FlagOnline, Time: 1000}, EventModeDetect)
+
+	events := binaryEvents(t, h.outstation.eventBuffer)
+	if len(events) != 3 {
+		t.Fatalf("Events: got %d, want 3", len(events))
+	}
+
+	if events[0].TimeQuality != types.TimeQualityUnsynchronized {
+		t.Errorf("Event before sync: got quality %s, want Unsynchronized", events[0].TimeQuality)
+	}
+
+	got := events[1].Time.ToTime()
+	if got.Before(synced) || got.Sub(synced) > time.Second {
+		t.Errorf("Event after sync: got time %v, want about %v", got.UTC(), synced)
+	}
+	if events[1].TimeQuality != types.TimeQualitySynchronized {
+		t.Errorf("Event after sync: got quality %s, want Synchronized", events[1].TimeQuality)
+	}
+
+	if events[2].Time != 1000 || events[2].TimeQuality != types.TimeQualityUnknown {
+		t.Errorf("Application timestamp: got %d (%s), want 1000 (Unknown)", events[2].Time, events[2].TimeQuality)
+	}
+}
+
+func TestTimeSyncInvalid(t *testing.T) {
+	h := newTestHarness(t, eventTestConfig())
+
+	// Two time objects in one write
+	b := app.NewObjectBuilder()
+	b.AddHeader(app.GroupTimeDate, app.TimeDateAbsolute, app.Qualifier8BitCount, app.CountRange{Count: 2})
+	b.AddRawData(make([]byte, 12))
+
+	resp := h.request(app.BuildWriteRequest(0, b.Build()))
+	if resp.IIN.IIN2&types.IIN2ParameterError == 0 {
+		t.Errorf("IIN2: got 0x%02X, want parameter error", resp.IIN.IIN2)
+	}
+	if resp.IIN.IIN1&types.IIN1NeedTime == 0 || len(h.callbacks.timeSyncs) != 0 {
+		t.Error("Invalid time sync should not synchronize the clock")
+	}
+}
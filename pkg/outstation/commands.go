@@ -0,0 +1,372 @@
+package outstation
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+var (
+	ErrUnknownControlObject = errors.New("unknown control object")
+	ErrMalformedControl     = errors.New("malformed control request")
+)
+
+// selectState tracks the last successful SELECT for select-before-operate
+type selectState struct {
+	valid     bool
+	seq       uint8
+	objects   []byte
+	timestamp time.Time
+}
+
+// controlObject is a single command object parsed from a control request
+type controlObject struct {
+	group     uint8
+	variation uint8
+	index     uint16
+	data      []byte // Raw object bytes, status byte last
+	statusPos int    // Offset of the status byte within the request objects
+}
+
+// parseControlObjects parses G12V1 and G41V1-4 objects from a control request
+func parseControlObjects(objects []byte) ([]controlObject, error) {
+	var controls []controlObject
+
+	parser := app.NewParser(objects)
+	for parser.HasMore() {
+		header, err := parser.ReadObjectHeader()
+		if err != nil {
+			return nil, ErrMalformedControl
+		}
+
+		switch {
+		case header.Group == app.GroupBinaryOutputCommand && header.Variation == 1:
+		case header.Group == app.GroupAnalogOutputCommand && header.Variation >= 1 && header.Variation <= 4:
+		default:
+			return nil, ErrUnknownControlObject
+		}
+		size := app.GetObjectSize(header.Group, header.Variation)
+
+		// Determine how indices are conveyed for this header
+		var start uint32
+		indexSize := 0
+		switch r := header.Range.(type) {
+		case app.StartStopRange:
+			if r.Stop < r.Start {
+				return nil, ErrMalformedControl
+			}
+			start = r.Start
+		case app.IndexPrefixRange:
+			indexSize = r.IndexSize
+		default:
+			return nil, ErrMalformedControl
+		}
+
+		count := app.GetCount(header.Range)
+		for i := uint32(0); i < count; i++ {
+			index := start + i
+			if indexSize > 0 {
+				if index, err = parser.ReadIndex(indexSize); err != nil {
+					return nil, ErrMalformedControl
+				}
+			}
+
+			data, err := parser.ReadBytes(size)
+			if err != nil {
+				return nil, ErrMalformedControl
+			}
+
+			controls = append(controls, controlObject{
+				group:     header.Group,
+				variation: header.Variation,
+				index:     uint16(index),
+				data:      data,
+				statusPos: len(objects) - parser.Remaining() - 1,
+			})
+		}
+	}
+
+	return controls, nil
+}
+
+// decodeCommand converts a control object into its types representation
+func decodeCommand(ctrl controlObject) (interface{}, error) {
+	if ctrl.group == app.GroupBinaryOutputCommand {
+		crob, err := app.ParseCROB(ctrl.data)
+		if err != nil {
+			return nil, err
+		}
+		return types.CROB{
+			OpType:    types.ControlCode(crob.ControlCode),
+			Count:     crob.Count,
+			OnTimeMs:  crob.OnTime,
+			OffTimeMs: crob.OffTime,
+		}, nil
+	}
+
+	switch ctrl.variation {
+	case 1:
+		ao, err := app.ParseAnalogOutputInt32(ctrl.data)
+		if err != nil {
+			return nil, err
+		}
+		return types.AnalogOutputInt32{Value: ao.Value.(int32)}, nil
+	case 2:
+		ao, err := app.ParseAnalogOutputInt16(ctrl.data)
+		if err != nil {
+			return nil, err
+		}
+		return types.AnalogOutputInt16{Value: ao.Value.(int16)}, nil
+	case 3:
+		ao, err := app.ParseAnalogOutputFloat(ctrl.data)
+		if err != nil {
+			return nil, err
+		}
+		return types.AnalogOutputFloat32{Value: ao.Value.(float32)}, nil
+	default:
+		ao, err := app.ParseAnalogOutputDouble(ctrl.data)
+		if err != nil {
+			return nil, err
+		}
+		return types.AnalogOutputDouble64{Value: ao.Value.(float64)}, nil
+	}
+}
+
+// selectCommand passes a single command to the application's Select handler
+func (o *outstation) selectCommand(ctrl controlObject) types.CommandStatus {
+	cmd, err := decodeCommand(ctrl)
+	if err != nil {
+		return types.CommandStatusFormatError
+	}
+
+	switch c := cmd.(type) {
+	case types.CROB:
+		return o.callbacks.SelectCROB(c, ctrl.index)
+	case types.AnalogOutputInt32:
+		return o.callbacks.SelectAnalogOutputInt32(c, ctrl.index)
+	case types.AnalogOutputInt16:
+		return o.callbacks.SelectAnalogOutputInt16(c, ctrl.index)
+	case types.AnalogOutputFloat32:
+		return o.callbacks.SelectAnalogOutputFloat32(c, ctrl.index)
+	case types.AnalogOutputDouble64:
+		return o.callbacks.SelectAnalogOutputDouble64(c, ctrl.index)
+	default:
+		return types.CommandStatusNotSupported
+	}
+}
+
+// operateCommand passes a single command to the application's Operate handler
+func (o *outstation) operateCommand(ctrl controlObject, opType OperateType) types.CommandStatus {
+	cmd, err := decodeCommand(ctrl)
+	if err != nil {
+		return types.CommandStatusFormatError
+	}
+
+	handler := &databaseUpdateHandler{database: o.database}
+
+	switch c := cmd.(type) {
+	case types.CROB:
+		return o.callbacks.OperateCROB(c, ctrl.index, opType, handler)
+	case types.AnalogOutputInt32:
+		return o.callbacks.OperateAnalogOutputInt32(c, ctrl.index, opType, handler)
+	case types.AnalogOutputInt16:
+		return o.callbacks.OperateAnalogOutputInt16(c, ctrl.index, opType, handler)
+	case types.AnalogOutputFloat32:
+		return o.callbacks.OperateAnalogOutputFloat32(c, ctrl.index, opType, handler)
+	case types.AnalogOutputDouble64:
+		return o.callbacks.OperateAnalogOutputDouble64(c, ctrl.index, opType, handler)
+	default:
+		return types.CommandStatusNotSupported
+	}
+}
+
+// processControls runs fn for every control object and echoes the objects back
+// with each status byte replaced by the returned command status
+func (o *outstation) processControls(objects []byte, controls []controlObject, fn func(controlObject) types.CommandStatus) ([]byte, bool) {
+	response := make([]byte, len(objects))
+	copy(response, objects)
+
+	if o.config.MaxControlsPerRequest > 0 && uint(len(controls)) > o.config.MaxControlsPerRequest {
+		for _, ctrl := range controls {
+			response[ctrl.statusPos] = uint8(types.CommandStatusTooManyOps)
+		}
+		return response, false
+	}
+
+	allSuccess := true
+
+	o.callbacks.Begin()
+	for _, ctrl := range controls {
+		status := fn(ctrl)
+		if status != types.CommandStatusSuccess {
+			allSuccess = false
+		}
+		response[ctrl.statusPos] = uint8(status)
+	}
+	o.callbacks.End()
+
+	return response, allSuccess
+}
+
+// echoStatus echoes the objects back with every status byte set to status
+func echoStatus(objects []byte, controls []controlObject, status types.CommandStatus) []byte {
+	response := make([]byte, len(objects))
+	copy(response, objects)
+	for _, ctrl := range controls {
+		response[ctrl.statusPos] = uint8(status)
+	}
+	return response
+}
+
+// controlErrorIIN maps a control parse error to the IIN2 bit reported to the master
+func controlErrorIIN(err error) uint8 {
+	if errors.Is(err, ErrUnknownControlObject) {
+		return types.IIN2ObjectUnknown
+	}
+	return types.IIN2ParameterError
+}
+
+// handleSelect handles SELECT requests
+func (o *outstation) handleSelect(apdu *app.APDU) error {
+	o.logger.Debug("Outstation %s: Handling SELECT request", o.config.ID)
+
+	iin := o.responseIIN()
+
+	controls, err := parseControlObjects(apdu.Objects)
+	if err != nil {
+		o.logger.Warn("Outstation %s: Invalid SELECT request: %v", o.config.ID, err)
+		o.clearSelect()
+		iin.IIN2 |= controlErrorIIN(err)
+		response := app.NewResponseAPDU(apdu.Sequence, iin, nil)
+		return o.session.sendAPDU(response.Serialize())
+	}
+
+	responseData, allSuccess := o.processControls(apdu.Objects, controls, o.selectCommand)
+
+	o.stateMu.Lock()
+	if allSuccess {
+		objects := make([]byte, len(apdu.Objects))
+		copy(objects, apdu.Objects)
+		o.selected = selectState{
+			valid:     true,
+			seq:       apdu.Sequence,
+			objects:   objects,
+			timestamp: time.Now(),
+		}
+	} else {
+		o.selected = selectState{}
+	}
+	o.stateMu.Unlock()
+
+	response := app.NewResponseAPDU(apdu.Sequence, iin, responseData)
+	return o.session.sendAPDU(response.Serialize())
+}
+
+// handleOperate handles OPERATE requests
+func (o *outstation) handleOperate(apdu *app.APDU) error {
+	o.logger.Debug("Outstation %s: Handling OPERATE request", o.config.ID)
+
+	iin := o.responseIIN()
+
+	controls, err := parseControlObjects(apdu.Objects)
+	if err != nil {
+		o.logger.Warn("Outstation %s: Invalid OPERATE request: %v", o.config.ID, err)
+		o.clearSelect()
+		iin.IIN2 |= controlErrorIIN(err)
+		response := app.NewResponseAPDU(apdu.Sequence, iin, nil)
+		return o.session.sendAPDU(response.Serialize())
+	}
+
+	var responseData []byte
+	if status := o.checkSelect(apdu); status != types.CommandStatusSuccess {
+		o.logger.Warn("Outstation %s: OPERATE rejected: %s", o.config.ID, status)
+		responseData = echoStatus(apdu.Objects, controls, status)
+	} else {
+		responseData, _ = o.processControls(apdu.Objects, controls, func(ctrl controlObject) types.CommandStatus {
+			return o.operateCommand(ctrl, OperateTypeSelectBeforeOperate)
+		})
+	}
+	o.clearSelect()
+
+	response := app.NewResponseAPDU(apdu.Sequence, iin, responseData)
+	return o.session.sendAPDU(response.Serialize())
+}
+
+// handleDirectOperate handles DIRECT OPERATE and DIRECT OPERATE NO ACK requests
+func (o *outstation) handleDirectOperate(apdu *app.APDU) error {
+	o.logger.Debug("Outstation %s: Handling %s request", o.config.ID, apdu.FunctionCode)
+
+	noAck := apdu.FunctionCode == app.FuncDirectOperateNoAck
+	opType := OperateTypeDirectOperate
+	if noAck {
+		opType = OperateTypeDirectOperateNoAck
+	}
+
+	iin := o.responseIIN()
+
+	controls, err := parseControlObjects(apdu.Objects)
+	if err != nil {
+		o.logger.Warn("Outstation %s: Invalid %s request: %v", o.config.ID, apdu.FunctionCode, err)
+		if noAck {
+			return nil
+		}
+		iin.IIN2 |= controlErrorIIN(err)
+		response := app.NewResponseAPDU(apdu.Sequence, iin, nil)
+		return o.session.sendAPDU(response.Serialize())
+	}
+
+	responseData, _ := o.processControls(apdu.Objects, controls, func(ctrl controlObject) types.CommandStatus {
+		return o.operateCommand(ctrl, opType)
+	})
+
+	if noAck {
+		return nil
+	}
+
+	response := app.NewResponseAPDU(apdu.Sequence, iin, responseData)
+	return o.session.sendAPDU(response.Serialize())
+}
+
+// checkSelect verifies that an OPERATE matches the armed SELECT
+func (o *outstation) checkSelect(apdu *app.APDU) types.CommandStatus {
+	o.stateMu.RLock()
+	defer o.stateMu.RUnlock()
+
+	sel := o.selected
+	if !sel.valid {
+		return types.CommandStatusNoSelect
+	}
+
+	// OPERATE must immediately follow SELECT with identical objects
+	if apdu.Sequence != (sel.seq+1)&app.AppCtrlSeqMask || !bytes.Equal(apdu.Objects, sel.objects) {
+		return types.CommandStatusNoSelect
+	}
+
+	if o.config.SelectTimeout > 0 && time.Since(sel.timestamp) > o.config.SelectTimeout {
+		return types.CommandStatusTimeout
+	}
+
+	return types.CommandStatusSuccess
+}
+
+// clearSelect disarms any pending SELECT
+func (o *outstation) clearSelect() {
+	o.stateMu.Lock()
+	o.selected = selectState{}
+	o.stateMu.Unlock()
+}
+
+// databaseUpdateHandler applies measurement updates made while processing commands
+type databaseUpdateHandler struct {
+	database *Database
+}
+
+// Update applies a measurement to the database (implements UpdateHandler)
+func (h *databaseUpdateHandler) Update(meas interface{}, index uint16, mode EventMode) bool {
+	h.database.mu.Lock()
+	defer h.database.mu.Unlock()
+	return h.database.update(meas, index, mode)
+}
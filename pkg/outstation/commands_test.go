@@ -0,0 +1,169 @@
+package outstation
+
+import (
+	"testing"
+	"time"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+func crobObjects(index uint16) []byte {
+	return app.BuildCROBRequest(index, app.NewLatchOn())
+}
+
+func TestSelectBeforeOperate(t *testing.T) {
+	h := newTestHarness(t, OutstationConfig{SelectTimeout: time.Second})
+
+	objects := crobObjects(3)
+
+	resp := h.request(app.BuildSelectRequest(4, objects))
+	if statuses := commandStatuses(t, resp.Objects); len(statuses) != 1 || statuses[0] != types.CommandStatusSuccess {
+		t.Fatalf("SELECT statuses: got %v, want [Success]", statuses)
+	}
+
+	resp = h.request(app.BuildOperateRequest(5, objects))
+	if statuses := commandStatuses(t, resp.Objects); len(statuses) != 1 || statuses[0] != types.CommandStatusSuccess {
+		t.Fatalf("OPERATE statuses: got %v, want [Success]", statuses)
+	}
+
+	if len(h.callbacks.selects) != 1 || h.callbacks.selects[0] != 3 {
+		t.Errorf("SelectCROB calls: got %v, want [3]", h.callbacks.selects)
+	}
+	if len(h.callbacks.operates) != 1 || h.callbacks.opTypes[0] != OperateTypeSelectBeforeOperate {
+		t.Errorf("OperateCROB calls: got %v (%v)", h.callbacks.operates, h.callbacks.opTypes)
+	}
+	if h.callbacks.begins != 2 || h.callbacks.ends != 2 {
+		t.Errorf("Begin/End: got %d/%d, want 2/2", h.callbacks.begins, h.callbacks.ends)
+	}
+
+	// A second OPERATE without a new SELECT must be rejected
+	resp = h.request(app.BuildOperateRequest(6, objects))
+	if statuses := commandStatuses(t, resp.Objects); statuses[0] != types.CommandStatusNoSelect {
+		t.Errorf("Repeated OPERATE status: got %s, want NoSelect", statuses[0])
+	}
+}
+
+func TestOperateWithoutMatchingSelect(t *testing.T) {
+	tests := []struct {
+		name       string
+		operateSeq uint8
+		objects    []byte
+	}{
+		{"wrong sequence", 7, crobObjects(3)},
+		{"different objects", 5, crobObjects(4)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHarness(t, OutstationConfig{})
+
+			h.request(app.BuildSelectRequest(4, crobObjects(3)))
+			resp := h.request(app.BuildOperateRequest(tt.operateSeq, tt.objects))
+
+			if statuses := commandStatuses(t, resp.Objects); statuses[0] != types.CommandStatusNoSelect {
+				t.Errorf("OPERATE status: got %s, want NoSelect", statuses[0])
+			}
+			if len(h.callbacks.operates) != 0 {
+				t.Errorf("Operate handler should not be called, got %v", h.callbacks.operates)
+			}
+		})
+	}
+}
+
+func TestOperateAfterSelectTimeout(t *testing.T) {
+	h := newTestHarness(t, OutstationConfig{SelectTimeout: 10 * time.Millisecond})
+
+	objects := crobObjects(1)
+	h.request(app.BuildSelectRequest(0, objects))
+	time.Sleep(30 * time.Millisecond)
+
+	resp := h.request(app.BuildOperateRequest(1, objects))
+	if statuses := commandStatuses(t, resp.Objects); statuses[0] != types.CommandStatusTimeout {
+		t.Errorf("OPERATE status: got %s, want Timeout", statuses[0])
+	}
+}
+
+func TestSelectFailureDoesNotArm(t *testing.T) {
+	h := newTestHarness(t, OutstationConfig{})
+	h.callbacks.selectStatus = types.CommandStatusNotSupported
+
+	objects := crobObjects(2)
+	resp := h.request(app.BuildSelectRequest(0, objects))
+	if statuses := commandStatuses(t, resp.Objects); statuses[0] != types.CommandStatusNotSupported {
+		t.Fatalf("SELECT status: got %s, want NotSupported", statuses[0])
+	}
+
+	resp = h.request(app.BuildOperateRequest(1, objects))
+	if statuses := commandStatuses(t, resp.Objects); statuses[0] != types.CommandStatusNoSelect {
+		t.Errorf("OPERATE status: got %s, want NoSelect", statuses[0])
+	}
+}
+
+func TestDirectOperateAnalogOutputs(t *testing.T) {
+	h := newTestHarness(t, OutstationConfig{})
+
+	// Two float setpoints using 16-bit index prefixes, as sent by most masters
+	builder := app.NewObjectBuilder()
+	builder.AddHeader(app.GroupAnalogOutputCommand, 3, app.Qualifier16BitIndexPrefix,
+		app.IndexPrefixRange{Count: 2, IndexSize: 2})
+	builder.AddIndex(2, 7)
+	builder.AddRawData([]byte{0x00, 0x00, 0x20, 0x41, 0x00}) // 10.0
+	builder.AddIndex(2, 9)
+	builder.AddRawData([]byte{0x00, 0x00, 0xA0, 0xC0, 0x00}) // -5.0
+	objects := builder.Build()
+
+	resp := h.request(app.BuildDirectOperateRequest(2, objects))
+
+	statuses := commandStatuses(t, resp.Objects)
+	if len(statuses) != 2 {
+		t.Fatalf("Expected 2 statuses, got %d", len(statuses))
+	}
+	if len(h.callbacks.operates) != 2 || h.callbacks.operates[0] != 7 || h.callbacks.operates[1] != 9 {
+		t.Errorf("Operate indices: got %v, want [7 9]", h.callbacks.operates)
+	}
+	if h.callbacks.aoValues[0] != 10.0 || h.callbacks.aoValues[1] != -5.0 {
+		t.Errorf("Operate values: got %v, want [10 -5]", h.callbacks.aoValues)
+	}
+}
+
+func TestDirectOperateNoAck(t *testing.T) {
+	h := newTestHarness(t, OutstationConfig{})
+
+	apdu := app.NewRequestAPDU(app.FuncDirectOperateNoAck, 0, crobObjects(0))
+	if err := h.outstation.onReceiveAPDU(apdu.Serialize()); err != nil {
+		t.Fatalf("onReceiveAPDU failed: %v", err)
+	}
+	h.expectNoResponse()
+
+	if len(h.callbacks.opTypes) != 1 || h.callbacks.opTypes[0] != OperateTypeDirectOperateNoAck {
+		t.Errorf("Operate types: got %v, want [DirectOperateNoAck]", h.callbacks.opTypes)
+	}
+}
+
+func TestTooManyControls(t *testing.T) {
+	h := newTestHarness(t, OutstationConfig{MaxControlsPerRequest: 1})
+
+	objects := append(crobObjects(0), crobObjects(1)...)
+	resp := h.request(app.BuildDirectOperateRequest(0, objects))
+
+	for i, status := range commandStatuses(t, resp.Objects) {
+		if status != types.CommandStatusTooManyOps {
+			t.Errorf("Status %d: got %s, want TooManyOps", i, status)
+		}
+	}
+	if len(h.callbacks.operates) != 0 {
+		t.Errorf("Operate handler should not be called, got %v", h.callbacks.operates)
+	}
+}
+
+func TestUnknownControlObject(t *testing.T) {
+	h := newTestHarness(t, OutstationConfig{})
+
+	objects := app.BuildRangeRead(app.GroupBinaryInput, 2, 0, 0)
+	resp := h.request(app.BuildSelectRequest(0, objects))
+
+	if resp.IIN.IIN2&types.IIN2ObjectUnknown == 0 {
+		t.Errorf("Expected IIN2 object unknown, got IIN2=0x%02X", resp.IIN.IIN2)
+	}
+}
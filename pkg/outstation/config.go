@@ -1,29 +1,94 @@
 package outstation
 
 import (
+	"io"
+	"io/fs"
 	"time"
 
+	"avaneesh/dnp3-go/pkg/secauth"
 	"avaneesh/dnp3-go/pkg/types"
 )
 
 // OutstationConfig configures an outstation session
 type OutstationConfig struct {
-	ID                    string
-	LocalAddress          uint16
-	RemoteAddress         uint16
-	Database              DatabaseConfig
-	MaxBinaryEvents       uint
-	MaxAnalogEvents       uint
-	MaxCounterEvents      uint
-	MaxDoubleBitEvents    uint
-	AllowUnsolicited      bool
-	UnsolConfirmTimeout   time.Duration
-	SelectTimeout         time.Duration
-	MaxControlsPerRequest uint
-	LocalControl          bool
-	DeviceTrouble         bool
-	MaxRxFragSize         uint16
-	MaxTxFragSize         uint16
+	ID                     string
+	LocalAddress           uint16
+	RemoteAddress          uint16
+	Database               DatabaseConfig
+	MaxBinaryEvents        uint
+	MaxAnalogEvents        uint
+	MaxCounterEvents       uint
+	MaxDoubleBitEvents     uint
+	MaxFrozenCounterEvents uint
+	MaxBinaryOutputEvents  uint
+	MaxAnalogOutputEvents  uint
+	MaxOctetStringEvents   uint
+	EventOverflowPolicy    EventOverflowPolicy
+	AllowUnsolicited       bool
+	UnsolConfirmTimeout    time.Duration
+	UnsolMaxRetries        uint
+	SolConfirmTimeout      time.Duration
+	SelectTimeout          time.Duration
+	MaxControlsPerRequest  uint
+	LocalControl           bool
+	DeviceTrouble          bool
+	MaxRxFragSize          uint16
+	MaxTxFragSize          uint16
+	TimeSyncInterval       time.Duration // Time after a sync before NeedTime is set again, zero for never
+	DeviceAttributes       DeviceAttributes
+	FileTransfer           FileTransferConfig
+	SecureAuth             *secauth.OutstationConfig // Authenticate critical requests (G120), nil to disable
+}
+
+// eventBufferConfig returns the event buffer capacities from the configuration
+func (c OutstationConfig) eventBufferConfig() EventBufferConfig {
+	return EventBufferConfig{
+		MaxBinary:             c.MaxBinaryEvents,
+		MaxDoubleBitBinary:    c.MaxDoubleBitEvents,
+		MaxAnalog:             c.MaxAnalogEvents,
+		MaxCounter:            c.MaxCounterEvents,
+		MaxFrozenCounter:      c.MaxFrozenCounterEvents,
+		MaxBinaryOutputStatus: c.MaxBinaryOutputEvents,
+		MaxAnalogOutputStatus: c.MaxAnalogOutputEvents,
+		MaxOctetString:        c.MaxOctetStringEvents,
+		OverflowPolicy:        c.EventOverflowPolicy,
+	}
+}
+
+// DeviceAttributes are the device attributes (G0) the outstation reports.
+// Empty strings are not reported. The point counts, highest indices and
+// fragment sizes of set 0 are reported from the configuration.
+type DeviceAttributes struct {
+	ManufacturerName     string                  // Variation 252
+	ProductNameAndModel  string                  // Variation 250
+	SoftwareVersion      string                  // Variation 242
+	HardwareVersion      string                  // Variation 243
+	SerialNumber         string                  // Variation 248
+	SubsetAndConformance string                  // Variation 249, e.g. "2:2012"
+	DeviceName           string                  // Variation 247, user-assigned
+	IDCode               string                  // Variation 246, user-assigned
+	LocationName         string                  // Variation 245, user-assigned
+	OwnerName            string                  // Variation 244, user-assigned
+	UserDefined          []types.DeviceAttribute // Further attributes of set 0 or of sets 1 and above
+}
+
+// FileTransferConfig serves files to the master (G70). File transfer is
+// disabled when FileSystem is nil.
+type FileTransferConfig struct {
+	FileSystem        FileSystem
+	MaxBlockSize      uint16                               // Largest block of file data, zero for as much as a fragment holds
+	MaxOpenFiles      uint                                 // Zero for 4
+	InactivityTimeout time.Duration                        // An open file unused this long is closed, zero for 1 minute
+	Authenticate      func(userName, password string) bool // When set, OPEN and DELETE need a key from AUTHENTICATE FILE
+}
+
+// FileSystem is the file store of file transfer. Files and directories are
+// read through fs.FS; names use forward slashes relative to its root.
+type FileSystem interface {
+	fs.FS
+	// OpenWrite creates a file, or truncates it unless appending
+	OpenWrite(name string, append bool) (io.WriteCloser, error)
+	Remove(name string) error
 }
 
 // DatabaseConfig defines point counts and configurations
@@ -35,6 +100,7 @@ type DatabaseConfig struct {
 	FrozenCounter []FrozenCounterPointConfig
 	BinaryOutput  []BinaryOutputStatusPointConfig
 	AnalogOutput  []AnalogOutputStatusPointConfig
+	OctetString   []OctetStringPointConfig
 }
 
 // Point config types
@@ -42,12 +108,23 @@ type BinaryPointConfig struct {
 	StaticVariation uint8
 	EventVariation  uint8
 	Class           uint8
+	ChatterFilter   ChatterFilterConfig
 }
 
 type DoubleBitBinaryPointConfig struct {
 	StaticVariation uint8
 	EventVariation  uint8
 	Class           uint8
+	ChatterFilter   ChatterFilterConfig
+}
+
+// ChatterFilterConfig suppresses the events of a binary or double-bit point
+// that changes state Transitions times within Window, until it has been
+// quiet for QuietPeriod. The zero value disables the filter.
+type ChatterFilterConfig struct {
+	Transitions uint
+	Window      time.Duration
+	QuietPeriod time.Duration
 }
 
 type AnalogPointConfig struct {
@@ -55,6 +132,8 @@ type AnalogPointConfig struct {
 	EventVariation  uint8
 	Class           uint8
 	Deadband        float64
+	DeadbandMode    DeadbandMode
+	FullScale       float64 // Span of the point, for DeadbandPercent
 }
 
 type CounterPointConfig struct {
@@ -62,6 +141,8 @@ type CounterPointConfig struct {
 	EventVariation  uint8
 	Class           uint8
 	Deadband        uint32
+	DeadbandMode    DeadbandMode
+	FullScale       uint32 // Span of the point, for DeadbandPercent
 }
 
 type FrozenCounterPointConfig struct {
@@ -83,12 +164,33 @@ type AnalogOutputStatusPointConfig struct {
 	Deadband        float64
 }
 
+// OctetStringPointConfig configures an octet string point. Static (G110)
+// and event (G111) objects are reported in the variation equal to the length
+// of the string.
+type OctetStringPointConfig struct {
+	Class uint8
+}
+
 // OutstationCallbacks defines application callbacks for outstation
 type OutstationCallbacks interface {
 	CommandHandler
 	OnConfirmReceived(unsolicited bool, numClass1, numClass2, numClass3 uint)
 	OnUnsolicitedResponse(success bool, seq uint8)
 	GetApplicationIIN() types.IIN
+
+	// OnTimeSync is called with the new time after the master synchronizes
+	// the outstation clock
+	OnTimeSync(t time.Time)
+
+	// OnEventBufferOverflow is called when events of a type are lost because
+	// its buffer is full, once until the master confirms events again. It
+	// runs while the database is updated and must not block.
+	OnEventBufferOverflow(eventType EventType)
+
+	// Restart requests return the time the master should wait before
+	// communicating with the outstation again
+	OnColdRestart() time.Duration
+	OnWarmRestart() time.Duration
 }
 
 // CommandHandler processes commands from master
@@ -126,11 +228,31 @@ const (
 	OperateTypeDirectOperateNoAck
 )
 
+// EventOverflowPolicy chooses which event is lost when the buffer for an
+// event type is full
+type EventOverflowPolicy int
+
+const (
+	EventOverflowDiscardOldest   EventOverflowPolicy = iota // Discard the oldest event of the type
+	EventOverflowDiscardNewest                              // Discard the incoming event
+	EventOverflowKeepFirstOfType                            // Keep the first event of each point
+)
+
+// DeadbandMode chooses how an analog or counter point decides that a change
+// from the value last reported in an event is large enough to report again
+type DeadbandMode int
+
+const (
+	DeadbandAbsolute    DeadbandMode = iota // The change exceeds Deadband
+	DeadbandPercent                         // The change exceeds Deadband percent of FullScale
+	DeadbandIntegrating                     // The change summed over time exceeds Deadband value-seconds
+)
+
 // EventMode controls event generation
 type EventMode int
 
 const (
-	EventModeDetect   EventMode = iota
+	EventModeDetect EventMode = iota
 	EventModeForce
 	EventModeSuppress
 )
@@ -146,4 +268,5 @@ const (
 	MeasurementTypeFrozenCounter
 	MeasurementTypeBinaryOutputStatus
 	MeasurementTypeAnalogOutputStatus
+	MeasurementTypeOctetString
 )
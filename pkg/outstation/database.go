@@ -1,6 +1,7 @@
 package outstation
 
 import (
+	"bytes"
 	"math"
 	"sync"
 
@@ -17,10 +18,14 @@ type Database struct {
 	frozenCounter []FrozenCounterPoint
 	binaryOutput  []BinaryOutputStatusPoint
 	analogOutput  []AnalogOutputStatusPoint
+	octetString   []OctetStringPoint
 
 	// Event buffer
 	eventBuffer *EventBuffer
 
+	// Clock used to timestamp events
+	clock *clock
+
 	mu sync.RWMutex
 }
 
@@ -32,6 +37,7 @@ type BinaryPoint struct {
 	staticVariation uint8
 	eventVariation  uint8
 	class           uint8
+	chatter         chatterFilter
 }
 
 // DoubleBitBinaryPoint stores a double-bit binary input point
@@ -40,6 +46,7 @@ type DoubleBitBinaryPoint struct {
 	staticVariation uint8
 	eventVariation  uint8
 	class           uint8
+	chatter         chatterFilter
 }
 
 // AnalogPoint stores an analog input point
@@ -49,6 +56,7 @@ type AnalogPoint struct {
 	eventVariation  uint8
 	class           uint8
 	deadband        float64
+	tracker         deadbandTracker
 }
 
 // CounterPoint stores a counter point
@@ -58,6 +66,7 @@ type CounterPoint struct {
 	eventVariation  uint8
 	class           uint8
 	deadband        uint32
+	tracker         deadbandTracker
 }
 
 // FrozenCounterPoint stores a frozen counter point
@@ -85,6 +94,18 @@ type AnalogOutputStatusPoint struct {
 	deadband        float64
 }
 
+// OctetStringPoint stores an octet string point
+type OctetStringPoint struct {
+	value types.OctetString
+	class uint8
+}
+
+// initialOctetString is the value of an octet string point before its first
+// update: a single zero byte, as DNP3 cannot report an empty string
+func initialOctetString() types.OctetString {
+	return types.OctetString{Value: []byte{0}}
+}
+
 // NewDatabase creates a new database from configuration
 func NewDatabase(config DatabaseConfig, eventBuffer *EventBuffer) *Database {
 	db := &Database{
@@ -95,7 +116,9 @@ func NewDatabase(config DatabaseConfig, eventBuffer *EventBuffer) *Database {
 		frozenCounter: make([]FrozenCounterPoint, len(config.FrozenCounter)),
 		binaryOutput:  make([]BinaryOutputStatusPoint, len(config.BinaryOutput)),
 		analogOutput:  make([]AnalogOutputStatusPoint, len(config.AnalogOutput)),
+		octetString:   make([]OctetStringPoint, len(config.OctetString)),
 		eventBuffer:   eventBuffer,
+		clock:         newClock(0),
 	}
 
 	// Initialize binary points
@@ -110,6 +133,8 @@ func NewDatabase(config DatabaseConfig, eventBuffer *EventBuffer) *Database {
 			eventVariation:  cfg.EventVariation,
 			class:           cfg.Class,
 		}
+		index := uint16(i)
+		db.binary[i].chatter = chatterFilter{config: cfg.ChatterFilter, onQuiet: func() { db.releaseBinaryChatter(index) }}
 	}
 
 	// Initialize analog points
@@ -124,6 +149,7 @@ func NewDatabase(config DatabaseConfig, eventBuffer *EventBuffer) *Database {
 			eventVariation:  cfg.EventVariation,
 			class:           cfg.Class,
 			deadband:        cfg.Deadband,
+			tracker:         deadbandTracker{mode: cfg.DeadbandMode, fullScale: cfg.FullScale},
 		}
 	}
 
@@ -139,112 +165,409 @@ func NewDatabase(config DatabaseConfig, eventBuffer *EventBuffer) *Database {
 			eventVariation:  cfg.EventVariation,
 			class:           cfg.Class,
 			deadband:        cfg.Deadband,
+			tracker:         deadbandTracker{mode: cfg.DeadbandMode, fullScale: float64(cfg.FullScale)},
 		}
 	}
 
-	// Initialize other point types similarly...
+	// Initialize double-bit binary points
+	for i, cfg := range config.DoubleBit {
+		db.doubleBit[i] = DoubleBitBinaryPoint{
+			value: types.DoubleBitBinary{
+				Value: types.DoubleBitIndeterminate,
+				Time:  types.ZeroTime(),
+			},
+			staticVariation: cfg.StaticVariation,
+			eventVariation:  cfg.EventVariation,
+			class:           cfg.Class,
+		}
+		index := uint16(i)
+		db.doubleBit[i].chatter = chatterFilter{config: cfg.ChatterFilter, onQuiet: func() { db.releaseDoubleBitChatter(index) }}
+	}
+
+	// Initialize frozen counter points
+	for i, cfg := range config.FrozenCounter {
+		db.frozenCounter[i] = FrozenCounterPoint{
+			value:           types.FrozenCounter{Time: types.ZeroTime()},
+			staticVariation: cfg.StaticVariation,
+			eventVariation:  cfg.EventVariation,
+			class:           cfg.Class,
+		}
+	}
+
+	// Initialize binary output status points
+	for i, cfg := range config.BinaryOutput {
+		db.binaryOutput[i] = BinaryOutputStatusPoint{
+			value:           types.BinaryOutputStatus{Time: types.ZeroTime()},
+			staticVariation: cfg.StaticVariation,
+			eventVariation:  cfg.EventVariation,
+			class:           cfg.Class,
+		}
+	}
+
+	// Initialize analog output status points
+	for i, cfg := range config.AnalogOutput {
+		db.analogOutput[i] = AnalogOutputStatusPoint{
+			value:           types.AnalogOutputStatus{Time: types.ZeroTime()},
+			staticVariation: cfg.StaticVariation,
+			eventVariation:  cfg.EventVariation,
+			class:           cfg.Class,
+			deadband:        cfg.Deadband,
+		}
+	}
+
+	// Initialize octet string points
+	for i, cfg := range config.OctetString {
+		db.octetString[i] = OctetStringPoint{value: initialOctetString(), class: cfg.Class}
+	}
 
 	return db
 }
 
+// applyUpdates applies a batch of updates while holding the lock once, so
+// readers never observe a partially applied batch
+func (db *Database) applyUpdates(updates []measurementUpdate) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, u := range updates {
+		db.update(u.measurement, u.index, u.mode)
+	}
+}
+
+// update applies a single measurement based on its type (caller holds the lock)
+func (db *Database) update(meas interface{}, index uint16, mode EventMode) bool {
+	switch v := meas.(type) {
+	case types.Binary:
+		return db.updateBinary(index, v, mode)
+	case types.DoubleBitBinary:
+		return db.updateDoubleBitBinary(index, v, mode)
+	case types.Analog:
+		return db.updateAnalog(index, v, mode)
+	case types.Counter:
+		return db.updateCounter(index, v, mode)
+	case types.FrozenCounter:
+		return db.updateFrozenCounter(index, v, mode)
+	case types.BinaryOutputStatus:
+		return db.updateBinaryOutputStatus(index, v, mode)
+	case types.AnalogOutputStatus:
+		return db.updateAnalogOutputStatus(index, v, mode)
+	case types.OctetString:
+		return db.updateOctetString(index, v, mode)
+	default:
+		return false
+	}
+}
+
 // UpdateBinary updates a binary point
 func (db *Database) UpdateBinary(index uint16, value types.Binary, mode EventMode) {
 	db.mu.Lock()
 	defer db.mu.Unlock()
+	db.updateBinary(index, value, mode)
+}
 
+func (db *Database) updateBinary(index uint16, value types.Binary, mode EventMode) bool {
 	if int(index) >= len(db.binary) {
-		return
+		return false
 	}
 
 	point := &db.binary[index]
-	oldValue := point.value.Value
+	var transition bool
+	value.Flags, transition = point.chatter.filter(point.value.Value != value.Value, value.Flags)
+	changed := transition || point.value.Flags != value.Flags
 
 	// Update value
 	point.value = value
 
-	// Check if event should be generated
-	shouldGenerate := false
-	switch mode {
-	case EventModeForce:
-		shouldGenerate = true
-	case EventModeDetect:
-		shouldGenerate = (oldValue != value.Value)
-	case EventModeSuppress:
-		shouldGenerate = false
-	}
-
-	if shouldGenerate && point.class > 0 {
-		db.eventBuffer.AddBinaryEvent(index, value, point.class)
+	if shouldGenerateEvent(mode, changed) && point.class > 0 {
+		// Events carry the time of occurrence
+		if !value.Time.IsValid() {
+			value.Time, value.TimeQuality = db.clock.stamp()
+		}
+		db.eventBuffer.AddBinaryEvent(index, value, point.class, point.eventVariation)
 	}
+	return true
 }
 
 // UpdateAnalog updates an analog point
 func (db *Database) UpdateAnalog(index uint16, value types.Analog, mode EventMode) {
 	db.mu.Lock()
 	defer db.mu.Unlock()
+	db.updateAnalog(index, value, mode)
+}
 
+func (db *Database) updateAnalog(index uint16, value types.Analog, mode EventMode) bool {
 	if int(index) >= len(db.analog) {
-		return
+		return false
 	}
 
 	point := &db.analog[index]
-	oldValue := point.value.Value
+	exceeded := point.tracker.exceeded(value.Value, point.deadband, db.sampleTime(value.Time))
+	changed := exceeded || point.value.Flags != value.Flags
 
 	// Update value
 	point.value = value
 
-	// Check if event should be generated
-	shouldGenerate := false
-	switch mode {
-	case EventModeForce:
-		shouldGenerate = true
-	case EventModeDetect:
-		// Check deadband
-		shouldGenerate = math.Abs(oldValue-value.Value) > point.deadband
-	case EventModeSuppress:
-		shouldGenerate = false
-	}
-
-	if shouldGenerate && point.class > 0 {
-		db.eventBuffer.AddAnalogEvent(index, value, point.class)
+	if shouldGenerateEvent(mode, changed) && point.class > 0 {
+		point.tracker.report(value.Value)
+		if !value.Time.IsValid() {
+			value.Time, value.TimeQuality = db.clock.stamp()
+		}
+		db.eventBuffer.AddAnalogEvent(index, value, point.class, point.eventVariation)
 	}
+	return true
 }
 
 // UpdateCounter updates a counter point
 func (db *Database) UpdateCounter(index uint16, value types.Counter, mode EventMode) {
 	db.mu.Lock()
 	defer db.mu.Unlock()
+	db.updateCounter(index, value, mode)
+}
 
+func (db *Database) updateCounter(index uint16, value types.Counter, mode EventMode) bool {
 	if int(index) >= len(db.counter) {
-		return
+		return false
 	}
 
 	point := &db.counter[index]
-	oldValue := point.value.Value
+	exceeded := point.tracker.exceeded(float64(value.Value), float64(point.deadband), db.sampleTime(value.Time))
+	changed := exceeded || point.value.Flags != value.Flags
 
 	// Update value
 	point.value = value
 
-	// Check if event should be generated
-	shouldGenerate := false
-	switch mode {
-	case EventModeForce:
-		shouldGenerate = true
-	case EventModeDetect:
-		// Check deadband
-		var diff uint32
-		if value.Value > oldValue {
-			diff = value.Value - oldValue
-		} else {
-			diff = oldValue - value.Value
+	if shouldGenerateEvent(mode, changed) && point.class > 0 {
+		point.tracker.report(float64(value.Value))
+		if !value.Time.IsValid() {
+			value.Time, value.TimeQuality = db.clock.stamp()
+		}
+		db.eventBuffer.AddCounterEvent(index, value, point.class, point.eventVariation)
+	}
+	return true
+}
+
+// UpdateDoubleBitBinary updates a double-bit binary point
+func (db *Database) UpdateDoubleBitBinary(index uint16, value types.DoubleBitBinary, mode EventMode) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.updateDoubleBitBinary(index, value, mode)
+}
+
+func (db *Database) updateDoubleBitBinary(index uint16, value types.DoubleBitBinary, mode EventMode) bool {
+	if int(index) >= len(db.doubleBit) {
+		return false
+	}
+
+	point := &db.doubleBit[index]
+	var transition bool
+	value.Flags, transition = point.chatter.filter(point.value.Value != value.Value, value.Flags)
+	changed := transition || point.value.Flags != value.Flags
+
+	// Update value
+	point.value = value
+
+	if shouldGenerateEvent(mode, changed) && point.class > 0 {
+		if !value.Time.IsValid() {
+			value.Time, value.TimeQuality = db.clock.stamp()
+		}
+		db.eventBuffer.AddDoubleBitBinaryEvent(index, value, point.class, point.eventVariation)
+	}
+	return true
+}
+
+// UpdateFrozenCounter updates a frozen counter point
+func (db *Database) UpdateFrozenCounter(index uint16, value types.FrozenCounter, mode EventMode) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.updateFrozenCounter(index, value, mode)
+}
+
+func (db *Database) updateFrozenCounter(index uint16, value types.FrozenCounter, mode EventMode) bool {
+	if int(index) >= len(db.frozenCounter) {
+		return false
+	}
+
+	point := &db.frozenCounter[index]
+	changed := point.value.Value != value.Value || point.value.Flags != value.Flags
+
+	// Update value
+	point.value = value
+
+	if shouldGenerateEvent(mode, changed) && point.class > 0 {
+		if !value.Time.IsValid() {
+			value.Time, value.TimeQuality = db.clock.stamp()
+		}
+		db.eventBuffer.AddFrozenCounterEvent(index, value, point.class, point.eventVariation)
+	}
+	return true
+}
+
+// reset returns every point to its initial value, e.g. on cold restart
+func (db *Database) reset() {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for i := range db.binary {
+		db.binary[i].value = types.Binary{}
+		db.binary[i].chatter.reset()
+	}
+	for i := range db.doubleBit {
+		db.doubleBit[i].value = types.DoubleBitBinary{Value: types.DoubleBitIndeterminate}
+		db.doubleBit[i].chatter.reset()
+	}
+	for i := range db.analog {
+		db.analog[i].value = types.Analog{}
+		db.analog[i].tracker.reset()
+	}
+	for i := range db.counter {
+		db.counter[i].value = types.Counter{}
+		db.counter[i].tracker.reset()
+	}
+	for i := range db.frozenCounter {
+		db.frozenCounter[i].value = types.FrozenCounter{}
+	}
+	for i := range db.binaryOutput {
+		db.binaryOutput[i].value = types.BinaryOutputStatus{}
+	}
+	for i := range db.analogOutput {
+		db.analogOutput[i].value = types.AnalogOutputStatus{}
+	}
+	for i := range db.octetString {
+		db.octetString[i].value = initialOctetString()
+	}
+}
+
+// freezeCounters copies counters into the frozen counters of the same index,
+// stamped with the freeze time, and clears the frozen counters if requested.
+// A counter without a frozen counter of its index is neither frozen nor
+// cleared. Each frozen counter reports a frozen counter event.
+func (db *Database) freezeCounters(indices []int, clear bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	at, quality := db.clock.stamp()
+
+	for _, i := range indices {
+		if i >= len(db.counter) {
+			continue
+		}
+
+		counter := &db.counter[i]
+		frozen := db.updateFrozenCounter(uint16(i), types.FrozenCounter{
+			Value:       counter.value.Value,
+			Flags:       counter.value.Flags,
+			Time:        at,
+			TimeQuality: quality,
+		}, EventModeForce)
+
+		// Only a count that was frozen may be cleared; the deadband then
+		// measures from zero
+		if clear && frozen {
+			counter.value.Value = 0
+			counter.tracker.reset()
 		}
-		shouldGenerate = diff > point.deadband
-	case EventModeSuppress:
-		shouldGenerate = false
 	}
+}
+
+// UpdateBinaryOutputStatus updates a binary output status point
+func (db *Database) UpdateBinaryOutputStatus(index uint16, value types.BinaryOutputStatus, mode EventMode) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.updateBinaryOutputStatus(index, value, mode)
+}
 
-	if shouldGenerate && point.class > 0 {
-		db.eventBuffer.AddCounterEvent(index, value, point.class)
+func (db *Database) updateBinaryOutputStatus(index uint16, value types.BinaryOutputStatus, mode EventMode) bool {
+	if int(index) >= len(db.binaryOutput) {
+		return false
+	}
+
+	point := &db.binaryOutput[index]
+	changed := point.value.Value != value.Value || point.value.Flags != value.Flags
+
+	// Update value
+	point.value = value
+
+	if shouldGenerateEvent(mode, changed) && point.class > 0 {
+		if !value.Time.IsValid() {
+			value.Time, value.TimeQuality = db.clock.stamp()
+		}
+		db.eventBuffer.AddBinaryOutputStatusEvent(index, value, point.class, point.eventVariation)
+	}
+	return true
+}
+
+// UpdateAnalogOutputStatus updates an analog output status point
+func (db *Database) UpdateAnalogOutputStatus(index uint16, value types.AnalogOutputStatus, mode EventMode) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.updateAnalogOutputStatus(index, value, mode)
+}
+
+func (db *Database) updateAnalogOutputStatus(index uint16, value types.AnalogOutputStatus, mode EventMode) bool {
+	if int(index) >= len(db.analogOutput) {
+		return false
+	}
+
+	point := &db.analogOutput[index]
+	changed := math.Abs(point.value.Value-value.Value) > point.deadband || point.value.Flags != value.Flags
+
+	// Update value
+	point.value = value
+
+	if shouldGenerateEvent(mode, changed) && point.class > 0 {
+		if !value.Time.IsValid() {
+			value.Time, value.TimeQuality = db.clock.stamp()
+		}
+		db.eventBuffer.AddAnalogOutputStatusEvent(index, value, point.class, point.eventVariation)
+	}
+	return true
+}
+
+// UpdateOctetString updates an octet string point
+func (db *Database) UpdateOctetString(index uint16, value types.OctetString, mode EventMode) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.updateOctetString(index, value, mode)
+}
+
+// updateOctetString stores a copy of the string. Strings DNP3 cannot carry
+// (empty or longer than 255 bytes) are rejected.
+func (db *Database) updateOctetString(index uint16, value types.OctetString, mode EventMode) bool {
+	if int(index) >= len(db.octetString) || len(value.Value) == 0 || len(value.Value) > types.MaxOctetStringLength {
+		return false
+	}
+
+	point := &db.octetString[index]
+	changed := !bytes.Equal(point.value.Value, value.Value)
+
+	// Update value
+	value.Value = append([]byte(nil), value.Value...)
+	point.value = value
+
+	if shouldGenerateEvent(mode, changed) && point.class > 0 {
+		db.eventBuffer.AddOctetStringEvent(index, value, point.class)
+	}
+	return true
+}
+
+// sampleTime returns the time of an update for deadband integration: its own
+// timestamp if it has one, otherwise the outstation time now
+func (db *Database) sampleTime(t types.DNP3Time) types.DNP3Time {
+	if t.IsValid() {
+		return t
+	}
+	return db.clock.now()
+}
+
+// shouldGenerateEvent decides whether an update produces an event
+func shouldGenerateEvent(mode EventMode, changed bool) bool {
+	switch mode {
+	case EventModeForce:
+		return true
+	case EventModeDetect:
+		return changed
+	default:
+		return false
 	}
 }
 
@@ -304,3 +627,63 @@ func (db *Database) CounterCount() int {
 	defer db.mu.RUnlock()
 	return len(db.counter)
 }
+
+// GetDoubleBitBinary returns a double-bit binary point value
+func (db *Database) GetDoubleBitBinary(index uint16) (types.DoubleBitBinary, bool) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if int(index) >= len(db.doubleBit) {
+		return types.DoubleBitBinary{}, false
+	}
+
+	return db.doubleBit[index].value, true
+}
+
+// GetFrozenCounter returns a frozen counter point value
+func (db *Database) GetFrozenCounter(index uint16) (types.FrozenCounter, bool) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if int(index) >= len(db.frozenCounter) {
+		return types.FrozenCounter{}, false
+	}
+
+	return db.frozenCounter[index].value, true
+}
+
+// GetBinaryOutputStatus returns a binary output status point value
+func (db *Database) GetBinaryOutputStatus(index uint16) (types.BinaryOutputStatus, bool) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if int(index) >= len(db.binaryOutput) {
+		return types.BinaryOutputStatus{}, false
+	}
+
+	return db.binaryOutput[index].value, true
+}
+
+// GetAnalogOutputStatus returns an analog output status point value
+func (db *Database) GetAnalogOutputStatus(index uint16) (types.AnalogOutputStatus, bool) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if int(index) >= len(db.analogOutput) {
+		return types.AnalogOutputStatus{}, false
+	}
+
+	return db.analogOutput[index].value, true
+}
+
+// GetOctetString returns an octet string point value
+func (db *Database) GetOctetString(index uint16) (types.OctetString, bool) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if int(index) >= len(db.octetString) {
+		return types.OctetString{}, false
+	}
+
+	return db.octetString[index].value, true
+}
@@ -0,0 +1,172 @@
+package outstation
+
+import (
+	"testing"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// staticHeader describes one object header of a static response
+type staticHeader struct {
+	group     uint8
+	variation uint8
+	objects   [][]byte
+}
+
+// parseStaticHeaders decodes start-stop static headers from response objects
+func parseStaticHeaders(t *testing.T, objects []byte) []staticHeader {
+	t.Helper()
+
+	var headers []staticHeader
+	parser := app.NewParser(objects)
+	for parser.HasMore() {
+		header, err := parser.ReadObjectHeader()
+		if err != nil {
+			t.Fatalf("ReadObjectHeader failed: %v", err)
+		}
+		size := app.GetObjectSize(header.Group, header.Variation)
+		if size == 0 {
+			t.Fatalf("G%dV%d: unexpected variable size object", header.Group, header.Variation)
+		}
+
+		sh := staticHeader{group: header.Group, variation: header.Variation}
+		for i := uint32(0); i < app.GetCount(header.Range); i++ {
+			data, err := parser.ReadBytes(size)
+			if err != nil {
+				t.Fatalf("ReadBytes failed: %v", err)
+			}
+			sh.objects = append(sh.objects, data)
+		}
+		headers = append(headers, sh)
+	}
+	return headers
+}
+
+func TestEventsForOutputAndFrozenPoints(t *testing.T) {
+	config := allTypesConfig()
+	config.Database.DoubleBit[0].EventVariation = app.DoubleBitBinaryEventWithTime
+	config.Database.AnalogOutput[0].EventVariation = app.AnalogOutputEventFloatNoTime
+	h := newTestHarness(t, config)
+	db := h.outstation.database
+
+	db.UpdateDoubleBitBinary(0, types.DoubleBitBinary{Value: types.DoubleBitOn, Flags: types.FlagOnline, Time: 500}, EventModeDetect)
+	db.UpdateBinaryOutputStatus(0, types.BinaryOutputStatus{Value: true, Flags: types.FlagOnline}, EventModeDetect)
+	db.UpdateFrozenCounter(0, types.FrozenCounter{Value: 9, Flags: types.FlagOnline}, EventModeDetect)
+	db.UpdateAnalogOutputStatus(0, types.AnalogOutputStatus{Value: 3.5, Flags: types.FlagOnline}, EventModeDetect)
+
+	resp := h.request(eventPoll(0))
+
+	want := []eventHeader{
+		{app.GroupDoubleBitBinaryEvent, app.DoubleBitBinaryEventWithTime, []uint32{0}},
+		{app.GroupBinaryOutputEvent, app.BinaryOutputEventWithoutTime, []uint32{0}},
+		{app.GroupFrozenCounterEvent, app.FrozenCounterEvent32BitWithFlag, []uint32{0}},
+		{app.GroupAnalogOutputEvent, app.AnalogOutputEventFloatNoTime, []uint32{0}},
+	}
+	got := parseEventHeaders(t, resp.Objects)
+	if len(got) != len(want) {
+		t.Fatalf("Headers: got %d, want %d (%+v)", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i].group != want[i].group || got[i].variation != want[i].variation {
+			t.Errorf("Header %d: got G%dV%d, want G%dV%d", i, got[i].group, got[i].variation, want[i].group, want[i].variation)
+		}
+	}
+
+	// Double-bit state is carried in the top two bits of the flags octet
+	parser := app.NewParser(resp.Objects)
+	parser.ReadObjectHeader()
+	parser.ReadIndex(2)
+	event, _ := parser.ReadBytes(7)
+	if event[0] != 0x81 {
+		t.Errorf("Double-bit event flags: got 0x%02X, want 0x81", event[0])
+	}
+	if ts := app.ParseTime48(event[1:]); ts != 500 {
+		t.Errorf("Double-bit event time: got %d, want 500", ts)
+	}
+}
+
+func TestChangeDetection(t *testing.T) {
+	tests := []struct {
+		name   string
+		update func(db *Database)
+		want   int
+	}{
+		{"same double-bit state", func(db *Database) {
+			db.UpdateDoubleBitBinary(0, types.DoubleBitBinary{Value: types.DoubleBitIndeterminate}, EventModeDetect)
+		}, 0},
+		{"double-bit flags change", func(db *Database) {
+			db.UpdateDoubleBitBinary(0, types.DoubleBitBinary{Value: types.DoubleBitIndeterminate, Flags: types.FlagOnline}, EventModeDetect)
+		}, 1},
+		{"binary output unchanged", func(db *Database) {
+			db.UpdateBinaryOutputStatus(0, types.BinaryOutputStatus{}, EventModeDetect)
+		}, 0},
+		{"frozen counter changed", func(db *Database) {
+			db.UpdateFrozenCounter(0, types.FrozenCounter{Value: 1}, EventModeDetect)
+		}, 1},
+		{"analog output within deadband", func(db *Database) {
+			db.UpdateAnalogOutputStatus(0, types.AnalogOutputStatus{Value: 0.5}, EventModeDetect)
+		}, 0},
+		{"analog output beyond deadband", func(db *Database) {
+			db.UpdateAnalogOutputStatus(0, types.AnalogOutputStatus{Value: 1.5}, EventModeDetect)
+		}, 1},
+		{"forced", func(db *Database) {
+			db.UpdateBinaryOutputStatus(0, types.BinaryOutputStatus{}, EventModeForce)
+		}, 1},
+		{"suppressed", func(db *Database) {
+			db.UpdateFrozenCounter(0, types.FrozenCounter{Value: 1}, EventModeSuppress)
+		}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := allTypesConfig()
+			config.Database.AnalogOutput[0].Deadband = 1.0
+			eb := NewEventBuffer(EventBufferConfig{})
+			db := NewDatabase(config.Database, eb)
+
+			tt.update(db)
+			if got := eb.GetClass1Count(); got != tt.want {
+				t.Errorf("Events: got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStaticReadOutputAndFrozenPoints(t *testing.T) {
+	h := newTestHarness(t, allTypesConfig())
+	db := h.outstation.database
+
+	db.UpdateDoubleBitBinary(0, types.DoubleBitBinary{Value: types.DoubleBitOff, Flags: types.FlagOnline}, EventModeSuppress)
+	db.UpdateBinaryOutputStatus(0, types.BinaryOutputStatus{Value: true, Flags: types.FlagOnline}, EventModeSuppress)
+	db.UpdateFrozenCounter(0, types.FrozenCounter{Value: 77, Flags: types.FlagOnline}, EventModeSuppress)
+	db.UpdateAnalogOutputStatus(0, types.AnalogOutputStatus{Value: -4, Flags: types.FlagOnline}, EventModeSuppress)
+
+	resp := h.request(app.BuildReadRequest(0, app.BuildClassRead(app.Class0)))
+
+	found := make(map[uint8]staticHeader)
+	for _, sh := range parseStaticHeaders(t, resp.Objects) {
+		found[sh.group] = sh
+	}
+
+	if sh, ok := found[app.GroupDoubleBitBinaryInput]; !ok || sh.objects[0][0] != 0x41 {
+		t.Errorf("G3: got %+v, want flags 0x41", sh)
+	}
+	if sh, ok := found[app.GroupBinaryOutput]; !ok || sh.objects[0][0] != app.FlagOnline|app.FlagState {
+		t.Errorf("G10: got %+v, want flags 0x81", sh)
+	}
+	if sh, ok := found[app.GroupFrozenCounter]; !ok || app.ParseCounter32Bit(sh.objects[0]).Value != 77 {
+		t.Errorf("G21: got %+v, want 77", sh)
+	}
+	if sh, ok := found[app.GroupAnalogOutputStatus]; !ok || app.ParseAnalogInputFloat(sh.objects[0]).Value != float32(-4) {
+		t.Errorf("G40: got %+v, want -4", sh)
+	}
+
+	// A specific group read returns only that group in the requested variation
+	resp = h.request(app.BuildReadRequest(1, app.BuildRangeRead(app.GroupAnalogOutputStatus, app.AnalogOutputStatusDouble, 0, 0)))
+	headers := parseStaticHeaders(t, resp.Objects)
+	if len(headers) != 1 || headers[0].variation != app.AnalogOutputStatusDouble ||
+		app.ParseAnalogInputDouble(headers[0].objects[0]).Value != -4.0 {
+		t.Errorf("G40V4 read: got %+v", headers)
+	}
+}
@@ -0,0 +1,116 @@
+package outstation
+
+import (
+	"math"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// analogDeadbandType reads the analog input deadbands (G34). It is not part
+// of Class 0, so it is kept out of staticTypes.
+var analogDeadbandType = &staticType{
+	group:            app.GroupAnalogInputDeadband,
+	defaultVariation: app.AnalogDeadbandFloat,
+	variations:       []uint8{app.AnalogDeadband16Bit, app.AnalogDeadband32Bit, app.AnalogDeadbandFloat},
+	count:            func(db *Database) int { return len(db.analog) },
+	staticVariation:  func(db *Database, i int) uint8 { return app.AnalogDeadbandAny },
+	serialize: func(db *Database, i int, variation uint8) []byte {
+		return app.AnalogDeadband{Value: db.analog[i].deadband}.Serialize(variation)
+	},
+}
+
+// findReadType returns the type answering a READ of an object group: the
+// static groups and the analog input deadbands
+func findReadType(group uint8) *staticType {
+	if group == app.GroupAnalogInputDeadband {
+		return analogDeadbandType
+	}
+	return findStaticType(group)
+}
+
+// writeDeadbands applies a WRITE of analog input deadbands to the points,
+// taking effect for the next update. Returns IIN2 bits for any error.
+func (o *outstation) writeDeadbands(header *app.ObjectHeader, parser *app.Parser) uint8 {
+	size := app.GetObjectSize(header.Group, header.Variation)
+	if size == 0 {
+		o.logger.Debug("Outstation %s: Unsupported deadband variation G34V%d", o.config.ID, header.Variation)
+		return types.IIN2ObjectUnknown
+	}
+
+	count := app.GetCount(header.Range)
+	var iin2 uint8
+
+	o.database.mu.Lock()
+	defer o.database.mu.Unlock()
+
+	for i := uint32(0); i < count; i++ {
+		var index uint32
+		switch r := header.Range.(type) {
+		case app.StartStopRange:
+			index = r.Start + i
+		case app.IndexPrefixRange:
+			var err error
+			if index, err = parser.ReadIndex(r.IndexSize); err != nil {
+				return iin2 | types.IIN2ParameterError
+			}
+		default:
+			return iin2 | types.IIN2ParameterError
+		}
+
+		data, err := parser.ReadBytes(size)
+		if err != nil {
+			return iin2 | types.IIN2ParameterError
+		}
+
+		deadband := app.ParseAnalogDeadband(header.Variation, data).Value
+		if int64(index) >= int64(len(o.database.analog)) || deadband < 0 || math.IsNaN(deadband) {
+			iin2 |= types.IIN2ParameterError
+			continue
+		}
+		o.database.analog[index].deadband = deadband
+		o.logger.Debug("Outstation %s: Analog %d deadband set to %g", o.config.ID, index, deadband)
+	}
+
+	return iin2
+}
+
+// deadbandTracker detects changes of an analog or counter point against the
+// value last reported in an event, so slow drifts are reported eventually
+type deadbandTracker struct {
+	mode      DeadbandMode
+	fullScale float64
+	reported  float64        // Value of the last event, or the initial value
+	last      float64        // Value held since lastTime
+	lastTime  types.DNP3Time // Time of the last update, zero before the first
+	integral  float64        // Deviation from reported summed since, in value-seconds
+}
+
+// exceeded records an update of the point at the given time and returns
+// whether it has moved from the reported value by more than the deadband
+func (t *deadbandTracker) exceeded(value, deadband float64, at types.DNP3Time) bool {
+	switch t.mode {
+	case DeadbandPercent:
+		return math.Abs(value-t.reported) > deadband/100*t.fullScale
+	case DeadbandIntegrating:
+		// The previous value was held until now; opposite deviations cancel
+		if t.lastTime.IsValid() && at > t.lastTime {
+			t.integral += (t.last - t.reported) * float64(at-t.lastTime) / 1000
+		}
+		t.last, t.lastTime = value, at
+		return math.Abs(t.integral) > deadband
+	default:
+		return math.Abs(value-t.reported) > deadband
+	}
+}
+
+// report makes value the reference for later changes, after an event
+func (t *deadbandTracker) report(value float64) {
+	t.reported = value
+	t.integral = 0
+}
+
+// reset forgets the point's history, as after a cold restart
+func (t *deadbandTracker) reset() {
+	*t = deadbandTracker{mode: t.mode, fullScale: t.fullScale}
+}
@@ -0,0 +1,173 @@
+package outstation
+
+import (
+	"testing"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// deadbandConfig has two analog inputs with deadbands 0.5 and 2.6
+func deadbandConfig() OutstationConfig {
+	config := eventTestConfig()
+	config.Database.Analog[0].Deadband = 0.5
+	config.Database.Analog = append(config.Database.Analog,
+		AnalogPointConfig{StaticVariation: 5, EventVariation: app.AnalogInputEventFloatNoTime, Class: 1, Deadband: 2.6})
+	return config
+}
+
+func TestReadDeadbands(t *testing.T) {
+	tests := []struct {
+		name      string
+		variation uint8
+		want      []float64
+	}{
+		{"default variation", app.AnalogDeadbandAny, []float64{0.5, 2.6}},
+		{"16-bit", app.AnalogDeadband16Bit, []float64{1, 3}},
+		{"32-bit", app.AnalogDeadband32Bit, []float64{1, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHarness(t, deadbandConfig())
+
+			resp := h.request(app.BuildReadRequest(0, app.BuildAllObjects(app.GroupAnalogInputDeadband, tt.variation)))
+			if resp.IIN.IIN2 != 0 {
+				t.Fatalf("IIN2: got 0x%02X, want 0", resp.IIN.IIN2)
+			}
+			headers := parseStaticHeaders(t, resp.Objects)
+			if len(headers) != 1 || headers[0].group != app.GroupAnalogInputDeadband || len(headers[0].objects) != len(tt.want) {
+				t.Fatalf("Expected one G34 header with %d objects, got %+v", len(tt.want), headers)
+			}
+			for i, data := range headers[0].objects {
+				if got := app.ParseAnalogDeadband(headers[0].variation, data).Value; float32(got) != float32(tt.want[i]) {
+					t.Errorf("Deadband %d: got %g, want %g", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWriteDeadbandsAppliesToUpdates(t *testing.T) {
+	h := newTestHarness(t, deadbandConfig())
+	db := h.outstation.database
+
+	objects := app.BuildAnalogDeadbands(app.AnalogDeadband32Bit, []types.IndexedDeadband{{Index: 0, Value: 10}})
+	resp := h.request(app.BuildWriteRequest(0, objects))
+	if resp.IIN.IIN2 != 0 {
+		t.Fatalf("IIN2: got 0x%02X, want 0", resp.IIN.IIN2)
+	}
+	if db.analog[0].deadband != 10 || db.analog[1].deadband != 2.6 {
+		t.Fatalf("Deadbands: got %g/%g, want 10/2.6", db.analog[0].deadband, db.analog[1].deadband)
+	}
+
+	// The first update brings the point online
+	eb := h.outstation.eventBuffer
+	db.UpdateAnalog(0, types.Analog{Value: 0, Flags: types.FlagOnline}, EventModeDetect)
+	base := eb.GetClass1Count()
+
+	db.UpdateAnalog(0, types.Analog{Value: 5, Flags: types.FlagOnline}, EventModeDetect)
+	if eb.GetClass1Count() != base {
+		t.Error("A change within the written deadband should not generate an event")
+	}
+	db.UpdateAnalog(0, types.Analog{Value: 11, Flags: types.FlagOnline}, EventModeDetect)
+	if eb.GetClass1Count() != base+1 {
+		t.Errorf("Class 1 events: got %d, want one more beyond the deadband", eb.GetClass1Count())
+	}
+}
+
+func TestWriteDeadbandsInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		objects  []byte
+		wantIIN2 uint8
+	}{
+		{"index out of range", app.BuildAnalogDeadbands(app.AnalogDeadbandFloat, []types.IndexedDeadband{{Index: 5, Value: 1}}), types.IIN2ParameterError},
+		{"negative deadband", app.BuildAnalogDeadbands(app.AnalogDeadbandFloat, []types.IndexedDeadband{{Index: 0, Value: -1}}), types.IIN2ParameterError},
+		{"unknown variation", app.BuildRangeRead(app.GroupAnalogInputDeadband, 4, 0, 0), types.IIN2ObjectUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHarness(t, deadbandConfig())
+
+			resp := h.request(app.BuildWriteRequest(0, tt.objects))
+			if resp.IIN.IIN2 != tt.wantIIN2 {
+				t.Errorf("IIN2: got 0x%02X, want 0x%02X", resp.IIN.IIN2, tt.wantIIN2)
+			}
+			if h.outstation.database.analog[0].deadband != 0.5 {
+				t.Errorf("Deadband changed to %g by an invalid WRITE", h.outstation.database.analog[0].deadband)
+			}
+		})
+	}
+}
+
+// analogEvents returns the values of the buffered analog events in order
+func analogEvents(eb *EventBuffer) []float64 {
+	var got []float64
+	for _, e := range eb.SelectEvents(app.ClassAll, false) {
+		if v, ok := e.Value.(types.Analog); ok {
+			got = append(got, v.Value)
+		}
+	}
+	eb.Unselect(false)
+	return got
+}
+
+func TestDeadbandModes(t *testing.T) {
+	// Updates one second apart, after the point comes online at 0
+	tests := []struct {
+		name      string
+		point     AnalogPointConfig
+		updates   []float64
+		wantValue []float64
+	}{
+		{"absolute reports a slow drift", AnalogPointConfig{Deadband: 1}, []float64{0.6, 1.2, 1.8, 2.4}, []float64{0, 1.2, 2.4}},
+		{"percent of full scale", AnalogPointConfig{Deadband: 5, DeadbandMode: DeadbandPercent, FullScale: 200}, []float64{6, 11, 15, 22}, []float64{0, 11, 22}},
+		{"integrating", AnalogPointConfig{Deadband: 10, DeadbandMode: DeadbandIntegrating}, []float64{4, 4, 4, 4}, []float64{0, 4}},
+		{"integrating cancels noise", AnalogPointConfig{Deadband: 3, DeadbandMode: DeadbandIntegrating}, []float64{2, -2, 2, -2}, []float64{0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.point.Class = 1
+			tt.point.EventVariation = app.AnalogInputEventFloatNoTime
+			h := newTestHarness(t, OutstationConfig{Database: DatabaseConfig{Analog: []AnalogPointConfig{tt.point}}})
+			db := h.outstation.database
+
+			at := types.DNP3Time(1000000)
+			db.UpdateAnalog(0, types.Analog{Value: 0, Flags: types.FlagOnline, Time: at}, EventModeDetect)
+			for _, v := range tt.updates {
+				at += 1000
+				db.UpdateAnalog(0, types.Analog{Value: v, Flags: types.FlagOnline, Time: at}, EventModeDetect)
+			}
+
+			got := analogEvents(h.outstation.eventBuffer)
+			if len(got) != len(tt.wantValue) {
+				t.Fatalf("Events: got %v, want %v", got, tt.wantValue)
+			}
+			for i := range got {
+				if got[i] != tt.wantValue[i] {
+					t.Fatalf("Events: got %v, want %v", got, tt.wantValue)
+				}
+			}
+		})
+	}
+}
+
+func TestCounterPercentDeadband(t *testing.T) {
+	config := OutstationConfig{Database: DatabaseConfig{Counter: []CounterPointConfig{
+		{EventVariation: app.CounterEvent32BitWithFlag, Class: 3, Deadband: 10, DeadbandMode: DeadbandPercent, FullScale: 1000},
+	}}}
+	h := newTestHarness(t, config)
+	db := h.outstation.database
+	eb := h.outstation.eventBuffer
+
+	db.UpdateCounter(0, types.Counter{Value: 0, Flags: types.FlagOnline}, EventModeDetect)
+	for _, v := range []uint32{50, 100, 150} {
+		db.UpdateCounter(0, types.Counter{Value: v, Flags: types.FlagOnline}, EventModeDetect)
+	}
+	if eb.GetClass3Count() != 2 {
+		t.Errorf("Class 3 events: got %d, want 2 (online, then 150 beyond 10%% of 1000)", eb.GetClass3Count())
+	}
+}
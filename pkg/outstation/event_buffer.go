@@ -4,25 +4,81 @@ import (
 	"container/list"
 	"sync"
 
+	"avaneesh/dnp3-go/pkg/app"
 	"avaneesh/dnp3-go/pkg/types"
 )
 
-// EventBuffer manages event storage per class
+// defaultMaxEvents is the capacity of an event type left unconfigured
+const defaultMaxEvents = 100
+
+// EventBuffer manages event storage per class, limiting how many events of
+// each type it holds
 type EventBuffer struct {
 	class1 *list.List
 	class2 *list.List
 	class3 *list.List
 
-	maxSize uint
-	mu      sync.RWMutex
+	limits     [numEventTypes]uint
+	policy     EventOverflowPolicy
+	counts     [numEventTypes]uint
+	highWater  [numEventTypes]uint
+	discarded  [numEventTypes]uint64
+	overflowed [numEventTypes]bool // Overflow already reported for the type
+
+	nextSeq  uint64 // Insertion counter used to report events in order across classes
+	overflow bool   // An event was lost since the master last confirmed events (IIN2.3)
+	mu       sync.RWMutex
+
+	// onOverflow is called, outside the buffer lock, when a type first loses
+	// an event after the overflow was last cleared
+	onOverflow func(EventType)
+}
+
+// EventBufferConfig sets the capacity of the event buffer per event type.
+// A zero capacity uses the default of 100 events.
+type EventBufferConfig struct {
+	MaxBinary             uint
+	MaxDoubleBitBinary    uint
+	MaxAnalog             uint
+	MaxCounter            uint
+	MaxFrozenCounter      uint
+	MaxBinaryOutputStatus uint
+	MaxAnalogOutputStatus uint
+	MaxOctetString        uint
+	OverflowPolicy        EventOverflowPolicy
+}
+
+// EventTypeStats reports how one event type uses its share of the buffer
+type EventTypeStats struct {
+	Limit     uint   // Configured capacity
+	Count     uint   // Events buffered now
+	HighWater uint   // Most events buffered at once
+	Discarded uint64 // Events lost because the type was full
+}
+
+// EventBufferStats reports event buffer usage per event type
+type EventBufferStats struct {
+	Binary             EventTypeStats
+	DoubleBitBinary    EventTypeStats
+	Analog             EventTypeStats
+	Counter            EventTypeStats
+	FrozenCounter      EventTypeStats
+	BinaryOutputStatus EventTypeStats
+	AnalogOutputStatus EventTypeStats
+	OctetString        EventTypeStats
 }
 
 // Event represents a generic event
 type Event struct {
-	Index uint16
-	Type  EventType
-	Value interface{}
-	Class uint8
+	Index     uint16
+	Type      EventType
+	Value     interface{}
+	Class     uint8
+	Variation uint8 // Event variation to report (0 = type default)
+
+	seq         uint64
+	selected    bool // Included in a response awaiting confirmation
+	unsolicited bool // Selected by an unsolicited rather than a solicited response
 }
 
 // EventType identifies the type of event
@@ -36,55 +92,170 @@ const (
 	EventTypeFrozenCounter
 	EventTypeBinaryOutputStatus
 	EventTypeAnalogOutputStatus
+	EventTypeOctetString
+
+	numEventTypes = iota
 )
 
+// String returns the event type name
+func (t EventType) String() string {
+	switch t {
+	case EventTypeBinary:
+		return "Binary"
+	case EventTypeDoubleBitBinary:
+		return "DoubleBitBinary"
+	case EventTypeAnalog:
+		return "Analog"
+	case EventTypeCounter:
+		return "Counter"
+	case EventTypeFrozenCounter:
+		return "FrozenCounter"
+	case EventTypeBinaryOutputStatus:
+		return "BinaryOutputStatus"
+	case EventTypeAnalogOutputStatus:
+		return "AnalogOutputStatus"
+	case EventTypeOctetString:
+		return "OctetString"
+	default:
+		return "Unknown"
+	}
+}
+
 // NewEventBuffer creates a new event buffer
-func NewEventBuffer(maxSize uint) *EventBuffer {
-	return &EventBuffer{
-		class1:  list.New(),
-		class2:  list.New(),
-		class3:  list.New(),
-		maxSize: maxSize,
+func NewEventBuffer(config EventBufferConfig) *EventBuffer {
+	eb := &EventBuffer{
+		class1: list.New(),
+		class2: list.New(),
+		class3: list.New(),
 	}
+	eb.configure(config)
+	return eb
+}
+
+// configure changes the per-type capacities and overflow policy. Events
+// already buffered are kept even if a type is now over its capacity.
+func (eb *EventBuffer) configure(config EventBufferConfig) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	limits := [numEventTypes]uint{
+		EventTypeBinary:             config.MaxBinary,
+		EventTypeDoubleBitBinary:    config.MaxDoubleBitBinary,
+		EventTypeAnalog:             config.MaxAnalog,
+		EventTypeCounter:            config.MaxCounter,
+		EventTypeFrozenCounter:      config.MaxFrozenCounter,
+		EventTypeBinaryOutputStatus: config.MaxBinaryOutputStatus,
+		EventTypeAnalogOutputStatus: config.MaxAnalogOutputStatus,
+		EventTypeOctetString:        config.MaxOctetString,
+	}
+	for t, limit := range limits {
+		if limit == 0 {
+			limit = defaultMaxEvents
+		}
+		eb.limits[t] = limit
+	}
+	eb.policy = config.OverflowPolicy
 }
 
 // AddBinaryEvent adds a binary event
-func (eb *EventBuffer) AddBinaryEvent(index uint16, value types.Binary, class uint8) {
+func (eb *EventBuffer) AddBinaryEvent(index uint16, value types.Binary, class, variation uint8) {
 	event := &Event{
-		Index: index,
-		Type:  EventTypeBinary,
-		Value: value,
-		Class: class,
+		Index:     index,
+		Type:      EventTypeBinary,
+		Value:     value,
+		Class:     class,
+		Variation: variation,
 	}
 	eb.addEvent(event, class)
 }
 
 // AddAnalogEvent adds an analog event
-func (eb *EventBuffer) AddAnalogEvent(index uint16, value types.Analog, class uint8) {
+func (eb *EventBuffer) AddAnalogEvent(index uint16, value types.Analog, class, variation uint8) {
 	event := &Event{
-		Index: index,
-		Type:  EventTypeAnalog,
-		Value: value,
-		Class: class,
+		Index:     index,
+		Type:      EventTypeAnalog,
+		Value:     value,
+		Class:     class,
+		Variation: variation,
 	}
 	eb.addEvent(event, class)
 }
 
 // AddCounterEvent adds a counter event
-func (eb *EventBuffer) AddCounterEvent(index uint16, value types.Counter, class uint8) {
+func (eb *EventBuffer) AddCounterEvent(index uint16, value types.Counter, class, variation uint8) {
+	event := &Event{
+		Index:     index,
+		Type:      EventTypeCounter,
+		Value:     value,
+		Class:     class,
+		Variation: variation,
+	}
+	eb.addEvent(event, class)
+}
+
+// AddDoubleBitBinaryEvent adds a double-bit binary event
+func (eb *EventBuffer) AddDoubleBitBinaryEvent(index uint16, value types.DoubleBitBinary, class, variation uint8) {
+	event := &Event{
+		Index:     index,
+		Type:      EventTypeDoubleBitBinary,
+		Value:     value,
+		Class:     class,
+		Variation: variation,
+	}
+	eb.addEvent(event, class)
+}
+
+// AddFrozenCounterEvent adds a frozen counter event
+func (eb *EventBuffer) AddFrozenCounterEvent(index uint16, value types.FrozenCounter, class, variation uint8) {
+	event := &Event{
+		Index:     index,
+		Type:      EventTypeFrozenCounter,
+		Value:     value,
+		Class:     class,
+		Variation: variation,
+	}
+	eb.addEvent(event, class)
+}
+
+// AddBinaryOutputStatusEvent adds a binary output status event
+func (eb *EventBuffer) AddBinaryOutputStatusEvent(index uint16, value types.BinaryOutputStatus, class, variation uint8) {
+	event := &Event{
+		Index:     index,
+		Type:      EventTypeBinaryOutputStatus,
+		Value:     value,
+		Class:     class,
+		Variation: variation,
+	}
+	eb.addEvent(event, class)
+}
+
+// AddAnalogOutputStatusEvent adds an analog output status event
+func (eb *EventBuffer) AddAnalogOutputStatusEvent(index uint16, value types.AnalogOutputStatus, class, variation uint8) {
+	event := &Event{
+		Index:     index,
+		Type:      EventTypeAnalogOutputStatus,
+		Value:     value,
+		Class:     class,
+		Variation: variation,
+	}
+	eb.addEvent(event, class)
+}
+
+// AddOctetStringEvent adds an octet string event
+func (eb *EventBuffer) AddOctetStringEvent(index uint16, value types.OctetString, class uint8) {
 	event := &Event{
 		Index: index,
-		Type:  EventTypeCounter,
+		Type:  EventTypeOctetString,
 		Value: value,
 		Class: class,
 	}
 	eb.addEvent(event, class)
 }
 
-// addEvent adds an event to the appropriate class buffer
+// addEvent adds an event to the appropriate class buffer, applying the
+// overflow policy if its type is full
 func (eb *EventBuffer) addEvent(event *Event, class uint8) {
 	eb.mu.Lock()
-	defer eb.mu.Unlock()
 
 	var targetList *list.List
 	switch class {
@@ -95,17 +266,120 @@ func (eb *EventBuffer) addEvent(event *Event, class uint8) {
 	case 3:
 		targetList = eb.class3
 	default:
+		eb.mu.Unlock()
 		return
 	}
 
-	// Check capacity
-	if uint(targetList.Len()) >= eb.maxSize {
-		// Remove oldest event
-		targetList.Remove(targetList.Front())
+	t := event.Type
+	keep := true
+	report := false
+	if eb.counts[t] >= eb.limits[t] {
+		keep = eb.makeRoom(event)
+		eb.discarded[t]++
+		eb.overflow = true
+		report = !eb.overflowed[t]
+		eb.overflowed[t] = true
+	}
+
+	if keep {
+		eb.nextSeq++
+		event.seq = eb.nextSeq
+		targetList.PushBack(event)
+		eb.counts[t]++
+		if eb.counts[t] > eb.highWater[t] {
+			eb.highWater[t] = eb.counts[t]
+		}
+	}
+
+	onOverflow := eb.onOverflow
+	eb.mu.Unlock()
+
+	if report && onOverflow != nil {
+		onOverflow(t)
+	}
+}
+
+// makeRoom discards a buffered event of the incoming event's type according
+// to the overflow policy and returns false if the incoming event should be
+// discarded instead
+func (eb *EventBuffer) makeRoom(event *Event) bool {
+	var victim func(*Event) bool
+	switch eb.policy {
+	case EventOverflowDiscardNewest:
+		return false
+
+	case EventOverflowKeepFirstOfType:
+		// Keep the first event of every point: a point that already has an
+		// event loses the new one, otherwise a later event of another point
+		// is discarded
+		first := make(map[uint16]uint64)
+		eb.forEach(func(_ *list.List, _ *list.Element, e *Event) {
+			if e.Type != event.Type {
+				return
+			}
+			if seq, ok := first[e.Index]; !ok || e.seq < seq {
+				first[e.Index] = e.seq
+			}
+		})
+		if _, ok := first[event.Index]; ok {
+			return false
+		}
+		victim = func(e *Event) bool { return e.seq != first[e.Index] }
+
+	default:
+		victim = func(*Event) bool { return true }
 	}
 
-	// Add new event
-	targetList.PushBack(event)
+	// Discard the oldest matching event. Events awaiting confirmation are
+	// kept, so the CONFIRM clears exactly what was reported; with every
+	// event reported the incoming one is discarded.
+	var oldestList *list.List
+	var oldest *list.Element
+	eb.forEach(func(l *list.List, elem *list.Element, e *Event) {
+		if e.Type == event.Type && !e.selected && victim(e) && (oldest == nil || e.seq < oldest.Value.(*Event).seq) {
+			oldestList, oldest = l, elem
+		}
+	})
+	if oldest == nil {
+		return false
+	}
+	oldestList.Remove(oldest)
+	eb.counts[event.Type]--
+	return true
+}
+
+// forEach calls fn for every buffered event (caller holds mu)
+func (eb *EventBuffer) forEach(fn func(*list.List, *list.Element, *Event)) {
+	for _, l := range []*list.List{eb.class1, eb.class2, eb.class3} {
+		for e := l.Front(); e != nil; e = e.Next() {
+			fn(l, e, e.Value.(*Event))
+		}
+	}
+}
+
+// Stats returns the buffer usage of each event type
+func (eb *EventBuffer) Stats() EventBufferStats {
+	eb.mu.RLock()
+	defer eb.mu.RUnlock()
+
+	stats := func(t EventType) EventTypeStats {
+		return EventTypeStats{
+			Limit:     eb.limits[t],
+			Count:     eb.counts[t],
+			HighWater: eb.highWater[t],
+			Discarded: eb.discarded[t],
+		}
+	}
+	return EventBufferStats{
+		Binary:             stats(EventTypeBinary),
+		DoubleBitBinary:    stats(EventTypeDoubleBitBinary),
+		Analog:             stats(EventTypeAnalog),
+		Counter:            stats(EventTypeCounter),
+		FrozenCounter:      stats(EventTypeFrozenCounter),
+		BinaryOutputStatus: stats(EventTypeBinaryOutputStatus),
+		AnalogOutputStatus: stats(EventTypeAnalogOutputStatus),
+		OctetString:        stats(EventTypeOctetString),
+	}
 }
 
 // GetClass1Count returns the number of Class 1 events
@@ -129,10 +403,22 @@ func (eb *EventBuffer) GetClass3Count() int {
 	return eb.class3.Len()
 }
 
+// Clear removes all events, e.g. on restart
+func (eb *EventBuffer) Clear() {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	eb.class1 = list.New()
+	eb.class2 = list.New()
+	eb.class3 = list.New()
+	eb.counts = [numEventTypes]uint{}
+	eb.clearOverflow()
+}
+
 // ClearClass1 clears Class 1 events
 func (eb *EventBuffer) ClearClass1() {
 	eb.mu.Lock()
 	defer eb.mu.Unlock()
+	eb.uncount(eb.class1)
 	eb.class1 = list.New()
 }
 
@@ -140,6 +426,7 @@ func (eb *EventBuffer) ClearClass1() {
 func (eb *EventBuffer) ClearClass2() {
 	eb.mu.Lock()
 	defer eb.mu.Unlock()
+	eb.uncount(eb.class2)
 	eb.class2 = list.New()
 }
 
@@ -147,12 +434,160 @@ func (eb *EventBuffer) ClearClass2() {
 func (eb *EventBuffer) ClearClass3() {
 	eb.mu.Lock()
 	defer eb.mu.Unlock()
+	eb.uncount(eb.class3)
 	eb.class3 = list.New()
 }
 
+// uncount removes the events of a class list from the type counts (caller holds mu)
+func (eb *EventBuffer) uncount(l *list.List) {
+	for e := l.Front(); e != nil; e = e.Next() {
+		eb.counts[e.Value.(*Event).Type]--
+	}
+}
+
+// clearOverflow resets IIN2.3 and rearms the overflow callback (caller holds mu)
+func (eb *EventBuffer) clearOverflow() {
+	eb.overflow = false
+	eb.overflowed = [numEventTypes]bool{}
+}
+
 // HasEvents returns true if any events are buffered
 func (eb *EventBuffer) HasEvents() bool {
 	eb.mu.RLock()
 	defer eb.mu.RUnlock()
 	return eb.class1.Len() > 0 || eb.class2.Len() > 0 || eb.class3.Len() > 0
 }
+
+// HasOverflowed returns true if events have been lost because the buffer was
+// full, until the master next confirms events
+func (eb *EventBuffer) HasOverflowed() bool {
+	eb.mu.RLock()
+	defer eb.mu.RUnlock()
+	return eb.overflow
+}
+
+// unreportedClasses returns the classes holding events not yet selected for
+// a response, reported in IIN1.1-1.3
+func (eb *EventBuffer) unreportedClasses() app.ClassField {
+	eb.mu.RLock()
+	defer eb.mu.RUnlock()
+
+	var classes app.ClassField
+	for class, l := range map[app.ClassField]*list.List{app.Class1: eb.class1, app.Class2: eb.class2, app.Class3: eb.class3} {
+		for e := l.Front(); e != nil; e = e.Next() {
+			if !e.Value.(*Event).selected {
+				classes |= class
+				break
+			}
+		}
+	}
+	return classes
+}
+
+// SelectEvents marks the unselected events of the given classes as selected
+// for a solicited or unsolicited response and returns copies of them in the
+// order they occurred
+func (eb *EventBuffer) SelectEvents(classes app.ClassField, unsolicited bool) []Event {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	var lists []*list.List
+	if classes.HasClass(app.Class1) {
+		lists = append(lists, eb.class1)
+	}
+	if classes.HasClass(app.Class2) {
+		lists = append(lists, eb.class2)
+	}
+	if classes.HasClass(app.Class3) {
+		lists = append(lists, eb.class3)
+	}
+
+	// Merge the per-class FIFOs by insertion order
+	cursors := make([]*list.Element, len(lists))
+	for i, l := range lists {
+		cursors[i] = l.Front()
+	}
+
+	var events []Event
+	for {
+		next := -1
+		for i, e := range cursors {
+			for e != nil && e.Value.(*Event).selected {
+				e = e.Next()
+			}
+			cursors[i] = e
+			if e != nil && (next < 0 || e.Value.(*Event).seq < cursors[next].Value.(*Event).seq) {
+				next = i
+			}
+		}
+		if next < 0 {
+			break
+		}
+
+		event := cursors[next].Value.(*Event)
+		event.selected = true
+		event.unsolicited = unsolicited
+		events = append(events, *event)
+		cursors[next] = cursors[next].Next()
+	}
+
+	return events
+}
+
+// ClearSelected removes the events selected for a solicited or unsolicited
+// response once the master has confirmed it and returns how many were removed
+// from each class
+func (eb *EventBuffer) ClearSelected(unsolicited bool) (numClass1, numClass2, numClass3 uint) {
+	return eb.clearSelectedThrough(unsolicited, ^uint64(0))
+}
+
+// clearSelectedThrough removes the selected events that occurred up to and
+// including the event with insertion sequence seq, i.e. those reported in the
+// confirmed fragments of a multi-fragment response
+func (eb *EventBuffer) clearSelectedThrough(unsolicited bool, seq uint64) (numClass1, numClass2, numClass3 uint) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	numClass1, numClass2, numClass3 = eb.removeSelected(eb.class1, unsolicited, seq), eb.removeSelected(eb.class2, unsolicited, seq), eb.removeSelected(eb.class3, unsolicited, seq)
+	if numClass1+numClass2+numClass3 > 0 {
+		eb.clearOverflow()
+	}
+	return numClass1, numClass2, numClass3
+}
+
+// Unselect returns the events selected for a solicited or unsolicited response
+// to the buffer so they are reported again
+func (eb *EventBuffer) Unselect(unsolicited bool) {
+	eb.unselectAfter(unsolicited, 0)
+}
+
+// unselectAfter returns the selected events that occurred after the event with
+// insertion sequence seq to the buffer, e.g. those that did not fit in a fragment
+func (eb *EventBuffer) unselectAfter(unsolicited bool, seq uint64) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	for _, l := range []*list.List{eb.class1, eb.class2, eb.class3} {
+		for e := l.Front(); e != nil; e = e.Next() {
+			if event := e.Value.(*Event); event.selected && event.unsolicited == unsolicited && event.seq > seq {
+				event.selected = false
+			}
+		}
+	}
+}
+
+// removeSelected removes events selected by the given response type that
+// occurred up to and including seq from a class list (caller holds mu)
+func (eb *EventBuffer) removeSelected(l *list.List, unsolicited bool, seq uint64) uint {
+	var removed uint
+	for e := l.Front(); e != nil; {
+		next := e.Next()
+		if event := e.Value.(*Event); event.selected && event.unsolicited == unsolicited && event.seq <= seq {
+			l.Remove(e)
+			eb.counts[event.Type]--
+			removed++
+		}
+		e = next
+	}
+	return removed
+}
@@ -0,0 +1,173 @@
+package outstation
+
+import (
+	"testing"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// bufferedBinaries returns the index and value of each binary event in order
+func bufferedBinaries(eb *EventBuffer) [][2]int {
+	var got [][2]int
+	for _, e := range eb.SelectEvents(app.ClassAll, false) {
+		if v, ok := e.Value.(types.Binary); ok {
+			value := 0
+			if v.Value {
+				value = 1
+			}
+			got = append(got, [2]int{int(e.Index), value})
+		}
+	}
+	eb.Unselect(false)
+	return got
+}
+
+func TestEventBufferOverflowPolicies(t *testing.T) {
+	// Point 0 changes twice, then points 1 and 2 change once each
+	adds := [][2]int{{0, 1}, {0, 0}, {1, 1}, {2, 1}}
+
+	tests := []struct {
+		name   string
+		policy EventOverflowPolicy
+		want   [][2]int
+	}{
+		{"discard oldest", EventOverflowDiscardOldest, [][2]int{{0, 0}, {1, 1}, {2, 1}}},
+		{"discard newest", EventOverflowDiscardNewest, [][2]int{{0, 1}, {0, 0}, {1, 1}}},
+		{"keep first of type", EventOverflowKeepFirstOfType, [][2]int{{0, 1}, {1, 1}, {2, 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eb := NewEventBuffer(EventBufferConfig{MaxBinary: 3, OverflowPolicy: tt.policy})
+			for _, add := range adds {
+				eb.AddBinaryEvent(uint16(add[0]), types.Binary{Value: add[1] == 1}, 1, 0)
+			}
+
+			got := bufferedBinaries(eb)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Events: got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Events: got %v, want %v", got, tt.want)
+				}
+			}
+			if !eb.HasOverflowed() {
+				t.Error("Overflow should be flagged")
+			}
+		})
+	}
+}
+
+func TestEventBufferKeepFirstOfTypeDropsRepeat(t *testing.T) {
+	eb := NewEventBuffer(EventBufferConfig{MaxBinary: 2, OverflowPolicy: EventOverflowKeepFirstOfType})
+	eb.AddBinaryEvent(0, types.Binary{Value: true}, 1, 0)
+	eb.AddBinaryEvent(1, types.Binary{Value: true}, 1, 0)
+
+	// Point 0 already has its first event buffered
+	eb.AddBinaryEvent(0, types.Binary{Value: false}, 1, 0)
+
+	got := bufferedBinaries(eb)
+	if len(got) != 2 || got[0] != [2]int{0, 1} || got[1] != [2]int{1, 1} {
+		t.Errorf("Events: got %v, want the first event of points 0 and 1", got)
+	}
+}
+
+func TestEventBufferDiscardOldestKeepsSelected(t *testing.T) {
+	eb := NewEventBuffer(EventBufferConfig{MaxBinary: 2, OverflowPolicy: EventOverflowDiscardOldest})
+	eb.AddBinaryEvent(0, types.Binary{Value: true}, 1, 0)
+	eb.SelectEvents(app.Class1, false)
+	eb.AddBinaryEvent(1, types.Binary{Value: true}, 1, 0)
+
+	// The oldest unreported event makes room, not the one awaiting CONFIRM
+	eb.AddBinaryEvent(2, types.Binary{Value: true}, 1, 0)
+	if n, _, _ := eb.ClearSelected(false); n != 1 {
+		t.Fatalf("CONFIRM cleared %d events, want 1", n)
+	}
+	if got := bufferedBinaries(eb); len(got) != 1 || got[0] != [2]int{2, 1} {
+		t.Errorf("Events after CONFIRM: got %v, want point 2", got)
+	}
+
+	// With every event reported the incoming event is discarded
+	eb.AddBinaryEvent(3, types.Binary{Value: true}, 1, 0)
+	eb.SelectEvents(app.Class1, false)
+	eb.AddBinaryEvent(4, types.Binary{Value: true}, 1, 0)
+	if n, _, _ := eb.ClearSelected(false); n != 2 {
+		t.Fatalf("Second CONFIRM cleared %d events, want 2", n)
+	}
+	if got := bufferedBinaries(eb); len(got) != 0 {
+		t.Errorf("Events after second CONFIRM: got %v, want none", got)
+	}
+}
+
+func TestEventBufferLimitsPerType(t *testing.T) {
+	eb := NewEventBuffer(EventBufferConfig{MaxBinary: 1, MaxAnalog: 2})
+
+	// Binary events in different classes share the binary limit
+	eb.AddBinaryEvent(0, types.Binary{Value: true}, 1, 0)
+	eb.AddBinaryEvent(1, types.Binary{Value: true}, 2, 0)
+	eb.AddAnalogEvent(0, types.Analog{Value: 1}, 1, 0)
+	eb.AddAnalogEvent(0, types.Analog{Value: 2}, 1, 0)
+
+	if c1, c2 := eb.GetClass1Count(), eb.GetClass2Count(); c1 != 2 || c2 != 1 {
+		t.Errorf("Class counts: got %d/%d, want 2/1", c1, c2)
+	}
+
+	stats := eb.Stats()
+	if stats.Binary != (EventTypeStats{Limit: 1, Count: 1, HighWater: 1, Discarded: 1}) {
+		t.Errorf("Binary stats: got %+v", stats.Binary)
+	}
+	if stats.Analog != (EventTypeStats{Limit: 2, Count: 2, HighWater: 2}) {
+		t.Errorf("Analog stats: got %+v", stats.Analog)
+	}
+	if stats.Counter.Limit != defaultMaxEvents {
+		t.Errorf("Counter limit: got %d, want default %d", stats.Counter.Limit, defaultMaxEvents)
+	}
+}
+
+func TestEventBufferHighWaterAfterConfirm(t *testing.T) {
+	eb := NewEventBuffer(EventBufferConfig{})
+	for i := 0; i < 5; i++ {
+		eb.AddCounterEvent(uint16(i), types.Counter{Value: uint32(i)}, 3, 0)
+	}
+
+	eb.SelectEvents(app.Class3, false)
+	eb.ClearSelected(false)
+	eb.AddCounterEvent(0, types.Counter{Value: 9}, 3, 0)
+
+	if stats := eb.Stats().Counter; stats.Count != 1 || stats.HighWater != 5 {
+		t.Errorf("Counter stats: got %+v, want count 1 and high-water 5", stats)
+	}
+}
+
+func TestEventBufferOverflowCallback(t *testing.T) {
+	config := eventTestConfig()
+	config.MaxBinaryEvents = 1
+	h := newTestHarness(t, config)
+	db := h.outstation.database
+
+	for i := 0; i < 3; i++ {
+		db.UpdateBinary(0, types.Binary{Value: i%2 == 0}, EventModeDetect)
+	}
+	if len(h.callbacks.overflows) != 1 || h.callbacks.overflows[0] != EventTypeBinary {
+		t.Fatalf("Overflow callbacks: got %v, want one for binary", h.callbacks.overflows)
+	}
+
+	resp := h.request(eventPoll(0))
+	if resp.IIN.IIN2&types.IIN2EventBufferOverflow == 0 {
+		t.Errorf("IIN2: got 0x%02X, want event buffer overflow", resp.IIN.IIN2)
+	}
+
+	// Confirming the events rearms the callback
+	h.outstation.onReceiveAPDU(app.BuildConfirmRequest(0).Serialize())
+	db.UpdateBinary(0, types.Binary{Value: false}, EventModeDetect)
+	db.UpdateBinary(0, types.Binary{Value: true}, EventModeDetect)
+	if len(h.callbacks.overflows) != 2 {
+		t.Errorf("Overflow callbacks after CONFIRM: got %d, want 2", len(h.callbacks.overflows))
+	}
+
+	if stats := h.outstation.EventBufferStats().Binary; stats.Discarded != 3 || stats.HighWater != 1 {
+		t.Errorf("Binary stats: got %+v, want 3 discarded and high-water 1", stats)
+	}
+}
@@ -0,0 +1,305 @@
+package outstation
+
+import (
+	"time"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// defaultSolConfirmTimeout is used when SolConfirmTimeout is not configured
+const defaultSolConfirmTimeout = 5 * time.Second
+
+// confirmState tracks a response fragment awaiting an application CONFIRM
+type confirmState struct {
+	pending   bool
+	seq       uint8
+	gen       uint64 // Distinguishes successive waits so stale timers are ignored
+	timer     *time.Timer
+	iin       types.IIN          // IIN bits for the request headers, repeated in each fragment
+	fragment  responseFragment   // Fragment awaiting confirmation
+	remaining []responseFragment // Fragments sent once it is confirmed
+}
+
+// writeEventData selects buffered events of the given classes and writes them
+func (o *outstation) writeEventData(w *fragmentWriter, classes app.ClassField) {
+	events := o.eventBuffer.SelectEvents(classes, false)
+	if len(events) == 0 {
+		return
+	}
+
+	o.logger.Debug("Outstation %s: Reporting %d events for %s", o.config.ID, len(events), classes)
+	writeEvents(w, events)
+}
+
+// writeEvents writes events in order as 16-bit index-prefixed objects,
+// starting a new header whenever the group or variation changes or the
+// current fragment is full
+func writeEvents(w *fragmentWriter, events []Event) {
+	for start := 0; start < len(events); {
+		group, variation := eventObjectType(events[start])
+
+		end := start + 1
+		for end < len(events) {
+			g, v := eventObjectType(events[end])
+			if g != group || v != variation {
+				break
+			}
+			end++
+		}
+
+		objectSize := 2 + len(serializeEvent(events[start], variation))
+		run := events[start:end]
+		for len(run) > 0 {
+			n := w.fit(len(run), func(k int) int {
+				return headerSize(group, variation, app.Qualifier16BitIndexPrefix,
+					app.IndexPrefixRange{Count: uint32(k), IndexSize: 2}) + k*objectSize
+			})
+
+			builder := app.NewObjectBuilder()
+			builder.AddHeader(group, variation, app.Qualifier16BitIndexPrefix,
+				app.IndexPrefixRange{Count: uint32(n), IndexSize: 2})
+			for _, event := range run[:n] {
+				builder.AddIndex(2, uint32(event.Index))
+				builder.AddRawData(serializeEvent(event, variation))
+			}
+			w.writeEvents(builder.Build(), run[n-1].seq)
+
+			run = run[n:]
+		}
+
+		start = end
+	}
+}
+
+// eventObjectType returns the group and variation used to report an event,
+// falling back to the default variation when none (or an unsupported one) is configured
+func eventObjectType(event Event) (uint8, uint8) {
+	switch event.Type {
+	case EventTypeBinary:
+		switch event.Variation {
+		case app.BinaryInputEventWithoutTime, app.BinaryInputEventWithTime:
+			return app.GroupBinaryInputEvent, event.Variation
+		}
+		return app.GroupBinaryInputEvent, app.BinaryInputEventWithoutTime
+	case EventTypeAnalog:
+		if event.Variation >= app.AnalogInputEvent32BitNoTime && event.Variation <= app.AnalogInputEventDoubleWithTime {
+			return app.GroupAnalogInputEvent, event.Variation
+		}
+		return app.GroupAnalogInputEvent, app.AnalogInputEvent32BitNoTime
+	case EventTypeCounter:
+		switch event.Variation {
+		case app.CounterEvent32BitWithFlag, app.CounterEvent16BitWithFlag,
+			app.CounterEvent32BitWithFlagTime, app.CounterEvent16BitWithFlagTime:
+			return app.GroupCounterEvent, event.Variation
+		}
+		return app.GroupCounterEvent, app.CounterEvent32BitWithFlag
+	case EventTypeDoubleBitBinary:
+		switch event.Variation {
+		case app.DoubleBitBinaryEventWithoutTime, app.DoubleBitBinaryEventWithTime:
+			return app.GroupDoubleBitBinaryEvent, event.Variation
+		}
+		return app.GroupDoubleBitBinaryEvent, app.DoubleBitBinaryEventWithoutTime
+	case EventTypeFrozenCounter:
+		switch event.Variation {
+		case app.FrozenCounterEvent32BitWithFlag, app.FrozenCounterEvent16BitWithFlag,
+			app.FrozenCounterEvent32BitWithFlagTime, app.FrozenCounterEvent16BitWithFlagTime:
+			return app.GroupFrozenCounterEvent, event.Variation
+		}
+		return app.GroupFrozenCounterEvent, app.FrozenCounterEvent32BitWithFlag
+	case EventTypeBinaryOutputStatus:
+		switch event.Variation {
+		case app.BinaryOutputEventWithoutTime, app.BinaryOutputEventWithTime:
+			return app.GroupBinaryOutputEvent, event.Variation
+		}
+		return app.GroupBinaryOutputEvent, app.BinaryOutputEventWithoutTime
+	case EventTypeAnalogOutputStatus:
+		if event.Variation >= app.AnalogOutputEvent32BitNoTime && event.Variation <= app.AnalogOutputEventDoubleWithTime {
+			return app.GroupAnalogOutputEvent, event.Variation
+		}
+		return app.GroupAnalogOutputEvent, app.AnalogOutputEvent32BitNoTime
+	case EventTypeOctetString:
+		// The variation is the length of the string
+		if v, ok := event.Value.(types.OctetString); ok {
+			return app.GroupOctetStringEvent, uint8(len(v.Value))
+		}
+	}
+	return 0, 0
+}
+
+// serializeEvent serializes a single event in the given variation
+func serializeEvent(event Event, variation uint8) []byte {
+	switch v := event.Value.(type) {
+	case types.Binary:
+		e := app.BinaryInputEvent{
+			Value:     v.Value,
+			Flags:     binaryFlags(v.Value, v.Flags),
+			Timestamp: uint64(v.Time),
+		}
+		if variation == app.BinaryInputEventWithTime {
+			return e.SerializeWithTime()
+		}
+		return e.SerializeWithoutTime()
+	case types.Analog:
+		e := app.AnalogInputEvent{
+			Value:     v.Value,
+			Flags:     uint8(v.Flags),
+			Timestamp: uint64(v.Time),
+		}
+		return e.Serialize(variation)
+	case types.Counter:
+		e := app.CounterEvent{
+			Value:     v.Value,
+			Flags:     uint8(v.Flags),
+			Timestamp: uint64(v.Time),
+		}
+		return e.Serialize(variation)
+	case types.DoubleBitBinary:
+		e := app.DoubleBitBinaryEvent{
+			Value:     uint8(v.Value),
+			Flags:     uint8(v.Flags),
+			Timestamp: uint64(v.Time),
+		}
+		return e.Serialize(variation)
+	case types.FrozenCounter:
+		e := app.FrozenCounterEvent{
+			Value:     v.Value,
+			Flags:     uint8(v.Flags),
+			Timestamp: uint64(v.Time),
+		}
+		return e.Serialize(variation)
+	case types.BinaryOutputStatus:
+		e := app.BinaryOutputEvent{
+			Value:     v.Value,
+			Flags:     binaryFlags(v.Value, v.Flags),
+			Timestamp: uint64(v.Time),
+		}
+		return e.Serialize(variation)
+	case types.AnalogOutputStatus:
+		e := app.AnalogOutputEvent{
+			Value:     v.Value,
+			Flags:     uint8(v.Flags),
+			Timestamp: uint64(v.Time),
+		}
+		return e.Serialize(variation)
+	case types.OctetString:
+		return v.Value
+	}
+	return nil
+}
+
+// binaryFlags returns the flags octet of a binary object with the state bit set from value
+func binaryFlags(value bool, flags types.Flags) uint8 {
+	result := uint8(flags) &^ app.FlagState
+	if value {
+		result |= app.FlagState
+	}
+	return result
+}
+
+// startSolConfirm waits for the master to confirm the solicited response
+// fragment with seq before clearing its events and sending the remaining ones
+func (o *outstation) startSolConfirm(seq uint8, iin types.IIN, fragment responseFragment, remaining []responseFragment) {
+	o.stateMu.Lock()
+	defer o.stateMu.Unlock()
+
+	o.solConfirm.gen++
+	o.solConfirm.pending = true
+	o.solConfirm.seq = seq
+	o.solConfirm.iin = iin
+	o.solConfirm.fragment = fragment
+	o.solConfirm.remaining = remaining
+
+	timeout := o.config.SolConfirmTimeout
+	if timeout <= 0 {
+		timeout = defaultSolConfirmTimeout
+	}
+
+	gen := o.solConfirm.gen
+	o.solConfirm.timer = time.AfterFunc(timeout, func() {
+		o.onSolConfirmTimeout(gen)
+	})
+}
+
+// onSolConfirmTimeout releases the selected events when no CONFIRM arrived in time
+func (o *outstation) onSolConfirmTimeout(gen uint64) {
+	o.stateMu.Lock()
+	if !o.solConfirm.pending || o.solConfirm.gen != gen {
+		o.stateMu.Unlock()
+		return
+	}
+	o.solConfirm.pending = false
+	o.solConfirm.timer = nil
+	o.solConfirm.remaining = nil
+	o.stateMu.Unlock()
+
+	o.logger.Warn("Outstation %s: Timeout waiting for CONFIRM, events retained", o.config.ID)
+	o.eventBuffer.Unselect(false)
+}
+
+// cancelSolConfirm stops waiting for a solicited CONFIRM, keeping the events buffered
+func (o *outstation) cancelSolConfirm() {
+	o.stateMu.Lock()
+	if !o.solConfirm.pending {
+		o.stateMu.Unlock()
+		return
+	}
+	if o.solConfirm.timer != nil {
+		o.solConfirm.timer.Stop()
+		o.solConfirm.timer = nil
+	}
+	o.solConfirm.pending = false
+	o.solConfirm.remaining = nil
+	o.stateMu.Unlock()
+
+	o.eventBuffer.Unselect(false)
+}
+
+// handleConfirm handles application layer CONFIRM messages
+func (o *outstation) handleConfirm(apdu *app.APDU) error {
+	if apdu.UNS {
+		select {
+		case o.unsolConfirm <- apdu.Sequence:
+		default:
+			o.logger.Debug("Outstation %s: Dropped unsolicited CONFIRM seq=%d", o.config.ID, apdu.Sequence)
+		}
+		return nil
+	}
+
+	o.stateMu.Lock()
+	if !o.solConfirm.pending || apdu.Sequence != o.solConfirm.seq {
+		o.stateMu.Unlock()
+		o.logger.Debug("Outstation %s: Unexpected CONFIRM seq=%d", o.config.ID, apdu.Sequence)
+		return nil
+	}
+	if o.solConfirm.timer != nil {
+		o.solConfirm.timer.Stop()
+		o.solConfirm.timer = nil
+	}
+	o.solConfirm.pending = false
+	iin := o.solConfirm.iin
+	fragment := o.solConfirm.fragment
+	remaining := o.solConfirm.remaining
+	o.solConfirm.remaining = nil
+	o.stateMu.Unlock()
+
+	if fragment.hasEvents {
+		numClass1, numClass2, numClass3 := o.eventBuffer.clearSelectedThrough(false, fragment.lastEvent)
+		o.logger.Debug("Outstation %s: CONFIRM seq=%d cleared events: class1=%d, class2=%d, class3=%d",
+			o.config.ID, apdu.Sequence, numClass1, numClass2, numClass3)
+
+		o.callbacks.OnConfirmReceived(false, numClass1, numClass2, numClass3)
+	}
+
+	if len(remaining) > 0 {
+		return o.sendResponseFragment(app.NextSequence(apdu.Sequence), false, iin, remaining)
+	}
+	return nil
+}
+
+// onEventBufferOverflow reports that events of a type are being lost; the
+// master learns of it through IIN2.3
+func (o *outstation) onEventBufferOverflow(eventType EventType) {
+	o.logger.Warn("Outstation %s: %s event buffer full, events discarded", o.config.ID, eventType)
+	o.callbacks.OnEventBufferOverflow(eventType)
+}
@@ -0,0 +1,216 @@
+package outstation
+
+import (
+	"testing"
+	"time"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+func eventTestConfig() OutstationConfig {
+	return OutstationConfig{
+		MaxBinaryEvents: 10,
+		Database: DatabaseConfig{
+			Binary: []BinaryPointConfig{
+				{StaticVariation: 2, EventVariation: app.BinaryInputEventWithTime, Class: 1},
+				{StaticVariation: 2, EventVariation: app.BinaryInputEventWithoutTime, Class: 2},
+			},
+			Analog: []AnalogPointConfig{
+				{StaticVariation: 5, EventVariation: app.AnalogInputEventFloatNoTime, Class: 1},
+			},
+			Counter: []CounterPointConfig{
+				{StaticVariation: 1, EventVariation: app.CounterEvent32BitWithFlagTime, Class: 3},
+			},
+		},
+	}
+}
+
+// eventHeader describes one object header of an event response
+type eventHeader struct {
+	group     uint8
+	variation uint8
+	indices   []uint32
+}
+
+// parseEventHeaders decodes index-prefixed event headers from response objects
+func parseEventHeaders(t *testing.T, objects []byte) []eventHeader {
+	t.Helper()
+
+	var headers []eventHeader
+	parser := app.NewParser(objects)
+	for parser.HasMore() {
+		header, err := parser.ReadObjectHeader()
+		if err != nil {
+			t.Fatalf("ReadObjectHeader failed: %v", err)
+		}
+		r, ok := header.Range.(app.IndexPrefixRange)
+		if !ok {
+			t.Fatalf("G%dV%d: expected index-prefixed range, got %T", header.Group, header.Variation, header.Range)
+		}
+
+		eh := eventHeader{group: header.Group, variation: header.Variation}
+		for i := uint32(0); i < r.Count; i++ {
+			index, err := parser.ReadIndex(r.IndexSize)
+			if err != nil {
+				t.Fatalf("ReadIndex failed: %v", err)
+			}
+			eh.indices = append(eh.indices, index)
+			parser.Skip(app.GetObjectSize(header.Group, header.Variation))
+		}
+		headers = append(headers, eh)
+	}
+	return headers
+}
+
+func eventPoll(seq uint8) *app.APDU {
+	return app.BuildEventPollRequest(seq)
+}
+
+func TestEventsReportedWithConfiguredVariation(t *testing.T) {
+	h := newTestHarness(t, eventTestConfig())
+	db := h.outstation.database
+
+	db.UpdateBinary(0, types.Binary{Value: true, Flags: types.FlagOnline, Time: 1000}, EventModeDetect)
+	db.UpdateAnalog(0, types.Analog{Value: 12.5, Flags: types.FlagOnline}, EventModeDetect)
+	db.UpdateBinary(1, types.Binary{Value: true, Flags: types.FlagOnline}, EventModeDetect)
+	db.UpdateCounter(0, types.Counter{Value: 7, Flags: types.FlagOnline}, EventModeDetect)
+
+	resp := h.request(eventPoll(3))
+	if !resp.CON {
+		t.Error("Response with events should request confirmation")
+	}
+
+	want := []eventHeader{
+		{app.GroupBinaryInputEvent, app.BinaryInputEventWithTime, []uint32{0}},
+		{app.GroupAnalogInputEvent, app.AnalogInputEventFloatNoTime, []uint32{0}},
+		{app.GroupBinaryInputEvent, app.BinaryInputEventWithoutTime, []uint32{1}},
+		{app.GroupCounterEvent, app.CounterEvent32BitWithFlagTime, []uint32{0}},
+	}
+	got := parseEventHeaders(t, resp.Objects)
+	if len(got) != len(want) {
+		t.Fatalf("Headers: got %d, want %d (%+v)", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i].group != want[i].group || got[i].variation != want[i].variation ||
+			len(got[i].indices) != 1 || got[i].indices[0] != want[i].indices[0] {
+			t.Errorf("Header %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	// Binary event with time carries the state bit and the supplied timestamp
+	parser := app.NewParser(resp.Objects)
+	parser.ReadObjectHeader()
+	parser.ReadIndex(2)
+	event, _ := parser.ReadBytes(7)
+	if event[0] != app.FlagOnline|app.FlagState {
+		t.Errorf("Binary event flags: got 0x%02X, want 0x81", event[0])
+	}
+	if ts := app.ParseTime48(event[1:]); ts != 1000 {
+		t.Errorf("Binary event time: got %d, want 1000", ts)
+	}
+}
+
+func TestEventsClearedOnlyByMatchingConfirm(t *testing.T) {
+	h := newTestHarness(t, eventTestConfig())
+	eb := h.outstation.eventBuffer
+
+	h.outstation.database.UpdateBinary(0, types.Binary{Value: true}, EventModeDetect)
+	h.request(eventPoll(5))
+
+	// CONFIRM with the wrong sequence is ignored
+	h.outstation.onReceiveAPDU(app.BuildConfirmRequest(6).Serialize())
+	if eb.GetClass1Count() != 1 {
+		t.Fatalf("Event removed by mismatched CONFIRM")
+	}
+
+	h.outstation.onReceiveAPDU(app.BuildConfirmRequest(5).Serialize())
+	if eb.GetClass1Count() != 0 {
+		t.Errorf("Class 1 count after CONFIRM: got %d, want 0", eb.GetClass1Count())
+	}
+	if confirms := h.callbacks.confirmCounts(); len(confirms) != 1 || confirms[0] != [3]uint{1, 0, 0} {
+		t.Errorf("OnConfirmReceived: got %v, want [[1 0 0]]", confirms)
+	}
+
+	resp := h.request(eventPoll(6))
+	if resp.CON || len(resp.Objects) != 0 {
+		t.Errorf("Expected null response after CONFIRM, got CON=%v objects=%d", resp.CON, len(resp.Objects))
+	}
+}
+
+func TestEventsRetainedWithoutConfirm(t *testing.T) {
+	h := newTestHarness(t, eventTestConfig())
+
+	h.outstation.database.UpdateBinary(0, types.Binary{Value: true}, EventModeDetect)
+	h.request(eventPoll(0))
+
+	// A new request instead of CONFIRM reports the same event again
+	resp := h.request(eventPoll(1))
+	if headers := parseEventHeaders(t, resp.Objects); len(headers) != 1 {
+		t.Fatalf("Expected event to be reported again, got %+v", headers)
+	}
+
+	h.outstation.onReceiveAPDU(app.BuildConfirmRequest(0).Serialize())
+	if h.outstation.eventBuffer.GetClass1Count() != 1 {
+		t.Error("CONFIRM for a superseded response should not clear events")
+	}
+}
+
+func TestEventsRetainedAfterConfirmTimeout(t *testing.T) {
+	config := eventTestConfig()
+	config.SolConfirmTimeout = 20 * time.Millisecond
+	h := newTestHarness(t, config)
+
+	h.outstation.database.UpdateBinary(0, types.Binary{Value: true}, EventModeDetect)
+	h.request(eventPoll(2))
+	time.Sleep(50 * time.Millisecond)
+
+	// The late CONFIRM no longer applies
+	h.outstation.onReceiveAPDU(app.BuildConfirmRequest(2).Serialize())
+	if h.outstation.eventBuffer.GetClass1Count() != 1 {
+		t.Fatalf("Event should be retained after confirm timeout")
+	}
+
+	resp := h.request(eventPoll(3))
+	if !resp.CON || len(parseEventHeaders(t, resp.Objects)) != 1 {
+		t.Errorf("Expected event to be reported again after timeout")
+	}
+}
+
+func TestSolConfirmTimeoutDefault(t *testing.T) {
+	h := newTestHarness(t, eventTestConfig())
+
+	h.outstation.database.UpdateBinary(0, types.Binary{Value: true}, EventModeDetect)
+	h.request(eventPoll(0))
+
+	h.outstation.stateMu.Lock()
+	timer := h.outstation.solConfirm.timer
+	h.outstation.stateMu.Unlock()
+	if timer == nil {
+		t.Fatal("No confirm timer started without SolConfirmTimeout")
+	}
+	if !timer.Stop() {
+		t.Error("Confirm timer expired immediately")
+	}
+}
+
+func TestClassReadOnlyReturnsRequestedClasses(t *testing.T) {
+	h := newTestHarness(t, eventTestConfig())
+	db := h.outstation.database
+
+	db.UpdateBinary(0, types.Binary{Value: true}, EventModeDetect) // Class 1
+	db.UpdateBinary(1, types.Binary{Value: true}, EventModeDetect) // Class 2
+
+	resp := h.request(app.BuildReadRequest(0, app.BuildClassRead(app.Class2)))
+
+	headers := parseEventHeaders(t, resp.Objects)
+	if len(headers) != 1 || headers[0].indices[0] != 1 {
+		t.Fatalf("Expected only the class 2 event, got %+v", headers)
+	}
+
+	h.outstation.onReceiveAPDU(app.BuildConfirmRequest(0).Serialize())
+	if h.outstation.eventBuffer.GetClass1Count() != 1 || h.outstation.eventBuffer.GetClass2Count() != 0 {
+		t.Errorf("Counts after CONFIRM: class1=%d class2=%d, want 1/0",
+			h.outstation.eventBuffer.GetClass1Count(), h.outstation.eventBuffer.GetClass2Count())
+	}
+}
@@ -0,0 +1,649 @@
+package outstation
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/fs"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// defaultMaxOpenFiles is used when MaxOpenFiles is not configured
+const defaultMaxOpenFiles = 4
+
+// defaultFileInactivityTimeout is used when InactivityTimeout is not configured
+const defaultFileInactivityTimeout = time.Minute
+
+// fileBlockOverhead is the size of a G70V5 header, object size and
+// transport object fields preceding the data of a block
+const fileBlockOverhead = 14
+
+// maxFileKeys bounds the authentication keys awaiting use
+const maxFileKeys = 8
+
+// fileState tracks the files opened by the master and the authentication
+// keys issued to it
+type fileState struct {
+	mu         sync.Mutex
+	open       map[uint32]*openFile
+	nextHandle uint32
+	keys       []uint32 // Issued and not yet used, oldest first
+}
+
+// openFile is a file or directory opened by the master
+type openFile struct {
+	name      string
+	blockSize int
+	block     uint32 // Next block to send or receive
+	done      bool   // Last block sent or received
+	sent      []byte // Last block object sent, resent to a repeated READ
+	sentSeq   uint8  // Sequence number of the READ it answered
+	reader    *bufio.Reader
+	writer    io.WriteCloser
+	closer    io.Closer   // File being read, nil for a directory
+	lastUsed  time.Time   // Time of the last block read or written
+	timer     *time.Timer // Closes the file once unused for the inactivity timeout
+}
+
+// close releases the file
+func (f *openFile) close() error {
+	if f.timer != nil {
+		f.timer.Stop()
+	}
+	if f.writer != nil {
+		return f.writer.Close()
+	}
+	if f.closer != nil {
+		return f.closer.Close()
+	}
+	return nil
+}
+
+// handleFile handles the OPEN, CLOSE, DELETE, GET FILE INFO, AUTHENTICATE
+// and ABORT FILE requests. Each carries a single G70 object and is answered
+// with one.
+func (o *outstation) handleFile(apdu *app.APDU) error {
+	if o.config.FileTransfer.FileSystem == nil {
+		o.logger.Warn("Outstation %s: File transfer not configured: %s", o.config.ID, apdu.FunctionCode)
+		return o.sendErrorResponse(apdu.Sequence)
+	}
+	o.logger.Debug("Outstation %s: Handling %s request", o.config.ID, apdu.FunctionCode)
+
+	var want uint8
+	switch apdu.FunctionCode {
+	case app.FuncOpenFile, app.FuncDeleteFile:
+		want = app.FileCommand
+	case app.FuncCloseFile, app.FuncAbortFile:
+		want = app.FileCommandStatus
+	case app.FuncGetFileInfo:
+		want = app.FileDescriptor
+	case app.FuncAuthenticateFile:
+		want = app.FileAuthentication
+	}
+
+	iin := o.responseIIN()
+	object, iin2 := readFileRequest(apdu.Objects, want)
+	iin.IIN2 |= iin2
+
+	var objects []byte
+	if iin2 == 0 {
+		switch apdu.FunctionCode {
+		case app.FuncOpenFile:
+			objects, iin2 = o.openFile(object)
+		case app.FuncCloseFile:
+			objects, iin2 = o.closeFile(object, false)
+		case app.FuncAbortFile:
+			objects, iin2 = o.closeFile(object, true)
+		case app.FuncDeleteFile:
+			objects, iin2 = o.deleteFile(object)
+		case app.FuncGetFileInfo:
+			objects, iin2 = o.fileInfo(object)
+		case app.FuncAuthenticateFile:
+			objects, iin2 = o.authenticateFile(object)
+		}
+		iin.IIN2 |= iin2
+	}
+
+	response := app.NewResponseAPDU(apdu.Sequence, iin, objects)
+	return o.session.sendAPDU(response.Serialize())
+}
+
+// readFileRequest returns the single G70 object of a file request, or the
+// IIN2 bits for a request without exactly one object of the variation
+func readFileRequest(objects []byte, variation uint8) ([]byte, uint8) {
+	parser := app.NewParser(objects)
+	header, err := parser.ReadObjectHeader()
+	if err != nil {
+		return nil, types.IIN2ParameterError
+	}
+	if header.Group != app.GroupFile || header.Variation != variation {
+		return nil, types.IIN2ObjectUnknown
+	}
+	if _, ok := header.Range.(app.FreeFormatRange); !ok || app.GetCount(header.Range) != 1 {
+		return nil, types.IIN2ParameterError
+	}
+	object, err := parser.ReadFreeFormatObject()
+	if err != nil || parser.HasMore() {
+		return nil, types.IIN2ParameterError
+	}
+	return object, 0
+}
+
+// fileName converts a requested name to a path of the file system: the
+// leading slash is dropped and the root directory is "."
+func fileName(name string) (string, bool) {
+	name = strings.TrimPrefix(name, "/")
+	if name == "" {
+		name = "."
+	}
+	return name, fs.ValidPath(name)
+}
+
+// fileErrorStatus returns the status reporting a file system error
+func fileErrorStatus(err error) app.FileStatus {
+	switch {
+	case errors.Is(err, fs.ErrNotExist), errors.Is(err, fs.ErrInvalid):
+		return app.FileStatusNotFound
+	case errors.Is(err, fs.ErrPermission):
+		return app.FileStatusPermissionDenied
+	default:
+		return app.FileStatusFatal
+	}
+}
+
+// fileSize returns the size of a file, false when it does not fit in the
+// 32 bits of the file objects
+func fileSize(info fs.FileInfo) (uint32, bool) {
+	size := info.Size()
+	return uint32(size), size >= 0 && size <= math.MaxUint32
+}
+
+// useFileKey reports whether file commands are allowed with a key. A key
+// from AUTHENTICATE FILE is needed when authentication is configured, and
+// is only good once.
+func (o *outstation) useFileKey(key uint32) bool {
+	if o.config.FileTransfer.Authenticate == nil {
+		return true
+	}
+
+	o.files.mu.Lock()
+	defer o.files.mu.Unlock()
+	for i, k := range o.files.keys {
+		if key != 0 && k == key {
+			o.files.keys = append(o.files.keys[:i], o.files.keys[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// authenticateFile answers AUTHENTICATE FILE with a key, or with zero when
+// the credentials are refused
+func (o *outstation) authenticateFile(object []byte) ([]byte, uint8) {
+	auth, err := app.ParseFileAuthentication(object)
+	if err != nil {
+		return nil, types.IIN2ParameterError
+	}
+
+	var key uint32
+	if authenticate := o.config.FileTransfer.Authenticate; authenticate == nil || authenticate(auth.UserName, auth.Password) {
+		var buf [4]byte
+		for key == 0 {
+			rand.Read(buf[:])
+			key = binary.LittleEndian.Uint32(buf[:])
+		}
+
+		o.files.mu.Lock()
+		o.files.keys = append(o.files.keys, key)
+		if len(o.files.keys) > maxFileKeys {
+			o.files.keys = o.files.keys[1:]
+		}
+		o.files.mu.Unlock()
+	} else {
+		o.logger.Warn("Outstation %s: File authentication refused for %q", o.config.ID, auth.UserName)
+	}
+
+	return app.BuildFileObject(app.FileAuthentication, app.FileAuthenticationObject{Key: key}.Serialize()), 0
+}
+
+// openFile answers OPEN with the handle, size and block size of the file.
+// A directory opened for reading is read as its file descriptors.
+func (o *outstation) openFile(object []byte) ([]byte, uint8) {
+	cmd, err := app.ParseFileCommand(object)
+	if err != nil {
+		return nil, types.IIN2ParameterError
+	}
+	status := func(s app.FileStatus) ([]byte, uint8) {
+		o.logger.Warn("Outstation %s: Cannot open %q: %s", o.config.ID, cmd.Name, s)
+		obj := app.FileCommandStatusObject{RequestID: cmd.RequestID, Status: s}
+		return app.BuildFileObject(app.FileCommandStatus, obj.Serialize()), 0
+	}
+
+	if !o.useFileKey(cmd.AuthKey) {
+		return status(app.FileStatusPermissionDenied)
+	}
+	name, ok := fileName(cmd.Name)
+	if !ok {
+		return status(app.FileStatusNotFound)
+	}
+
+	o.files.mu.Lock()
+	defer o.files.mu.Unlock()
+
+	maxOpen := o.config.FileTransfer.MaxOpenFiles
+	if maxOpen == 0 {
+		maxOpen = defaultMaxOpenFiles
+	}
+	if uint(len(o.files.open)) >= maxOpen {
+		return status(app.FileStatusTooManyOpen)
+	}
+	for _, f := range o.files.open {
+		if f.name == name && (f.writer != nil || cmd.Mode != app.FileModeRead) {
+			return status(app.FileStatusLocked)
+		}
+	}
+
+	file := &openFile{name: name, blockSize: o.fileBlockSize(cmd.MaxBlockSize)}
+	var size uint32
+	fsys := o.config.FileTransfer.FileSystem
+	switch cmd.Mode {
+	case app.FileModeRead:
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			return status(fileErrorStatus(err))
+		}
+		if info.IsDir() {
+			listing, err := o.listDirectory(name)
+			if err != nil {
+				return status(fileErrorStatus(err))
+			}
+			file.reader = bufio.NewReader(bytes.NewReader(listing))
+			size = uint32(len(listing))
+			break
+		}
+		if size, ok = fileSize(info); !ok {
+			o.logger.Warn("Outstation %s: Cannot open %q, %d bytes is too large", o.config.ID, name, info.Size())
+			return status(app.FileStatusFatal)
+		}
+		f, err := fsys.Open(name)
+		if err != nil {
+			return status(fileErrorStatus(err))
+		}
+		file.reader = bufio.NewReaderSize(f, file.blockSize+1)
+		file.closer = f
+	case app.FileModeWrite, app.FileModeAppend:
+		w, err := fsys.OpenWrite(name, cmd.Mode == app.FileModeAppend)
+		if err != nil {
+			return status(fileErrorStatus(err))
+		}
+		file.writer = w
+		size = cmd.Size
+	default:
+		return status(app.FileStatusInvalidMode)
+	}
+
+	if o.files.open == nil {
+		o.files.open = make(map[uint32]*openFile)
+	}
+	o.files.nextHandle++
+	if o.files.nextHandle == 0 {
+		o.files.nextHandle = 1
+	}
+	handle := o.files.nextHandle
+	o.files.open[handle] = file
+	file.lastUsed = time.Now()
+	file.timer = time.AfterFunc(o.fileInactivityTimeout(), func() { o.expireFile(handle, file) })
+	o.logger.Info("Outstation %s: Opened %q, handle %d, %d byte blocks", o.config.ID, name, handle, file.blockSize)
+
+	obj := app.FileCommandStatusObject{
+		Handle:       handle,
+		Size:         size,
+		MaxBlockSize: uint16(file.blockSize),
+		RequestID:    cmd.RequestID,
+		Status:       app.FileStatusSuccess,
+	}
+	return app.BuildFileObject(app.FileCommandStatus, obj.Serialize()), 0
+}
+
+// fileInactivityTimeout returns how long an open file may go unused
+func (o *outstation) fileInactivityTimeout() time.Duration {
+	if timeout := o.config.FileTransfer.InactivityTimeout; timeout > 0 {
+		return timeout
+	}
+	return defaultFileInactivityTimeout
+}
+
+// expireFile closes a file the master stopped using, as when it lost the
+// connection mid-transfer, so its handle does not stay taken for good. A
+// partly written file is removed as on ABORT.
+func (o *outstation) expireFile(handle uint32, file *openFile) {
+	o.files.mu.Lock()
+	defer o.files.mu.Unlock()
+
+	if o.files.open[handle] != file {
+		return
+	}
+	timeout := o.fileInactivityTimeout()
+	if idle := time.Since(file.lastUsed); idle < timeout {
+		// Used again while the timer fired
+		file.timer.Reset(timeout - idle)
+		return
+	}
+
+	delete(o.files.open, handle)
+	if err := file.close(); err != nil {
+		o.logger.Warn("Outstation %s: Failed to close %q: %v", o.config.ID, file.name, err)
+	}
+	if file.writer != nil && !file.done {
+		if err := o.config.FileTransfer.FileSystem.Remove(file.name); err != nil {
+			o.logger.Warn("Outstation %s: Failed to remove abandoned %q: %v", o.config.ID, file.name, err)
+		}
+	}
+	o.logger.Warn("Outstation %s: Closed %q, handle %d, unused for %s", o.config.ID, file.name, handle, timeout)
+}
+
+// fileBlockSize returns the block size granted for a requested size: no
+// more than configured, and small enough for a block to fit one fragment
+func (o *outstation) fileBlockSize(requested uint16) int {
+	size := o.newFragmentWriter().capacity - fileBlockOverhead
+	if limit := int(o.config.FileTransfer.MaxBlockSize); limit > 0 && limit < size {
+		size = limit
+	}
+	if requested > 0 && int(requested) < size {
+		size = int(requested)
+	}
+	return size
+}
+
+// listDirectory returns the file descriptors of a directory's entries
+func (o *outstation) listDirectory(name string) ([]byte, error) {
+	fsys := o.config.FileTransfer.FileSystem
+	entries, err := fs.ReadDir(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var listing []byte
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		size, ok := fileSize(info)
+		if !ok && !entry.IsDir() {
+			o.logger.Warn("Outstation %s: Left %q out of the listing, %d bytes is too large", o.config.ID, entry.Name(), info.Size())
+			continue
+		}
+		descriptor := app.FileDescriptorObject{
+			Name:        entry.Name(),
+			Type:        app.FileTypeSimple,
+			Size:        size,
+			Created:     app.FromTime(info.ModTime()),
+			Permissions: uint16(info.Mode().Perm()),
+		}
+		if entry.IsDir() {
+			// The size of a directory is its number of entries
+			descriptor.Type = app.FileTypeDirectory
+			children, _ := fs.ReadDir(fsys, strings.TrimPrefix(name+"/"+entry.Name(), "./"))
+			descriptor.Size = uint32(len(children))
+		}
+		listing = append(listing, descriptor.Serialize()...)
+	}
+	return listing, nil
+}
+
+// closeFile answers CLOSE or ABORT FILE. An aborted file being written is
+// removed.
+func (o *outstation) closeFile(object []byte, abort bool) ([]byte, uint8) {
+	req, err := app.ParseFileCommandStatus(object)
+	if err != nil {
+		return nil, types.IIN2ParameterError
+	}
+	obj := app.FileCommandStatusObject{Handle: req.Handle, RequestID: req.RequestID, Status: app.FileStatusSuccess}
+
+	o.files.mu.Lock()
+	file, ok := o.files.open[req.Handle]
+	delete(o.files.open, req.Handle)
+	o.files.mu.Unlock()
+
+	if !ok {
+		obj.Status = app.FileStatusInvalidHandle
+		return app.BuildFileObject(app.FileCommandStatus, obj.Serialize()), 0
+	}
+
+	if err := file.close(); err != nil {
+		o.logger.Warn("Outstation %s: Failed to close %q: %v", o.config.ID, file.name, err)
+		obj.Status = fileErrorStatus(err)
+	}
+	if abort && file.writer != nil {
+		if err := o.config.FileTransfer.FileSystem.Remove(file.name); err != nil {
+			o.logger.Warn("Outstation %s: Failed to remove aborted %q: %v", o.config.ID, file.name, err)
+		}
+	}
+	o.logger.Info("Outstation %s: Closed %q, handle %d", o.config.ID, file.name, req.Handle)
+	return app.BuildFileObject(app.FileCommandStatus, obj.Serialize()), 0
+}
+
+// deleteFile answers DELETE, a file command with the null mode
+func (o *outstation) deleteFile(object []byte) ([]byte, uint8) {
+	cmd, err := app.ParseFileCommand(object)
+	if err != nil {
+		return nil, types.IIN2ParameterError
+	}
+	obj := app.FileCommandStatusObject{RequestID: cmd.RequestID, Status: app.FileStatusSuccess}
+
+	name, ok := fileName(cmd.Name)
+	switch {
+	case cmd.Mode != app.FileModeNull:
+		obj.Status = app.FileStatusInvalidMode
+	case !o.useFileKey(cmd.AuthKey):
+		obj.Status = app.FileStatusPermissionDenied
+	case !ok || name == ".":
+		obj.Status = app.FileStatusNotFound
+	case o.isFileOpen(name):
+		obj.Status = app.FileStatusLocked
+	default:
+		if err := o.config.FileTransfer.FileSystem.Remove(name); err != nil {
+			o.logger.Warn("Outstation %s: Failed to delete %q: %v", o.config.ID, name, err)
+			obj.Status = fileErrorStatus(err)
+		} else {
+			o.logger.Info("Outstation %s: Deleted %q", o.config.ID, name)
+		}
+	}
+	return app.BuildFileObject(app.FileCommandStatus, obj.Serialize()), 0
+}
+
+// isFileOpen reports whether a file is open
+func (o *outstation) isFileOpen(name string) bool {
+	o.files.mu.Lock()
+	defer o.files.mu.Unlock()
+	for _, f := range o.files.open {
+		if f.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// fileInfo answers GET FILE INFO with the descriptor of the named file, or
+// with a command status when it cannot be found
+func (o *outstation) fileInfo(object []byte) ([]byte, uint8) {
+	req, _, err := app.ParseFileDescriptor(object)
+	if err != nil {
+		return nil, types.IIN2ParameterError
+	}
+	obj := app.FileCommandStatusObject{RequestID: req.RequestID, Status: app.FileStatusNotFound}
+
+	name, ok := fileName(req.Name)
+	if !ok {
+		return app.BuildFileObject(app.FileCommandStatus, obj.Serialize()), 0
+	}
+	fsys := o.config.FileTransfer.FileSystem
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		obj.Status = fileErrorStatus(err)
+		return app.BuildFileObject(app.FileCommandStatus, obj.Serialize()), 0
+	}
+	size, ok := fileSize(info)
+	if !ok && !info.IsDir() {
+		obj.Status = app.FileStatusFatal
+		return app.BuildFileObject(app.FileCommandStatus, obj.Serialize()), 0
+	}
+
+	descriptor := app.FileDescriptorObject{
+		Name:        req.Name,
+		Type:        app.FileTypeSimple,
+		Size:        size,
+		Created:     app.FromTime(info.ModTime()),
+		Permissions: uint16(info.Mode().Perm()),
+		RequestID:   req.RequestID,
+	}
+	if info.IsDir() {
+		entries, _ := fs.ReadDir(fsys, name)
+		descriptor.Type = app.FileTypeDirectory
+		descriptor.Size = uint32(len(entries))
+	}
+	return app.BuildFileObject(app.FileDescriptor, descriptor.Serialize()), 0
+}
+
+// readFileBlocks returns the next block of each file open for reading,
+// answering a READ of G70V5. A READ with the sequence number of the last
+// one repeats it after a lost response, and gets the same blocks again.
+func (o *outstation) readFileBlocks(header *app.ObjectHeader, seq uint8) ([][]byte, uint8) {
+	if o.config.FileTransfer.FileSystem == nil || header.Variation != app.FileTransport {
+		return nil, types.IIN2ObjectUnknown
+	}
+	if _, ok := header.Range.(app.NoRange); !ok {
+		return nil, types.IIN2ParameterError
+	}
+
+	o.files.mu.Lock()
+	defer o.files.mu.Unlock()
+
+	handles := make([]uint32, 0, len(o.files.open))
+	for handle := range o.files.open {
+		handles = append(handles, handle)
+	}
+	sort.Slice(handles, func(i, j int) bool { return handles[i] < handles[j] })
+
+	var blocks [][]byte
+	for _, handle := range handles {
+		file := o.files.open[handle]
+		switch {
+		case file.reader == nil:
+			continue
+		case file.sent != nil && file.sentSeq == seq:
+		case file.done:
+			file.sent = nil
+			continue
+		default:
+			file.sent, file.sentSeq = o.nextFileBlock(handle, file), seq
+		}
+		file.lastUsed = time.Now()
+		blocks = append(blocks, file.sent)
+	}
+	return blocks, 0
+}
+
+// nextFileBlock reads the next block of a file, returning its G70V5 object,
+// or a transport status when the file cannot be read
+func (o *outstation) nextFileBlock(handle uint32, file *openFile) []byte {
+	data := make([]byte, file.blockSize)
+	n, err := io.ReadFull(file.reader, data)
+	last := false
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		last = true
+	case err != nil:
+		o.logger.Warn("Outstation %s: Failed to read %q: %v", o.config.ID, file.name, err)
+		file.done = true
+		obj := app.FileTransportStatusObject{Handle: handle, Block: file.block, Status: app.FileStatusFatal, Text: err.Error()}
+		return app.BuildFileObject(app.FileTransportStatus, obj.Serialize())
+	default:
+		// A full block is the last one when nothing follows it
+		_, err := file.reader.Peek(1)
+		last = err == io.EOF
+	}
+
+	obj := app.FileTransportObject{Handle: handle, Block: file.block, Last: last, Data: data[:n]}
+	file.block++
+	file.done = last
+	return app.BuildFileObject(app.FileTransport, obj.Serialize())
+}
+
+// writeFileBlocks writes the G70V5 blocks of a WRITE request, returning a
+// transport status for each
+func (o *outstation) writeFileBlocks(header *app.ObjectHeader, parser *app.Parser) ([]byte, uint8) {
+	if o.config.FileTransfer.FileSystem == nil || header.Variation != app.FileTransport {
+		return nil, types.IIN2ObjectUnknown
+	}
+	if _, ok := header.Range.(app.FreeFormatRange); !ok {
+		return nil, types.IIN2ObjectUnknown
+	}
+
+	var statuses []byte
+	for i := uint32(0); i < app.GetCount(header.Range); i++ {
+		object, err := parser.ReadFreeFormatObject()
+		if err != nil {
+			return statuses, types.IIN2ParameterError
+		}
+		block, err := app.ParseFileTransport(object)
+		if err != nil {
+			return statuses, types.IIN2ParameterError
+		}
+		obj := app.FileTransportStatusObject{Handle: block.Handle, Block: block.Block, Last: block.Last, Status: o.writeFileBlock(block)}
+		statuses = append(statuses, app.BuildFileObject(app.FileTransportStatus, obj.Serialize())...)
+	}
+	return statuses, 0
+}
+
+// writeFileBlock writes a block to its file, which must be the next block
+// and no larger than the negotiated size
+func (o *outstation) writeFileBlock(block app.FileTransportObject) app.FileStatus {
+	o.files.mu.Lock()
+	defer o.files.mu.Unlock()
+
+	file, ok := o.files.open[block.Handle]
+	switch {
+	case !ok || file.writer == nil:
+		return app.FileStatusInvalidHandle
+	case file.done || block.Block != file.block:
+		o.logger.Warn("Outstation %s: Block %d of %q out of sequence, expected %d", o.config.ID, block.Block, file.name, file.block)
+		return app.FileStatusBlockSequence
+	case len(block.Data) > file.blockSize:
+		return app.FileStatusWriteBlockSize
+	}
+
+	if _, err := file.writer.Write(block.Data); err != nil {
+		o.logger.Warn("Outstation %s: Failed to write %q: %v", o.config.ID, file.name, err)
+		return fileErrorStatus(err)
+	}
+	file.block++
+	file.done = block.Last
+	file.lastUsed = time.Now()
+	return app.FileStatusSuccess
+}
+
+// closeFiles closes every open file and forgets the issued keys
+func (o *outstation) closeFiles() {
+	o.files.mu.Lock()
+	defer o.files.mu.Unlock()
+
+	for handle, file := range o.files.open {
+		if err := file.close(); err != nil {
+			o.logger.Warn("Outstation %s: Failed to close %q: %v", o.config.ID, file.name, err)
+		}
+		delete(o.files.open, handle)
+	}
+	o.files.keys = nil
+}
@@ -0,0 +1,457 @@
+package outstation
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// fileConfig serves a temporary directory holding data.bin (1000 bytes)
+// and an empty directory logs
+func fileConfig(t *testing.T) (OutstationConfig, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "data.bin"), bytes.Repeat([]byte("0123456789"), 100), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "logs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	fsys, err := DirFileSystem(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := allTypesConfig()
+	config.FileTransfer = FileTransferConfig{FileSystem: fsys}
+	return config, dir
+}
+
+// fileObjects returns the G70 objects of a response, by variation
+func fileObjects(t *testing.T, resp *app.APDU) ([]uint8, [][]byte) {
+	t.Helper()
+
+	var variations []uint8
+	var objects [][]byte
+	parser := app.NewParser(resp.Objects)
+	for parser.HasMore() {
+		header, err := parser.ReadObjectHeader()
+		if err != nil || header.Group != app.GroupFile {
+			t.Fatalf("Expected a G70 header, got %+v, %v", header, err)
+		}
+		for i := uint32(0); i < app.GetCount(header.Range); i++ {
+			object, err := parser.ReadFreeFormatObject()
+			if err != nil {
+				t.Fatalf("Failed to read file object: %v", err)
+			}
+			variations = append(variations, header.Variation)
+			objects = append(objects, object)
+		}
+	}
+	return variations, objects
+}
+
+// fileCommand sends a file request and returns the command status answering it
+func (h *testHarness) fileCommand(fc app.FunctionCode, variation uint8, object []byte) app.FileCommandStatusObject {
+	h.t.Helper()
+
+	resp := h.request(app.NewRequestAPDU(fc, 0, app.BuildFileObject(variation, object)))
+	variations, objects := fileObjects(h.t, resp)
+	if len(objects) != 1 || variations[0] != app.FileCommandStatus {
+		h.t.Fatalf("%s: expected one command status, got variations %v", fc, variations)
+	}
+	status, err := app.ParseFileCommandStatus(objects[0])
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	return status
+}
+
+// openFile opens a file and fails the test unless it succeeds
+func (h *testHarness) openFile(name string, mode app.FileMode, blockSize uint16) app.FileCommandStatusObject {
+	h.t.Helper()
+
+	cmd := app.FileCommandObject{Name: name, Mode: mode, MaxBlockSize: blockSize}
+	status := h.fileCommand(app.FuncOpenFile, app.FileCommand, cmd.Serialize())
+	if status.Status != app.FileStatusSuccess {
+		h.t.Fatalf("Open %q: %s", name, status.Status)
+	}
+	return status
+}
+
+// readBlock reads the next file block, failing unless there is exactly one.
+// A READ with the sequence number of the one before repeats it.
+func (h *testHarness) readBlock(seq uint8) app.FileTransportObject {
+	h.t.Helper()
+
+	resp := h.request(app.BuildReadRequest(seq, app.BuildAllObjects(app.GroupFile, app.FileTransport)))
+	variations, objects := fileObjects(h.t, resp)
+	if len(objects) != 1 || variations[0] != app.FileTransport {
+		h.t.Fatalf("Expected one block, got variations %v", variations)
+	}
+	block, err := app.ParseFileTransport(objects[0])
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	return block
+}
+
+// writeBlock writes a file block and returns its status
+func (h *testHarness) writeBlock(block app.FileTransportObject) app.FileStatus {
+	h.t.Helper()
+
+	resp := h.request(app.BuildWriteRequest(0, app.BuildFileObject(app.FileTransport, block.Serialize())))
+	variations, objects := fileObjects(h.t, resp)
+	if len(objects) != 1 || variations[0] != app.FileTransportStatus {
+		h.t.Fatalf("Expected one transport status, got variations %v", variations)
+	}
+	status, err := app.ParseFileTransportStatus(objects[0])
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	if status.Handle != block.Handle || status.Block != block.Block {
+		h.t.Errorf("Status for handle %d block %d, want handle %d block %d", status.Handle, status.Block, block.Handle, block.Block)
+	}
+	return status.Status
+}
+
+func TestReadFile(t *testing.T) {
+	config, _ := fileConfig(t)
+	h := newTestHarness(t, config)
+
+	open := h.openFile("/data.bin", app.FileModeRead, 400)
+	if open.Size != 1000 || open.MaxBlockSize != 400 {
+		t.Fatalf("Open: got size %d, block size %d, want 1000 and 400", open.Size, open.MaxBlockSize)
+	}
+
+	var data []byte
+	for i := uint32(0); i < 3; i++ {
+		block := h.readBlock(uint8(i))
+		if block.Handle != open.Handle || block.Block != i || block.Last != (i == 2) {
+			t.Fatalf("Block %d: got handle %d, block %d, last %v", i, block.Handle, block.Block, block.Last)
+		}
+		data = append(data, block.Data...)
+	}
+	if !bytes.Equal(data, bytes.Repeat([]byte("0123456789"), 100)) {
+		t.Errorf("Read %d bytes that differ from the file", len(data))
+	}
+
+	// Nothing more once the last block was sent
+	resp := h.request(app.BuildReadRequest(3, app.BuildAllObjects(app.GroupFile, app.FileTransport)))
+	if len(resp.Objects) != 0 || resp.IIN.IIN2 != 0 {
+		t.Errorf("Read after the last block: got % X, IIN2 0x%02X", resp.Objects, resp.IIN.IIN2)
+	}
+
+	closeStatus := h.fileCommand(app.FuncCloseFile, app.FileCommandStatus, app.FileCommandStatusObject{Handle: open.Handle}.Serialize())
+	if closeStatus.Status != app.FileStatusSuccess {
+		t.Errorf("Close: %s", closeStatus.Status)
+	}
+	closeStatus = h.fileCommand(app.FuncCloseFile, app.FileCommandStatus, app.FileCommandStatusObject{Handle: open.Handle}.Serialize())
+	if closeStatus.Status != app.FileStatusInvalidHandle {
+		t.Errorf("Second close: got %s, want INVALID_HANDLE", closeStatus.Status)
+	}
+}
+
+func TestReadFileRepeated(t *testing.T) {
+	config, _ := fileConfig(t)
+	h := newTestHarness(t, config)
+
+	open := h.openFile("/data.bin", app.FileModeRead, 600)
+	h.readBlock(1)
+
+	// The response to the second READ is lost, so the master repeats it
+	first := h.readBlock(2)
+	repeat := h.readBlock(2)
+	if repeat.Block != 1 || !repeat.Last || !bytes.Equal(repeat.Data, first.Data) {
+		t.Errorf("Repeated READ: got block %d, last %v, %d octets, want block 1 again", repeat.Block, repeat.Last, len(repeat.Data))
+	}
+
+	// The last block is also resent, and nothing after it
+	if repeat := h.readBlock(2); repeat.Block != 1 {
+		t.Errorf("Second repeat: got block %d, want 1", repeat.Block)
+	}
+	resp := h.request(app.BuildReadRequest(3, app.BuildAllObjects(app.GroupFile, app.FileTransport)))
+	if len(resp.Objects) != 0 {
+		t.Errorf("READ after the last block: got % X", resp.Objects)
+	}
+	h.fileCommand(app.FuncCloseFile, app.FileCommandStatus, app.FileCommandStatusObject{Handle: open.Handle}.Serialize())
+}
+
+// openStatus tries to open a file and returns the status
+func (h *testHarness) openStatus(name string, mode app.FileMode) app.FileStatus {
+	h.t.Helper()
+
+	cmd := app.FileCommandObject{Name: name, Mode: mode}
+	return h.fileCommand(app.FuncOpenFile, app.FileCommand, cmd.Serialize()).Status
+}
+
+func TestAbandonedFileReleased(t *testing.T) {
+	config, dir := fileConfig(t)
+	config.FileTransfer.MaxOpenFiles = 1
+	config.FileTransfer.InactivityTimeout = 100 * time.Millisecond
+	h := newTestHarness(t, config)
+
+	// A file in use stays open past the timeout
+	open := h.openFile("/data.bin", app.FileModeRead, 100)
+	for i := range 4 {
+		time.Sleep(40 * time.Millisecond)
+		h.readBlock(uint8(i))
+	}
+	if status := h.openStatus("/data.bin", app.FileModeRead); status != app.FileStatusTooManyOpen {
+		t.Fatalf("Open while in use: got %s, want TOO_MANY_OPEN", status)
+	}
+
+	// Once the master stops using it, the handle is released
+	waitFor(t, "abandoned file to close", func() bool { return !h.outstation.isFileOpen("data.bin") })
+	if block := h.writeBlock(app.FileTransportObject{Handle: open.Handle}); block != app.FileStatusInvalidHandle {
+		t.Errorf("Write to the expired handle: got %s, want INVALID_HANDLE", block)
+	}
+
+	// A partly written file is removed, as on ABORT
+	open = h.openFile("/partial.txt", app.FileModeWrite, 4)
+	h.writeBlock(app.FileTransportObject{Handle: open.Handle, Data: []byte("abcd")})
+	waitFor(t, "partly written file to close", func() bool { return !h.outstation.isFileOpen("partial.txt") })
+	if _, err := os.Stat(filepath.Join(dir, "partial.txt")); !os.IsNotExist(err) {
+		t.Errorf("Partly written file: got %v, want it removed", err)
+	}
+}
+
+func TestFilesClosedOnConnectionLost(t *testing.T) {
+	config, _ := fileConfig(t)
+	config.FileTransfer.MaxOpenFiles = 1
+	h := newTestHarness(t, config)
+
+	h.openFile("/data.bin", app.FileModeRead, 100)
+	h.outstation.session.OnConnectionLost()
+	if status := h.openStatus("/data.bin", app.FileModeRead); status != app.FileStatusSuccess {
+		t.Errorf("Open after the connection was lost: got %s, want SUCCESS", status)
+	}
+}
+
+func TestFileBlockSizeFitsFragment(t *testing.T) {
+	config, _ := fileConfig(t)
+	config.MaxTxFragSize = 249
+	h := newTestHarness(t, config)
+
+	open := h.openFile("/data.bin", app.FileModeRead, 1024)
+	if want := uint16(249 - responseHeaderSize - fileBlockOverhead); open.MaxBlockSize != want {
+		t.Errorf("Block size: got %d, want %d", open.MaxBlockSize, want)
+	}
+	resp := h.request(app.BuildReadRequest(0, app.BuildAllObjects(app.GroupFile, app.FileTransport)))
+	if !resp.FIN || len(resp.Objects)+responseHeaderSize != 249 {
+		t.Errorf("Block response: FIN %v, %d object octets, want a full single fragment", resp.FIN, len(resp.Objects))
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	config, dir := fileConfig(t)
+	h := newTestHarness(t, config)
+
+	open := h.openFile("/new.txt", app.FileModeWrite, 4)
+	if status := h.writeBlock(app.FileTransportObject{Handle: open.Handle, Block: 0, Data: []byte("abcd")}); status != app.FileStatusSuccess {
+		t.Fatalf("Block 0: %s", status)
+	}
+	if status := h.writeBlock(app.FileTransportObject{Handle: open.Handle, Block: 2, Data: []byte("ef")}); status != app.FileStatusBlockSequence {
+		t.Errorf("Skipped block: got %s, want BLOCK_SEQUENCE", status)
+	}
+	if status := h.writeBlock(app.FileTransportObject{Handle: open.Handle, Block: 1, Data: []byte("efghi")}); status != app.FileStatusWriteBlockSize {
+		t.Errorf("Oversized block: got %s, want WRITE_BLOCK_SIZE", status)
+	}
+	if status := h.writeBlock(app.FileTransportObject{Handle: open.Handle, Block: 1, Last: true, Data: []byte("ef")}); status != app.FileStatusSuccess {
+		t.Fatalf("Block 1: %s", status)
+	}
+	if status := h.writeBlock(app.FileTransportObject{Handle: 99, Data: []byte("x")}); status != app.FileStatusInvalidHandle {
+		t.Errorf("Unknown handle: got %s, want INVALID_HANDLE", status)
+	}
+	h.fileCommand(app.FuncCloseFile, app.FileCommandStatus, app.FileCommandStatusObject{Handle: open.Handle}.Serialize())
+
+	if data, err := os.ReadFile(filepath.Join(dir, "new.txt")); err != nil || string(data) != "abcdef" {
+		t.Errorf("Written file: got %q, %v, want \"abcdef\"", data, err)
+	}
+
+	// Appending keeps the contents; aborting a written file removes it
+	open = h.openFile("/new.txt", app.FileModeAppend, 0)
+	h.writeBlock(app.FileTransportObject{Handle: open.Handle, Last: true, Data: []byte("g")})
+	h.fileCommand(app.FuncCloseFile, app.FileCommandStatus, app.FileCommandStatusObject{Handle: open.Handle}.Serialize())
+	if data, _ := os.ReadFile(filepath.Join(dir, "new.txt")); string(data) != "abcdefg" {
+		t.Errorf("Appended file: got %q, want \"abcdefg\"", data)
+	}
+
+	open = h.openFile("/partial.txt", app.FileModeWrite, 0)
+	h.writeBlock(app.FileTransportObject{Handle: open.Handle, Data: []byte("x")})
+	h.fileCommand(app.FuncAbortFile, app.FileCommandStatus, app.FileCommandStatusObject{Handle: open.Handle}.Serialize())
+	if _, err := os.Stat(filepath.Join(dir, "partial.txt")); !os.IsNotExist(err) {
+		t.Errorf("Aborted file: got %v, want it removed", err)
+	}
+}
+
+func TestReadDirectory(t *testing.T) {
+	config, _ := fileConfig(t)
+	h := newTestHarness(t, config)
+
+	open := h.openFile("/", app.FileModeRead, 0)
+	block := h.readBlock(0)
+	if !block.Last || uint32(len(block.Data)) != open.Size {
+		t.Fatalf("Listing: got %d bytes, last %v, want %d bytes in one block", len(block.Data), block.Last, open.Size)
+	}
+
+	var names []string
+	for data := block.Data; len(data) > 0; {
+		descriptor, size, err := app.ParseFileDescriptor(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, descriptor.Name)
+		if descriptor.Name == "data.bin" && (descriptor.Type != app.FileTypeSimple || descriptor.Size != 1000) {
+			t.Errorf("data.bin: got %+v", descriptor)
+		}
+		if descriptor.Name == "logs" && (descriptor.Type != app.FileTypeDirectory || descriptor.Size != 0) {
+			t.Errorf("logs: got %+v", descriptor)
+		}
+		data = data[size:]
+	}
+	if len(names) != 2 {
+		t.Errorf("Entries: got %v, want data.bin and logs", names)
+	}
+
+	resp := h.request(app.NewRequestAPDU(app.FuncGetFileInfo, 0, app.BuildFileObject(app.FileDescriptor, app.FileDescriptorObject{Name: "/logs"}.Serialize())))
+	variations, objects := fileObjects(t, resp)
+	if len(objects) != 1 || variations[0] != app.FileDescriptor {
+		t.Fatalf("File info: got variations %v", variations)
+	}
+	if descriptor, _, _ := app.ParseFileDescriptor(objects[0]); descriptor.Name != "/logs" || descriptor.Type != app.FileTypeDirectory {
+		t.Errorf("File info: got %+v", descriptor)
+	}
+}
+
+func TestFileCommandErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  app.FileCommandObject
+		want app.FileStatus
+	}{
+		{"missing file", app.FileCommandObject{Name: "/none", Mode: app.FileModeRead}, app.FileStatusNotFound},
+		{"outside the root", app.FileCommandObject{Name: "/../data.bin", Mode: app.FileModeRead}, app.FileStatusNotFound},
+		{"invalid mode", app.FileCommandObject{Name: "/data.bin", Mode: 7}, app.FileStatusInvalidMode},
+		{"file open for reading", app.FileCommandObject{Name: "/open.bin", Mode: app.FileModeWrite}, app.FileStatusLocked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, dir := fileConfig(t)
+			os.WriteFile(filepath.Join(dir, "open.bin"), nil, 0644)
+			h := newTestHarness(t, config)
+			h.openFile("/open.bin", app.FileModeRead, 0)
+
+			if status := h.fileCommand(app.FuncOpenFile, app.FileCommand, tt.cmd.Serialize()); status.Status != tt.want {
+				t.Errorf("Open: got %s, want %s", status.Status, tt.want)
+			}
+		})
+	}
+
+	config, _ := fileConfig(t)
+	config.FileTransfer.MaxOpenFiles = 1
+	h := newTestHarness(t, config)
+	h.openFile("/data.bin", app.FileModeRead, 0)
+	if status := h.fileCommand(app.FuncOpenFile, app.FileCommand, app.FileCommandObject{Name: "/data.bin", Mode: app.FileModeRead}.Serialize()); status.Status != app.FileStatusTooManyOpen {
+		t.Errorf("Second open: got %s, want TOO_MANY_OPEN", status.Status)
+	}
+	if status := h.fileCommand(app.FuncDeleteFile, app.FileCommand, app.FileCommandObject{Name: "/data.bin"}.Serialize()); status.Status != app.FileStatusLocked {
+		t.Errorf("Delete of an open file: got %s, want FILE_LOCKED", status.Status)
+	}
+}
+
+func TestFileTooLarge(t *testing.T) {
+	config, dir := fileConfig(t)
+	f, err := os.Create(filepath.Join(dir, "logs", "huge.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Truncate(1 << 32); err != nil {
+		f.Close()
+		t.Skipf("Cannot create a 4 GiB file: %v", err)
+	}
+	f.Close()
+	h := newTestHarness(t, config)
+
+	// A size that does not fit in 32 bits is never reported
+	if status := h.openStatus("/logs/huge.bin", app.FileModeRead); status != app.FileStatusFatal {
+		t.Errorf("Open: got %s, want FATAL", status)
+	}
+
+	resp := h.request(app.NewRequestAPDU(app.FuncGetFileInfo, 1, app.BuildFileObject(app.FileDescriptor, app.FileDescriptorObject{Name: "/logs/huge.bin"}.Serialize())))
+	variations, objects := fileObjects(t, resp)
+	if len(objects) != 1 || variations[0] != app.FileCommandStatus {
+		t.Fatalf("File info: got variations %v, want a command status", variations)
+	}
+	if status, _ := app.ParseFileCommandStatus(objects[0]); status.Status != app.FileStatusFatal {
+		t.Errorf("File info: got %s, want FATAL", status.Status)
+	}
+
+	open := h.openFile("/logs", app.FileModeRead, 0)
+	if open.Size != 0 {
+		t.Errorf("Listing: got %d bytes, want the large file left out", open.Size)
+	}
+}
+
+func TestFileAuthentication(t *testing.T) {
+	config, dir := fileConfig(t)
+	config.FileTransfer.Authenticate = func(user, password string) bool { return user == "engineer" && password == "pw" }
+	h := newTestHarness(t, config)
+
+	authenticate := func(user, password string) uint32 {
+		auth := app.FileAuthenticationObject{UserName: user, Password: password}
+		resp := h.request(app.NewRequestAPDU(app.FuncAuthenticateFile, 0, app.BuildFileObject(app.FileAuthentication, auth.Serialize())))
+		_, objects := fileObjects(t, resp)
+		reply, err := app.ParseFileAuthentication(objects[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return reply.Key
+	}
+	deleteFile := func(key uint32) app.FileStatus {
+		cmd := app.FileCommandObject{Name: "/data.bin", AuthKey: key}
+		return h.fileCommand(app.FuncDeleteFile, app.FileCommand, cmd.Serialize()).Status
+	}
+
+	if key := authenticate("engineer", "wrong"); key != 0 {
+		t.Errorf("Refused credentials: got key %d, want 0", key)
+	}
+	if status := deleteFile(0); status != app.FileStatusPermissionDenied {
+		t.Errorf("Delete without a key: got %s, want PERMISSION_DENIED", status)
+	}
+
+	key := authenticate("engineer", "pw")
+	if key == 0 {
+		t.Fatal("Expected a key for valid credentials")
+	}
+	if status := deleteFile(key); status != app.FileStatusSuccess {
+		t.Fatalf("Delete with a key: %s", status)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "data.bin")); !os.IsNotExist(err) {
+		t.Errorf("Deleted file: got %v, want it removed", err)
+	}
+	if status := deleteFile(key); status != app.FileStatusPermissionDenied {
+		t.Errorf("Reused key: got %s, want PERMISSION_DENIED", status)
+	}
+}
+
+func TestFileTransferDisabled(t *testing.T) {
+	h := newTestHarness(t, allTypesConfig())
+
+	cmd := app.FileCommandObject{Name: "/data.bin", Mode: app.FileModeRead}
+	resp := h.request(app.NewRequestAPDU(app.FuncOpenFile, 0, app.BuildFileObject(app.FileCommand, cmd.Serialize())))
+	if resp.IIN.IIN2&types.IIN2NoFuncCodeSupport == 0 {
+		t.Errorf("Open: got IIN2 0x%02X, want function code not supported", resp.IIN.IIN2)
+	}
+	resp = h.request(app.BuildReadRequest(0, app.BuildAllObjects(app.GroupFile, app.FileTransport)))
+	if resp.IIN.IIN2&types.IIN2ObjectUnknown == 0 {
+		t.Errorf("Read of file blocks: got IIN2 0x%02X, want object unknown", resp.IIN.IIN2)
+	}
+}
@@ -0,0 +1,37 @@
+package outstation
+
+import (
+	"io"
+	"io/fs"
+	"os"
+)
+
+// dirFileSystem serves the files below a directory of the host
+type dirFileSystem struct {
+	fs.FS
+	root *os.Root
+}
+
+// DirFileSystem returns a FileSystem serving the files below dir. Names
+// cannot escape the directory, including through symbolic links.
+func DirFileSystem(dir string) (FileSystem, error) {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &dirFileSystem{FS: root.FS(), root: root}, nil
+}
+
+// OpenWrite creates a file, or truncates it unless appending
+func (d *dirFileSystem) OpenWrite(name string, append bool) (io.WriteCloser, error) {
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if append {
+		flag = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	return d.root.OpenFile(name, flag, 0644)
+}
+
+// Remove removes a file or an empty directory
+func (d *dirFileSystem) Remove(name string) error {
+	return d.root.Remove(name)
+}
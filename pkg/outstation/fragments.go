@@ -0,0 +1,114 @@
+package outstation
+
+import (
+	"sort"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// defaultMaxTxFragSize is used when MaxTxFragSize is not configured
+const defaultMaxTxFragSize = 2048
+
+// responseHeaderSize is the size of the control, function code and IIN octets
+const responseHeaderSize = 4
+
+// responseFragment holds the objects of one response fragment
+type responseFragment struct {
+	objects   []byte
+	hasEvents bool
+	lastEvent uint64 // Insertion sequence of the last event in the fragment
+}
+
+// fragmentWriter collects object headers into fragments no larger than
+// MaxTxFragSize. Headers are only split between objects.
+type fragmentWriter struct {
+	capacity  int // Object octets available per fragment
+	fragments []responseFragment
+}
+
+// newFragmentWriter creates a writer for the configured fragment size
+func (o *outstation) newFragmentWriter() *fragmentWriter {
+	size := int(o.config.MaxTxFragSize)
+	if size == 0 {
+		size = defaultMaxTxFragSize
+	}
+	return &fragmentWriter{
+		capacity:  size - responseHeaderSize,
+		fragments: []responseFragment{{}},
+	}
+}
+
+// current returns the fragment being filled
+func (w *fragmentWriter) current() *responseFragment {
+	return &w.fragments[len(w.fragments)-1]
+}
+
+// remaining returns the object octets still free in the current fragment
+func (w *fragmentWriter) remaining() int {
+	return w.capacity - len(w.current().objects)
+}
+
+// fit returns how many of n objects can be written under one header in the
+// current fragment, where size(k) is the encoded length of a header with k
+// objects. A new fragment is started when none fit, and at least one object
+// is always written even if it exceeds an empty fragment.
+func (w *fragmentWriter) fit(n int, size func(k int) int) int {
+	count := sort.Search(n, func(i int) bool { return size(i+1) > w.remaining() })
+	if count == 0 && len(w.current().objects) > 0 {
+		w.fragments = append(w.fragments, responseFragment{})
+		count = sort.Search(n, func(i int) bool { return size(i+1) > w.remaining() })
+	}
+	if count == 0 {
+		count = 1
+	}
+	return count
+}
+
+// write appends an encoded header and its objects to the current fragment
+func (w *fragmentWriter) write(data []byte) {
+	frag := w.current()
+	frag.objects = append(frag.objects, data...)
+}
+
+// writeEvents appends an encoded event header, recording the last event it holds
+func (w *fragmentWriter) writeEvents(data []byte, lastEvent uint64) {
+	w.write(data)
+	frag := w.current()
+	frag.hasEvents = true
+	frag.lastEvent = lastEvent
+}
+
+// headerSize returns the encoded length of an object header
+func headerSize(group, variation uint8, qualifier app.QualifierCode, rng app.Range) int {
+	builder := app.NewObjectBuilder()
+	builder.AddHeader(group, variation, qualifier, rng)
+	return len(builder.Build())
+}
+
+// sendResponseFragment sends the first of the remaining response fragments.
+// Every fragment but the last, and any fragment carrying events, requests a
+// CONFIRM; the next fragment is sent once it arrives.
+func (o *outstation) sendResponseFragment(seq uint8, first bool, requestIIN types.IIN, fragments []responseFragment) error {
+	frag := fragments[0]
+	rest := fragments[1:]
+
+	iin := o.responseIIN()
+	iin.IIN1 |= requestIIN.IIN1
+	iin.IIN2 |= requestIIN.IIN2
+
+	response := app.NewResponseAPDU(seq, iin, frag.objects)
+	response.FIR = first
+	response.FIN = len(rest) == 0
+	response.CON = frag.hasEvents || !response.FIN
+
+	if response.CON {
+		o.startSolConfirm(seq, requestIIN, frag, rest)
+	}
+
+	if !response.FIN {
+		o.logger.Debug("Outstation %s: Sending response fragment seq=%d, %d more to follow",
+			o.config.ID, seq, len(rest))
+	}
+	return o.session.sendAPDU(response.Serialize())
+}
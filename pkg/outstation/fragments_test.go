@@ -0,0 +1,132 @@
+package outstation
+
+import (
+	"testing"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+func TestMultiFragmentStaticResponse(t *testing.T) {
+	config := staticTestConfig(0, 300)
+	config.MaxTxFragSize = 256
+	h := newTestHarness(t, config)
+	for i := uint16(0); i < 300; i++ {
+		h.outstation.database.UpdateAnalog(i, types.Analog{Value: float64(i), Flags: types.FlagOnline}, EventModeSuppress)
+	}
+
+	resp := h.request(app.BuildReadRequest(7, app.BuildClassRead(app.Class0)))
+
+	var values []float32
+	seq := uint8(7)
+	for n := 0; ; n++ {
+		if resp.Sequence != seq {
+			t.Fatalf("Fragment %d: got seq %d, want %d", n, resp.Sequence, seq)
+		}
+		if resp.FIR != (n == 0) {
+			t.Errorf("Fragment %d: FIR=%v", n, resp.FIR)
+		}
+		if size := len(resp.Serialize()); size > 256 {
+			t.Errorf("Fragment %d: %d bytes exceeds MaxTxFragSize", n, size)
+		}
+
+		for _, sh := range parseStaticHeaders(t, resp.Objects) {
+			for _, obj := range sh.objects {
+				values = append(values, app.ParseAnalogInputFloat(obj).Value.(float32))
+			}
+		}
+
+		if resp.FIN {
+			if resp.CON {
+				t.Error("Final fragment without events should not request confirmation")
+			}
+			break
+		}
+		if !resp.CON {
+			t.Fatalf("Fragment %d: non-final fragment should request confirmation", n)
+		}
+
+		// The next fragment is only sent once this one is confirmed
+		h.expectNoResponse()
+		resp = h.request(app.BuildConfirmRequest(seq))
+		seq = app.NextSequence(seq)
+	}
+
+	if len(values) != 300 {
+		t.Fatalf("Points: got %d, want 300", len(values))
+	}
+	for i, v := range values {
+		if v != float32(i) {
+			t.Fatalf("Point %d: got %v, want %d", i, v, i)
+		}
+	}
+}
+
+func TestMultiFragmentEventsClearedPerFragment(t *testing.T) {
+	config := eventTestConfig()
+	config.MaxBinaryEvents = 50
+	config.MaxTxFragSize = 64
+	h := newTestHarness(t, config)
+	eb := h.outstation.eventBuffer
+
+	// Binary events with time take 9 bytes each including the index
+	for i := 0; i < 20; i++ {
+		h.outstation.database.UpdateBinary(0, types.Binary{Value: i%2 == 0}, EventModeDetect)
+	}
+
+	resp := h.request(eventPoll(0))
+	if resp.FIN || !resp.CON {
+		t.Fatalf("Expected first of several fragments, got FIN=%v CON=%v", resp.FIN, resp.CON)
+	}
+	first := len(parseEventHeaders(t, resp.Objects)[0].indices)
+
+	resp = h.request(app.BuildConfirmRequest(0))
+	if resp.FIR || resp.Sequence != 1 || !resp.CON {
+		t.Fatalf("Second fragment: got FIR=%v seq=%d CON=%v", resp.FIR, resp.Sequence, resp.CON)
+	}
+	if got := eb.GetClass1Count(); got != 20-first {
+		t.Errorf("Class 1 count after first CONFIRM: got %d, want %d", got, 20-first)
+	}
+	second := len(parseEventHeaders(t, resp.Objects)[0].indices)
+
+	// A new request abandons the response; unconfirmed events are reported again
+	resp = h.request(eventPoll(2))
+	if got := len(parseEventHeaders(t, resp.Objects)[0].indices); got != first {
+		t.Errorf("Events in new response: got %d, want %d", got, first)
+	}
+	if got := eb.GetClass1Count(); got != 20-first {
+		t.Errorf("Class 1 count after abandoned response: got %d, want %d", got, 20-first)
+	}
+	if second != first {
+		t.Errorf("Events per fragment: got %d and %d", first, second)
+	}
+}
+
+func TestUnsolicitedLimitedToOneFragment(t *testing.T) {
+	config := unsolTestConfig()
+	config.MaxBinaryEvents = 50
+	config.MaxTxFragSize = 64
+	h := newTestHarness(t, config)
+	h.confirmNullUnsolicited()
+
+	updates := NewUpdateBuilder()
+	for i := 0; i < 20; i++ {
+		updates.UpdateBinary(types.Binary{Value: i%2 == 0}, 0, EventModeDetect)
+	}
+	h.outstation.Apply(updates.Build())
+
+	total := 0
+	for total < 20 {
+		resp := h.receive()
+		if !resp.FIR || !resp.FIN || len(resp.Serialize()) > 64 {
+			t.Fatalf("Unsolicited response must be a single fragment, got FIR=%v FIN=%v size=%d",
+				resp.FIR, resp.FIN, len(resp.Serialize()))
+		}
+		total += len(parseEventHeaders(t, resp.Objects)[0].indices)
+		h.outstation.onReceiveAPDU(unsolConfirm(resp.Sequence))
+		h.expectUnsolResult(true, resp.Sequence)
+	}
+	if total != 20 {
+		t.Errorf("Events reported: got %d, want 20", total)
+	}
+}
@@ -0,0 +1,179 @@
+package outstation
+
+import (
+	"time"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// freezeState tracks the freeze-at-time request being carried out
+type freezeState struct {
+	gen      uint64 // Distinguishes successive schedules so stale timers are ignored
+	timer    *time.Timer
+	next     time.Time
+	interval time.Duration // Zero for a single freeze
+	indices  []int
+}
+
+// handleFreeze handles the IMMEDIATE FREEZE, FREEZE CLEAR and FREEZE AT TIME
+// requests and their NO ACK variants
+func (o *outstation) handleFreeze(apdu *app.APDU) error {
+	o.logger.Debug("Outstation %s: Handling %s request", o.config.ID, apdu.FunctionCode)
+
+	var noAck, clear, atTime bool
+	switch apdu.FunctionCode {
+	case app.FuncImmediateFreezeNoAck:
+		noAck = true
+	case app.FuncFreezeClear:
+		clear = true
+	case app.FuncFreezeClearNoAck:
+		clear, noAck = true, true
+	case app.FuncFreezeAtTime:
+		atTime = true
+	case app.FuncFreezeAtTimeNoAck:
+		atTime, noAck = true, true
+	}
+
+	iin := o.responseIIN()
+	indices, schedule, iin2 := o.parseFreezeObjects(apdu.Objects, atTime)
+	iin.IIN2 |= iin2
+
+	switch {
+	case len(indices) == 0:
+		// Nothing valid to freeze
+	case atTime:
+		o.scheduleFreeze(schedule, indices)
+	default:
+		o.database.freezeCounters(indices, clear)
+		o.notifyUnsolicited()
+	}
+
+	if noAck {
+		return nil
+	}
+
+	response := app.NewResponseAPDU(apdu.Sequence, iin, nil)
+	return o.session.sendAPDU(response.Serialize())
+}
+
+// parseFreezeObjects returns the counters selected by a freeze request and,
+// for freeze-at-time, its time and interval object
+func (o *outstation) parseFreezeObjects(objects []byte, atTime bool) ([]int, app.TimeAndInterval, uint8) {
+	var indices []int
+	var schedule app.TimeAndInterval
+	var iin2 uint8
+	haveSchedule := false
+
+	parser := app.NewParser(objects)
+	for parser.HasMore() {
+		header, err := parser.ReadObjectHeader()
+		if err != nil {
+			o.logger.Warn("Outstation %s: Failed to parse freeze object header: %v", o.config.ID, err)
+			return indices, schedule, iin2 | types.IIN2ParameterError
+		}
+
+		switch {
+		case atTime && header.Group == app.GroupTimeDate && header.Variation == app.TimeDateInterval:
+			data, err := parser.ReadBytes(app.GetObjectSize(header.Group, header.Variation))
+			if err != nil || app.GetCount(header.Range) != 1 {
+				return indices, schedule, iin2 | types.IIN2ParameterError
+			}
+			schedule = app.ParseTimeAndInterval(data)
+			haveSchedule = true
+
+		case header.Group == app.GroupCounter:
+			sel, selIIN, err := selectPoints(header, parser, o.database.CounterCount())
+			if err != nil {
+				return indices, schedule, iin2 | types.IIN2ParameterError
+			}
+			iin2 |= selIIN
+			indices = append(indices, sel.indices...)
+
+		default:
+			o.logger.Debug("Outstation %s: Unsupported freeze object G%dV%d", o.config.ID, header.Group, header.Variation)
+			return indices, schedule, iin2 | types.IIN2ObjectUnknown
+		}
+	}
+
+	// Freeze-at-time must say when to freeze
+	if atTime && !haveSchedule {
+		return nil, schedule, iin2 | types.IIN2ParameterError
+	}
+	return indices, schedule, iin2
+}
+
+// scheduleFreeze replaces any scheduled freeze with one at the given time,
+// repeated every interval if the interval is non-zero. The time is DNP3 time,
+// converted through the synchronized clock. A zero time starts the interval
+// from now; a time already past freezes at the next interval boundary, or
+// immediately for a single freeze.
+func (o *outstation) scheduleFreeze(schedule app.TimeAndInterval, indices []int) {
+	now := time.Now()
+	interval := time.Duration(schedule.Interval) * time.Millisecond
+
+	next := o.clock.localTime(types.DNP3Time(schedule.Time))
+	if schedule.Time == 0 {
+		next = now.Add(interval)
+	}
+	if next.Before(now) && interval > 0 {
+		periods := now.Sub(next)/interval + 1
+		next = next.Add(periods * interval)
+	}
+
+	o.stateMu.Lock()
+	defer o.stateMu.Unlock()
+
+	o.stopFreezeTimer()
+	o.freeze.gen++
+	o.freeze.next = next
+	o.freeze.interval = interval
+	o.freeze.indices = indices
+
+	gen := o.freeze.gen
+	o.freeze.timer = time.AfterFunc(time.Until(next), func() {
+		o.onScheduledFreeze(gen)
+	})
+
+	o.logger.Debug("Outstation %s: Freeze of %d counters scheduled at %s, interval %s",
+		o.config.ID, len(indices), next, interval)
+}
+
+// onScheduledFreeze performs a scheduled freeze and arms the next one
+func (o *outstation) onScheduledFreeze(gen uint64) {
+	o.stateMu.Lock()
+	if o.freeze.gen != gen {
+		o.stateMu.Unlock()
+		return
+	}
+	indices := o.freeze.indices
+	if o.freeze.interval > 0 {
+		o.freeze.next = o.freeze.next.Add(o.freeze.interval)
+		o.freeze.timer = time.AfterFunc(time.Until(o.freeze.next), func() {
+			o.onScheduledFreeze(gen)
+		})
+	} else {
+		o.freeze.timer = nil
+	}
+	o.stateMu.Unlock()
+
+	o.database.freezeCounters(indices, false)
+	o.notifyUnsolicited()
+}
+
+// cancelFreeze cancels any scheduled freeze
+func (o *outstation) cancelFreeze() {
+	o.stateMu.Lock()
+	defer o.stateMu.Unlock()
+
+	o.freeze.gen++
+	o.stopFreezeTimer()
+}
+
+// stopFreezeTimer stops the freeze timer (caller holds stateMu)
+func (o *outstation) stopFreezeTimer() {
+	if o.freeze.timer != nil {
+		o.freeze.timer.Stop()
+		o.freeze.timer = nil
+	}
+}
@@ -0,0 +1,243 @@
+package outstation
+
+import (
+	"testing"
+	"time"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+func freezeTestConfig() OutstationConfig {
+	return OutstationConfig{
+		MaxBinaryEvents: 20,
+		Database: DatabaseConfig{
+			Counter:       make([]CounterPointConfig, 3),
+			FrozenCounter: []FrozenCounterPointConfig{{Class: 1}, {Class: 1}, {Class: 1}},
+		},
+	}
+}
+
+// setCounters sets counters 0..n-1 to base, base+1, ... without events
+func setCounters(db *Database, base uint32) {
+	for i := 0; i < db.CounterCount(); i++ {
+		db.UpdateCounter(uint16(i), types.Counter{Value: base + uint32(i), Flags: types.FlagOnline}, EventModeSuppress)
+	}
+}
+
+func TestFreezeFunctions(t *testing.T) {
+	tests := []struct {
+		name        string
+		fc          app.FunctionCode
+		objects     []byte
+		wantFrozen  []uint32
+		wantCounter []uint32
+	}{
+		{"immediate freeze all", app.FuncImmediateFreeze, app.BuildAllObjects(app.GroupCounter, 0),
+			[]uint32{100, 101, 102}, []uint32{100, 101, 102}},
+		{"freeze clear all", app.FuncFreezeClear, app.BuildAllObjects(app.GroupCounter, 0),
+			[]uint32{100, 101, 102}, []uint32{0, 0, 0}},
+		{"freeze clear range", app.FuncFreezeClear, app.BuildRangeRead(app.GroupCounter, 0, 1, 1),
+			[]uint32{0, 101, 0}, []uint32{100, 0, 102}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHarness(t, freezeTestConfig())
+			db := h.outstation.database
+			setCounters(db, 100)
+
+			resp := h.request(app.NewRequestAPDU(tt.fc, 0, tt.objects))
+			if resp.IIN.IIN2 != 0 || len(resp.Objects) != 0 {
+				t.Errorf("Response: got IIN2=0x%02X objects=%d, want empty", resp.IIN.IIN2, len(resp.Objects))
+			}
+
+			for i := range tt.wantFrozen {
+				frozen, _ := db.GetFrozenCounter(uint16(i))
+				counter, _ := db.GetCounter(uint16(i))
+				if frozen.Value != tt.wantFrozen[i] {
+					t.Errorf("Frozen counter %d: got %d, want %d", i, frozen.Value, tt.wantFrozen[i])
+				}
+				if tt.wantFrozen[i] != 0 && (!frozen.Time.IsValid() || frozen.Flags != types.FlagOnline) {
+					t.Errorf("Frozen counter %d: got time %d flags 0x%02X, want freeze time and online", i, frozen.Time, frozen.Flags)
+				}
+				if counter.Value != tt.wantCounter[i] {
+					t.Errorf("Counter %d: got %d, want %d", i, counter.Value, tt.wantCounter[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFreezeClearWithoutFrozenCounter(t *testing.T) {
+	config := freezeTestConfig()
+	config.Database.FrozenCounter = config.Database.FrozenCounter[:2]
+	h := newTestHarness(t, config)
+	db := h.outstation.database
+	setCounters(db, 100)
+
+	h.request(app.NewRequestAPDU(app.FuncFreezeClear, 0, app.BuildAllObjects(app.GroupCounter, 0)))
+
+	if v, _ := db.GetCounter(1); v.Value != 0 {
+		t.Errorf("Counter 1: got %d, want 0", v.Value)
+	}
+	if v, _ := db.GetCounter(2); v.Value != 102 {
+		t.Errorf("Counter 2 has no frozen counter: got %d, want 102", v.Value)
+	}
+}
+
+func TestFreezeClearResetsDeadband(t *testing.T) {
+	config := freezeTestConfig()
+	config.Database.Counter = []CounterPointConfig{{Class: 1, Deadband: 10}}
+	config.Database.FrozenCounter = []FrozenCounterPointConfig{{}}
+	h := newTestHarness(t, config)
+	db := h.outstation.database
+
+	db.UpdateCounter(0, types.Counter{Value: 100, Flags: types.FlagOnline}, EventModeDetect)
+	h.request(eventPoll(0))
+	h.outstation.onReceiveAPDU(app.BuildConfirmRequest(0).Serialize())
+
+	h.request(app.NewRequestAPDU(app.FuncFreezeClear, 1, app.BuildAllObjects(app.GroupCounter, 0)))
+
+	// Counting on from zero stays within the deadband
+	db.UpdateCounter(0, types.Counter{Value: 5, Flags: types.FlagOnline}, EventModeDetect)
+	if n := h.outstation.eventBuffer.GetClass1Count(); n != 0 {
+		t.Errorf("Class 1 events after counting from zero: got %d, want 0", n)
+	}
+}
+
+func TestFreezeGeneratesFrozenCounterEvents(t *testing.T) {
+	h := newTestHarness(t, freezeTestConfig())
+	setCounters(h.outstation.database, 5)
+
+	// Freezing the same values again still reports an event per freeze
+	h.request(app.NewRequestAPDU(app.FuncImmediateFreeze, 0, app.BuildRangeRead(app.GroupCounter, 0, 0, 1)))
+	h.request(app.NewRequestAPDU(app.FuncImmediateFreeze, 1, app.BuildRangeRead(app.GroupCounter, 0, 0, 0)))
+
+	resp := h.request(eventPoll(2))
+	headers := parseEventHeaders(t, resp.Objects)
+	if len(headers) != 1 || headers[0].group != app.GroupFrozenCounterEvent {
+		t.Fatalf("Expected a G23 header, got %+v", headers)
+	}
+	if got := headers[0].indices; len(got) != 3 || got[0] != 0 || got[1] != 1 || got[2] != 0 {
+		t.Errorf("Event indices: got %v, want [0 1 0]", got)
+	}
+}
+
+func TestFreezeNoAck(t *testing.T) {
+	h := newTestHarness(t, freezeTestConfig())
+	setCounters(h.outstation.database, 7)
+
+	h.outstation.onReceiveAPDU(app.NewRequestAPDU(app.FuncFreezeClearNoAck, 0, app.BuildAllObjects(app.GroupCounter, 0)).Serialize())
+	h.expectNoResponse()
+
+	if v, _ := h.outstation.database.GetFrozenCounter(2); v.Value != 9 {
+		t.Errorf("Frozen counter 2: got %d, want 9", v.Value)
+	}
+	if v, _ := h.outstation.database.GetCounter(2); v.Value != 0 {
+		t.Errorf("Counter 2: got %d, want 0", v.Value)
+	}
+}
+
+func TestFreezeInvalidRequests(t *testing.T) {
+	tests := []struct {
+		name     string
+		fc       app.FunctionCode
+		objects  []byte
+		wantIIN2 uint8
+	}{
+		{"unknown object", app.FuncImmediateFreeze, app.BuildAllObjects(app.GroupAnalogInput, 0), types.IIN2ObjectUnknown},
+		{"index out of range", app.FuncImmediateFreeze, app.BuildRangeRead(app.GroupCounter, 0, 5, 6), types.IIN2ParameterError},
+		{"freeze at time without time", app.FuncFreezeAtTime, app.BuildAllObjects(app.GroupCounter, 0), types.IIN2ParameterError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHarness(t, freezeTestConfig())
+			setCounters(h.outstation.database, 1)
+
+			resp := h.request(app.NewRequestAPDU(tt.fc, 0, tt.objects))
+			if resp.IIN.IIN2&tt.wantIIN2 == 0 {
+				t.Errorf("IIN2: got 0x%02X, want bit 0x%02X", resp.IIN.IIN2, tt.wantIIN2)
+			}
+			if v, _ := h.outstation.database.GetFrozenCounter(0); v.Value != 0 {
+				t.Errorf("Frozen counter 0: got %d, want no freeze", v.Value)
+			}
+		})
+	}
+}
+
+func TestFreezeAtTime(t *testing.T) {
+	h := newTestHarness(t, freezeTestConfig())
+	db := h.outstation.database
+	setCounters(db, 10)
+
+	// A single freeze shortly in the future
+	objects := append(app.BuildFreezeAtTime(time.Now().Add(30*time.Millisecond), 0), app.BuildAllObjects(app.GroupCounter, 0)...)
+	resp := h.request(app.NewRequestAPDU(app.FuncFreezeAtTime, 0, objects))
+	if resp.IIN.IIN2 != 0 {
+		t.Errorf("IIN2: got 0x%02X, want 0", resp.IIN.IIN2)
+	}
+	if v, _ := db.GetFrozenCounter(0); v.Value != 0 {
+		t.Fatalf("Counters frozen before the scheduled time")
+	}
+	waitFor(t, "scheduled freeze", func() bool {
+		v, _ := db.GetFrozenCounter(0)
+		return v.Value == 10
+	})
+}
+
+func TestFreezeAtTimeSyncedClock(t *testing.T) {
+	h := newTestHarness(t, freezeTestConfig())
+	db := h.outstation.database
+	setCounters(db, 10)
+
+	// The master's time is an hour ahead of the local clock, and so is the
+	// time it asks the freeze for
+	offset := time.Hour
+	h.syncTime(0, time.Now().Add(offset))
+	objects := append(app.BuildFreezeAtTime(time.Now().Add(offset+30*time.Millisecond), 0), app.BuildAllObjects(app.GroupCounter, 0)...)
+	h.request(app.NewRequestAPDU(app.FuncFreezeAtTime, 1, objects))
+
+	if v, _ := db.GetFrozenCounter(0); v.Value != 0 {
+		t.Fatalf("Counters frozen before the scheduled time")
+	}
+	waitFor(t, "scheduled freeze", func() bool {
+		v, _ := db.GetFrozenCounter(0)
+		return v.Value == 10
+	})
+}
+
+func TestFreezeAtTimePeriodic(t *testing.T) {
+	h := newTestHarness(t, freezeTestConfig())
+	db := h.outstation.database
+	setCounters(db, 10)
+
+	// A start time in the past freezes at the following interval boundaries
+	objects := append(app.BuildFreezeAtTime(time.Now().Add(-time.Hour), 20*time.Millisecond),
+		app.BuildRangeRead(app.GroupCounter, 0, 2, 2)...)
+	h.request(app.NewRequestAPDU(app.FuncFreezeAtTime, 0, objects))
+
+	waitFor(t, "first periodic freeze", func() bool {
+		v, _ := db.GetFrozenCounter(2)
+		return v.Value == 12
+	})
+
+	setCounters(db, 50)
+	waitFor(t, "second periodic freeze", func() bool {
+		v, _ := db.GetFrozenCounter(2)
+		return v.Value == 52
+	})
+	if v, _ := db.GetFrozenCounter(0); v.Value != 0 {
+		t.Errorf("Frozen counter 0: got %d, want 0 (not selected)", v.Value)
+	}
+
+	// A new freeze-at-time request replaces the schedule
+	objects = append(app.BuildFreezeAtTime(time.Now().Add(time.Hour), 0), app.BuildRangeRead(app.GroupCounter, 0, 2, 2)...)
+	h.request(app.NewRequestAPDU(app.FuncFreezeAtTime, 1, objects))
+	setCounters(db, 90)
+	time.Sleep(60 * time.Millisecond)
+	if v, _ := db.GetFrozenCounter(2); v.Value != 52 {
+		t.Errorf("Frozen counter 2 after reschedule: got %d, want 52", v.Value)
+	}
+}
@@ -0,0 +1,151 @@
+package outstation
+
+import (
+	"fmt"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// iinDeviceRestartIndex is the G80V1 index of IIN1.7, the only IIN bit a
+// master may write (and only to clear it)
+const iinDeviceRestartIndex = 7
+
+// responseIIN returns the IIN bits the outstation maintains itself (buffered
+// event classes, need time, local control, device trouble, restart, event
+// buffer overflow and config corrupt) merged with the application IIN
+func (o *outstation) responseIIN() types.IIN {
+	iin := o.callbacks.GetApplicationIIN()
+
+	classes := o.eventBuffer.unreportedClasses()
+	if classes.HasClass(app.Class1) {
+		iin.IIN1 |= types.IIN1Class1Events
+	}
+	if classes.HasClass(app.Class2) {
+		iin.IIN1 |= types.IIN1Class2Events
+	}
+	if classes.HasClass(app.Class3) {
+		iin.IIN1 |= types.IIN1Class3Events
+	}
+	if o.clock.needTime() {
+		iin.IIN1 |= types.IIN1NeedTime
+	}
+	if o.eventBuffer.HasOverflowed() {
+		iin.IIN2 |= types.IIN2EventBufferOverflow
+	}
+
+	o.stateMu.RLock()
+	if o.config.LocalControl {
+		iin.IIN1 |= types.IIN1LocalControl
+	}
+	if o.config.DeviceTrouble {
+		iin.IIN1 |= types.IIN1DeviceTrouble
+	}
+	if o.deviceRestart {
+		iin.IIN1 |= types.IIN1DeviceRestart
+	}
+	if o.configCorrupt {
+		iin.IIN2 |= types.IIN2ConfigCorrupt
+	}
+	o.stateMu.RUnlock()
+
+	return iin
+}
+
+// checkDatabaseConfig returns an error for the first point configured with a
+// class, variation, deadband mode or chatter filter the outstation cannot use
+func checkDatabaseConfig(config DatabaseConfig) error {
+	var err error
+	check := func(group uint8, eventType EventType, index int, static, event, class uint8) {
+		if err != nil {
+			return
+		}
+		switch {
+		case class > 3:
+			err = fmt.Errorf("G%d point %d: invalid class %d", group, index, class)
+		case static != 0 && !findStaticType(group).supports(static):
+			err = fmt.Errorf("G%d point %d: unsupported static variation %d", group, index, static)
+		case event != 0:
+			if eventGroup, v := eventObjectType(Event{Type: eventType, Variation: event}); v != event {
+				err = fmt.Errorf("G%d point %d: unsupported event variation G%dV%d", group, index, eventGroup, event)
+			}
+		}
+	}
+	checkDeadband := func(group uint8, index int, mode DeadbandMode, fullScale float64) {
+		if err != nil {
+			return
+		}
+		switch {
+		case mode < DeadbandAbsolute || mode > DeadbandIntegrating:
+			err = fmt.Errorf("G%d point %d: unknown deadband mode %d", group, index, mode)
+		case mode == DeadbandPercent && fullScale <= 0:
+			err = fmt.Errorf("G%d point %d: percent deadband without a full scale", group, index)
+		}
+	}
+	checkChatter := func(group uint8, index int, filter ChatterFilterConfig) {
+		if err == nil && filter.Transitions > 0 && (filter.Window <= 0 || filter.QuietPeriod <= 0) {
+			err = fmt.Errorf("G%d point %d: chatter filter needs a window and a quiet period", group, index)
+		}
+	}
+
+	for i, p := range config.Binary {
+		check(app.GroupBinaryInput, EventTypeBinary, i, p.StaticVariation, p.EventVariation, p.Class)
+		checkChatter(app.GroupBinaryInput, i, p.ChatterFilter)
+	}
+	for i, p := range config.DoubleBit {
+		check(app.GroupDoubleBitBinaryInput, EventTypeDoubleBitBinary, i, p.StaticVariation, p.EventVariation, p.Class)
+		checkChatter(app.GroupDoubleBitBinaryInput, i, p.ChatterFilter)
+	}
+	for i, p := range config.Analog {
+		check(app.GroupAnalogInput, EventTypeAnalog, i, p.StaticVariation, p.EventVariation, p.Class)
+		checkDeadband(app.GroupAnalogInput, i, p.DeadbandMode, p.FullScale)
+	}
+	for i, p := range config.Counter {
+		check(app.GroupCounter, EventTypeCounter, i, p.StaticVariation, p.EventVariation, p.Class)
+		checkDeadband(app.GroupCounter, i, p.DeadbandMode, float64(p.FullScale))
+	}
+	for i, p := range config.FrozenCounter {
+		check(app.GroupFrozenCounter, EventTypeFrozenCounter, i, p.StaticVariation, p.EventVariation, p.Class)
+	}
+	for i, p := range config.BinaryOutput {
+		check(app.GroupBinaryOutput, EventTypeBinaryOutputStatus, i, p.StaticVariation, p.EventVariation, p.Class)
+	}
+	for i, p := range config.AnalogOutput {
+		check(app.GroupAnalogOutputStatus, EventTypeAnalogOutputStatus, i, p.StaticVariation, p.EventVariation, p.Class)
+	}
+	for i, p := range config.OctetString {
+		check(app.GroupOctetString, EventTypeOctetString, i, 0, 0, p.Class)
+	}
+	return err
+}
+
+// writeIIN handles a WRITE of G80V1 packed IIN bits and returns the IIN2 bits
+// to report. Clearing IIN1.7 is the only write allowed.
+func (o *outstation) writeIIN(header *app.ObjectHeader, parser *app.Parser) uint8 {
+	r, ok := header.Range.(app.StartStopRange)
+	if !ok || header.Variation != 1 || r.Stop < r.Start {
+		return types.IIN2ParameterError
+	}
+
+	count := int(r.Stop-r.Start) + 1
+	data, err := parser.ReadBytes((count + 7) / 8)
+	if err != nil {
+		return types.IIN2ParameterError
+	}
+
+	var iin2 uint8
+	for n := 0; n < count; n++ {
+		index := r.Start + uint32(n)
+		set := data[n/8]&(1<<(n%8)) != 0
+		if index != iinDeviceRestartIndex || set {
+			iin2 |= types.IIN2ParameterError
+			continue
+		}
+
+		o.stateMu.Lock()
+		o.deviceRestart = false
+		o.stateMu.Unlock()
+		o.logger.Debug("Outstation %s: Device restart IIN cleared by master", o.config.ID)
+	}
+	return iin2
+}
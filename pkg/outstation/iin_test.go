@@ -0,0 +1,136 @@
+package outstation
+
+import (
+	"testing"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// class0Read builds an integrity READ of static data only
+func class0Read(seq uint8) *app.APDU {
+	return app.BuildReadRequest(seq, app.BuildClassRead(app.Class0))
+}
+
+func TestClassEventIIN(t *testing.T) {
+	h := newTestHarness(t, eventTestConfig())
+	db := h.outstation.database
+
+	resp := h.request(class0Read(0))
+	if resp.IIN.IIN1&(types.IIN1Class1Events|types.IIN1Class2Events|types.IIN1Class3Events) != 0 {
+		t.Fatalf("IIN1: got 0x%02X, want no class bits without events", resp.IIN.IIN1)
+	}
+
+	db.UpdateBinary(0, types.Binary{Value: true, Flags: types.FlagOnline}, EventModeDetect)
+	db.UpdateCounter(0, types.Counter{Value: 1, Flags: types.FlagOnline}, EventModeDetect)
+	resp = h.request(class0Read(1))
+	if got := resp.IIN.IIN1 & (types.IIN1Class1Events | types.IIN1Class2Events | types.IIN1Class3Events); got != types.IIN1Class1Events|types.IIN1Class3Events {
+		t.Errorf("Class bits: got 0x%02X, want class 1 and 3", got)
+	}
+
+	// Events reported in the response no longer count as waiting
+	resp = h.request(app.BuildReadRequest(2, app.BuildClassRead(app.Class1)))
+	if resp.IIN.IIN1&types.IIN1Class1Events != 0 || resp.IIN.IIN1&types.IIN1Class3Events == 0 {
+		t.Errorf("IIN1 after reading class 1: got 0x%02X, want class 3 only", resp.IIN.IIN1)
+	}
+}
+
+func TestEventBufferOverflowIIN(t *testing.T) {
+	config := eventTestConfig()
+	config.MaxBinaryEvents = 2
+	h := newTestHarness(t, config)
+
+	for i := 0; i < 3; i++ {
+		h.outstation.database.UpdateBinary(0, types.Binary{Value: i%2 == 0}, EventModeDetect)
+	}
+
+	resp := h.request(eventPoll(0))
+	if resp.IIN.IIN2&types.IIN2EventBufferOverflow == 0 {
+		t.Fatalf("IIN2: got 0x%02X, want event buffer overflow", resp.IIN.IIN2)
+	}
+
+	// Confirming the events makes room and clears the overflow
+	h.outstation.onReceiveAPDU(app.BuildConfirmRequest(0).Serialize())
+	resp = h.request(class0Read(1))
+	if resp.IIN.IIN2&types.IIN2EventBufferOverflow != 0 {
+		t.Errorf("IIN2 after CONFIRM: got 0x%02X, want overflow cleared", resp.IIN.IIN2)
+	}
+}
+
+func TestConfiguredIIN(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*OutstationConfig)
+		wantIIN1 uint8
+		wantIIN2 uint8
+	}{
+		{"local control", func(c *OutstationConfig) { c.LocalControl = true }, types.IIN1LocalControl, 0},
+		{"device trouble", func(c *OutstationConfig) { c.DeviceTrouble = true }, types.IIN1DeviceTrouble, 0},
+		{"invalid class", func(c *OutstationConfig) { c.Database.Binary[1].Class = 5 }, 0, types.IIN2ConfigCorrupt},
+		{"invalid static variation", func(c *OutstationConfig) { c.Database.Analog[0].StaticVariation = 9 }, 0, types.IIN2ConfigCorrupt},
+		{"invalid event variation", func(c *OutstationConfig) { c.Database.Counter[0].EventVariation = 3 }, 0, types.IIN2ConfigCorrupt},
+		{"chatter filter without window", func(c *OutstationConfig) { c.Database.Binary[0].ChatterFilter.Transitions = 2 }, 0, types.IIN2ConfigCorrupt},
+		{"percent deadband without full scale", func(c *OutstationConfig) { c.Database.Analog[0].DeadbandMode = DeadbandPercent }, 0, types.IIN2ConfigCorrupt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := eventTestConfig()
+			tt.modify(&config)
+			h := newTestHarness(t, config)
+
+			resp := h.request(class0Read(0))
+			if resp.IIN.IIN1&tt.wantIIN1 != tt.wantIIN1 || resp.IIN.IIN2&tt.wantIIN2 != tt.wantIIN2 {
+				t.Errorf("IIN: got 0x%02X 0x%02X, want bits 0x%02X 0x%02X",
+					resp.IIN.IIN1, resp.IIN.IIN2, tt.wantIIN1, tt.wantIIN2)
+			}
+			if tt.wantIIN2 == 0 && resp.IIN.IIN2&types.IIN2ConfigCorrupt != 0 {
+				t.Error("Valid configuration reported as corrupt")
+			}
+		})
+	}
+}
+
+func TestRequestErrorIIN(t *testing.T) {
+	unknownThenBinary := append(app.BuildAllObjects(app.GroupFrozenAnalogInput, 0), app.BuildAllObjects(app.GroupBinaryInput, 0)...)
+
+	tests := []struct {
+		name     string
+		request  *app.APDU
+		wantIIN2 uint8
+	}{
+		{"unsupported function", app.NewRequestAPDU(app.FuncSaveConfiguration, 0, nil), types.IIN2NoFuncCodeSupport},
+		{"read unknown object", app.BuildReadRequest(0, unknownThenBinary), types.IIN2ObjectUnknown},
+		{"read bad class variation", app.BuildReadRequest(0, app.BuildAllObjects(app.GroupClass0Data, 7)), types.IIN2ObjectUnknown},
+		{"read class variation 0", app.BuildReadRequest(0, app.BuildAllObjects(app.GroupClass0Data, 0)), types.IIN2ParameterError},
+		{"read truncated header", app.BuildReadRequest(0, []byte{0x01, 0x02}), types.IIN2ParameterError},
+		{"write unknown object", app.BuildWriteRequest(0, app.BuildRangeRead(app.GroupBinaryInput, 2, 0, 0)), types.IIN2ObjectUnknown},
+		{"enable unsolicited non-class object", app.NewRequestAPDU(app.FuncEnableUnsolicited, 0, app.BuildAllObjects(app.GroupBinaryInput, 0)), types.IIN2ObjectUnknown},
+		{"enable unsolicited class 0", app.NewRequestAPDU(app.FuncEnableUnsolicited, 0, app.BuildClassRead(app.Class0)), types.IIN2ParameterError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHarness(t, eventTestConfig())
+
+			resp := h.request(tt.request)
+			if resp.IIN.IIN2 != tt.wantIIN2 {
+				t.Errorf("IIN2: got 0x%02X, want 0x%02X", resp.IIN.IIN2, tt.wantIIN2)
+			}
+			if resp.IIN.IIN1&types.IIN1DeviceRestart == 0 {
+				t.Error("Error responses should still carry the outstation IIN1 bits")
+			}
+		})
+	}
+}
+
+func TestReadUnknownObjectStillAnswersOthers(t *testing.T) {
+	h := newTestHarness(t, eventTestConfig())
+
+	objects := append(app.BuildAllObjects(app.GroupFrozenAnalogInput, 0), app.BuildAllObjects(app.GroupBinaryInput, 0)...)
+	resp := h.request(app.BuildReadRequest(0, objects))
+	headers := parseStaticHeaders(t, resp.Objects)
+	if len(headers) != 1 || len(headers[0].objects) != 2 {
+		t.Errorf("Expected the binary inputs after the unknown header, got %d headers", len(headers))
+	}
+}
@@ -0,0 +1,52 @@
+package outstation
+
+import (
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// writeOctetStrings applies a WRITE of octet strings (G110) to the points,
+// reporting an event for each string that changes. Returns IIN2 bits for
+// any error.
+func (o *outstation) writeOctetStrings(header *app.ObjectHeader, parser *app.Parser) uint8 {
+	// The variation is the length of every string under the header
+	size := app.GetObjectSize(header.Group, header.Variation)
+	if size == 0 {
+		o.logger.Debug("Outstation %s: Octet string WRITE without a length", o.config.ID)
+		return types.IIN2ObjectUnknown
+	}
+
+	count := app.GetCount(header.Range)
+	var iin2 uint8
+
+	o.database.mu.Lock()
+	defer o.database.mu.Unlock()
+
+	for i := uint32(0); i < count; i++ {
+		var index uint32
+		switch r := header.Range.(type) {
+		case app.StartStopRange:
+			index = r.Start + i
+		case app.IndexPrefixRange:
+			var err error
+			if index, err = parser.ReadIndex(r.IndexSize); err != nil {
+				return iin2 | types.IIN2ParameterError
+			}
+		default:
+			return iin2 | types.IIN2ParameterError
+		}
+
+		data, err := parser.ReadBytes(size)
+		if err != nil {
+			return iin2 | types.IIN2ParameterError
+		}
+
+		if index > 0xFFFF || !o.database.updateOctetString(uint16(index), types.OctetString{Value: data}, EventModeDetect) {
+			iin2 |= types.IIN2ParameterError
+			continue
+		}
+		o.logger.Debug("Outstation %s: Octet string %d written (%d bytes)", o.config.ID, index, size)
+	}
+
+	return iin2
+}
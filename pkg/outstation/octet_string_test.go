@@ -0,0 +1,119 @@
+package outstation
+
+import (
+	"bytes"
+	"testing"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// octetStringConfig has three class 1 octet strings
+func octetStringConfig() OutstationConfig {
+	return OutstationConfig{Database: DatabaseConfig{
+		OctetString: []OctetStringPointConfig{{Class: 1}, {Class: 1}, {Class: 1}},
+	}}
+}
+
+func octet(s string) types.OctetString {
+	return types.OctetString{Value: []byte(s)}
+}
+
+func TestOctetStringStaticVariationIsLength(t *testing.T) {
+	h := newTestHarness(t, octetStringConfig())
+	db := h.outstation.database
+
+	db.UpdateOctetString(0, octet("SN-1234"), EventModeSuppress)
+	db.UpdateOctetString(1, octet("TRIP"), EventModeSuppress)
+	db.UpdateOctetString(2, octet("OPEN"), EventModeSuppress)
+
+	resp := h.request(class0Read(0))
+	headers := parseStaticHeaders(t, resp.Objects)
+	if len(headers) != 2 {
+		t.Fatalf("Expected a header per string length, got %d", len(headers))
+	}
+	if headers[0].group != app.GroupOctetString || headers[0].variation != 7 || len(headers[0].objects) != 1 {
+		t.Errorf("First header: got G%dV%d with %d objects, want G110V7 with 1", headers[0].group, headers[0].variation, len(headers[0].objects))
+	}
+	if headers[1].variation != 4 || len(headers[1].objects) != 2 || string(headers[1].objects[1]) != "OPEN" {
+		t.Errorf("Second header: got V%d %q, want V4 with TRIP and OPEN", headers[1].variation, headers[1].objects)
+	}
+}
+
+func TestOctetStringEvents(t *testing.T) {
+	h := newTestHarness(t, octetStringConfig())
+	db := h.outstation.database
+
+	db.UpdateOctetString(1, octet("ALARM"), EventModeDetect)
+	db.UpdateOctetString(1, octet("ALARM"), EventModeDetect)
+	db.UpdateOctetString(2, octet("OK"), EventModeDetect)
+
+	resp := h.request(eventPoll(0))
+	want := []eventHeader{
+		{app.GroupOctetStringEvent, 5, []uint32{1}},
+		{app.GroupOctetStringEvent, 2, []uint32{2}},
+	}
+	got := parseEventHeaders(t, resp.Objects)
+	if len(got) != len(want) {
+		t.Fatalf("Event headers: got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i].group != want[i].group || got[i].variation != want[i].variation || got[i].indices[0] != want[i].indices[0] {
+			t.Errorf("Header %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestOctetStringRejectsInvalidLength(t *testing.T) {
+	h := newTestHarness(t, octetStringConfig())
+	db := h.outstation.database
+
+	db.UpdateOctetString(0, types.OctetString{}, EventModeDetect)
+	db.UpdateOctetString(0, types.OctetString{Value: make([]byte, 256)}, EventModeDetect)
+	if v, _ := db.GetOctetString(0); !bytes.Equal(v.Value, []byte{0}) {
+		t.Errorf("Value: got % X, want the initial single zero byte", v.Value)
+	}
+}
+
+func TestWriteOctetStrings(t *testing.T) {
+	h := newTestHarness(t, octetStringConfig())
+	db := h.outstation.database
+
+	objects := app.BuildOctetStrings([]types.IndexedOctetString{{Index: 0, Value: octet("feeder 7")}, {Index: 2, Value: octet("bay 3")}})
+	resp := h.request(app.BuildWriteRequest(0, objects))
+	if resp.IIN.IIN2 != 0 {
+		t.Fatalf("IIN2: got 0x%02X, want 0", resp.IIN.IIN2)
+	}
+	if v, _ := db.GetOctetString(0); string(v.Value) != "feeder 7" {
+		t.Errorf("String 0: got %q", v.Value)
+	}
+	if v, _ := db.GetOctetString(2); string(v.Value) != "bay 3" {
+		t.Errorf("String 2: got %q", v.Value)
+	}
+	if n := h.outstation.eventBuffer.GetClass1Count(); n != 2 {
+		t.Errorf("Class 1 events: got %d, want one per written string", n)
+	}
+}
+
+func TestOctetStringInvalidRequests(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  *app.APDU
+		wantIIN2 uint8
+	}{
+		{"write index out of range", app.BuildWriteRequest(0, app.BuildOctetStrings([]types.IndexedOctetString{{Index: 3, Value: octet("x")}})), types.IIN2ParameterError},
+		{"write without length", app.BuildWriteRequest(0, app.BuildRangeRead(app.GroupOctetString, 0, 0, 0)), types.IIN2ObjectUnknown},
+		{"read specific length", app.BuildReadRequest(0, app.BuildAllObjects(app.GroupOctetString, 1)), types.IIN2ObjectUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHarness(t, octetStringConfig())
+
+			resp := h.request(tt.request)
+			if resp.IIN.IIN2 != tt.wantIIN2 {
+				t.Errorf("IIN2: got 0x%02X, want 0x%02X", resp.IIN.IIN2, tt.wantIIN2)
+			}
+		})
+	}
+}
@@ -11,14 +11,20 @@ import (
 	"avaneesh/dnp3-go/pkg/channel"
 	"avaneesh/dnp3-go/pkg/internal/logger"
 	"avaneesh/dnp3-go/pkg/link"
+	"avaneesh/dnp3-go/pkg/secauth"
 	"avaneesh/dnp3-go/pkg/transport"
 	"avaneesh/dnp3-go/pkg/types"
 )
 
 var (
 	ErrOutstationDisabled = errors.New("outstation is disabled")
+	ErrUpdateQueueFull    = errors.New("measurement update queue full")
 )
 
+// maxPendingUpdates bounds the measurement updates queued by Apply and not
+// yet written to the database
+const maxPendingUpdates = 65536
+
 // outstation implements the Outstation interface
 type outstation struct {
 	config    OutstationConfig
@@ -35,14 +41,28 @@ type outstation struct {
 	// State
 	enabled           bool
 	seqCounter        *app.SequenceCounter
+	unsolSeq          *app.UnsolicitedSequenceCounter
 	unsolicitedMask   app.ClassField // Classes enabled for unsolicited responses
+	unsolNullPending  bool           // Null unsolicited response not yet confirmed
+	deviceRestart     bool           // IIN1.7, set until cleared by the master
+	configCorrupt     bool           // IIN2.5, the database configuration has invalid points
+	clock             *clock         // Synchronized time used to stamp events
+	selected          selectState    // Armed SELECT awaiting OPERATE
+	solConfirm        confirmState   // Solicited response awaiting CONFIRM
+	freeze            freezeState    // Scheduled freeze-at-time
+	files             fileState      // Files opened by the master
+	auth              *secauth.Outstation // Secure Authentication, nil when disabled
 	stateMu           sync.RWMutex
 
 	// Concurrency
 	ctx        context.Context
 	cancel     context.CancelFunc
 	wg         sync.WaitGroup
-	updateChan chan *updateRequest
+	updateMu       sync.Mutex
+	pendingUpdates []measurementUpdate // Applied batches not yet written to the database
+	updateTrigger  chan struct{}
+	unsolTrigger   chan struct{}
+	unsolConfirm   chan uint8 // Sequence numbers of received unsolicited CONFIRMs
 }
 
 // session connects the outstation to a channel
@@ -52,12 +72,7 @@ type session struct {
 	channel     *channel.Channel
 	outstation  *outstation
 	transport   *transport.Layer
-}
-
-// updateRequest represents an update request
-type updateRequest struct {
-	builder *UpdateBuilder
-	resp    chan error
+	sendMu      sync.Mutex // Serializes solicited and unsolicited transmissions
 }
 
 // New creates a new outstation
@@ -66,17 +81,26 @@ func New(config OutstationConfig, callbacks OutstationCallbacks, ch *channel.Cha
 		log = logger.NewNoOpLogger()
 	}
 
+	if err := checkDeviceAttributes(config); err != nil {
+		return nil, err
+	}
+
+	var auth *secauth.Outstation
+	if config.SecureAuth != nil {
+		var err error
+		if auth, err = secauth.NewOutstation(*config.SecureAuth); err != nil {
+			return nil, err
+		}
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// Create event buffer
-	maxEvents := config.MaxBinaryEvents
-	if config.MaxAnalogEvents > maxEvents {
-		maxEvents = config.MaxAnalogEvents
-	}
-	eventBuffer := NewEventBuffer(maxEvents)
+	eventBuffer := NewEventBuffer(config.eventBufferConfig())
 
-	// Create database
+	// Create database, stamping events from the synchronized clock
 	database := NewDatabase(config.Database, eventBuffer)
+	database.clock = newClock(config.TimeSyncInterval)
 
 	o := &outstation{
 		config:          config,
@@ -85,12 +109,20 @@ func New(config OutstationConfig, callbacks OutstationCallbacks, ch *channel.Cha
 		database:        database,
 		eventBuffer:     eventBuffer,
 		enabled:         false,
-		seqCounter:      app.NewSequenceCounter(),
-		unsolicitedMask: app.ClassAll, // Start with all classes enabled
-		ctx:             ctx,
-		cancel:          cancel,
-		updateChan:      make(chan *updateRequest, 100),
-	}
+		seqCounter:       app.NewSequenceCounter(),
+		unsolSeq:         app.NewUnsolicitedSequenceCounter(),
+		unsolicitedMask:  app.ClassAll, // Start with all classes enabled
+		unsolNullPending: true,         // Announce the restart before any events
+		deviceRestart:    true,
+		clock:            database.clock,
+		auth:             auth,
+		ctx:              ctx,
+		cancel:           cancel,
+		updateTrigger:    make(chan struct{}, 1),
+		unsolTrigger:     make(chan struct{}, 1),
+		unsolConfirm:     make(chan uint8, 1),
+	}
+	eventBuffer.onOverflow = o.onEventBufferOverflow
 
 	// Create session
 	o.session = &session{
@@ -107,6 +139,12 @@ func New(config OutstationConfig, callbacks OutstationCallbacks, ch *channel.Cha
 		return nil, err
 	}
 
+	// Points the outstation cannot report are flagged to the master rather than refused
+	if err := checkDatabaseConfig(config.Database); err != nil {
+		o.logger.Warn("Outstation %s: Database configuration corrupt: %v", config.ID, err)
+		o.configCorrupt = true
+	}
+
 	o.logger.Info("Outstation %s created: local=%d, remote=%d", config.ID, config.LocalAddress, config.RemoteAddress)
 	return o, nil
 }
@@ -157,6 +195,9 @@ func (o *outstation) Shutdown() error {
 	o.logger.Info("Outstation %s shutting down", o.config.ID)
 
 	o.Disable()
+	o.cancelSolConfirm()
+	o.cancelFreeze()
+	o.closeFiles()
 	o.cancel()
 	o.wg.Wait()
 
@@ -164,28 +205,31 @@ func (o *outstation) Shutdown() error {
 	return nil
 }
 
-// Apply applies measurement updates atomically
+// Apply queues measurement updates without blocking. Each batch is applied
+// atomically and in order by the update processor. A batch that does not
+// fit in the queue is rejected whole with ErrUpdateQueueFull.
 func (o *outstation) Apply(updates *Updates) error {
 	if !o.isEnabled() {
 		return ErrOutstationDisabled
 	}
+	if updates == nil || len(updates.Data) == 0 {
+		return nil
+	}
 
-	// For now, create a simple builder
-	builder := NewUpdateBuilder()
-
-	req := &updateRequest{
-		builder: builder,
-		resp:    make(chan error, 1),
+	o.updateMu.Lock()
+	if len(o.pendingUpdates)+len(updates.Data) > maxPendingUpdates {
+		o.updateMu.Unlock()
+		o.logger.Warn("Outstation %s: Update queue full, rejected %d updates", o.config.ID, len(updates.Data))
+		return ErrUpdateQueueFull
 	}
+	o.pendingUpdates = append(o.pendingUpdates, updates.Data...)
+	o.updateMu.Unlock()
 
 	select {
-	case o.updateChan <- req:
-		return <-req.resp
-	case <-time.After(1 * time.Second):
-		return errors.New("update queue full")
-	case <-o.ctx.Done():
-		return o.ctx.Err()
+	case o.updateTrigger <- struct{}{}:
+	default:
 	}
+	return nil
 }
 
 // SetConfig updates the outstation configuration
@@ -194,65 +238,41 @@ func (o *outstation) SetConfig(config OutstationConfig) error {
 	defer o.stateMu.Unlock()
 
 	o.config = config
+	o.clock.setResyncInterval(config.TimeSyncInterval)
+	o.eventBuffer.configure(config.eventBufferConfig())
 	return nil
 }
 
+// EventBufferStats returns the event buffer usage of each event type
+func (o *outstation) EventBufferStats() EventBufferStats {
+	return o.eventBuffer.Stats()
+}
+
 // updateProcessor processes measurement updates
 func (o *outstation) updateProcessor() {
 	for {
 		select {
 		case <-o.ctx.Done():
 			return
-		case req := <-o.updateChan:
-			o.applyUpdates(req.builder)
-			req.resp <- nil
+		case <-o.updateTrigger:
+			o.applyUpdates()
 		}
 	}
 }
 
-// applyUpdates applies updates to the database
-func (o *outstation) applyUpdates(builder *UpdateBuilder) {
-	updates := builder.GetUpdates()
+// applyUpdates writes all queued batches to the database in one step
+func (o *outstation) applyUpdates() {
+	o.updateMu.Lock()
+	updates := o.pendingUpdates
+	o.pendingUpdates = nil
+	o.updateMu.Unlock()
 
-	for key, value := range updates {
-		switch key.pointType {
-		case MeasurementTypeBinary:
-			if val, ok := value.measurement.(types.Binary); ok {
-				o.database.UpdateBinary(key.index, val, value.mode)
-			}
-		case MeasurementTypeAnalog:
-			if val, ok := value.measurement.(types.Analog); ok {
-				o.database.UpdateAnalog(key.index, val, value.mode)
-			}
-		case MeasurementTypeCounter:
-			if val, ok := value.measurement.(types.Counter); ok {
-				o.database.UpdateCounter(key.index, val, value.mode)
-			}
-		}
+	if len(updates) == 0 {
+		return
 	}
-}
-
-// unsolicitedProcessor sends unsolicited responses periodically
-func (o *outstation) unsolicitedProcessor() {
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-o.ctx.Done():
-			return
-		case <-ticker.C:
-			if o.eventBuffer.HasEvents() {
-				o.sendUnsolicitedResponse()
-			}
-		}
-	}
-}
 
-// sendUnsolicitedResponse sends an unsolicited response
-func (o *outstation) sendUnsolicitedResponse() {
-	// TODO: Build and send unsolicited response
-	o.logger.Debug("Outstation %s: Would send unsolicited response", o.config.ID)
+	o.database.applyUpdates(updates)
+	o.notifyUnsolicited()
 }
 
 // isEnabled returns true if outstation is enabled
@@ -355,6 +375,9 @@ func (s *session) OnConnectionEstablished() {
 func (s *session) OnConnectionLost() {
 	s.outstation.logger.Info("Outstation session %d: Connection lost", s.linkAddress)
 	s.transport.Reset()
+
+	// A transfer cannot resume on a new connection
+	s.outstation.closeFiles()
 }
 
 // sendLinkAck sends a link layer ACK response
@@ -401,6 +424,9 @@ func (s *session) sendLinkStatus() error {
 
 // sendAPDU sends an APDU through the channel
 func (s *session) sendAPDU(apdu []byte) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+
 	// Segment through transport layer
 	segments := s.transport.Send(apdu)
 
@@ -430,6 +456,7 @@ func (s *session) sendAPDU(apdu []byte) error {
 
 // onReceiveAPDU handles received APDU
 func (o *outstation) onReceiveAPDU(data []byte) error {
+	received := time.Now() // Time synchronization measures from here
 	apdu, err := app.Parse(data)
 	if err != nil {
 		o.logger.Error("Outstation %s: APDU parse error: %v", o.config.ID, err)
@@ -438,8 +465,29 @@ func (o *outstation) onReceiveAPDU(data []byte) error {
 
 	o.logger.Debug("Outstation %s: Received APDU: %s", o.config.ID, apdu)
 
+	// Critical requests are only processed once authenticated
+	if o.auth != nil {
+		if apdu, err = o.authenticate(apdu, data); apdu == nil {
+			return err
+		}
+	}
+
+	// Any request other than the matching OPERATE cancels a pending SELECT
+	switch apdu.FunctionCode {
+	case app.FuncSelect, app.FuncOperate, app.FuncConfirm:
+	default:
+		o.clearSelect()
+	}
+
+	// A new request abandons any unconfirmed events; they are reported again later
+	if apdu.FunctionCode != app.FuncConfirm {
+		o.cancelSolConfirm()
+	}
+
 	// Process based on function code
 	switch apdu.FunctionCode {
+	case app.FuncConfirm:
+		return o.handleConfirm(apdu)
 	case app.FuncRead:
 		return o.handleRead(apdu)
 	case app.FuncWrite:
@@ -448,12 +496,27 @@ func (o *outstation) onReceiveAPDU(data []byte) error {
 		return o.handleSelect(apdu)
 	case app.FuncOperate:
 		return o.handleOperate(apdu)
-	case app.FuncDirectOperate:
+	case app.FuncDirectOperate, app.FuncDirectOperateNoAck:
 		return o.handleDirectOperate(apdu)
+	case app.FuncImmediateFreeze, app.FuncImmediateFreezeNoAck,
+		app.FuncFreezeClear, app.FuncFreezeClearNoAck,
+		app.FuncFreezeAtTime, app.FuncFreezeAtTimeNoAck:
+		return o.handleFreeze(apdu)
+	case app.FuncDelayMeasurement:
+		return o.handleDelayMeasurement(apdu, received)
+	case app.FuncRecordCurrentTime:
+		return o.handleRecordCurrentTime(apdu, received)
+	case app.FuncColdRestart, app.FuncWarmRestart:
+		return o.handleRestart(apdu)
+	case app.FuncAssignClass:
+		return o.handleAssignClass(apdu)
 	case app.FuncEnableUnsolicited:
 		return o.handleEnableUnsolicited(apdu)
 	case app.FuncDisableUnsolicited:
 		return o.handleDisableUnsolicited(apdu)
+	case app.FuncOpenFile, app.FuncCloseFile, app.FuncDeleteFile,
+		app.FuncGetFileInfo, app.FuncAuthenticateFile, app.FuncAbortFile:
+		return o.handleFile(apdu)
 	default:
 		o.logger.Warn("Outstation %s: Unsupported function: %s", o.config.ID, apdu.FunctionCode)
 		return o.sendErrorResponse(apdu.Sequence)
@@ -464,44 +527,11 @@ func (o *outstation) onReceiveAPDU(data []byte) error {
 func (o *outstation) handleRead(apdu *app.APDU) error {
 	o.logger.Debug("Outstation %s: Handling READ request", o.config.ID)
 
-	// Build response with IIN
-	iin := o.callbacks.GetApplicationIIN()
+	// Build response fragments from database
+	fragments, readIIN := o.buildReadResponse(apdu.Sequence, apdu.Objects)
 
-	// Build response data from database
-	responseData := o.buildReadResponse(apdu.Objects)
-
-	response := app.NewResponseAPDU(apdu.Sequence, iin, responseData)
-	return o.session.sendAPDU(response.Serialize())
-}
-
-// handleSelect handles SELECT requests
-func (o *outstation) handleSelect(apdu *app.APDU) error {
-	o.logger.Debug("Outstation %s: Handling SELECT request", o.config.ID)
-
-	// TODO: Process SELECT through command handler
-	iin := o.callbacks.GetApplicationIIN()
-	response := app.NewResponseAPDU(apdu.Sequence, iin, nil)
-	return o.session.sendAPDU(response.Serialize())
-}
-
-// handleOperate handles OPERATE requests
-func (o *outstation) handleOperate(apdu *app.APDU) error {
-	o.logger.Debug("Outstation %s: Handling OPERATE request", o.config.ID)
-
-	// TODO: Process OPERATE through command handler
-	iin := o.callbacks.GetApplicationIIN()
-	response := app.NewResponseAPDU(apdu.Sequence, iin, nil)
-	return o.session.sendAPDU(response.Serialize())
-}
-
-// handleDirectOperate handles DIRECT OPERATE requests
-func (o *outstation) handleDirectOperate(apdu *app.APDU) error {
-	o.logger.Debug("Outstation %s: Handling DIRECT OPERATE request", o.config.ID)
-
-	// TODO: Process DIRECT OPERATE through command handler
-	iin := o.callbacks.GetApplicationIIN()
-	response := app.NewResponseAPDU(apdu.Sequence, iin, nil)
-	return o.session.sendAPDU(response.Serialize())
+	// Events are only released once the master confirms the fragment holding them
+	return o.sendResponseFragment(apdu.Sequence, true, readIIN, fragments)
 }
 
 // handleEnableUnsolicited handles ENABLE UNSOLICITED requests
@@ -509,7 +539,7 @@ func (o *outstation) handleEnableUnsolicited(apdu *app.APDU) error {
 	o.logger.Debug("Outstation %s: Handling ENABLE UNSOLICITED request", o.config.ID)
 
 	// Parse object headers to determine which classes to enable
-	classesToEnable := o.parseClassMask(apdu.Objects)
+	classesToEnable, iin2 := o.parseClassMask(apdu.Objects)
 
 	o.stateMu.Lock()
 	o.unsolicitedMask |= classesToEnable
@@ -518,9 +548,16 @@ func (o *outstation) handleEnableUnsolicited(apdu *app.APDU) error {
 	o.logger.Info("Outstation %s: Enabled unsolicited for classes: %s", o.config.ID, classesToEnable)
 
 	// Send empty response with IIN
-	iin := o.callbacks.GetApplicationIIN()
+	iin := o.responseIIN()
+	iin.IIN2 |= iin2
 	response := app.NewResponseAPDU(apdu.Sequence, iin, nil)
-	return o.session.sendAPDU(response.Serialize())
+	if err := o.session.sendAPDU(response.Serialize()); err != nil {
+		return err
+	}
+
+	// Report any events already buffered for the newly enabled classes
+	o.notifyUnsolicited()
+	return nil
 }
 
 // handleDisableUnsolicited handles DISABLE UNSOLICITED requests
@@ -528,7 +565,7 @@ func (o *outstation) handleDisableUnsolicited(apdu *app.APDU) error {
 	o.logger.Debug("Outstation %s: Handling DISABLE UNSOLICITED request", o.config.ID)
 
 	// Parse object headers to determine which classes to disable
-	classesToDisable := o.parseClassMask(apdu.Objects)
+	classesToDisable, iin2 := o.parseClassMask(apdu.Objects)
 
 	o.stateMu.Lock()
 	o.unsolicitedMask &^= classesToDisable
@@ -537,45 +574,45 @@ func (o *outstation) handleDisableUnsolicited(apdu *app.APDU) error {
 	o.logger.Info("Outstation %s: Disabled unsolicited for classes: %s", o.config.ID, classesToDisable)
 
 	// Send empty response with IIN
-	iin := o.callbacks.GetApplicationIIN()
+	iin := o.responseIIN()
+	iin.IIN2 |= iin2
 	response := app.NewResponseAPDU(apdu.Sequence, iin, nil)
 	return o.session.sendAPDU(response.Serialize())
 }
 
-// parseClassMask parses object headers to extract class mask
-func (o *outstation) parseClassMask(objects []byte) app.ClassField {
+// parseClassMask parses the event class headers (G60V2-4) of an ENABLE or
+// DISABLE UNSOLICITED request and returns the IIN2 bits for any other header
+func (o *outstation) parseClassMask(objects []byte) (app.ClassField, uint8) {
 	var mask app.ClassField
+	var iin2 uint8
 
 	parser := app.NewParser(objects)
 	for parser.HasMore() {
 		header, err := parser.ReadObjectHeader()
 		if err != nil {
 			o.logger.Warn("Outstation %s: Failed to parse object header: %v", o.config.ID, err)
+			iin2 |= types.IIN2ParameterError
 			break
 		}
 
-		// Map object groups to class fields
-		switch header.Group {
-		case app.GroupClass0Data:
-			mask |= app.Class0
-		case app.GroupClass1Data:
-			mask |= app.Class1
-		case app.GroupClass2Data:
-			mask |= app.Class2
-		case app.GroupClass3Data:
-			mask |= app.Class3
+		switch {
+		case header.Group != app.GroupClass0Data || header.Variation > 4:
+			iin2 |= types.IIN2ObjectUnknown
+		case header.Variation < 2:
+			// Class 0 has no events to report unsolicited
+			iin2 |= types.IIN2ParameterError
+		default:
+			mask |= app.ClassField(1 << (header.Variation - 1))
 		}
 	}
 
-	return mask
+	return mask, iin2
 }
 
-// sendErrorResponse sends an error response
+// sendErrorResponse sends an empty response reporting an unsupported function code
 func (o *outstation) sendErrorResponse(seq uint8) error {
-	iin := types.IIN{
-		IIN1: 0,
-		IIN2: types.IIN2NoFuncCodeSupport,
-	}
+	iin := o.responseIIN()
+	iin.IIN2 |= types.IIN2NoFuncCodeSupport
 	response := app.NewResponseAPDU(seq, iin, nil)
 	return o.session.sendAPDU(response.Serialize())
 }
@@ -585,68 +622,71 @@ func (o *outstation) handleWrite(apdu *app.APDU) error {
 	o.logger.Debug("Outstation %s: Handling WRITE request", o.config.ID)
 
 	parser := app.NewParser(apdu.Objects)
+	var iin2 uint8
+	var objects []byte // File transport statuses
 
 	for parser.HasMore() {
 		header, err := parser.ReadObjectHeader()
 		if err != nil {
 			o.logger.Warn("Outstation %s: Failed to parse WRITE object header: %v", o.config.ID, err)
+			iin2 |= types.IIN2ParameterError
 			break
 		}
 
 		switch header.Group {
 		case app.GroupTimeDate:
 			// Group 50 - Time synchronization
-			o.handleTimeSync(header, parser)
+			iin2 |= o.handleTimeSync(header, parser)
 		case app.GroupInternalIndications:
-			// Group 80 - IIN manipulation (used to clear restart flags)
-			o.logger.Debug("Outstation %s: WRITE Group 80 (IIN control) - acknowledged", o.config.ID)
-			// Skip the data
-			if count, ok := header.Range.(app.CountRange); ok {
-				parser.Skip(int(count.Count))
-			}
+			// Group 80 - IIN manipulation (used to clear the restart flag)
+			iin2 |= o.writeIIN(header, parser)
+		case app.GroupAnalogInputDeadband:
+			// Group 34 - Analog input deadbands
+			iin2 |= o.writeDeadbands(header, parser)
+		case app.GroupOctetString:
+			// Group 110 - Octet strings
+			iin2 |= o.writeOctetStrings(header, parser)
+		case app.GroupFile:
+			// Group 70 - File transport blocks
+			statuses, fileIIN2 := o.writeFileBlocks(header, parser)
+			objects = append(objects, statuses...)
+			iin2 |= fileIIN2
 		default:
+			// The object size is unknown, so the remaining headers cannot be parsed
 			o.logger.Debug("Outstation %s: WRITE for unsupported group %d", o.config.ID, header.Group)
+			iin2 |= types.IIN2ObjectUnknown
+		}
+		if iin2&types.IIN2ObjectUnknown != 0 {
+			break
 		}
 	}
 
-	// Send empty acknowledgment
-	iin := o.callbacks.GetApplicationIIN()
-	response := app.NewResponseAPDU(apdu.Sequence, iin, nil)
+	// Acknowledge, with the status of any file blocks
+	iin := o.responseIIN()
+	iin.IIN2 |= iin2
+	response := app.NewResponseAPDU(apdu.Sequence, iin, objects)
 	return o.session.sendAPDU(response.Serialize())
 }
 
-// handleTimeSync handles time synchronization (Group 50 Variation 1)
-func (o *outstation) handleTimeSync(header *app.ObjectHeader, parser *app.Parser) {
-	if header.Variation == 1 {
-		// Variation 1: Absolute time (6 bytes - 48-bit milliseconds since epoch)
-		if data, err := parser.ReadBytes(6); err == nil {
-			// DNP3 time is 48-bit milliseconds since Jan 1, 1970 00:00 UTC
-			timestamp := uint64(data[0]) |
-				uint64(data[1])<<8 |
-				uint64(data[2])<<16 |
-				uint64(data[3])<<24 |
-				uint64(data[4])<<32 |
-				uint64(data[5])<<40
-
-			o.logger.Info("Outstation %s: Time synchronized - DNP3 time: %d ms", o.config.ID, timestamp)
-			// TODO: Store time offset for timestamping events
-		}
-	}
-}
+// buildReadResponse builds the response fragments for the READ request with
+// sequence number seq, along with IIN bits for headers that could not be
+// satisfied
+func (o *outstation) buildReadResponse(seq uint8, requestObjects []byte) ([]responseFragment, types.IIN) {
+	var iin types.IIN
+	w := o.newFragmentWriter()
 
-// buildReadResponse builds response data for READ requests
-func (o *outstation) buildReadResponse(requestObjects []byte) []byte {
-	if len(requestObjects) == 0 {
-		return []byte{}
-	}
+	// Headers are parsed first and written afterwards. Event classes are gathered
+	// and reported together, in order of occurrence, at the position of the first
+	// event class header.
+	var writes []func(w *fragmentWriter)
+	var eventClasses app.ClassField
 
 	parser := app.NewParser(requestObjects)
-	var responseData []byte
-
 	for parser.HasMore() {
 		header, err := parser.ReadObjectHeader()
 		if err != nil {
 			o.logger.Warn("Outstation %s: Failed to parse READ object header: %v", o.config.ID, err)
+			iin.IIN2 |= types.IIN2ParameterError
 			break
 		}
 
@@ -656,204 +696,63 @@ func (o *outstation) buildReadResponse(requestObjects []byte) []byte {
 		// Handle class reads
 		switch header.Group {
 		case app.GroupClass0Data:
-			// Class 0 - All static data
-			responseData = append(responseData, o.buildStaticData()...)
-		case app.GroupClass1Data:
-			// Class 1 events
-			responseData = append(responseData, o.buildEventData(1)...)
-		case app.GroupClass2Data:
-			// Class 2 events
-			responseData = append(responseData, o.buildEventData(2)...)
-		case app.GroupClass3Data:
-			// Class 3 events
-			responseData = append(responseData, o.buildEventData(3)...)
-		case app.GroupBinaryInput:
-			// Binary input static data
-			responseData = append(responseData, o.buildBinaryInputResponse(header)...)
-		case app.GroupAnalogInput:
-			// Analog input static data
-			responseData = append(responseData, o.buildAnalogInputResponse(header)...)
-		case app.GroupCounter:
-			// Counter static data
-			responseData = append(responseData, o.buildCounterResponse(header)...)
+			// Group 60: variation 1 is Class 0 static data, 2-4 are Class 1/2/3 events
+			if header.Variation > 4 {
+				iin.IIN2 |= types.IIN2ObjectUnknown
+				break
+			}
+			if header.Variation == 0 {
+				iin.IIN2 |= types.IIN2ParameterError
+				break
+			}
+			if header.Variation == 1 {
+				writes = append(writes, o.writeStaticData)
+				break
+			}
+			if eventClasses == 0 {
+				writes = append(writes, func(w *fragmentWriter) { o.writeEventData(w, eventClasses) })
+			}
+			eventClasses |= app.ClassField(1 << (header.Variation - 1))
+		case app.GroupDeviceAttributes:
+			attributes, iin2 := o.selectDeviceAttributes(header)
+			iin.IIN2 |= iin2
+			writes = append(writes, func(w *fragmentWriter) { o.writeDeviceAttributes(w, attributes) })
+		case app.GroupFile:
+			blocks, iin2 := o.readFileBlocks(header, seq)
+			iin.IIN2 |= iin2
+			writes = append(writes, func(w *fragmentWriter) {
+				for _, block := range blocks {
+					w.fit(1, func(int) int { return len(block) })
+					w.write(block)
+				}
+			})
 		default:
-			o.logger.Debug("Outstation %s: Unsupported READ group %d", o.config.ID, header.Group)
-		}
-	}
-
-	return responseData
-}
-
-// buildStaticData builds all static data (Class 0)
-func (o *outstation) buildStaticData() []byte {
-	var data []byte
-
-	// Build binary inputs
-	o.database.mu.RLock()
-	if len(o.database.binary) > 0 {
-		data = append(data, o.buildBinaryInputResponse(nil)...)
-	}
-	// Build analog inputs
-	if len(o.database.analog) > 0 {
-		data = append(data, o.buildAnalogInputResponse(nil)...)
-	}
-	// Build counters
-	if len(o.database.counter) > 0 {
-		data = append(data, o.buildCounterResponse(nil)...)
-	}
-	o.database.mu.RUnlock()
-
-	return data
-}
-
-// buildEventData builds event data for specified class
-func (o *outstation) buildEventData(class uint8) []byte {
-	// TODO: Implement event data building from event buffer
-	// For now, return empty (NULL response = no events)
-	o.logger.Debug("Outstation %s: Building event data for class %d (not yet implemented)", o.config.ID, class)
-	return []byte{}
-}
-
-// buildBinaryInputResponse builds binary input response using app layer helpers
-func (o *outstation) buildBinaryInputResponse(header *app.ObjectHeader) []byte {
-	o.database.mu.RLock()
-	defer o.database.mu.RUnlock()
-
-	if len(o.database.binary) == 0 {
-		return []byte{}
-	}
-
-	// Determine variation to use
-	variation := uint8(app.BinaryInputWithFlags) // Default to variation 2 (with flags)
-	if header != nil && header.Variation != app.VariationAny {
-		variation = header.Variation
-	} else if len(o.database.binary) > 0 {
-		variation = o.database.binary[0].staticVariation
-	}
-
-	// Use app layer builder
-	builder := app.NewObjectBuilder()
-
-	// Add object header
-	builder.AddHeader(
-		app.GroupBinaryInput,
-		variation,
-		app.Qualifier8BitStartStop,
-		app.StartStopRange{Start: 0, Stop: uint32(len(o.database.binary) - 1)},
-	)
-
-	// Serialize values using app layer helper
-	for _, point := range o.database.binary {
-		bi := app.BinaryInput{
-			Value: point.value.Value,
-			Flags: uint8(point.value.Flags),
-		}
-		builder.AddRawData(bi.Serialize())
-	}
-
-	return builder.Build()
-}
-
-// buildAnalogInputResponse builds analog input response using app layer helpers
-func (o *outstation) buildAnalogInputResponse(header *app.ObjectHeader) []byte {
-	o.database.mu.RLock()
-	defer o.database.mu.RUnlock()
-
-	if len(o.database.analog) == 0 {
-		return []byte{}
-	}
-
-	// Determine variation to use
-	variation := uint8(app.AnalogInputFloat) // Default to variation 5 (float)
-	if header != nil && header.Variation != app.VariationAny {
-		variation = header.Variation
-	} else if len(o.database.analog) > 0 {
-		variation = o.database.analog[0].staticVariation
-	}
-
-	// Use app layer builder
-	builder := app.NewObjectBuilder()
-
-	// Add object header
-	builder.AddHeader(
-		app.GroupAnalogInput,
-		variation,
-		app.Qualifier8BitStartStop,
-		app.StartStopRange{Start: 0, Stop: uint32(len(o.database.analog) - 1)},
-	)
+			st := findReadType(header.Group)
+			if st == nil {
+				o.logger.Debug("Outstation %s: Unsupported READ group %d", o.config.ID, header.Group)
+				iin.IIN2 |= types.IIN2ObjectUnknown
+				// Skip any index list so the following headers can still be parsed
+				if r, ok := header.Range.(app.IndexPrefixRange); ok {
+					parser.Skip(int(r.Count) * r.IndexSize)
+				}
+				break
+			}
 
-	// Serialize values using app layer helper
-	for _, point := range o.database.analog {
-		ai := app.AnalogInput{
-			Value: point.value.Value,
-			Flags: uint8(point.value.Flags),
-		}
+			sel, iin2, err := o.selectStaticPoints(st, header, parser)
+			if err != nil {
+				o.logger.Warn("Outstation %s: Failed to parse READ indices: %v", o.config.ID, err)
+				iin.IIN2 |= types.IIN2ParameterError
+				break
+			}
+			iin.IIN2 |= iin2
 
-		var serialized []byte
-		switch variation {
-		case app.AnalogInputFloat:
-			serialized = ai.SerializeFloat()
-		case app.AnalogInput32Bit:
-			serialized = ai.Serialize32Bit()
-		case app.AnalogInput16Bit:
-			serialized = ai.Serialize16Bit()
-		case app.AnalogInputDouble:
-			serialized = ai.SerializeDouble()
-		default:
-			serialized = ai.SerializeFloat()
+			variation := header.Variation
+			writes = append(writes, func(w *fragmentWriter) { o.writeStaticResponse(w, st, sel, variation) })
 		}
-		builder.AddRawData(serialized)
 	}
 
-	return builder.Build()
-}
-
-// buildCounterResponse builds counter response using app layer helpers
-func (o *outstation) buildCounterResponse(header *app.ObjectHeader) []byte {
-	o.database.mu.RLock()
-	defer o.database.mu.RUnlock()
-
-	if len(o.database.counter) == 0 {
-		return []byte{}
+	for _, write := range writes {
+		write(w)
 	}
-
-	// Determine variation
-	variation := uint8(app.Counter32BitWithFlag) // Default to variation 5
-	if header != nil && header.Variation != app.VariationAny {
-		variation = header.Variation
-	} else if len(o.database.counter) > 0 {
-		variation = o.database.counter[0].staticVariation
-	}
-
-	// Use app layer builder
-	builder := app.NewObjectBuilder()
-
-	// Add object header
-	builder.AddHeader(
-		app.GroupCounter,
-		variation,
-		app.Qualifier8BitStartStop,
-		app.StartStopRange{Start: 0, Stop: uint32(len(o.database.counter) - 1)},
-	)
-
-	// Serialize values using app layer helper
-	for _, point := range o.database.counter {
-		counter := app.Counter{
-			Value: point.value.Value,
-			Flags: uint8(point.value.Flags),
-		}
-
-		var serialized []byte
-		switch variation {
-		case app.Counter32BitWithFlag, app.Counter32Bit:
-			serialized = counter.Serialize32Bit()
-		case app.Counter16BitWithFlag, app.Counter16Bit:
-			serialized = counter.Serialize16Bit()
-		default:
-			serialized = counter.Serialize32Bit()
-		}
-		builder.AddRawData(serialized)
-	}
-
-	return builder.Build()
+	return w.fragments, iin
 }
@@ -0,0 +1,276 @@
+package outstation
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/channel"
+	"avaneesh/dnp3-go/pkg/link"
+	"avaneesh/dnp3-go/pkg/transport"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// fakePhysical is an in-memory physical channel capturing written frames
+type fakePhysical struct {
+	writes chan []byte
+}
+
+func newFakePhysical() *fakePhysical {
+	return &fakePhysical{writes: make(chan []byte, 256)}
+}
+
+func (f *fakePhysical) Read(ctx context.Context) ([]byte, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (f *fakePhysical) Write(ctx context.Context, data []byte) error {
+	f.writes <- data
+	return nil
+}
+
+func (f *fakePhysical) Close() error                                               { return nil }
+func (f *fakePhysical) Statistics() channel.TransportStats                         { return channel.TransportStats{} }
+func (f *fakePhysical) SetConnectionStateListener(channel.ConnectionStateListener) {}
+
+// testCallbacks records command handler invocations
+type testCallbacks struct {
+	selectStatus  types.CommandStatus
+	operateStatus types.CommandStatus
+	selects       []uint16
+	operates      []uint16
+	opTypes       []OperateType
+	aoValues      []float64
+	begins        int
+	ends          int
+	unsolResults  chan unsolResult
+	restartDelay  time.Duration
+	restarts      []bool // true for each cold restart, false for each warm restart
+	timeSyncs     []time.Time
+	overflows     []EventType
+
+	mu       sync.Mutex
+	confirms [][3]uint // Event counts per class from OnConfirmReceived
+}
+
+// unsolResult records an OnUnsolicitedResponse call
+type unsolResult struct {
+	success bool
+	seq     uint8
+}
+
+func (c *testCallbacks) Begin() { c.begins++ }
+func (c *testCallbacks) End()   { c.ends++ }
+
+func (c *testCallbacks) SelectCROB(crob types.CROB, index uint16) types.CommandStatus {
+	c.selects = append(c.selects, index)
+	return c.selectStatus
+}
+
+func (c *testCallbacks) OperateCROB(crob types.CROB, index uint16, opType OperateType, handler UpdateHandler) types.CommandStatus {
+	c.operates = append(c.operates, index)
+	c.opTypes = append(c.opTypes, opType)
+	return c.operateStatus
+}
+
+func (c *testCallbacks) SelectAnalogOutputInt32(ao types.AnalogOutputInt32, index uint16) types.CommandStatus {
+	c.selects = append(c.selects, index)
+	return c.selectStatus
+}
+
+func (c *testCallbacks) OperateAnalogOutputInt32(ao types.AnalogOutputInt32, index uint16, opType OperateType, handler UpdateHandler) types.CommandStatus {
+	c.operates = append(c.operates, index)
+	c.aoValues = append(c.aoValues, float64(ao.Value))
+	return c.operateStatus
+}
+
+func (c *testCallbacks) SelectAnalogOutputInt16(ao types.AnalogOutputInt16, index uint16) types.CommandStatus {
+	c.selects = append(c.selects, index)
+	return c.selectStatus
+}
+
+func (c *testCallbacks) OperateAnalogOutputInt16(ao types.AnalogOutputInt16, index uint16, opType OperateType, handler UpdateHandler) types.CommandStatus {
+	c.operates = append(c.operates, index)
+	c.aoValues = append(c.aoValues, float64(ao.Value))
+	return c.operateStatus
+}
+
+func (c *testCallbacks) SelectAnalogOutputFloat32(ao types.AnalogOutputFloat32, index uint16) types.CommandStatus {
+	c.selects = append(c.selects, index)
+	return c.selectStatus
+}
+
+func (c *testCallbacks) OperateAnalogOutputFloat32(ao types.AnalogOutputFloat32, index uint16, opType OperateType, handler UpdateHandler) types.CommandStatus {
+	c.operates = append(c.operates, index)
+	c.aoValues = append(c.aoValues, float64(ao.Value))
+	return c.operateStatus
+}
+
+func (c *testCallbacks) SelectAnalogOutputDouble64(ao types.AnalogOutputDouble64, index uint16) types.CommandStatus {
+	c.selects = append(c.selects, index)
+	return c.selectStatus
+}
+
+func (c *testCallbacks) OperateAnalogOutputDouble64(ao types.AnalogOutputDouble64, index uint16, opType OperateType, handler UpdateHandler) types.CommandStatus {
+	c.operates = append(c.operates, index)
+	c.aoValues = append(c.aoValues, ao.Value)
+	return c.operateStatus
+}
+
+func (c *testCallbacks) OnConfirmReceived(unsolicited bool, numClass1, numClass2, numClass3 uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.confirms = append(c.confirms, [3]uint{numClass1, numClass2, numClass3})
+}
+
+func (c *testCallbacks) OnUnsolicitedResponse(success bool, seq uint8) {
+	c.unsolResults <- unsolResult{success, seq}
+}
+
+// confirmCounts returns the recorded OnConfirmReceived event counts
+func (c *testCallbacks) confirmCounts() [][3]uint {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([][3]uint(nil), c.confirms...)
+}
+func (c *testCallbacks) GetApplicationIIN() types.IIN { return types.IIN{} }
+
+func (c *testCallbacks) OnTimeSync(t time.Time) {
+	c.timeSyncs = append(c.timeSyncs, t)
+}
+
+func (c *testCallbacks) OnEventBufferOverflow(eventType EventType) {
+	c.overflows = append(c.overflows, eventType)
+}
+
+func (c *testCallbacks) OnColdRestart() time.Duration {
+	c.restarts = append(c.restarts, true)
+	return c.restartDelay
+}
+
+func (c *testCallbacks) OnWarmRestart() time.Duration {
+	c.restarts = append(c.restarts, false)
+	return c.restartDelay
+}
+
+// testHarness drives an outstation directly at the APDU level
+type testHarness struct {
+	t          *testing.T
+	outstation *outstation
+	callbacks  *testCallbacks
+	physical   *fakePhysical
+	rx         *transport.Layer
+}
+
+func newTestHarness(t *testing.T, config OutstationConfig) *testHarness {
+	t.Helper()
+
+	physical := newFakePhysical()
+	ch := channel.New("test", physical, nil)
+	if err := ch.Open(); err != nil {
+		t.Fatalf("Open channel failed: %v", err)
+	}
+	t.Cleanup(func() { ch.Close() })
+
+	if config.LocalAddress == 0 {
+		config.LocalAddress = 1024
+		config.RemoteAddress = 1
+	}
+
+	callbacks := &testCallbacks{unsolResults: make(chan unsolResult, 16)}
+	o, err := New(config, callbacks, ch, nil)
+	if err != nil {
+		t.Fatalf("New outstation failed: %v", err)
+	}
+	t.Cleanup(func() { o.Shutdown() })
+
+	return &testHarness{
+		t:          t,
+		outstation: o,
+		callbacks:  callbacks,
+		physical:   physical,
+		rx:         transport.NewLayer(),
+	}
+}
+
+// request delivers a request APDU and returns the response APDU
+func (h *testHarness) request(apdu *app.APDU) *app.APDU {
+	h.t.Helper()
+
+	if err := h.outstation.onReceiveAPDU(apdu.Serialize()); err != nil {
+		h.t.Fatalf("onReceiveAPDU failed: %v", err)
+	}
+	return h.receive()
+}
+
+// receive waits for the next APDU sent by the outstation
+func (h *testHarness) receive() *app.APDU {
+	h.t.Helper()
+
+	for {
+		select {
+		case data := <-h.physical.writes:
+			frame, _, err := link.Parse(data)
+			if err != nil {
+				h.t.Fatalf("Link parse failed: %v", err)
+			}
+			apduData, err := h.rx.Receive(frame.UserData)
+			if err != nil {
+				h.t.Fatalf("Transport receive failed: %v", err)
+			}
+			if apduData == nil {
+				continue
+			}
+			apdu, err := app.Parse(apduData)
+			if err != nil {
+				h.t.Fatalf("APDU parse failed: %v", err)
+			}
+			return apdu
+		case <-time.After(time.Second):
+			h.t.Fatalf("Timed out waiting for response")
+			return nil
+		}
+	}
+}
+
+// expectNoResponse verifies that the outstation sends nothing
+func (h *testHarness) expectNoResponse() {
+	h.t.Helper()
+
+	select {
+	case <-h.physical.writes:
+		h.t.Fatalf("Unexpected response")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// commandStatuses extracts the status byte of each echoed command object
+func commandStatuses(t *testing.T, objects []byte) []types.CommandStatus {
+	t.Helper()
+
+	var statuses []types.CommandStatus
+	parser := app.NewParser(objects)
+	for parser.HasMore() {
+		header, err := parser.ReadObjectHeader()
+		if err != nil {
+			t.Fatalf("ReadObjectHeader failed: %v", err)
+		}
+		size := app.GetObjectSize(header.Group, header.Variation)
+		indexSize := 0
+		if r, ok := header.Range.(app.IndexPrefixRange); ok {
+			indexSize = r.IndexSize
+		}
+		for i := uint32(0); i < app.GetCount(header.Range); i++ {
+			parser.Skip(indexSize)
+			data, err := parser.ReadBytes(size)
+			if err != nil {
+				t.Fatalf("ReadBytes failed: %v", err)
+			}
+			statuses = append(statuses, types.CommandStatus(data[size-1]))
+		}
+	}
+	return statuses
+}
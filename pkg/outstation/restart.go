@@ -0,0 +1,55 @@
+package outstation
+
+import (
+	"time"
+
+	"avaneesh/dnp3-go/pkg/app"
+)
+
+// handleRestart handles COLD RESTART and WARM RESTART requests. The reply
+// tells the master how long to wait; the outstation then reinitialises its
+// protocol state, and on cold restart its database, and reports IIN1.7.
+func (o *outstation) handleRestart(apdu *app.APDU) error {
+	cold := apdu.FunctionCode == app.FuncColdRestart
+	o.logger.Info("Outstation %s: Handling %s request", o.config.ID, apdu.FunctionCode)
+
+	var delay time.Duration
+	if cold {
+		delay = o.callbacks.OnColdRestart()
+	} else {
+		delay = o.callbacks.OnWarmRestart()
+	}
+
+	response := app.NewResponseAPDU(apdu.Sequence, o.responseIIN(), app.BuildTimeDelay(delay))
+	err := o.session.sendAPDU(response.Serialize())
+
+	o.restart(cold)
+	return err
+}
+
+// restart reinitialises the outstation as after power-up. Buffered events,
+// pending SELECT, CONFIRM and freeze state, open files and the unsolicited
+// sequence are discarded; point values are reset only on cold restart.
+func (o *outstation) restart(cold bool) {
+	o.clearSelect()
+	o.cancelSolConfirm()
+	o.cancelFreeze()
+	o.closeFiles()
+
+	if cold {
+		o.database.reset()
+		o.clock.invalidate()
+	}
+	o.eventBuffer.Clear()
+
+	o.stateMu.Lock()
+	o.seqCounter.Reset()
+	o.unsolSeq.Reset()
+	o.unsolicitedMask = app.ClassAll
+	o.unsolNullPending = true
+	o.deviceRestart = true
+	o.stateMu.Unlock()
+
+	// Announce the restart with a null unsolicited response
+	o.notifyUnsolicited()
+}
@@ -0,0 +1,120 @@
+package outstation
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+func TestRestartReplyAndState(t *testing.T) {
+	tests := []struct {
+		name      string
+		request   *app.APDU
+		delay     time.Duration
+		wantVar   uint8
+		wantDelay uint16
+		wantReset bool
+	}{
+		{"cold restart", app.BuildColdRestartRequest(0), 1500 * time.Millisecond, app.TimeDelayFine, 1500, true},
+		{"warm restart", app.BuildWarmRestartRequest(0), 0, app.TimeDelayFine, 0, false},
+		{"long delay", app.BuildColdRestartRequest(0), 2 * time.Minute, app.TimeDelayCoarse, 120, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHarness(t, eventTestConfig())
+			h.callbacks.restartDelay = tt.delay
+			db := h.outstation.database
+			db.UpdateBinary(0, types.Binary{Value: true, Flags: types.FlagOnline}, EventModeDetect)
+
+			resp := h.request(tt.request)
+			if resp.IIN.IIN1&types.IIN1DeviceRestart == 0 {
+				t.Error("Restart response should set IIN1.7")
+			}
+
+			parser := app.NewParser(resp.Objects)
+			header, err := parser.ReadObjectHeader()
+			if err != nil || header.Group != app.GroupTimeDelay || header.Variation != tt.wantVar {
+				t.Fatalf("Expected G52V%d, got %+v (%v)", tt.wantVar, header, err)
+			}
+			data, _ := parser.ReadBytes(2)
+			if got := binary.LittleEndian.Uint16(data); got != tt.wantDelay {
+				t.Errorf("Delay: got %d, want %d", got, tt.wantDelay)
+			}
+			if len(h.callbacks.restarts) != 1 || h.callbacks.restarts[0] != tt.wantReset {
+				t.Errorf("Restart callbacks: got %v", h.callbacks.restarts)
+			}
+
+			if count := h.outstation.eventBuffer.GetClass1Count(); count != 0 {
+				t.Errorf("Events after restart: got %d, want 0", count)
+			}
+			point, _ := db.GetBinary(0)
+			if point.Value == tt.wantReset {
+				t.Errorf("Binary 0 after restart: got %v, want reset=%v", point.Value, tt.wantReset)
+			}
+		})
+	}
+}
+
+func TestRestartIINClearedByMaster(t *testing.T) {
+	h := newTestHarness(t, eventTestConfig())
+
+	resp := h.request(app.BuildReadRequest(0, app.BuildClassRead(app.Class0)))
+	if resp.IIN.IIN1&types.IIN1DeviceRestart == 0 {
+		t.Fatal("IIN1.7 should be set after startup")
+	}
+
+	resp = h.request(app.BuildWriteRequest(1, app.BuildClearRestartIIN()))
+	if resp.IIN.IIN1&types.IIN1DeviceRestart != 0 || resp.IIN.IIN2 != 0 {
+		t.Errorf("WRITE response: got IIN1=0x%02X IIN2=0x%02X, want restart cleared", resp.IIN.IIN1, resp.IIN.IIN2)
+	}
+
+	resp = h.request(app.BuildReadRequest(2, app.BuildClassRead(app.Class0)))
+	if resp.IIN.IIN1&types.IIN1DeviceRestart != 0 {
+		t.Error("IIN1.7 should stay clear")
+	}
+
+	// A restart raises it again
+	h.request(app.BuildWarmRestartRequest(3))
+	resp = h.request(app.BuildReadRequest(0, app.BuildClassRead(app.Class0)))
+	if resp.IIN.IIN1&types.IIN1DeviceRestart == 0 {
+		t.Error("IIN1.7 should be set after warm restart")
+	}
+}
+
+func TestWriteIINInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		objects []byte
+	}{
+		{"set restart bit", func() []byte {
+			b := app.NewObjectBuilder()
+			b.AddHeader(app.GroupInternalIndications, 1, app.StartStopQualifier(7, 7), app.StartStopRange{Start: 7, Stop: 7})
+			b.AddRawData([]byte{0x01})
+			return b.Build()
+		}()},
+		{"other index", func() []byte {
+			b := app.NewObjectBuilder()
+			b.AddHeader(app.GroupInternalIndications, 1, app.StartStopQualifier(4, 4), app.StartStopRange{Start: 4, Stop: 4})
+			b.AddRawData([]byte{0x00})
+			return b.Build()
+		}()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHarness(t, eventTestConfig())
+
+			resp := h.request(app.BuildWriteRequest(0, tt.objects))
+			if resp.IIN.IIN2&types.IIN2ParameterError == 0 {
+				t.Errorf("IIN2: got 0x%02X, want parameter error", resp.IIN.IIN2)
+			}
+			if resp.IIN.IIN1&types.IIN1DeviceRestart == 0 {
+				t.Error("IIN1.7 should remain set")
+			}
+		})
+	}
+}
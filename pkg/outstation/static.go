@@ -0,0 +1,393 @@
+package outstation
+
+import (
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// staticType describes how the points of one static object group are read.
+// The accessors are called with the database lock held.
+type staticType struct {
+	group            uint8
+	defaultVariation uint8
+	packedVariation  uint8   // Bit-packed variation, 0 if the group has none
+	variations       []uint8 // Variations that can be reported
+	variableLength   bool    // Each object's variation is its length (octet strings)
+
+	count           func(db *Database) int
+	staticVariation func(db *Database, index int) uint8
+	serialize       func(db *Database, index int, variation uint8) []byte
+	pack            func(db *Database, indices []int) []byte
+	setClass        func(db *Database, index int, class uint8) // Event class, changed by ASSIGN CLASS
+}
+
+// staticTypes lists the static groups in the order they are reported for Class 0
+var staticTypes = []*staticType{
+	{
+		group:            app.GroupBinaryInput,
+		defaultVariation: app.BinaryInputWithFlags,
+		packedVariation:  app.BinaryInputPacked,
+		variations:       []uint8{app.BinaryInputPacked, app.BinaryInputWithFlags},
+		count:            func(db *Database) int { return len(db.binary) },
+		staticVariation:  func(db *Database, i int) uint8 { return db.binary[i].staticVariation },
+		setClass:         func(db *Database, i int, class uint8) { db.binary[i].class = class },
+		serialize: func(db *Database, i int, variation uint8) []byte {
+			v := db.binary[i].value
+			return app.BinaryInput{Value: v.Value, Flags: binaryFlags(v.Value, v.Flags)}.Serialize()
+		},
+		pack: func(db *Database, indices []int) []byte {
+			states := make([]bool, len(indices))
+			for n, i := range indices {
+				states[n] = db.binary[i].value.Value
+			}
+			return app.PackBits(states)
+		},
+	},
+	{
+		group:            app.GroupDoubleBitBinaryInput,
+		defaultVariation: app.DoubleBitBinaryInputWithFlags,
+		packedVariation:  app.DoubleBitBinaryInputPacked,
+		variations:       []uint8{app.DoubleBitBinaryInputPacked, app.DoubleBitBinaryInputWithFlags},
+		count:            func(db *Database) int { return len(db.doubleBit) },
+		staticVariation:  func(db *Database, i int) uint8 { return db.doubleBit[i].staticVariation },
+		setClass:         func(db *Database, i int, class uint8) { db.doubleBit[i].class = class },
+		serialize: func(db *Database, i int, variation uint8) []byte {
+			v := db.doubleBit[i].value
+			return app.DoubleBitBinaryInput{Value: uint8(v.Value), Flags: uint8(v.Flags)}.Serialize()
+		},
+		pack: func(db *Database, indices []int) []byte {
+			states := make([]uint8, len(indices))
+			for n, i := range indices {
+				states[n] = uint8(db.doubleBit[i].value.Value)
+			}
+			return app.PackDoubleBits(states)
+		},
+	},
+	{
+		group:            app.GroupBinaryOutput,
+		defaultVariation: app.BinaryOutputWithFlags,
+		packedVariation:  app.BinaryOutputPacked,
+		variations:       []uint8{app.BinaryOutputPacked, app.BinaryOutputWithFlags},
+		count:            func(db *Database) int { return len(db.binaryOutput) },
+		staticVariation:  func(db *Database, i int) uint8 { return db.binaryOutput[i].staticVariation },
+		setClass:         func(db *Database, i int, class uint8) { db.binaryOutput[i].class = class },
+		serialize: func(db *Database, i int, variation uint8) []byte {
+			v := db.binaryOutput[i].value
+			return app.BinaryOutput{Value: v.Value, Flags: binaryFlags(v.Value, v.Flags)}.Serialize()
+		},
+		pack: func(db *Database, indices []int) []byte {
+			states := make([]bool, len(indices))
+			for n, i := range indices {
+				states[n] = db.binaryOutput[i].value.Value
+			}
+			return app.PackBits(states)
+		},
+	},
+	{
+		group:            app.GroupCounter,
+		defaultVariation: app.Counter32BitWithFlag,
+		variations:       []uint8{app.Counter32Bit, app.Counter16Bit, app.Counter32BitWithFlag, app.Counter16BitWithFlag},
+		count:            func(db *Database) int { return len(db.counter) },
+		staticVariation:  func(db *Database, i int) uint8 { return db.counter[i].staticVariation },
+		setClass:         func(db *Database, i int, class uint8) { db.counter[i].class = class },
+		serialize: func(db *Database, i int, variation uint8) []byte {
+			v := db.counter[i].value
+			return app.Counter{Value: v.Value, Flags: uint8(v.Flags)}.Serialize(variation)
+		},
+	},
+	{
+		group:            app.GroupFrozenCounter,
+		defaultVariation: app.FrozenCounter32BitWithFlag,
+		variations: []uint8{app.FrozenCounter32BitWithFlag, app.FrozenCounter16BitWithFlag,
+			app.FrozenCounter32BitWithFlagTime, app.FrozenCounter16BitWithFlagTime,
+			app.FrozenCounter32Bit, app.FrozenCounter16Bit},
+		count:           func(db *Database) int { return len(db.frozenCounter) },
+		staticVariation: func(db *Database, i int) uint8 { return db.frozenCounter[i].staticVariation },
+		setClass:        func(db *Database, i int, class uint8) { db.frozenCounter[i].class = class },
+		serialize: func(db *Database, i int, variation uint8) []byte {
+			v := db.frozenCounter[i].value
+			return app.FrozenCounter{Value: v.Value, Flags: uint8(v.Flags), Timestamp: uint64(v.Time)}.Serialize(variation)
+		},
+	},
+	{
+		group:            app.GroupAnalogInput,
+		defaultVariation: app.AnalogInputFloat,
+		variations: []uint8{app.AnalogInput32Bit, app.AnalogInput16Bit, app.AnalogInput32BitNoFlag,
+			app.AnalogInput16BitNoFlag, app.AnalogInputFloat, app.AnalogInputDouble},
+		count:           func(db *Database) int { return len(db.analog) },
+		staticVariation: func(db *Database, i int) uint8 { return db.analog[i].staticVariation },
+		setClass:        func(db *Database, i int, class uint8) { db.analog[i].class = class },
+		serialize: func(db *Database, i int, variation uint8) []byte {
+			v := db.analog[i].value
+			return app.AnalogInput{Value: v.Value, Flags: uint8(v.Flags)}.Serialize(variation)
+		},
+	},
+	{
+		group:            app.GroupAnalogOutputStatus,
+		defaultVariation: app.AnalogOutputStatusFloat,
+		variations: []uint8{app.AnalogOutputStatus32Bit, app.AnalogOutputStatus16Bit,
+			app.AnalogOutputStatusFloat, app.AnalogOutputStatusDouble},
+		count:           func(db *Database) int { return len(db.analogOutput) },
+		staticVariation: func(db *Database, i int) uint8 { return db.analogOutput[i].staticVariation },
+		setClass:        func(db *Database, i int, class uint8) { db.analogOutput[i].class = class },
+		serialize: func(db *Database, i int, variation uint8) []byte {
+			v := db.analogOutput[i].value
+			return app.AnalogOutputStatus{Value: v.Value, Flags: uint8(v.Flags)}.Serialize(variation)
+		},
+	},
+	{
+		group:           app.GroupOctetString,
+		variableLength:  true,
+		count:           func(db *Database) int { return len(db.octetString) },
+		staticVariation: func(db *Database, i int) uint8 { return uint8(len(db.octetString[i].value.Value)) },
+		setClass:        func(db *Database, i int, class uint8) { db.octetString[i].class = class },
+		serialize: func(db *Database, i int, variation uint8) []byte {
+			return db.octetString[i].value.Value
+		},
+	},
+}
+
+// findStaticType returns the static type for an object group, or nil
+func findStaticType(group uint8) *staticType {
+	for _, st := range staticTypes {
+		if st.group == group {
+			return st
+		}
+	}
+	return nil
+}
+
+// supports reports whether the variation can be reported for this group
+func (st *staticType) supports(variation uint8) bool {
+	if st.variableLength {
+		return variation > 0
+	}
+	for _, v := range st.variations {
+		if v == variation {
+			return true
+		}
+	}
+	return false
+}
+
+// pointSelection lists the points requested by a static READ header
+type pointSelection struct {
+	indices    []int
+	contiguous bool // Reported with a start-stop range rather than index prefixes
+}
+
+// selectPoints resolves the range of a READ header against the number of
+// points, consuming any index list from the parser. Indices that do not exist
+// are dropped and reported as IIN2.2 parameter error.
+func selectPoints(header *app.ObjectHeader, parser *app.Parser, count int) (pointSelection, uint8, error) {
+	var iin2 uint8
+
+	switch r := header.Range.(type) {
+	case app.StartStopRange:
+		if r.Start > r.Stop || int64(r.Start) >= int64(count) {
+			return pointSelection{}, types.IIN2ParameterError, nil
+		}
+		stop := int(r.Stop)
+		if int64(r.Stop) >= int64(count) {
+			stop = count - 1
+			iin2 |= types.IIN2ParameterError
+		}
+		return contiguousPoints(int(r.Start), stop), iin2, nil
+
+	case app.CountRange:
+		n := int(r.Count)
+		if int64(r.Count) > int64(count) {
+			n = count
+			iin2 |= types.IIN2ParameterError
+		}
+		return contiguousPoints(0, n-1), iin2, nil
+
+	case app.NoRange:
+		return contiguousPoints(0, count-1), 0, nil
+
+	case app.IndexPrefixRange:
+		sel := pointSelection{}
+		for i := uint32(0); i < r.Count; i++ {
+			index, err := parser.ReadIndex(r.IndexSize)
+			if err != nil {
+				return pointSelection{}, 0, err
+			}
+			if int64(index) >= int64(count) {
+				iin2 |= types.IIN2ParameterError
+				continue
+			}
+			sel.indices = append(sel.indices, int(index))
+		}
+		return sel, iin2, nil
+
+	default:
+		return pointSelection{}, types.IIN2ParameterError, nil
+	}
+}
+
+// contiguousPoints selects the points start through stop
+func contiguousPoints(start, stop int) pointSelection {
+	sel := pointSelection{contiguous: true}
+	for i := start; i <= stop; i++ {
+		sel.indices = append(sel.indices, i)
+	}
+	return sel
+}
+
+// selectStaticPoints resolves the points requested by a READ header for a
+// static group. No points are selected if the variation cannot be reported.
+func (o *outstation) selectStaticPoints(st *staticType, header *app.ObjectHeader, parser *app.Parser) (pointSelection, uint8, error) {
+	o.database.mu.RLock()
+	count := st.count(o.database)
+	o.database.mu.RUnlock()
+
+	sel, iin2, err := selectPoints(header, parser, count)
+	if err != nil {
+		return pointSelection{}, 0, err
+	}
+
+	// Points of a variable length group are only read with their own lengths
+	if header.Variation != app.VariationAny && (st.variableLength || !st.supports(header.Variation)) {
+		o.logger.Debug("Outstation %s: Unsupported static variation G%dV%d", o.config.ID, header.Group, header.Variation)
+		return pointSelection{}, iin2 | types.IIN2ObjectUnknown, nil
+	}
+	return sel, iin2, nil
+}
+
+// writeStaticResponse writes the points selected by a static READ header
+func (o *outstation) writeStaticResponse(w *fragmentWriter, st *staticType, sel pointSelection, requested uint8) {
+	o.database.mu.RLock()
+	defer o.database.mu.RUnlock()
+
+	o.writeStaticPoints(w, st, sel, requested)
+}
+
+// writeStaticData writes all static data (Class 0)
+func (o *outstation) writeStaticData(w *fragmentWriter) {
+	o.database.mu.RLock()
+	defer o.database.mu.RUnlock()
+
+	for _, st := range staticTypes {
+		if count := st.count(o.database); count > 0 {
+			o.writeStaticPoints(w, st, contiguousPoints(0, count-1), app.VariationAny)
+		}
+	}
+}
+
+// writeStaticPoints writes the selected points in the requested variation.
+// When any variation was requested each point is reported in its configured
+// variation, with one header per run of points sharing a variation.
+func (o *outstation) writeStaticPoints(w *fragmentWriter, st *staticType, sel pointSelection, requested uint8) {
+	if len(sel.indices) == 0 {
+		return
+	}
+
+	if requested != app.VariationAny {
+		o.writeStaticRun(w, st, sel, requested)
+		return
+	}
+
+	start := 0
+	variation := o.pointVariation(st, sel.indices[0])
+	for n := 1; n <= len(sel.indices); n++ {
+		var next uint8
+		if n < len(sel.indices) {
+			if next = o.pointVariation(st, sel.indices[n]); next == variation {
+				continue
+			}
+		}
+		run := pointSelection{indices: sel.indices[start:n], contiguous: sel.contiguous}
+		o.writeStaticRun(w, st, run, variation)
+		start, variation = n, next
+	}
+}
+
+// pointVariation returns the configured static variation of a point, or the
+// group default if the configured one cannot be reported
+func (o *outstation) pointVariation(st *staticType, index int) uint8 {
+	if v := st.staticVariation(o.database, index); st.supports(v) {
+		return v
+	}
+	return st.defaultVariation
+}
+
+// writeStaticRun writes the selected points in one variation, splitting them
+// into several headers where they do not fit in the current fragment
+func (o *outstation) writeStaticRun(w *fragmentWriter, st *staticType, sel pointSelection, variation uint8) {
+	// Packed objects cannot carry index prefixes
+	if !sel.contiguous && variation == st.packedVariation {
+		variation = st.defaultVariation
+	}
+
+	indices := sel.indices
+	for len(indices) > 0 {
+		var n int
+		if sel.contiguous {
+			n = o.writeStaticRange(w, st, indices, variation)
+		} else {
+			n = o.writeStaticIndexed(w, st, indices, variation)
+		}
+		indices = indices[n:]
+	}
+}
+
+// writeStaticRange writes as many of the contiguous points as fit in the
+// current fragment under a start-stop header and returns how many were written
+func (o *outstation) writeStaticRange(w *fragmentWriter, st *staticType, indices []int, variation uint8) int {
+	start := uint32(indices[0])
+	last := uint32(indices[len(indices)-1])
+	headerLen := headerSize(st.group, variation, app.StartStopQualifier(start, last), app.StartStopRange{Start: start, Stop: last})
+
+	packed := variation == st.packedVariation
+	objectSize := 0
+	if !packed {
+		objectSize = len(st.serialize(o.database, indices[0], variation))
+	}
+
+	n := w.fit(len(indices), func(k int) int {
+		if packed {
+			return headerLen + len(st.pack(o.database, indices[:k]))
+		}
+		return headerLen + k*objectSize
+	})
+
+	stop := uint32(indices[n-1])
+	builder := app.NewObjectBuilder()
+	builder.AddHeader(st.group, variation, app.StartStopQualifier(start, stop), app.StartStopRange{Start: start, Stop: stop})
+	if packed {
+		builder.AddRawData(st.pack(o.database, indices[:n]))
+	} else {
+		for _, i := range indices[:n] {
+			builder.AddRawData(st.serialize(o.database, i, variation))
+		}
+	}
+	w.write(builder.Build())
+	return n
+}
+
+// writeStaticIndexed writes as many of the listed points as fit in the current
+// fragment under an index-prefixed header and returns how many were written
+func (o *outstation) writeStaticIndexed(w *fragmentWriter, st *staticType, indices []int, variation uint8) int {
+	var maxIndex uint32
+	for _, i := range indices {
+		if uint32(i) > maxIndex {
+			maxIndex = uint32(i)
+		}
+	}
+
+	count := uint32(len(indices))
+	qualifier, indexSize := app.IndexPrefixQualifier(count, maxIndex)
+	headerLen := headerSize(st.group, variation, qualifier, app.IndexPrefixRange{Count: count, IndexSize: indexSize})
+	objectSize := indexSize + len(st.serialize(o.database, indices[0], variation))
+
+	n := w.fit(len(indices), func(k int) int { return headerLen + k*objectSize })
+
+	builder := app.NewObjectBuilder()
+	builder.AddHeader(st.group, variation, qualifier, app.IndexPrefixRange{Count: uint32(n), IndexSize: indexSize})
+	for _, i := range indices[:n] {
+		builder.AddIndex(indexSize, uint32(i))
+		builder.AddRawData(st.serialize(o.database, i, variation))
+	}
+	w.write(builder.Build())
+	return n
+}
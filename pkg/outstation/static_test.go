@@ -0,0 +1,256 @@
+package outstation
+
+import (
+	"bytes"
+	"testing"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+func staticTestConfig(numBinary, numAnalog int) OutstationConfig {
+	return OutstationConfig{
+		Database: DatabaseConfig{
+			Binary: make([]BinaryPointConfig, numBinary),
+			Analog: make([]AnalogPointConfig, numAnalog),
+		},
+	}
+}
+
+// readHeader reads the single object header of a static READ response
+func readHeader(t *testing.T, resp *app.APDU) (*app.ObjectHeader, *app.Parser) {
+	t.Helper()
+
+	parser := app.NewParser(resp.Objects)
+	header, err := parser.ReadObjectHeader()
+	if err != nil {
+		t.Fatalf("ReadObjectHeader failed: %v", err)
+	}
+	return header, parser
+}
+
+func TestStaticReadRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		start     uint32
+		stop      uint32
+		wantStart uint32
+		wantStop  uint32
+		wantIIN2  uint8
+	}{
+		{"within range", 2, 4, 2, 4, 0},
+		{"single point", 9, 9, 9, 9, 0},
+		{"stop beyond last point", 7, 20, 7, 9, types.IIN2ParameterError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHarness(t, staticTestConfig(0, 10))
+
+			resp := h.request(app.BuildReadRequest(0, app.BuildRangeRead(app.GroupAnalogInput, app.AnalogInputFloat, tt.start, tt.stop)))
+			if resp.IIN.IIN2&types.IIN2ParameterError != tt.wantIIN2 {
+				t.Errorf("IIN2: got 0x%02X, want parameter error bit 0x%02X", resp.IIN.IIN2, tt.wantIIN2)
+			}
+
+			header, parser := readHeader(t, resp)
+			r, ok := header.Range.(app.StartStopRange)
+			if !ok || r.Start != tt.wantStart || r.Stop != tt.wantStop {
+				t.Fatalf("Range: got %+v, want %d-%d", header.Range, tt.wantStart, tt.wantStop)
+			}
+			if want := int(tt.wantStop-tt.wantStart+1) * 5; parser.Remaining() != want {
+				t.Errorf("Object data: got %d bytes, want %d", parser.Remaining(), want)
+			}
+		})
+	}
+}
+
+func TestStaticReadOutOfRange(t *testing.T) {
+	h := newTestHarness(t, staticTestConfig(0, 10))
+
+	resp := h.request(app.BuildReadRequest(0, app.BuildRangeRead(app.GroupAnalogInput, 0, 10, 12)))
+	if resp.IIN.IIN2&types.IIN2ParameterError == 0 {
+		t.Errorf("Expected IIN2.2 parameter error, got IIN2=0x%02X", resp.IIN.IIN2)
+	}
+	if len(resp.Objects) != 0 {
+		t.Errorf("Expected no objects, got % X", resp.Objects)
+	}
+}
+
+func TestStaticReadLargePointCount(t *testing.T) {
+	h := newTestHarness(t, staticTestConfig(0, 300))
+	h.outstation.database.UpdateAnalog(299, types.Analog{Value: 42, Flags: types.FlagOnline}, EventModeSuppress)
+
+	resp := h.request(app.BuildReadRequest(0, app.BuildClassRead(app.Class0)))
+
+	header, parser := readHeader(t, resp)
+	if header.Qualifier != app.Qualifier16BitStartStop {
+		t.Errorf("Qualifier: got 0x%02X, want 0x01", header.Qualifier)
+	}
+	if r := header.Range.(app.StartStopRange); r.Start != 0 || r.Stop != 299 {
+		t.Fatalf("Range: got %d-%d, want 0-299", r.Start, r.Stop)
+	}
+
+	parser.Skip(299 * 5)
+	data, _ := parser.ReadBytes(5)
+	if v := app.ParseAnalogInputFloat(data).Value; v != float32(42) {
+		t.Errorf("Point 299: got %v, want 42", v)
+	}
+}
+
+func TestStaticReadIndexList(t *testing.T) {
+	tests := []struct {
+		name          string
+		indices       []uint32
+		wantQualifier app.QualifierCode
+		wantIndices   []uint32
+		wantIIN2      uint8
+	}{
+		{"8-bit indices", []uint32{1, 5}, app.Qualifier8BitIndexPrefix, []uint32{1, 5}, 0},
+		{"16-bit indices", []uint32{3, 400}, app.Qualifier16BitIndexPrefix, []uint32{3, 400}, 0},
+		{"unknown index dropped", []uint32{2, 999}, app.Qualifier8BitIndexPrefix, []uint32{2}, types.IIN2ParameterError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHarness(t, staticTestConfig(500, 0))
+			h.outstation.database.UpdateBinary(uint16(tt.wantIndices[0]), types.Binary{Value: true, Flags: types.FlagOnline}, EventModeSuppress)
+
+			resp := h.request(app.BuildReadRequest(0, app.BuildIndexRead(app.GroupBinaryInput, 0, tt.indices)))
+			if resp.IIN.IIN2&types.IIN2ParameterError != tt.wantIIN2 {
+				t.Errorf("IIN2: got 0x%02X, want parameter error bit 0x%02X", resp.IIN.IIN2, tt.wantIIN2)
+			}
+
+			header, parser := readHeader(t, resp)
+			if header.Qualifier != tt.wantQualifier {
+				t.Errorf("Qualifier: got 0x%02X, want 0x%02X", header.Qualifier, tt.wantQualifier)
+			}
+			r := header.Range.(app.IndexPrefixRange)
+			if int(r.Count) != len(tt.wantIndices) {
+				t.Fatalf("Count: got %d, want %d", r.Count, len(tt.wantIndices))
+			}
+			for i, want := range tt.wantIndices {
+				index, _ := parser.ReadIndex(r.IndexSize)
+				flags, _ := parser.ReadBytes(1)
+				if index != want {
+					t.Errorf("Index %d: got %d, want %d", i, index, want)
+				}
+				if i == 0 && flags[0] != app.FlagOnline|app.FlagState {
+					t.Errorf("Flags: got 0x%02X, want 0x81", flags[0])
+				}
+			}
+		})
+	}
+}
+
+func TestStaticReadPackedBinary(t *testing.T) {
+	h := newTestHarness(t, staticTestConfig(10, 0))
+	db := h.outstation.database
+	db.UpdateBinary(0, types.Binary{Value: true}, EventModeSuppress)
+	db.UpdateBinary(9, types.Binary{Value: true}, EventModeSuppress)
+
+	resp := h.request(app.BuildReadRequest(0, app.BuildRangeRead(app.GroupBinaryInput, app.BinaryInputPacked, 0, 9)))
+
+	header, parser := readHeader(t, resp)
+	if header.Variation != app.BinaryInputPacked {
+		t.Errorf("Variation: got %d, want 1", header.Variation)
+	}
+	if data, _ := parser.ReadBytes(parser.Remaining()); !bytes.Equal(data, []byte{0x01, 0x02}) {
+		t.Errorf("Packed data: got % X, want 01 02", data)
+	}
+}
+
+func TestStaticReadUnsupportedVariation(t *testing.T) {
+	h := newTestHarness(t, staticTestConfig(0, 2))
+
+	objects := app.BuildRangeRead(app.GroupAnalogInput, 9, 0, 1)
+	objects = append(objects, app.BuildRangeRead(app.GroupAnalogInput, app.AnalogInput16Bit, 0, 1)...)
+	resp := h.request(app.BuildReadRequest(0, objects))
+
+	if resp.IIN.IIN2&types.IIN2ObjectUnknown == 0 {
+		t.Errorf("Expected IIN2.1 object unknown, got IIN2=0x%02X", resp.IIN.IIN2)
+	}
+
+	// The valid header is still answered
+	header, _ := readHeader(t, resp)
+	if header.Variation != app.AnalogInput16Bit {
+		t.Errorf("Variation: got %d, want 2", header.Variation)
+	}
+}
+
+func TestStaticReadMixedVariations(t *testing.T) {
+	config := staticTestConfig(0, 4)
+	config.Database.Analog[0].StaticVariation = app.AnalogInput32Bit
+	config.Database.Analog[1].StaticVariation = app.AnalogInput32Bit
+	config.Database.Analog[2].StaticVariation = app.AnalogInputFloat
+	config.Database.Analog[3].StaticVariation = app.AnalogInput32Bit
+	h := newTestHarness(t, config)
+
+	resp := h.request(app.BuildReadRequest(0, app.BuildClassRead(app.Class0)))
+
+	want := []struct {
+		variation   uint8
+		start, stop uint32
+	}{
+		{app.AnalogInput32Bit, 0, 1},
+		{app.AnalogInputFloat, 2, 2},
+		{app.AnalogInput32Bit, 3, 3},
+	}
+	parser := app.NewParser(resp.Objects)
+	for i, w := range want {
+		header, err := parser.ReadObjectHeader()
+		if err != nil {
+			t.Fatalf("Header %d: %v", i, err)
+		}
+		r, ok := header.Range.(app.StartStopRange)
+		if header.Variation != w.variation || !ok || r.Start != w.start || r.Stop != w.stop {
+			t.Errorf("Header %d: got G%dV%d %+v, want G30V%d %d-%d",
+				i, header.Group, header.Variation, header.Range, w.variation, w.start, w.stop)
+		}
+		parser.Skip(int(app.GetCount(header.Range)) * app.GetObjectSize(header.Group, header.Variation))
+	}
+	if parser.HasMore() {
+		t.Errorf("Unexpected trailing data: %d bytes", parser.Remaining())
+	}
+
+	// A specific variation applies to every point
+	resp = h.request(app.BuildReadRequest(1, app.BuildRangeRead(app.GroupAnalogInput, app.AnalogInput16Bit, 0, 3)))
+	header, parser := readHeader(t, resp)
+	if header.Variation != app.AnalogInput16Bit || parser.Remaining() != 4*3 {
+		t.Errorf("G30V2 read: got G%dV%d with %d bytes", header.Group, header.Variation, parser.Remaining())
+	}
+}
+
+func TestStaticReadMixedVariationsIndexList(t *testing.T) {
+	config := OutstationConfig{
+		Database: DatabaseConfig{
+			Counter: []CounterPointConfig{
+				{StaticVariation: app.Counter32BitWithFlag},
+				{StaticVariation: app.Counter16Bit},
+				{StaticVariation: app.Counter16Bit},
+			},
+		},
+	}
+	h := newTestHarness(t, config)
+	h.outstation.database.UpdateCounter(2, types.Counter{Value: 0x1234, Flags: types.FlagOnline}, EventModeSuppress)
+
+	resp := h.request(app.BuildReadRequest(0, app.BuildIndexRead(app.GroupCounter, 0, []uint32{0, 2, 1})))
+
+	parser := app.NewParser(resp.Objects)
+	header, _ := parser.ReadObjectHeader()
+	if header.Variation != app.Counter32BitWithFlag || app.GetCount(header.Range) != 1 {
+		t.Fatalf("First header: got G%dV%d count %d, want G20V1 count 1",
+			header.Group, header.Variation, app.GetCount(header.Range))
+	}
+	parser.Skip(1 + 5)
+
+	header, _ = parser.ReadObjectHeader()
+	if header.Variation != app.Counter16Bit || app.GetCount(header.Range) != 2 {
+		t.Fatalf("Second header: got G%dV%d count %d, want G20V6 count 2",
+			header.Group, header.Variation, app.GetCount(header.Range))
+	}
+	index, _ := parser.ReadIndex(1)
+	data, _ := parser.ReadBytes(2)
+	if index != 2 || !bytes.Equal(data, []byte{0x34, 0x12}) {
+		t.Errorf("Point: got index %d data % X, want index 2 data 34 12", index, data)
+	}
+}
@@ -0,0 +1,66 @@
+package outstation
+
+import (
+	"time"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+// handleTimeSync handles a WRITE of the absolute time (Group 50 Variation 1)
+// or of the last recorded time (Variation 3) and returns the IIN2 bits to
+// report
+func (o *outstation) handleTimeSync(header *app.ObjectHeader, parser *app.Parser) uint8 {
+	if app.GetCount(header.Range) != 1 {
+		return types.IIN2ParameterError
+	}
+
+	switch header.Variation {
+	case app.TimeDateAbsolute, app.TimeDateLastRecorded:
+	default:
+		return types.IIN2ParameterError
+	}
+
+	// 48-bit milliseconds since epoch
+	data, err := parser.ReadBytes(app.GetObjectSize(header.Group, header.Variation))
+	if err != nil {
+		return types.IIN2ParameterError
+	}
+	t := types.DNP3Time(app.ParseTime48(data))
+
+	if header.Variation == app.TimeDateAbsolute {
+		o.clock.set(t)
+	} else {
+		// The master's time when RECORD CURRENT TIME was received
+		now, ok := o.clock.setRecorded(t)
+		if !ok {
+			o.logger.Warn("Outstation %s: Last recorded time written without RECORD CURRENT TIME", o.config.ID)
+			return types.IIN2ParameterError
+		}
+		t = now
+	}
+
+	o.logger.Info("Outstation %s: Time synchronized to %s", o.config.ID, t.ToTime().UTC())
+	o.callbacks.OnTimeSync(t.ToTime())
+	return 0
+}
+
+// handleDelayMeasurement handles DELAY MEASUREMENT, replying with the time
+// since the request was received so the master can work out the link delay
+func (o *outstation) handleDelayMeasurement(apdu *app.APDU, received time.Time) error {
+	o.logger.Debug("Outstation %s: Handling DELAY MEASUREMENT", o.config.ID)
+
+	iin := o.responseIIN()
+	response := app.NewResponseAPDU(apdu.Sequence, iin, app.BuildTimeDelay(time.Since(received)))
+	return o.session.sendAPDU(response.Serialize())
+}
+
+// handleRecordCurrentTime handles RECORD CURRENT TIME, remembering when the
+// request arrived for the last recorded time the master writes next
+func (o *outstation) handleRecordCurrentTime(apdu *app.APDU, received time.Time) error {
+	o.clock.record(received)
+	o.logger.Debug("Outstation %s: Handling RECORD CURRENT TIME", o.config.ID)
+
+	response := app.NewResponseAPDU(apdu.Sequence, o.responseIIN(), nil)
+	return o.session.sendAPDU(response.Serialize())
+}
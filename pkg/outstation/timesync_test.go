@@ -0,0 +1,71 @@
+package outstation
+
+import (
+	"testing"
+	"time"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+func TestDelayMeasurement(t *testing.T) {
+	h := newTestHarness(t, eventTestConfig())
+
+	resp := h.request(app.BuildDelayMeasurementRequest(0))
+	if resp.IIN.IIN2 != 0 {
+		t.Fatalf("IIN2: got 0x%02X, want 0", resp.IIN.IIN2)
+	}
+
+	parser := app.NewParser(resp.Objects)
+	header, err := parser.ReadObjectHeader()
+	if err != nil || header.Group != app.GroupTimeDelay || header.Variation != app.TimeDelayFine {
+		t.Fatalf("Expected G52V2, got %+v (%v)", header, err)
+	}
+	data, _ := parser.ReadBytes(2)
+	if delay := app.ParseTimeDelay(header.Variation, data); delay > 100*time.Millisecond {
+		t.Errorf("Processing delay: got %s, want close to 0", delay)
+	}
+}
+
+func TestLANTimeSync(t *testing.T) {
+	h := newTestHarness(t, eventTestConfig())
+
+	recorded := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	resp := h.request(app.BuildRecordCurrentTimeRequest(0))
+	if resp.IIN.IIN2 != 0 || len(resp.Objects) != 0 {
+		t.Fatalf("RECORD CURRENT TIME response: got IIN2=0x%02X objects=%d, want empty", resp.IIN.IIN2, len(resp.Objects))
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	resp = h.request(app.BuildLastRecordedTimeRequest(1, recorded))
+	if resp.IIN.IIN1&types.IIN1NeedTime != 0 || resp.IIN.IIN2 != 0 {
+		t.Errorf("WRITE G50V3 response: got IIN1=0x%02X IIN2=0x%02X, want NeedTime cleared", resp.IIN.IIN1, resp.IIN.IIN2)
+	}
+
+	// The clock runs on from the recorded time, not from the time of the write
+	elapsed := h.outstation.clock.now().ToTime().Sub(recorded)
+	if elapsed < 20*time.Millisecond || elapsed > time.Second {
+		t.Errorf("Clock: %s after recorded time, want the time since RECORD CURRENT TIME", elapsed)
+	}
+	if len(h.callbacks.timeSyncs) != 1 {
+		t.Errorf("OnTimeSync calls: got %d, want 1", len(h.callbacks.timeSyncs))
+	}
+
+	// Each recorded time is used once
+	resp = h.request(app.BuildLastRecordedTimeRequest(2, recorded))
+	if resp.IIN.IIN2&types.IIN2ParameterError == 0 {
+		t.Errorf("Second WRITE G50V3: got IIN2=0x%02X, want parameter error", resp.IIN.IIN2)
+	}
+}
+
+func TestLastRecordedTimeWithoutRecord(t *testing.T) {
+	h := newTestHarness(t, eventTestConfig())
+
+	resp := h.request(app.BuildLastRecordedTimeRequest(0, time.Now()))
+	if resp.IIN.IIN2&types.IIN2ParameterError == 0 {
+		t.Errorf("IIN2: got 0x%02X, want parameter error", resp.IIN.IIN2)
+	}
+	if resp.IIN.IIN1&types.IIN1NeedTime == 0 || len(h.callbacks.timeSyncs) != 0 {
+		t.Error("Clock should remain unsynchronized")
+	}
+}
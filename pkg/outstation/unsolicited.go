@@ -0,0 +1,161 @@
+package outstation
+
+import (
+	"time"
+
+	"avaneesh/dnp3-go/pkg/app"
+)
+
+// defaultUnsolConfirmTimeout is used when UnsolConfirmTimeout is not configured
+const defaultUnsolConfirmTimeout = 5 * time.Second
+
+// unsolicitedProcessor sends the null unsolicited response required after
+// restart and then reports events of the enabled classes as they occur
+func (o *outstation) unsolicitedProcessor() {
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		o.processUnsolicited()
+
+		select {
+		case <-o.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-o.unsolTrigger:
+		}
+	}
+}
+
+// notifyUnsolicited wakes the unsolicited processor, e.g. after new events
+func (o *outstation) notifyUnsolicited() {
+	select {
+	case o.unsolTrigger <- struct{}{}:
+	default:
+	}
+}
+
+// processUnsolicited performs at most one unsolicited transaction
+func (o *outstation) processUnsolicited() {
+	if !o.isEnabled() {
+		return
+	}
+
+	o.stateMu.RLock()
+	nullPending := o.unsolNullPending
+	mask := o.unsolicitedMask
+	solPending := o.solConfirm.pending
+	o.stateMu.RUnlock()
+
+	// Never interleave with a solicited response still awaiting confirmation
+	if solPending {
+		return
+	}
+
+	// Events may only be reported once the master has confirmed the null response
+	if nullPending {
+		seq, confirmed := o.sendUnsolicitedResponse(nil)
+		if confirmed {
+			o.stateMu.Lock()
+			o.unsolNullPending = false
+			o.stateMu.Unlock()
+			o.notifyUnsolicited()
+		}
+		o.callbacks.OnUnsolicitedResponse(confirmed, seq)
+		return
+	}
+
+	if mask&app.ClassAll == 0 {
+		return
+	}
+
+	events := o.eventBuffer.SelectEvents(mask, true)
+	if len(events) == 0 {
+		return
+	}
+
+	// Unsolicited responses are single fragments; events that do not fit are
+	// reported in the next one
+	w := o.newFragmentWriter()
+	writeEvents(w, events)
+	fragment := w.fragments[0]
+	if len(w.fragments) > 1 {
+		o.eventBuffer.unselectAfter(true, fragment.lastEvent)
+	}
+
+	seq, confirmed := o.sendUnsolicitedResponse(fragment.objects)
+	if confirmed {
+		numClass1, numClass2, numClass3 := o.eventBuffer.ClearSelected(true)
+		o.callbacks.OnConfirmReceived(true, numClass1, numClass2, numClass3)
+		o.notifyUnsolicited() // Report events that arrived meanwhile
+	} else {
+		o.eventBuffer.Unselect(true)
+	}
+	o.callbacks.OnUnsolicitedResponse(confirmed, seq)
+}
+
+// sendUnsolicitedResponse sends an unsolicited response and waits for its
+// CONFIRM, repeating the same fragment up to UnsolMaxRetries times
+func (o *outstation) sendUnsolicitedResponse(objects []byte) (uint8, bool) {
+	seq := o.unsolSeq.Next()
+	response := app.NewUnsolicitedResponseAPDU(seq, o.responseIIN(), objects)
+	data := response.Serialize()
+
+	for attempt := uint(0); attempt <= o.config.UnsolMaxRetries; attempt++ {
+		if attempt > 0 {
+			o.logger.Debug("Outstation %s: Retrying unsolicited response seq=%d (%d/%d)",
+				o.config.ID, seq, attempt, o.config.UnsolMaxRetries)
+		}
+
+		o.drainUnsolConfirms()
+		if err := o.session.sendAPDU(data); err != nil {
+			o.logger.Warn("Outstation %s: Failed to send unsolicited response: %v", o.config.ID, err)
+		} else if o.waitUnsolConfirm(seq) {
+			return seq, true
+		}
+
+		if o.ctx.Err() != nil {
+			break
+		}
+	}
+
+	o.logger.Warn("Outstation %s: Unsolicited response seq=%d not confirmed", o.config.ID, seq)
+	return seq, false
+}
+
+// waitUnsolConfirm waits for an unsolicited CONFIRM with the given sequence
+func (o *outstation) waitUnsolConfirm(seq uint8) bool {
+	timeout := o.config.UnsolConfirmTimeout
+	if timeout <= 0 {
+		timeout = defaultUnsolConfirmTimeout
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case confirmed := <-o.unsolConfirm:
+			if confirmed == seq {
+				return true
+			}
+			o.logger.Debug("Outstation %s: Ignoring unsolicited CONFIRM seq=%d, expected %d",
+				o.config.ID, confirmed, seq)
+		case <-timer.C:
+			return false
+		case <-o.ctx.Done():
+			return false
+		}
+	}
+}
+
+// drainUnsolConfirms discards stale confirmations before a new transmission
+func (o *outstation) drainUnsolConfirms() {
+	for {
+		select {
+		case <-o.unsolConfirm:
+		default:
+			return
+		}
+	}
+}
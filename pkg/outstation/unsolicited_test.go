@@ -0,0 +1,129 @@
+package outstation
+
+import (
+	"testing"
+	"time"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+func unsolTestConfig() OutstationConfig {
+	config := eventTestConfig()
+	config.AllowUnsolicited = true
+	config.UnsolConfirmTimeout = 100 * time.Millisecond
+	return config
+}
+
+// unsolConfirm builds the CONFIRM a master sends for an unsolicited response
+func unsolConfirm(seq uint8) []byte {
+	confirm := app.BuildConfirmRequest(seq)
+	confirm.UNS = true
+	return confirm.Serialize()
+}
+
+// expectUnsolResult waits for the next OnUnsolicitedResponse call
+func (h *testHarness) expectUnsolResult(success bool, seq uint8) {
+	h.t.Helper()
+
+	select {
+	case r := <-h.callbacks.unsolResults:
+		if r.success != success || r.seq != seq {
+			h.t.Fatalf("OnUnsolicitedResponse: got (%v, %d), want (%v, %d)", r.success, r.seq, success, seq)
+		}
+	case <-time.After(time.Second):
+		h.t.Fatalf("Timed out waiting for OnUnsolicitedResponse")
+	}
+}
+
+// confirmNullUnsolicited enables the outstation and completes the startup null response
+func (h *testHarness) confirmNullUnsolicited() {
+	h.t.Helper()
+
+	h.outstation.Enable()
+	resp := h.receive()
+	if resp.FunctionCode != app.FuncUnsolicitedResponse || !resp.UNS || !resp.CON || len(resp.Objects) != 0 {
+		h.t.Fatalf("Expected null unsolicited response, got %s", resp)
+	}
+	h.outstation.onReceiveAPDU(unsolConfirm(resp.Sequence))
+	h.expectUnsolResult(true, resp.Sequence)
+}
+
+func TestNullUnsolicitedAtStartup(t *testing.T) {
+	h := newTestHarness(t, unsolTestConfig())
+
+	// Events that occur before the null response is confirmed are held back
+	h.outstation.database.UpdateBinary(0, types.Binary{Value: true}, EventModeDetect)
+
+	h.confirmNullUnsolicited()
+
+	resp := h.receive()
+	if resp.FunctionCode != app.FuncUnsolicitedResponse || resp.Sequence != 1 {
+		t.Fatalf("Expected unsolicited events with seq 1, got %s", resp)
+	}
+	if headers := parseEventHeaders(t, resp.Objects); len(headers) != 1 || headers[0].group != app.GroupBinaryInputEvent {
+		t.Errorf("Unexpected event headers: %+v", headers)
+	}
+}
+
+func TestUnsolicitedEventsClearedOnConfirm(t *testing.T) {
+	h := newTestHarness(t, unsolTestConfig())
+	h.confirmNullUnsolicited()
+
+	h.outstation.database.UpdateAnalog(0, types.Analog{Value: 3}, EventModeDetect)
+	h.outstation.database.UpdateCounter(0, types.Counter{Value: 1}, EventModeDetect)
+	h.outstation.notifyUnsolicited()
+
+	resp := h.receive()
+	if !resp.UNS || !resp.CON {
+		t.Fatalf("Expected confirmable unsolicited response, got %s", resp)
+	}
+	h.outstation.onReceiveAPDU(unsolConfirm(resp.Sequence))
+	h.expectUnsolResult(true, resp.Sequence)
+
+	if h.outstation.eventBuffer.HasEvents() {
+		t.Error("Events should be removed after unsolicited CONFIRM")
+	}
+	if confirms := h.callbacks.confirmCounts(); len(confirms) != 1 || confirms[0] != [3]uint{1, 0, 1} {
+		t.Errorf("OnConfirmReceived: got %v, want [[1 0 1]]", confirms)
+	}
+}
+
+func TestUnsolicitedRetriesThenFails(t *testing.T) {
+	config := unsolTestConfig()
+	config.UnsolConfirmTimeout = 20 * time.Millisecond
+	config.UnsolMaxRetries = 2
+	h := newTestHarness(t, config)
+
+	h.outstation.Enable()
+
+	// The same fragment is sent once and then repeated for each retry
+	for i := 0; i < 3; i++ {
+		resp := h.receive()
+		if resp.FunctionCode != app.FuncUnsolicitedResponse || resp.Sequence != 0 {
+			t.Fatalf("Attempt %d: got %s, want unsolicited seq 0", i, resp)
+		}
+	}
+	h.expectUnsolResult(false, 0)
+}
+
+func TestUnsolicitedDisabledClasses(t *testing.T) {
+	h := newTestHarness(t, unsolTestConfig())
+	h.confirmNullUnsolicited()
+
+	resp := h.request(app.BuildDisableUnsolicitedRequest(0, app.Class1, app.Class2, app.Class3))
+	if resp.FunctionCode != app.FuncResponse {
+		t.Fatalf("Expected response to DISABLE UNSOLICITED, got %s", resp)
+	}
+
+	h.outstation.database.UpdateBinary(0, types.Binary{Value: true}, EventModeDetect)
+	h.outstation.notifyUnsolicited()
+	h.expectNoResponse()
+
+	// Re-enabling class 1 reports the buffered event
+	h.request(app.BuildEnableUnsolicitedRequest(1, app.Class1))
+	resp = h.receive()
+	if resp.FunctionCode != app.FuncUnsolicitedResponse || len(parseEventHeaders(t, resp.Objects)) != 1 {
+		t.Errorf("Expected unsolicited event after enable, got %s", resp)
+	}
+}
@@ -6,83 +6,84 @@ import (
 
 // UpdateBuilder builds atomic measurement updates
 type UpdateBuilder struct {
-	updates map[updateKey]updateValue
+	updates []measurementUpdate
 }
 
-type updateKey struct {
-	pointType MeasurementType
-	index     uint16
-}
-
-type updateValue struct {
+// measurementUpdate is a single point update; updates keep the order in which
+// they were added so events are generated in sequence
+type measurementUpdate struct {
+	pointType   MeasurementType
+	index       uint16
 	measurement interface{}
 	mode        EventMode
 }
 
 // NewUpdateBuilder creates a new update builder
 func NewUpdateBuilder() *UpdateBuilder {
-	return &UpdateBuilder{
-		updates: make(map[updateKey]updateValue),
-	}
+	return &UpdateBuilder{}
+}
+
+// add appends an update to the batch
+func (b *UpdateBuilder) add(pointType MeasurementType, value interface{}, index uint16, mode EventMode) *UpdateBuilder {
+	b.updates = append(b.updates, measurementUpdate{
+		pointType:   pointType,
+		index:       index,
+		measurement: value,
+		mode:        mode,
+	})
+	return b
 }
 
 // UpdateBinary updates a binary point
 func (b *UpdateBuilder) UpdateBinary(value types.Binary, index uint16, mode EventMode) *UpdateBuilder {
-	key := updateKey{pointType: MeasurementTypeBinary, index: index}
-	b.updates[key] = updateValue{measurement: value, mode: mode}
-	return b
+	return b.add(MeasurementTypeBinary, value, index, mode)
 }
 
 // UpdateDoubleBitBinary updates a double-bit binary point
 func (b *UpdateBuilder) UpdateDoubleBitBinary(value types.DoubleBitBinary, index uint16, mode EventMode) *UpdateBuilder {
-	key := updateKey{pointType: MeasurementTypeDoubleBitBinary, index: index}
-	b.updates[key] = updateValue{measurement: value, mode: mode}
-	return b
+	return b.add(MeasurementTypeDoubleBitBinary, value, index, mode)
 }
 
 // UpdateAnalog updates an analog point
 func (b *UpdateBuilder) UpdateAnalog(value types.Analog, index uint16, mode EventMode) *UpdateBuilder {
-	key := updateKey{pointType: MeasurementTypeAnalog, index: index}
-	b.updates[key] = updateValue{measurement: value, mode: mode}
-	return b
+	return b.add(MeasurementTypeAnalog, value, index, mode)
 }
 
 // UpdateCounter updates a counter point
 func (b *UpdateBuilder) UpdateCounter(value types.Counter, index uint16, mode EventMode) *UpdateBuilder {
-	key := updateKey{pointType: MeasurementTypeCounter, index: index}
-	b.updates[key] = updateValue{measurement: value, mode: mode}
-	return b
+	return b.add(MeasurementTypeCounter, value, index, mode)
 }
 
 // UpdateFrozenCounter updates a frozen counter point
 func (b *UpdateBuilder) UpdateFrozenCounter(value types.FrozenCounter, index uint16, mode EventMode) *UpdateBuilder {
-	key := updateKey{pointType: MeasurementTypeFrozenCounter, index: index}
-	b.updates[key] = updateValue{measurement: value, mode: mode}
-	return b
+	return b.add(MeasurementTypeFrozenCounter, value, index, mode)
 }
 
 // UpdateBinaryOutputStatus updates a binary output status point
 func (b *UpdateBuilder) UpdateBinaryOutputStatus(value types.BinaryOutputStatus, index uint16, mode EventMode) *UpdateBuilder {
-	key := updateKey{pointType: MeasurementTypeBinaryOutputStatus, index: index}
-	b.updates[key] = updateValue{measurement: value, mode: mode}
-	return b
+	return b.add(MeasurementTypeBinaryOutputStatus, value, index, mode)
 }
 
 // UpdateAnalogOutputStatus updates an analog output status point
 func (b *UpdateBuilder) UpdateAnalogOutputStatus(value types.AnalogOutputStatus, index uint16, mode EventMode) *UpdateBuilder {
-	key := updateKey{pointType: MeasurementTypeAnalogOutputStatus, index: index}
-	b.updates[key] = updateValue{measurement: value, mode: mode}
-	return b
+	return b.add(MeasurementTypeAnalogOutputStatus, value, index, mode)
+}
+
+// UpdateOctetString updates an octet string point
+func (b *UpdateBuilder) UpdateOctetString(value types.OctetString, index uint16, mode EventMode) *UpdateBuilder {
+	return b.add(MeasurementTypeOctetString, value, index, mode)
 }
 
 // Build builds the updates object
 func (b *UpdateBuilder) Build() *Updates {
+	data := make([]measurementUpdate, len(b.updates))
+	copy(data, b.updates)
 	return &Updates{
-		Data: b.updates,
+		Data: data,
 	}
 }
 
-// GetUpdates returns the updates map (for internal use)
-func (b *UpdateBuilder) GetUpdates() map[updateKey]updateValue {
+// GetUpdates returns the pending updates (for internal use)
+func (b *UpdateBuilder) GetUpdates() []measurementUpdate {
 	return b.updates
 }
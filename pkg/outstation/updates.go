@@ -1,11 +1,11 @@
 package outstation
 
-// Updates represents a batch of measurement updates (simplified)
+// Updates represents an ordered batch of measurement updates applied atomically
 type Updates struct {
-	Data map[updateKey]updateValue
+	Data []measurementUpdate
 }
 
 // NewUpdates creates a new Updates with the given data
-func NewUpdates(data map[updateKey]updateValue) *Updates {
+func NewUpdates(data []measurementUpdate) *Updates {
 	return &Updates{Data: data}
 }
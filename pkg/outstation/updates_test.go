@@ -0,0 +1,146 @@
+package outstation
+
+import (
+	"testing"
+	"time"
+
+	"avaneesh/dnp3-go/pkg/app"
+	"avaneesh/dnp3-go/pkg/types"
+)
+
+func allTypesConfig() OutstationConfig {
+	return OutstationConfig{
+		MaxBinaryEvents: 10,
+		Database: DatabaseConfig{
+			Binary:        []BinaryPointConfig{{Class: 1}},
+			DoubleBit:     []DoubleBitBinaryPointConfig{{Class: 1}},
+			Analog:        []AnalogPointConfig{{Class: 1}},
+			Counter:       []CounterPointConfig{{Class: 1}},
+			FrozenCounter: []FrozenCounterPointConfig{{Class: 1}},
+			BinaryOutput:  []BinaryOutputStatusPointConfig{{Class: 1}},
+			AnalogOutput:  []AnalogOutputStatusPointConfig{{Class: 1}},
+		},
+	}
+}
+
+// waitFor polls cond until it holds or the test times out
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestApplyAllMeasurementTypes(t *testing.T) {
+	h := newTestHarness(t, allTypesConfig())
+	if err := h.outstation.Enable(); err != nil {
+		t.Fatalf("Enable failed: %v", err)
+	}
+	db := h.outstation.database
+
+	updates := NewUpdateBuilder().
+		UpdateBinary(types.Binary{Value: true}, 0, EventModeDetect).
+		UpdateDoubleBitBinary(types.DoubleBitBinary{Value: types.DoubleBitOn}, 0, EventModeDetect).
+		UpdateAnalog(types.Analog{Value: 1.5}, 0, EventModeDetect).
+		UpdateCounter(types.Counter{Value: 42}, 0, EventModeDetect).
+		UpdateFrozenCounter(types.FrozenCounter{Value: 41}, 0, EventModeDetect).
+		UpdateBinaryOutputStatus(types.BinaryOutputStatus{Value: true}, 0, EventModeDetect).
+		UpdateAnalogOutputStatus(types.AnalogOutputStatus{Value: -2.5}, 0, EventModeDetect).
+		Build()
+
+	if err := h.outstation.Apply(updates); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	// The last update of the batch becomes visible together with the first
+	waitFor(t, "updates", func() bool {
+		v, _ := db.GetAnalogOutputStatus(0)
+		return v.Value == -2.5
+	})
+
+	if v, _ := db.GetBinary(0); !v.Value {
+		t.Errorf("Binary: got %v, want true", v.Value)
+	}
+	if v, _ := db.GetDoubleBitBinary(0); v.Value != types.DoubleBitOn {
+		t.Errorf("DoubleBitBinary: got %v, want %v", v.Value, types.DoubleBitOn)
+	}
+	if v, _ := db.GetAnalog(0); v.Value != 1.5 {
+		t.Errorf("Analog: got %v, want 1.5", v.Value)
+	}
+	if v, _ := db.GetCounter(0); v.Value != 42 {
+		t.Errorf("Counter: got %v, want 42", v.Value)
+	}
+	if v, _ := db.GetFrozenCounter(0); v.Value != 41 {
+		t.Errorf("FrozenCounter: got %v, want 41", v.Value)
+	}
+	if v, _ := db.GetBinaryOutputStatus(0); !v.Value {
+		t.Errorf("BinaryOutputStatus: got %v, want true", v.Value)
+	}
+}
+
+func TestApplyPreservesOrderAcrossBatches(t *testing.T) {
+	h := newTestHarness(t, allTypesConfig())
+	h.outstation.Enable()
+
+	// Many small batches must not block and must produce events in order
+	for i := 0; i < 6; i++ {
+		updates := NewUpdateBuilder().
+			UpdateBinary(types.Binary{Value: i%2 == 0}, 0, EventModeDetect).
+			Build()
+		if err := h.outstation.Apply(updates); err != nil {
+			t.Fatalf("Apply %d failed: %v", i, err)
+		}
+	}
+
+	waitFor(t, "events", func() bool {
+		return h.outstation.eventBuffer.GetClass1Count() == 6
+	})
+
+	events := h.outstation.eventBuffer.SelectEvents(app.Class1, false)
+	for i, event := range events {
+		if got := event.Value.(types.Binary).Value; got != (i%2 == 0) {
+			t.Errorf("Event %d: got %v, want %v", i, got, i%2 == 0)
+		}
+	}
+}
+
+func TestApplyWhenDisabled(t *testing.T) {
+	h := newTestHarness(t, allTypesConfig())
+
+	updates := NewUpdateBuilder().UpdateBinary(types.Binary{Value: true}, 0, EventModeDetect).Build()
+	if err := h.outstation.Apply(updates); err != ErrOutstationDisabled {
+		t.Errorf("Apply: got %v, want %v", err, ErrOutstationDisabled)
+	}
+}
+
+func TestApplyQueueFull(t *testing.T) {
+	h := newTestHarness(t, allTypesConfig())
+	h.outstation.Enable()
+
+	// Fill the queue as a producer outrunning the update processor would
+	h.outstation.updateMu.Lock()
+	h.outstation.pendingUpdates = make([]measurementUpdate, maxPendingUpdates)
+	h.outstation.updateMu.Unlock()
+
+	updates := NewUpdateBuilder().UpdateBinary(types.Binary{Value: true}, 0, EventModeDetect).Build()
+	if err := h.outstation.Apply(updates); err != ErrUpdateQueueFull {
+		t.Fatalf("Apply: got %v, want %v", err, ErrUpdateQueueFull)
+	}
+
+	h.outstation.updateMu.Lock()
+	queued := len(h.outstation.pendingUpdates)
+	h.outstation.pendingUpdates = nil
+	h.outstation.updateMu.Unlock()
+	if queued != maxPendingUpdates {
+		t.Errorf("Queued updates: got %d, want %d", queued, maxPendingUpdates)
+	}
+
+	if err := h.outstation.Apply(updates); err != nil {
+		t.Errorf("Apply after the queue drained: %v", err)
+	}
+}
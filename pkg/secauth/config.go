@@ -0,0 +1,156 @@
+package secauth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"avaneesh/dnp3-go/pkg/app"
+)
+
+var (
+	ErrAuthentication = errors.New("secure authentication failed")
+	ErrInvalidConfig  = errors.New("invalid secure authentication configuration")
+)
+
+// DefaultUser is the user number of a device with a single user
+const DefaultUser uint16 = 1
+
+// Defaults used when the configuration leaves a value zero
+const (
+	DefaultMACAlgorithm      = app.MACHMACSHA256Trunc16
+	DefaultKeyChangeInterval = 15 * time.Minute
+)
+
+// sessionKeySize is the length of the session keys set by the master
+const sessionKeySize = 32
+
+// challengeDataSize is the length of the pseudo-random challenge data
+const challengeDataSize = 16
+
+// Role authorizes the critical requests of a user, following the roles of
+// IEC 62351-8
+type Role uint16
+
+const (
+	RoleViewer     Role = 0     // Nothing critical
+	RoleOperator   Role = 1     // Controls and freezes
+	RoleEngineer   Role = 2     // Writes, configuration and files
+	RoleInstaller  Role = 3     // As engineer, plus restarts and applications
+	RoleSecAdm     Role = 4     // Security administration, nothing critical
+	RoleSecAud     Role = 5     // Security audit, nothing critical
+	RoleRBACMnt    Role = 6     // Role management, nothing critical
+	RoleSingleUser Role = 32768 // Everything
+)
+
+// String returns the name of the role
+func (r Role) String() string {
+	switch r {
+	case RoleViewer:
+		return "VIEWER"
+	case RoleOperator:
+		return "OPERATOR"
+	case RoleEngineer:
+		return "ENGINEER"
+	case RoleInstaller:
+		return "INSTALLER"
+	case RoleSecAdm:
+		return "SECADM"
+	case RoleSecAud:
+		return "SECAUD"
+	case RoleRBACMnt:
+		return "RBACMNT"
+	case RoleSingleUser:
+		return "SINGLE_USER"
+	default:
+		return fmt.Sprintf("Role(%d)", uint16(r))
+	}
+}
+
+// Permits reports whether the role may issue a critical request
+func (r Role) Permits(fc app.FunctionCode) bool {
+	switch r {
+	case RoleSingleUser:
+		return IsCritical(fc)
+	case RoleOperator:
+		return isControl(fc)
+	case RoleEngineer:
+		return isConfiguration(fc)
+	case RoleInstaller:
+		return isConfiguration(fc) || isRestart(fc)
+	default:
+		return false
+	}
+}
+
+// IsCritical reports whether a request must be authenticated: controls,
+// writes, configuration, restarts and file operations
+func IsCritical(fc app.FunctionCode) bool {
+	return isControl(fc) || isConfiguration(fc) || isRestart(fc)
+}
+
+func isControl(fc app.FunctionCode) bool {
+	switch fc {
+	case app.FuncSelect, app.FuncOperate, app.FuncDirectOperate, app.FuncDirectOperateNoAck,
+		app.FuncImmediateFreeze, app.FuncImmediateFreezeNoAck,
+		app.FuncFreezeClear, app.FuncFreezeClearNoAck,
+		app.FuncFreezeAtTime, app.FuncFreezeAtTimeNoAck:
+		return true
+	}
+	return false
+}
+
+func isConfiguration(fc app.FunctionCode) bool {
+	switch fc {
+	case app.FuncWrite, app.FuncAssignClass, app.FuncSaveConfiguration,
+		app.FuncEnableUnsolicited, app.FuncDisableUnsolicited,
+		app.FuncOpenFile, app.FuncCloseFile, app.FuncDeleteFile,
+		app.FuncGetFileInfo, app.FuncAuthenticateFile, app.FuncAbortFile:
+		return true
+	}
+	return false
+}
+
+func isRestart(fc app.FunctionCode) bool {
+	switch fc {
+	case app.FuncColdRestart, app.FuncWarmRestart, app.FuncInitializeData,
+		app.FuncInitializeApplication, app.FuncStartApplication, app.FuncStopApplication:
+		return true
+	}
+	return false
+}
+
+// User is a user known to the outstation
+type User struct {
+	Number    uint16
+	UpdateKey []byte // 16 octets for AES-128 key wrap, 32 for AES-256
+	Role      Role
+}
+
+// OutstationConfig configures Secure Authentication of an outstation
+type OutstationConfig struct {
+	Users                 []User
+	MACAlgorithm          app.MACAlgorithm // MAC of challenges; zero for DefaultMACAlgorithm
+	KeyChangeInterval     time.Duration    // Session keys expire after twice this; zero for DefaultKeyChangeInterval
+	DisableAggressiveMode bool
+}
+
+// MasterConfig configures Secure Authentication of a master
+type MasterConfig struct {
+	User              uint16        // Zero for DefaultUser
+	UpdateKey         []byte        // Shared with the outstation: 16 octets for AES-128 key wrap, 32 for AES-256
+	KeyChangeInterval time.Duration // Zero for DefaultKeyChangeInterval
+	AggressiveMode    bool          // Authenticate critical requests without waiting for a challenge
+}
+
+// keyWrapAlgorithm returns the key wrap algorithm of an update key
+func keyWrapAlgorithm(updateKey []byte) (app.KeyWrapAlgorithm, error) {
+	switch len(updateKey) {
+	case 16:
+		return app.KeyWrapAES128, nil
+	case 32:
+		return app.KeyWrapAES256, nil
+	default:
+		return 0, fmt.Errorf("%w: %d octet update key, want 16 or 32", ErrInvalidConfig, len(updateKey))
+	}
+}
@@ -0,0 +1,101 @@
+package secauth
+
+import (
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+// errKeyUnwrap is returned when wrapped data fails its integrity check
+var errKeyUnwrap = errors.New("key unwrap integrity check failed")
+
+// keyWrapIV is the default initial value of RFC 3394
+var keyWrapIV = []byte{0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6}
+
+// computeMAC returns the HMAC-SHA-256 of the data, truncated for the
+// algorithm
+func computeMAC(size int, key []byte, data ...[]byte) []byte {
+	h := hmac.New(sha256.New, key)
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)[:size]
+}
+
+// macEqual compares MAC values in constant time
+func macEqual(a, b []byte) bool {
+	return hmac.Equal(a, b)
+}
+
+// randomBytes returns n pseudo-random octets
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	rand.Read(b)
+	return b
+}
+
+// wrapKey wraps data, a multiple of 8 octets, with the AES key wrap of
+// RFC 3394
+func wrapKey(kek, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 16 || len(data)%8 != 0 {
+		return nil, errors.New("key wrap data must be a multiple of 8 octets, at least 16")
+	}
+
+	n := len(data) / 8
+	out := make([]byte, 8+len(data))
+	copy(out, keyWrapIV)
+	copy(out[8:], data)
+
+	buf := make([]byte, 16)
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			copy(buf, out[:8])
+			copy(buf[8:], out[8*i:8*i+8])
+			block.Encrypt(buf, buf)
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(out[:8], binary.BigEndian.Uint64(buf[:8])^t)
+			copy(out[8*i:], buf[8:])
+		}
+	}
+	return out, nil
+}
+
+// unwrapKey reverses wrapKey, failing when the data was not wrapped with
+// the key
+func unwrapKey(kek, wrapped []byte) ([]byte, error) {
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < 24 || len(wrapped)%8 != 0 {
+		return nil, errKeyUnwrap
+	}
+
+	n := len(wrapped)/8 - 1
+	out := append([]byte(nil), wrapped...)
+
+	buf := make([]byte, 16)
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(buf[:8], binary.BigEndian.Uint64(out[:8])^t)
+			copy(buf[8:], out[8*i:8*i+8])
+			block.Decrypt(buf, buf)
+			copy(out[:8], buf[:8])
+			copy(out[8*i:], buf[8:])
+		}
+	}
+
+	if subtle.ConstantTimeCompare(out[:8], keyWrapIV) != 1 {
+		return nil, errKeyUnwrap
+	}
+	return out[8:], nil
+}
@@ -0,0 +1,46 @@
+package secauth
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestKeyWrap(t *testing.T) {
+	// RFC 3394 sections 4.1 and 4.6
+	tests := []struct {
+		kek, data, wrapped string
+	}{
+		{
+			kek:     "000102030405060708090A0B0C0D0E0F",
+			data:    "00112233445566778899AABBCCDDEEFF",
+			wrapped: "1FA68B0A8112B447AEF34BD8FB5A7B829D3E862371D2CFE5",
+		},
+		{
+			kek:     "000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F",
+			data:    "00112233445566778899AABBCCDDEEFF000102030405060708090A0B0C0D0E0F",
+			wrapped: "28C9F404C4B810F4CBCCB35CFB87F8263F5786E2D80ED326CBC7F0E71A99F43BFB988B9B7A02DD21",
+		},
+	}
+
+	for _, tt := range tests {
+		kek, _ := hex.DecodeString(tt.kek)
+		data, _ := hex.DecodeString(tt.data)
+		expected, _ := hex.DecodeString(tt.wrapped)
+
+		wrapped, err := wrapKey(kek, data)
+		if err != nil || !bytes.Equal(wrapped, expected) {
+			t.Errorf("Wrap with %d octet key: got %X, %v, want %X", len(kek), wrapped, err, expected)
+			continue
+		}
+		if got, err := unwrapKey(kek, wrapped); err != nil || !bytes.Equal(got, data) {
+			t.Errorf("Unwrap with %d octet key: got %X, %v, want %X", len(kek), got, err, data)
+		}
+
+		wrapped[len(wrapped)-1] ^= 1
+		if _, err := unwrapKey(kek, wrapped); !errors.Is(err, errKeyUnwrap) {
+			t.Errorf("Tampered unwrap with %d octet key: got %v, want errKeyUnwrap", len(kek), err)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package secauth
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"avaneesh/dnp3-go/pkg/app"
+)
+
+// Master holds the session keys and challenge state of a master user. It
+// builds the Group 120 objects of each exchange; the caller sends them and
+// returns the outstation's objects.
+type Master struct {
+	config   MasterConfig
+	user     uint16
+	keyWrap  app.KeyWrapAlgorithm
+	interval time.Duration
+
+	mu            sync.Mutex
+	controlKey    []byte
+	monitoringKey []byte
+	keysSetAt     time.Time
+	keyChange     *keyChange // Keys sent, awaiting the outstation's status
+
+	csq               uint32
+	challenge         []byte // Last challenge object, covered by aggressive mode MACs
+	challengeMAC      app.MACAlgorithm
+	aggressiveRefused bool // The outstation refused aggressive mode
+}
+
+// keyChange is a key change awaiting confirmation
+type keyChange struct {
+	ksq           uint32
+	object        []byte // Key change object, covered by the confirming MAC
+	controlKey    []byte
+	monitoringKey []byte
+}
+
+// NewMaster creates the authentication state of a master
+func NewMaster(config MasterConfig) (*Master, error) {
+	keyWrap, err := keyWrapAlgorithm(config.UpdateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Master{
+		config:   config,
+		user:     config.User,
+		keyWrap:  keyWrap,
+		interval: config.KeyChangeInterval,
+	}
+	if m.user == 0 {
+		m.user = DefaultUser
+	}
+	if m.interval == 0 {
+		m.interval = DefaultKeyChangeInterval
+	}
+	return m, nil
+}
+
+// NeedsKeyChange reports whether session keys must be set before the next
+// critical request
+func (m *Master) NeedsKeyChange() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.controlKey == nil || time.Since(m.keysSetAt) >= m.interval
+}
+
+// InvalidateKeys discards the session keys, forcing a key change
+func (m *Master) InvalidateKeys() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.invalidate()
+}
+
+func (m *Master) invalidate() {
+	m.controlKey = nil
+	m.monitoringKey = nil
+	m.challenge = nil
+}
+
+// KeyStatusRequest returns the objects of the AUTH REQUEST that starts a
+// key change
+func (m *Master) KeyStatusRequest() []byte {
+	return app.BuildKeyStatusRequest(m.user)
+}
+
+// ChangeKeys reads the key status answering KeyStatusRequest and returns
+// the objects of the AUTH REQUEST carrying new session keys
+func (m *Master) ChangeKeys(objects []byte) ([]byte, error) {
+	object, err := expectAuthObject(objects, app.AuthKeyStatus)
+	if err != nil {
+		return nil, err
+	}
+	status, err := app.ParseAuthKeyStatus(object)
+	if err != nil {
+		return nil, err
+	}
+	if status.User != m.user {
+		return nil, fmt.Errorf("%w: key status for user %d, want %d", ErrAuthentication, status.User, m.user)
+	}
+	if status.KeyWrap != m.keyWrap {
+		return nil, fmt.Errorf("%w: outstation uses key wrap algorithm %d, want %d", ErrAuthentication, status.KeyWrap, m.keyWrap)
+	}
+
+	change := &keyChange{
+		ksq:           status.KSQ,
+		controlKey:    randomBytes(sessionKeySize),
+		monitoringKey: randomBytes(sessionKeySize),
+	}
+
+	// The keys are wrapped with the status they answer, so a stale or
+	// replayed key change is refused
+	status.MACValue = nil
+	plain := binary.LittleEndian.AppendUint16(nil, sessionKeySize)
+	plain = append(plain, change.controlKey...)
+	plain = append(plain, change.monitoringKey...)
+	plain = append(plain, status.Serialize()...)
+	if pad := len(plain) % 8; pad != 0 {
+		plain = append(plain, make([]byte, 8-pad)...)
+	}
+	wrapped, err := wrapKey(m.config.UpdateKey, plain)
+	if err != nil {
+		return nil, err
+	}
+
+	change.object = app.AuthKeyChangeObject{KSQ: status.KSQ, User: m.user, WrappedKeys: wrapped}.Serialize()
+
+	m.mu.Lock()
+	m.keyChange = change
+	m.mu.Unlock()
+	return app.BuildAuthObject(app.AuthKeyChange, change.object), nil
+}
+
+// ConfirmKeys reads the key status answering ChangeKeys, putting the new
+// session keys in use once the outstation proves it holds them
+func (m *Master) ConfirmKeys(objects []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	change := m.keyChange
+	m.keyChange = nil
+	if change == nil {
+		return errors.New("no key change in progress")
+	}
+
+	object, err := expectAuthObject(objects, app.AuthKeyStatus)
+	if err != nil {
+		return err
+	}
+	status, err := app.ParseAuthKeyStatus(object)
+	if err != nil {
+		return err
+	}
+	if status.Status != app.KeyStatusOK {
+		return fmt.Errorf("%w: key status %s", ErrAuthentication, status.Status)
+	}
+	if status.KSQ != change.ksq || status.User != m.user {
+		return fmt.Errorf("%w: key status KSQ %d user %d, want KSQ %d user %d",
+			ErrAuthentication, status.KSQ, status.User, change.ksq, m.user)
+	}
+	size := status.MAC.Size()
+	if size == 0 || !macEqual(status.MACValue, computeMAC(size, change.monitoringKey, change.object)) {
+		return fmt.Errorf("%w: key status MAC mismatch", ErrAuthentication)
+	}
+
+	m.controlKey = change.controlKey
+	m.monitoringKey = change.monitoringKey
+	m.keysSetAt = time.Now()
+	return nil
+}
+
+// Reply reads the challenge of a critical request and returns the objects
+// of the AUTH REQUEST answering it. request is the request as sent.
+func (m *Master) Reply(objects, request []byte) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	object, err := expectAuthObject(objects, app.AuthChallenge)
+	if err != nil {
+		if errors.Is(err, ErrAuthentication) {
+			m.refused(objects)
+		}
+		return nil, err
+	}
+	challenge, err := app.ParseAuthChallenge(object)
+	if err != nil {
+		return nil, err
+	}
+	size := challenge.MAC.Size()
+	if size == 0 {
+		return nil, fmt.Errorf("%w: unsupported MAC algorithm %d", ErrAuthentication, challenge.MAC)
+	}
+	if m.controlKey == nil {
+		return nil, fmt.Errorf("%w: no session keys", ErrAuthentication)
+	}
+
+	m.csq = challenge.CSQ
+	m.challenge = append([]byte(nil), object...)
+	m.challengeMAC = challenge.MAC
+
+	reply := app.AuthReplyObject{
+		CSQ:  challenge.CSQ,
+		User: m.user,
+		MAC:  computeMAC(size, m.controlKey, object, request),
+	}
+	return app.BuildAuthObject(app.AuthReply, reply.Serialize()), nil
+}
+
+// Aggressive returns the request authenticated in aggressive mode, or nil
+// when it must wait for a challenge instead. Aggressive mode needs a
+// challenge since the last failure to keep the challenge sequence in step.
+func (m *Master) Aggressive(request *app.APDU) *app.APDU {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.config.AggressiveMode || m.aggressiveRefused || m.controlKey == nil || m.challenge == nil {
+		return nil
+	}
+
+	csq := m.csq + 1
+	aggressive := *request
+	aggressive.Objects = append(app.BuildAggressiveMode(app.AuthAggressiveModeObject{CSQ: csq, User: m.user}), request.Objects...)
+	mac := computeMAC(m.challengeMAC.Size(), m.controlKey, m.challenge, aggressive.Serialize())
+	aggressive.Objects = append(aggressive.Objects, buildMACTrailer(mac)...)
+
+	m.csq = csq
+	return &aggressive
+}
+
+// Refused reads the outstation's answer to an authenticated request that
+// was not processed, returning why
+func (m *Master) Refused(objects []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	v, object, err := readAuthObject(objects)
+	switch {
+	case err != nil:
+		return err
+	case v != app.AuthError:
+		return fmt.Errorf("%w: unexpected Group 120 Var %d", ErrAuthentication, v)
+	}
+	m.refused(objects)
+	return authError(object)
+}
+
+// refused resets the state an error object puts in doubt
+func (m *Master) refused(objects []byte) {
+	v, object, err := readAuthObject(objects)
+	if err != nil || v != app.AuthError {
+		return
+	}
+	if e, err := app.ParseAuthError(object); err == nil {
+		switch e.Code {
+		case app.AuthErrorAggressiveUnsupported:
+			m.aggressiveRefused = true
+			return
+		case app.AuthErrorAuthorizationFailed:
+			return // Keys are fine, the user may not issue the request
+		}
+	}
+	m.invalidate()
+}
@@ -0,0 +1,74 @@
+package secauth
+
+import (
+	"fmt"
+
+	"avaneesh/dnp3-go/pkg/app"
+)
+
+// macTrailerSize is the length of the Group 120 Var 9 header and size
+// prefix that end an aggressive mode request, before the MAC value
+const macTrailerSize = 6
+
+// aggressiveHeaderSize is the length of the Group 120 Var 3 header and
+// object that start an aggressive mode request
+const aggressiveHeaderSize = 4 + 6
+
+// readAuthObject reads the first Group 120 object of a fragment, returning
+// its variation and the object without any size prefix
+func readAuthObject(objects []byte) (uint8, []byte, error) {
+	parser := app.NewParser(objects)
+	header, err := parser.ReadObjectHeader()
+	if err != nil {
+		return 0, nil, err
+	}
+	if header.Group != app.GroupAuthentication || app.GetCount(header.Range) != 1 {
+		return 0, nil, fmt.Errorf("expected one Group 120 object, got g%dv%d", header.Group, header.Variation)
+	}
+
+	if header.Qualifier == app.QualifierFreeFormat {
+		object, err := parser.ReadFreeFormatObject()
+		return header.Variation, object, err
+	}
+	size := app.GetObjectSize(header.Group, header.Variation)
+	if size == 0 {
+		return 0, nil, fmt.Errorf("unexpected Group 120 Var %d header", header.Variation)
+	}
+	object, err := parser.ReadBytes(size)
+	return header.Variation, object, err
+}
+
+// authError converts a Group 120 Var 7 object to an error
+func authError(object []byte) error {
+	e, err := app.ParseAuthError(object)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrAuthentication, err)
+	}
+	if e.Text != "" {
+		return fmt.Errorf("%w: user %d: %s (%s)", ErrAuthentication, e.User, e.Code, e.Text)
+	}
+	return fmt.Errorf("%w: user %d: %s", ErrAuthentication, e.User, e.Code)
+}
+
+// expectAuthObject reads the first Group 120 object of a response, which
+// must have the given variation; an error object is returned as an error
+func expectAuthObject(objects []byte, variation uint8) ([]byte, error) {
+	v, object, err := readAuthObject(objects)
+	if err != nil {
+		return nil, err
+	}
+	switch v {
+	case variation:
+		return object, nil
+	case app.AuthError:
+		return nil, authError(object)
+	default:
+		return nil, fmt.Errorf("expected Group 120 Var %d, got Var %d", variation, v)
+	}
+}
+
+// buildMACTrailer builds the Group 120 Var 9 object ending an aggressive
+// mode request
+func buildMACTrailer(mac []byte) []byte {
+	return app.BuildAuthObject(app.AuthMAC, mac)
+}
@@ -0,0 +1,292 @@
+package secauth
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"avaneesh/dnp3-go/pkg/app"
+)
+
+// Outstation authenticates the critical requests an outstation receives.
+// Receive sits between parsing a request and processing it.
+type Outstation struct {
+	config      OutstationConfig
+	mac         app.MACAlgorithm
+	keyLifetime time.Duration
+
+	mu         sync.Mutex
+	users      map[uint16]*userState
+	csq        uint32
+	challenge  []byte    // Last challenge object sent
+	pending    *app.APDU // Critical request awaiting its reply
+	pendingRaw []byte
+}
+
+// userState is the session key state of one user
+type userState struct {
+	User
+	keyWrap       app.KeyWrapAlgorithm
+	status        app.KeyStatus
+	ksq           uint32
+	lastStatus    []byte // Last key status sent, which the next key change must carry
+	controlKey    []byte
+	monitoringKey []byte
+	keysSetAt     time.Time
+}
+
+// NewOutstation creates the authentication state of an outstation
+func NewOutstation(config OutstationConfig) (*Outstation, error) {
+	o := &Outstation{
+		config:      config,
+		mac:         config.MACAlgorithm,
+		keyLifetime: 2 * config.KeyChangeInterval,
+		users:       make(map[uint16]*userState, len(config.Users)),
+	}
+	if o.mac == 0 {
+		o.mac = DefaultMACAlgorithm
+	}
+	if o.mac.Size() == 0 {
+		return nil, fmt.Errorf("%w: unsupported MAC algorithm %d", ErrInvalidConfig, o.mac)
+	}
+	if o.keyLifetime == 0 {
+		o.keyLifetime = 2 * DefaultKeyChangeInterval
+	}
+
+	if len(config.Users) == 0 {
+		return nil, fmt.Errorf("%w: no users", ErrInvalidConfig)
+	}
+	for _, user := range config.Users {
+		if _, ok := o.users[user.Number]; ok || user.Number == 0 {
+			return nil, fmt.Errorf("%w: invalid or duplicate user %d", ErrInvalidConfig, user.Number)
+		}
+		keyWrap, err := keyWrapAlgorithm(user.UpdateKey)
+		if err != nil {
+			return nil, fmt.Errorf("user %d: %w", user.Number, err)
+		}
+		o.users[user.Number] = &userState{User: user, keyWrap: keyWrap, status: app.KeyStatusNotInit}
+	}
+	return o, nil
+}
+
+// Receive authenticates a request, raw being the request as received. It
+// returns the request to process, if any, and the objects of an AUTH
+// RESPONSE to send, if any. Requests that are not critical pass through.
+func (o *Outstation) Receive(request *app.APDU, raw []byte) (*app.APDU, []byte, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	switch request.FunctionCode {
+	case app.FuncAuthRequest:
+		return o.receiveAuthRequest(request)
+	case app.FuncAuthRequestNoAck:
+		process, _, err := o.receiveAuthRequest(request)
+		return process, nil, err
+	}
+
+	if !IsCritical(request.FunctionCode) {
+		return request, nil, nil
+	}
+
+	if v, _, err := readAuthObject(request.Objects); err == nil && v == app.AuthAggressiveMode {
+		return o.receiveAggressive(request, raw)
+	}
+
+	// Challenge the request and hold it until the master replies
+	o.csq++
+	challenge := app.AuthChallengeObject{
+		CSQ:    o.csq,
+		MAC:    o.mac,
+		Reason: app.AuthReasonCritical,
+		Data:   randomBytes(challengeDataSize),
+	}
+	o.challenge = challenge.Serialize()
+	o.pending = request
+	o.pendingRaw = append([]byte(nil), raw...)
+	return nil, app.BuildAuthObject(app.AuthChallenge, o.challenge), nil
+}
+
+// receiveAuthRequest handles the objects of an AUTH REQUEST
+func (o *Outstation) receiveAuthRequest(request *app.APDU) (*app.APDU, []byte, error) {
+	v, object, err := readAuthObject(request.Objects)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch v {
+	case app.AuthKeyStatusRequest:
+		if len(object) < 2 {
+			return nil, o.errorObject(0, app.AuthErrorAuthenticationFailed),
+				fmt.Errorf("%w: key status request of %d bytes", ErrAuthentication, len(object))
+		}
+		number := binary.LittleEndian.Uint16(object)
+		user, ok := o.users[number]
+		if !ok {
+			return nil, o.errorObject(number, app.AuthErrorUnknownUser), fmt.Errorf("%w: unknown user %d", ErrAuthentication, number)
+		}
+		user.ksq++
+		return nil, o.keyStatus(user, nil), nil
+
+	case app.AuthKeyChange:
+		change, err := app.ParseAuthKeyChange(object)
+		if err != nil {
+			return nil, nil, err
+		}
+		user, ok := o.users[change.User]
+		if !ok {
+			return nil, o.errorObject(change.User, app.AuthErrorUnknownUser), fmt.Errorf("%w: unknown user %d", ErrAuthentication, change.User)
+		}
+		if err := user.changeKeys(change); err != nil {
+			user.status = app.KeyStatusAuthFail
+			return nil, o.keyStatus(user, nil), err
+		}
+		return nil, o.keyStatus(user, computeMAC(o.mac.Size(), user.monitoringKey, object)), nil
+
+	case app.AuthReply:
+		reply, err := app.ParseAuthReply(object)
+		if err != nil {
+			return nil, nil, err
+		}
+		pending, pendingRaw := o.pending, o.pendingRaw
+		o.pending, o.pendingRaw = nil, nil // A reply is only ever accepted once
+		if pending == nil || reply.CSQ != o.csq {
+			return nil, o.errorObject(reply.User, app.AuthErrorAuthenticationFailed),
+				fmt.Errorf("%w: reply CSQ %d matches no challenge", ErrAuthentication, reply.CSQ)
+		}
+		if code, err := o.authenticate(reply.User, pending.FunctionCode, reply.MAC, o.challenge, pendingRaw); err != nil {
+			return nil, o.errorObject(reply.User, code), err
+		}
+		return pending, nil, nil
+
+	default:
+		return nil, nil, fmt.Errorf("unexpected Group 120 Var %d in AUTH REQUEST", v)
+	}
+}
+
+// receiveAggressive authenticates a request carrying its own MAC, returning
+// it without the authentication objects
+func (o *Outstation) receiveAggressive(request *app.APDU, raw []byte) (*app.APDU, []byte, error) {
+	// Any challenged request is superseded
+	o.pending, o.pendingRaw = nil, nil
+
+	_, object, _ := readAuthObject(request.Objects)
+	aggressive, err := app.ParseAuthAggressiveMode(object)
+	if err != nil {
+		return nil, nil, err
+	}
+	if o.config.DisableAggressiveMode {
+		return nil, o.errorObject(aggressive.User, app.AuthErrorAggressiveUnsupported),
+			fmt.Errorf("%w: aggressive mode disabled", ErrAuthentication)
+	}
+
+	// The MAC object ends the request and covers everything before it
+	size := o.mac.Size()
+	trailer := len(request.Objects) - macTrailerSize - size
+	if trailer < aggressiveHeaderSize || !bytes.Equal(request.Objects[trailer:trailer+macTrailerSize],
+		buildMACTrailer(make([]byte, size))[:macTrailerSize]) {
+		return nil, o.errorObject(aggressive.User, app.AuthErrorAuthenticationFailed),
+			fmt.Errorf("%w: aggressive mode request without a MAC", ErrAuthentication)
+	}
+	mac := request.Objects[trailer+macTrailerSize:]
+	covered := raw[:len(raw)-len(request.Objects)+trailer]
+
+	if o.challenge == nil || aggressive.CSQ != o.csq+1 {
+		return nil, o.errorObject(aggressive.User, app.AuthErrorAuthenticationFailed),
+			fmt.Errorf("%w: aggressive mode CSQ %d, want %d", ErrAuthentication, aggressive.CSQ, o.csq+1)
+	}
+	code, err := o.authenticate(aggressive.User, request.FunctionCode, mac, o.challenge, covered)
+	if err == nil || code == app.AuthErrorAuthorizationFailed {
+		o.csq = aggressive.CSQ // Spent once the MAC is good
+	}
+	if err != nil {
+		return nil, o.errorObject(aggressive.User, code), err
+	}
+
+	stripped := *request
+	stripped.Objects = request.Objects[aggressiveHeaderSize:trailer]
+	return &stripped, nil, nil
+}
+
+// authenticate checks the MAC of a critical request and that the user may
+// issue it
+func (o *Outstation) authenticate(number uint16, fc app.FunctionCode, mac []byte, data ...[]byte) (app.AuthErrorCode, error) {
+	user, ok := o.users[number]
+	if !ok {
+		return app.AuthErrorUnknownUser, fmt.Errorf("%w: unknown user %d", ErrAuthentication, number)
+	}
+	o.expireKeys(user)
+	if user.controlKey == nil {
+		return app.AuthErrorAuthenticationFailed, fmt.Errorf("%w: user %d has no valid session keys", ErrAuthentication, number)
+	}
+	if !macEqual(mac, computeMAC(o.mac.Size(), user.controlKey, data...)) {
+		return app.AuthErrorAuthenticationFailed, fmt.Errorf("%w: MAC mismatch for user %d", ErrAuthentication, number)
+	}
+	if !user.Role.Permits(fc) {
+		return app.AuthErrorAuthorizationFailed, fmt.Errorf("%w: user %d role %s may not issue %s",
+			ErrAuthentication, number, user.Role, fc)
+	}
+	return 0, nil
+}
+
+// changeKeys unwraps and installs the session keys of a key change
+func (u *userState) changeKeys(change app.AuthKeyChangeObject) error {
+	lastStatus := u.lastStatus
+	u.lastStatus = nil // Each status answers one key change
+	u.controlKey, u.monitoringKey = nil, nil
+
+	if lastStatus == nil || change.KSQ != u.ksq {
+		return fmt.Errorf("%w: key change KSQ %d, want %d", ErrAuthentication, change.KSQ, u.ksq)
+	}
+	plain, err := unwrapKey(u.UpdateKey, change.WrappedKeys)
+	if err != nil {
+		return fmt.Errorf("%w: user %d: %v", ErrAuthentication, u.Number, err)
+	}
+
+	size := 0
+	if len(plain) >= 2 {
+		size = int(binary.LittleEndian.Uint16(plain))
+	}
+	end := 2 + 2*size
+	if size < 16 || end+len(lastStatus) > len(plain) || !bytes.Equal(plain[end:end+len(lastStatus)], lastStatus) {
+		return fmt.Errorf("%w: user %d: key change does not answer the last key status", ErrAuthentication, u.Number)
+	}
+
+	u.controlKey = plain[2 : 2+size]
+	u.monitoringKey = plain[2+size : end]
+	u.keysSetAt = time.Now()
+	u.status = app.KeyStatusOK
+	return nil
+}
+
+// expireKeys discards session keys the master has not changed in time
+func (o *Outstation) expireKeys(user *userState) {
+	if user.controlKey != nil && time.Since(user.keysSetAt) >= o.keyLifetime {
+		user.controlKey, user.monitoringKey = nil, nil
+		user.status = app.KeyStatusCommFail
+	}
+}
+
+// keyStatus builds the key status of a user with a new challenge
+func (o *Outstation) keyStatus(user *userState, mac []byte) []byte {
+	o.expireKeys(user)
+	status := app.AuthKeyStatusObject{
+		KSQ:       user.ksq,
+		User:      user.Number,
+		KeyWrap:   user.keyWrap,
+		Status:    user.status,
+		MAC:       o.mac,
+		Challenge: randomBytes(challengeDataSize),
+	}
+	user.lastStatus = status.Serialize()
+
+	status.MACValue = mac
+	return app.BuildAuthObject(app.AuthKeyStatus, status.Serialize())
+}
+
+// errorObject builds an error object for a refused request
+func (o *Outstation) errorObject(user uint16, code app.AuthErrorCode) []byte {
+	object := app.AuthErrorObject{CSQ: o.csq, User: user, Code: code, Time: app.Now()}
+	return app.BuildAuthObject(app.AuthError, object.Serialize())
+}
@@ -0,0 +1,352 @@
+package secauth
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"avaneesh/dnp3-go/pkg/app"
+)
+
+var (
+	testUpdateKey  = bytes.Repeat([]byte{0x5A}, 16)
+	otherUpdateKey = bytes.Repeat([]byte{0xA5}, 16)
+)
+
+// newPair creates a master for user 1 and an outstation that knows user 1
+// with the given role
+func newPair(t *testing.T, role Role, aggressive bool) (*Master, *Outstation) {
+	t.Helper()
+
+	m, err := NewMaster(MasterConfig{UpdateKey: testUpdateKey, AggressiveMode: aggressive})
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, err := NewOutstation(OutstationConfig{Users: []User{{Number: DefaultUser, UpdateKey: testUpdateKey, Role: role}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, o
+}
+
+// send passes a request through the outstation as received off the wire
+func send(o *Outstation, request *app.APDU) (*app.APDU, []byte, error) {
+	return o.Receive(request, request.Serialize())
+}
+
+// changeKeys runs a key change between the master and the outstation
+func changeKeys(m *Master, o *Outstation) error {
+	_, status, err := send(o, app.NewRequestAPDU(app.FuncAuthRequest, 0, m.KeyStatusRequest()))
+	if err != nil {
+		return err
+	}
+	change, err := m.ChangeKeys(status)
+	if err != nil {
+		return err
+	}
+	_, status, err = send(o, app.NewRequestAPDU(app.FuncAuthRequest, 1, change))
+	if err != nil {
+		// The outstation still answers with its key status
+		if cerr := m.ConfirmKeys(status); cerr == nil {
+			return errors.New("master accepted a failed key change")
+		}
+		return err
+	}
+	return m.ConfirmKeys(status)
+}
+
+// operate builds a DIRECT OPERATE request for point 0
+func operate(seq uint8) *app.APDU {
+	return app.BuildDirectOperateRequest(seq, app.BuildCROBRequest(0, app.NewCROB(app.ControlCodeLatchOn, 1, 0, 0)))
+}
+
+// challenge sends a critical request and answers the outstation's
+// challenge, returning the request the outstation processes
+func challenge(t *testing.T, m *Master, o *Outstation, request *app.APDU) (*app.APDU, []byte, error) {
+	t.Helper()
+
+	raw := request.Serialize()
+	processed, objects, err := o.Receive(request, raw)
+	if err != nil || processed != nil {
+		t.Fatalf("Critical request: got %v, %v, want a challenge", processed, err)
+	}
+	reply, err := m.Reply(objects, raw)
+	if err != nil {
+		t.Fatalf("Reply: %v", err)
+	}
+	return send(o, app.NewRequestAPDU(app.FuncAuthRequest, request.Sequence, reply))
+}
+
+func TestNonCriticalPassesThrough(t *testing.T) {
+	_, o := newPair(t, RoleViewer, false)
+
+	request := app.BuildIntegrityPollRequest(0)
+	processed, reply, err := send(o, request)
+	if err != nil || processed != request || reply != nil {
+		t.Errorf("READ: got %v, % X, %v, want the request unchanged", processed, reply, err)
+	}
+}
+
+func TestChallengeResponse(t *testing.T) {
+	m, o := newPair(t, RoleSingleUser, false)
+
+	if !m.NeedsKeyChange() {
+		t.Fatal("Master without session keys does not need a key change")
+	}
+	if err := changeKeys(m, o); err != nil {
+		t.Fatalf("Key change: %v", err)
+	}
+	if m.NeedsKeyChange() {
+		t.Error("Master needs a key change straight after one")
+	}
+
+	request := operate(2)
+	raw := request.Serialize()
+	_, objects, _ := o.Receive(request, raw)
+	reply, err := m.Reply(objects, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authRequest := app.NewRequestAPDU(app.FuncAuthRequest, 2, reply)
+
+	processed, response, err := send(o, authRequest)
+	if err != nil || response != nil || processed == nil {
+		t.Fatalf("Reply: got %v, % X, %v, want the operate to process", processed, response, err)
+	}
+	if processed.FunctionCode != app.FuncDirectOperate || processed.Sequence != 2 || !bytes.Equal(processed.Objects, request.Objects) {
+		t.Errorf("Processed %v, want %v", processed, request)
+	}
+
+	// A replayed reply answers no outstanding challenge
+	processed, response, err = send(o, authRequest)
+	if !errors.Is(err, ErrAuthentication) || processed != nil {
+		t.Fatalf("Replay: got %v, %v, want ErrAuthentication", processed, err)
+	}
+	if err := m.Refused(response); !errors.Is(err, ErrAuthentication) {
+		t.Errorf("Refused: got %v, want ErrAuthentication", err)
+	}
+	if !m.NeedsKeyChange() {
+		t.Error("Master kept its session keys after an authentication error")
+	}
+}
+
+func TestChallengeWrongMAC(t *testing.T) {
+	m, o := newPair(t, RoleSingleUser, false)
+	if err := changeKeys(m, o); err != nil {
+		t.Fatal(err)
+	}
+
+	// The master signs a different request from the one challenged
+	request := operate(3)
+	_, objects, _ := send(o, request)
+	reply, err := m.Reply(objects, operate(4).Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	processed, response, err := send(o, app.NewRequestAPDU(app.FuncAuthRequest, 3, reply))
+	if !errors.Is(err, ErrAuthentication) || processed != nil || response == nil {
+		t.Errorf("Wrong MAC: got %v, % X, %v, want an error object", processed, response, err)
+	}
+}
+
+func TestRoleDenied(t *testing.T) {
+	m, o := newPair(t, RoleOperator, false)
+	if err := changeKeys(m, o); err != nil {
+		t.Fatal(err)
+	}
+
+	if processed, _, err := challenge(t, m, o, operate(0)); err != nil || processed == nil {
+		t.Errorf("Operator control: got %v, %v, want it processed", processed, err)
+	}
+
+	processed, response, err := challenge(t, m, o, app.BuildColdRestartRequest(1))
+	if !errors.Is(err, ErrAuthentication) || processed != nil {
+		t.Fatalf("Operator restart: got %v, %v, want ErrAuthentication", processed, err)
+	}
+	if err := m.Refused(response); err == nil || !bytes.Contains([]byte(err.Error()), []byte("AUTHORIZATION_FAILED")) {
+		t.Errorf("Refused: got %v, want AUTHORIZATION_FAILED", err)
+	}
+	if m.NeedsKeyChange() {
+		t.Error("Authorization failure discarded the session keys")
+	}
+}
+
+func TestKeyChangeWrongUpdateKey(t *testing.T) {
+	m, err := NewMaster(MasterConfig{UpdateKey: otherUpdateKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, o := newPair(t, RoleSingleUser, false)
+
+	if err := changeKeys(m, o); !errors.Is(err, ErrAuthentication) {
+		t.Fatalf("Key change with the wrong update key: got %v, want ErrAuthentication", err)
+	}
+	if !m.NeedsKeyChange() {
+		t.Error("Master uses keys the outstation refused")
+	}
+
+	// Without keys the master cannot answer a challenge
+	request := operate(0)
+	_, objects, _ := send(o, request)
+	if _, err := m.Reply(objects, request.Serialize()); !errors.Is(err, ErrAuthentication) {
+		t.Errorf("Reply without keys: got %v, want ErrAuthentication", err)
+	}
+}
+
+func TestKeyChangeReplay(t *testing.T) {
+	m, o := newPair(t, RoleSingleUser, false)
+
+	_, status, _ := send(o, app.NewRequestAPDU(app.FuncAuthRequest, 0, m.KeyStatusRequest()))
+	change, err := m.ChangeKeys(status)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, status, err = send(o, app.NewRequestAPDU(app.FuncAuthRequest, 1, change)); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ConfirmKeys(status); err != nil {
+		t.Fatal(err)
+	}
+
+	// The same keys again no longer answer the last key status
+	if _, _, err := send(o, app.NewRequestAPDU(app.FuncAuthRequest, 2, change)); !errors.Is(err, ErrAuthentication) {
+		t.Errorf("Replayed key change: got %v, want ErrAuthentication", err)
+	}
+}
+
+func TestUnknownUser(t *testing.T) {
+	m, err := NewMaster(MasterConfig{User: 7, UpdateKey: testUpdateKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, o := newPair(t, RoleSingleUser, false)
+
+	_, status, err := send(o, app.NewRequestAPDU(app.FuncAuthRequest, 0, m.KeyStatusRequest()))
+	if !errors.Is(err, ErrAuthentication) {
+		t.Fatalf("Unknown user: got %v, want ErrAuthentication", err)
+	}
+	if _, err := m.ChangeKeys(status); err == nil || !bytes.Contains([]byte(err.Error()), []byte("UNKNOWN_USER")) {
+		t.Errorf("ChangeKeys: got %v, want UNKNOWN_USER", err)
+	}
+}
+
+func TestTruncatedKeyStatusRequest(t *testing.T) {
+	m, o := newPair(t, RoleSingleUser, false)
+
+	// A free-format key status request too short to hold the user number
+	request := app.NewRequestAPDU(app.FuncAuthRequest, 0, []byte{app.GroupAuthentication, app.AuthKeyStatusRequest, byte(app.QualifierFreeFormat), 1, 0, 0})
+	_, status, err := send(o, request)
+	if !errors.Is(err, ErrAuthentication) {
+		t.Fatalf("Truncated request: got %v, want ErrAuthentication", err)
+	}
+	if _, err := m.ChangeKeys(status); err == nil || !bytes.Contains([]byte(err.Error()), []byte("AUTHENTICATION_FAILED")) {
+		t.Errorf("ChangeKeys: got %v, want AUTHENTICATION_FAILED", err)
+	}
+}
+
+func TestAggressiveMode(t *testing.T) {
+	m, o := newPair(t, RoleSingleUser, true)
+	if err := changeKeys(m, o); err != nil {
+		t.Fatal(err)
+	}
+
+	// Aggressive mode needs a challenge to start from
+	if m.Aggressive(operate(0)) != nil {
+		t.Fatal("Aggressive mode used before any challenge")
+	}
+	if _, _, err := challenge(t, m, o, operate(0)); err != nil {
+		t.Fatal(err)
+	}
+
+	for seq := uint8(1); seq <= 2; seq++ {
+		request := operate(seq)
+		aggressive := m.Aggressive(request)
+		if aggressive == nil {
+			t.Fatal("Aggressive mode not used after a challenge")
+		}
+		processed, response, err := send(o, aggressive)
+		if err != nil || response != nil || processed == nil {
+			t.Fatalf("Aggressive request %d: got %v, % X, %v", seq, processed, response, err)
+		}
+		if !bytes.Equal(processed.Objects, request.Objects) {
+			t.Errorf("Aggressive request %d: processed objects % X, want % X", seq, processed.Objects, request.Objects)
+		}
+
+		// Replaying it repeats a challenge sequence number
+		if processed, _, err := send(o, aggressive); !errors.Is(err, ErrAuthentication) || processed != nil {
+			t.Errorf("Replayed aggressive request %d: got %v, %v, want ErrAuthentication", seq, processed, err)
+		}
+	}
+
+	// A tampered request fails its MAC
+	aggressive := m.Aggressive(operate(3))
+	aggressive.Objects[len(aggressive.Objects)-1] ^= 1
+	if processed, _, err := send(o, aggressive); !errors.Is(err, ErrAuthentication) || processed != nil {
+		t.Errorf("Tampered aggressive request: got %v, %v, want ErrAuthentication", processed, err)
+	}
+}
+
+func TestAggressiveModeDisabled(t *testing.T) {
+	m, err := NewMaster(MasterConfig{UpdateKey: testUpdateKey, AggressiveMode: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, err := NewOutstation(OutstationConfig{
+		Users:                 []User{{Number: DefaultUser, UpdateKey: testUpdateKey, Role: RoleSingleUser}},
+		DisableAggressiveMode: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := changeKeys(m, o); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := challenge(t, m, o, operate(0)); err != nil {
+		t.Fatal(err)
+	}
+
+	_, response, err := send(o, m.Aggressive(operate(1)))
+	if !errors.Is(err, ErrAuthentication) {
+		t.Fatalf("Aggressive request: got %v, want ErrAuthentication", err)
+	}
+	m.Refused(response)
+	if m.Aggressive(operate(2)) != nil {
+		t.Error("Master kept using aggressive mode after the outstation refused it")
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	if _, err := NewMaster(MasterConfig{UpdateKey: make([]byte, 20)}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("20 octet update key: got %v, want ErrInvalidConfig", err)
+	}
+	if _, err := NewOutstation(OutstationConfig{}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("No users: got %v, want ErrInvalidConfig", err)
+	}
+	users := []User{{Number: 1, UpdateKey: testUpdateKey}, {Number: 1, UpdateKey: testUpdateKey}}
+	if _, err := NewOutstation(OutstationConfig{Users: users}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Duplicate user: got %v, want ErrInvalidConfig", err)
+	}
+}
+
+func TestRolePermits(t *testing.T) {
+	tests := []struct {
+		role    Role
+		fc      app.FunctionCode
+		permits bool
+	}{
+		{RoleOperator, app.FuncDirectOperate, true},
+		{RoleOperator, app.FuncWrite, false},
+		{RoleEngineer, app.FuncWrite, true},
+		{RoleEngineer, app.FuncColdRestart, false},
+		{RoleInstaller, app.FuncColdRestart, true},
+		{RoleViewer, app.FuncSelect, false},
+		{RoleSecAdm, app.FuncWrite, false},
+		{RoleSingleUser, app.FuncOpenFile, true},
+		{RoleSingleUser, app.FuncRead, false}, // Not critical, never challenged
+	}
+	for _, tt := range tests {
+		if got := tt.role.Permits(tt.fc); got != tt.permits {
+			t.Errorf("%s permits %s: got %v, want %v", tt.role, tt.fc, got, tt.permits)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package types
+
+// AttributeType is the data type code of a device attribute value
+type AttributeType uint8
+
+const (
+	AttributeVisibleString AttributeType = 1   // VSTR, Value is a string
+	AttributeUnsignedInt   AttributeType = 2   // UINT, Value is a uint32
+	AttributeSignedInt     AttributeType = 3   // INT, Value is an int32
+	AttributeFloat         AttributeType = 4   // FLT, Value is a float64
+	AttributeOctetString   AttributeType = 5   // OSTR, Value is a []byte
+	AttributeBitString     AttributeType = 6   // BSTR, Value is a []byte
+	AttributeTime          AttributeType = 7   // DNP3 time, Value is a DNP3Time
+	AttributeList          AttributeType = 254 // List of variations, Value is a []AttributeListEntry
+)
+
+// String returns the name of the attribute data type
+func (t AttributeType) String() string {
+	switch t {
+	case AttributeVisibleString:
+		return "VSTR"
+	case AttributeUnsignedInt:
+		return "UINT"
+	case AttributeSignedInt:
+		return "INT"
+	case AttributeFloat:
+		return "FLT"
+	case AttributeOctetString:
+		return "OSTR"
+	case AttributeBitString:
+		return "BSTR"
+	case AttributeTime:
+		return "TIME"
+	case AttributeList:
+		return "LIST"
+	default:
+		return "Unknown"
+	}
+}
+
+// DeviceAttribute is a device attribute (Group 0). Set 0 holds the standard
+// attributes, each identified by its variation; sets 1 and above are
+// user-defined. The Go type of Value follows Type.
+type DeviceAttribute struct {
+	Set       uint16
+	Variation uint8
+	Type      AttributeType
+	Value     interface{}
+}
+
+// AttributeListEntry is an entry of the list of attribute variations
+// supported by an attribute set (Group 0 variation 255)
+type AttributeListEntry struct {
+	Variation uint8
+	Writable  bool
+}
+
+// StringAttribute creates a visible string attribute
+func StringAttribute(set uint16, variation uint8, value string) DeviceAttribute {
+	return DeviceAttribute{Set: set, Variation: variation, Type: AttributeVisibleString, Value: value}
+}
+
+// UintAttribute creates an unsigned integer attribute
+func UintAttribute(set uint16, variation uint8, value uint32) DeviceAttribute {
+	return DeviceAttribute{Set: set, Variation: variation, Type: AttributeUnsignedInt, Value: value}
+}
+
+// IntAttribute creates a signed integer attribute
+func IntAttribute(set uint16, variation uint8, value int32) DeviceAttribute {
+	return DeviceAttribute{Set: set, Variation: variation, Type: AttributeSignedInt, Value: value}
+}
+
+// FloatAttribute creates a floating point attribute
+func FloatAttribute(set uint16, variation uint8, value float64) DeviceAttribute {
+	return DeviceAttribute{Set: set, Variation: variation, Type: AttributeFloat, Value: value}
+}
+
+// OctetStringAttribute creates an octet string attribute
+func OctetStringAttribute(set uint16, variation uint8, value []byte) DeviceAttribute {
+	return DeviceAttribute{Set: set, Variation: variation, Type: AttributeOctetString, Value: value}
+}
+
+// TimeAttribute creates a DNP3 time attribute
+func TimeAttribute(set uint16, variation uint8, value DNP3Time) DeviceAttribute {
+	return DeviceAttribute{Set: set, Variation: variation, Type: AttributeTime, Value: value}
+}
+
+// FindDeviceAttribute returns the attribute of a set and variation
+func FindDeviceAttribute(attributes []DeviceAttribute, set uint16, variation uint8) (DeviceAttribute, bool) {
+	for _, a := range attributes {
+		if a.Set == set && a.Variation == variation {
+			return a, true
+		}
+	}
+	return DeviceAttribute{}, false
+}
@@ -0,0 +1,10 @@
+package types
+
+// FileInfo describes a file or directory of the outstation
+type FileInfo struct {
+	Name        string
+	Directory   bool
+	Size        uint32 // Bytes of a file, entries of a directory
+	Created     DNP3Time
+	Permissions uint16 // Unix-style permission bits
+}
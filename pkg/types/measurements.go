@@ -2,9 +2,10 @@ package types
 
 // Binary represents a binary input (on/off) measurement
 type Binary struct {
-	Value bool
-	Flags Flags
-	Time  DNP3Time
+	Value       bool
+	Flags       Flags
+	Time        DNP3Time
+	TimeQuality TimeQuality
 }
 
 // GetFlags returns the quality flags for this measurement
@@ -29,9 +30,10 @@ const (
 
 // DoubleBitBinary represents a double-bit binary input measurement
 type DoubleBitBinary struct {
-	Value DoubleBitValue
-	Flags Flags
-	Time  DNP3Time
+	Value       DoubleBitValue
+	Flags       Flags
+	Time        DNP3Time
+	TimeQuality TimeQuality
 }
 
 // GetFlags returns the quality flags for this measurement
@@ -46,9 +48,10 @@ func (d DoubleBitBinary) GetTime() DNP3Time {
 
 // Analog represents an analog input measurement
 type Analog struct {
-	Value float64 // Always stored as float64 internally
-	Flags Flags
-	Time  DNP3Time
+	Value       float64 // Always stored as float64 internally
+	Flags       Flags
+	Time        DNP3Time
+	TimeQuality TimeQuality
 }
 
 // GetFlags returns the quality flags for this measurement
@@ -63,9 +66,10 @@ func (a Analog) GetTime() DNP3Time {
 
 // Counter represents a counter value
 type Counter struct {
-	Value uint32
-	Flags Flags
-	Time  DNP3Time
+	Value       uint32
+	Flags       Flags
+	Time        DNP3Time
+	TimeQuality TimeQuality
 }
 
 // GetFlags returns the quality flags for this measurement
@@ -80,9 +84,10 @@ func (c Counter) GetTime() DNP3Time {
 
 // FrozenCounter represents a frozen counter value
 type FrozenCounter struct {
-	Value uint32
-	Flags Flags
-	Time  DNP3Time
+	Value       uint32
+	Flags       Flags
+	Time        DNP3Time
+	TimeQuality TimeQuality
 }
 
 // GetFlags returns the quality flags for this measurement
@@ -97,9 +102,10 @@ func (f FrozenCounter) GetTime() DNP3Time {
 
 // BinaryOutputStatus represents the status of a binary output
 type BinaryOutputStatus struct {
-	Value bool
-	Flags Flags
-	Time  DNP3Time
+	Value       bool
+	Flags       Flags
+	Time        DNP3Time
+	TimeQuality TimeQuality
 }
 
 // GetFlags returns the quality flags for this measurement
@@ -114,9 +120,10 @@ func (b BinaryOutputStatus) GetTime() DNP3Time {
 
 // AnalogOutputStatus represents the status of an analog output
 type AnalogOutputStatus struct {
-	Value float64
-	Flags Flags
-	Time  DNP3Time
+	Value       float64
+	Flags       Flags
+	Time        DNP3Time
+	TimeQuality TimeQuality
 }
 
 // GetFlags returns the quality flags for this measurement
@@ -129,11 +136,14 @@ func (a AnalogOutputStatus) GetTime() DNP3Time {
 	return a.Time
 }
 
-// OctetString represents an arbitrary byte string
+// OctetString represents an arbitrary byte string of 1 to 255 bytes
 type OctetString struct {
 	Value []byte
 }
 
+// MaxOctetStringLength is the longest octet string DNP3 can carry
+const MaxOctetStringLength = 255
+
 // IntervalUnits represents the units for TimeAndInterval
 type IntervalUnits uint8
 
@@ -194,6 +204,12 @@ type IndexedFrozenCounter struct {
 	Value FrozenCounter
 }
 
+// IndexedDeadband is an analog input deadband with its index
+type IndexedDeadband struct {
+	Index uint16
+	Value float64
+}
+
 // IndexedBinaryOutputStatus is a binary output status with its index
 type IndexedBinaryOutputStatus struct {
 	Index uint16
@@ -205,3 +221,9 @@ type IndexedAnalogOutputStatus struct {
 	Index uint16
 	Value AnalogOutputStatus
 }
+
+// IndexedOctetString is an octet string with its index
+type IndexedOctetString struct {
+	Index uint16
+	Value OctetString
+}
@@ -14,6 +14,10 @@ const (
 	FlagOverRange    Flags = 0x20 // Value exceeds measurement range
 	FlagReferenceErr Flags = 0x40 // Reference error (e.g., ADC error)
 	FlagReserved     Flags = 0x80 // Reserved bit
+
+	// FlagChatterFilter is the binary input meaning of the OverRange bit:
+	// events are being suppressed because the point changes too often
+	FlagChatterFilter Flags = 0x20
 )
 
 // Quality flag helper methods
@@ -53,6 +57,11 @@ func (f Flags) IsOverRange() bool {
 	return f&FlagOverRange != 0
 }
 
+// IsChatterFiltered returns true if a binary input's chatter filter is active
+func (f Flags) IsChatterFiltered() bool {
+	return f&FlagChatterFilter != 0
+}
+
 // HasReferenceErr returns true if there's a reference error
 func (f Flags) HasReferenceErr() bool {
 	return f&FlagReferenceErr != 0
@@ -78,3 +87,11 @@ func (f Flags) WithRestart(restart bool) Flags {
 	}
 	return f &^ FlagRestart
 }
+
+// WithChatterFilter returns a copy of flags with chatter filter bit set
+func (f Flags) WithChatterFilter(active bool) Flags {
+	if active {
+		return f | FlagChatterFilter
+	}
+	return f &^ FlagChatterFilter
+}
@@ -39,6 +39,10 @@ func TestFlags_IndividualBits(t *testing.T) {
 		// ReferenceErr flag
 		{"ReferenceErr set", FlagReferenceErr, Flags.HasReferenceErr, true},
 		{"ReferenceErr not set", Flags(0x00), Flags.HasReferenceErr, false},
+
+		// ChatterFilter flag
+		{"ChatterFilter set", FlagChatterFilter, Flags.IsChatterFiltered, true},
+		{"ChatterFilter not set", Flags(0x00), Flags.IsChatterFiltered, false},
 	}
 
 	for _, tt := range tests {
@@ -204,6 +208,30 @@ func TestFlags_WithRestart(t *testing.T) {
 	}
 }
 
+// TestFlags_WithChatterFilter tests setting and clearing the chatter filter flag
+func TestFlags_WithChatterFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		initial Flags
+		active  bool
+		want    Flags
+	}{
+		{"Set chatter filter on online", FlagOnline, true, FlagOnline | FlagChatterFilter},
+		{"Clear chatter filter preserves other flags", FlagOnline | FlagChatterFilter | FlagLocalForced, false, FlagOnline | FlagLocalForced},
+		{"Clear chatter filter when already clear", FlagOnline, false, FlagOnline},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.initial.WithChatterFilter(tt.active)
+			if result != tt.want {
+				t.Errorf("WithChatterFilter(%v) = 0x%02X, want 0x%02X (initial=0x%02X)",
+					tt.active, result, tt.want, tt.initial)
+			}
+		})
+	}
+}
+
 // TestFlags_AllBitsUnique verifies all flag constants are unique
 func TestFlags_AllBitsUnique(t *testing.T) {
 	flags := []Flags{
@@ -267,6 +295,7 @@ func TestFlags_ConstantValues(t *testing.T) {
 		{"FlagOverRange", FlagOverRange, 0x20},
 		{"FlagReferenceErr", FlagReferenceErr, 0x40},
 		{"FlagReserved", FlagReserved, 0x80},
+		{"FlagChatterFilter", FlagChatterFilter, 0x20},
 	}
 
 	for _, tt := range tests {
@@ -30,3 +30,24 @@ func (t DNP3Time) IsValid() bool {
 func ZeroTime() DNP3Time {
 	return DNP3Time(0)
 }
+
+// TimeQuality indicates how far a timestamp can be trusted
+type TimeQuality uint8
+
+const (
+	TimeQualityUnknown        TimeQuality = 0 // Not stated by the source of the timestamp
+	TimeQualityUnsynchronized TimeQuality = 1 // Taken from a clock not synchronized since startup or within the resync interval
+	TimeQualitySynchronized   TimeQuality = 2 // Taken from a synchronized clock
+)
+
+// String returns the time quality name
+func (q TimeQuality) String() string {
+	switch q {
+	case TimeQualityUnsynchronized:
+		return "Unsynchronized"
+	case TimeQualitySynchronized:
+		return "Synchronized"
+	default:
+		return "Unknown"
+	}
+}